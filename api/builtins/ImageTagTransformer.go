@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strings"
 
+	"sigs.k8s.io/kustomize/api/image"
 	"sigs.k8s.io/kustomize/api/transform"
 	"sigs.k8s.io/kustomize/api/types"
 
@@ -67,6 +68,16 @@ func (p *ImageTagTransformerPlugin) mutateImage(in interface{}) (interface{}, er
 	}
 	if p.ImageTag.Digest != "" {
 		tag = "@" + p.ImageTag.Digest
+	} else if p.ImageTag.ResolveDigest && !strings.HasPrefix(tag, "@") {
+		t := strings.TrimPrefix(tag, ":")
+		if t == "" {
+			t = "latest"
+		}
+		digest, err := image.DefaultDigestResolver.Resolve(name, t)
+		if err != nil {
+			return nil, err
+		}
+		tag = "@" + digest
 	}
 	return name + tag, nil
 }
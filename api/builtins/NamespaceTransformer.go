@@ -25,12 +25,21 @@ type NamespaceTransformerPlugin struct {
 	// kunstruct transformer.
 	// TODO: change the default to use kyaml when it is stable
 	YAMLSupport bool `json:"yamlSupport,omitempty" yaml:"yamlSupport,omitempty"`
+
+	// UnsetOnly if true only sets the namespace of the resources
+	// without a namespace. See types.NamespaceOptions.
+	UnsetOnly bool `json:"unsetOnly,omitempty" yaml:"unsetOnly,omitempty"`
+
+	// Exclude selects the resources which aren't changed.
+	Exclude []types.Selector `json:"exclude,omitempty" yaml:"exclude,omitempty"`
 }
 
 func (p *NamespaceTransformerPlugin) Config(
 	_ *resmap.PluginHelpers, c []byte) (err error) {
 	p.Namespace = ""
 	p.FieldSpecs = nil
+	p.UnsetOnly = false
+	p.Exclude = nil
 	return yaml.Unmarshal(c, p)
 }
 
@@ -38,11 +47,24 @@ func (p *NamespaceTransformerPlugin) Transform(m resmap.ResMap) error {
 	if len(p.Namespace) == 0 {
 		return nil
 	}
+	excluded := map[*resource.Resource]bool{}
+	for _, s := range p.Exclude {
+		resources, err := m.Select(s)
+		if err != nil {
+			return err
+		}
+		for _, r := range resources {
+			excluded[r] = true
+		}
+	}
 	for _, r := range m.Resources() {
 		if len(r.Map()) == 0 {
 			// Don't mutate empty objects?
 			continue
 		}
+		if excluded[r] || (p.UnsetOnly && r.GetNamespace() != "") {
+			continue
+		}
 
 		id := r.OrgId()
 
@@ -63,6 +85,7 @@ func (p *NamespaceTransformerPlugin) Transform(m resmap.ResMap) error {
 			err := filtersutil.ApplyToJSON(namespace.Filter{
 				Namespace: p.Namespace,
 				FsSlice:   p.FieldSpecs,
+				UnsetOnly: p.UnsetOnly,
 			}, r.Kunstructured)
 			if err != nil {
 				return err
@@ -122,6 +145,14 @@ func (p *NamespaceTransformerPlugin) changeNamespace(
 					if name != "default" {
 						continue
 					}
+					if p.UnsetOnly {
+						// only move the subjects pointing at the
+						// default namespace
+						ns, _ := inMap["namespace"].(string)
+						if ns != "" && ns != "default" {
+							continue
+						}
+					}
 					inMap["namespace"] = p.Namespace
 					l[idx] = inMap
 				default:
@@ -4,6 +4,8 @@
 package builtins
 
 import (
+	"fmt"
+
 	"sigs.k8s.io/kustomize/api/kv"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
@@ -32,6 +34,11 @@ func (p *SecretGeneratorPlugin) Config(h *resmap.PluginHelpers, config []byte) (
 }
 
 func (p *SecretGeneratorPlugin) Generate() (resmap.ResMap, error) {
+	if len(p.CommandSources) > 0 && !p.h.ExecEnabled() {
+		return nil, fmt.Errorf(
+			"secret %s has commands, which only run if exec is enabled "+
+				"(kustomize build --enable_exec)", p.SecretArgs.Name)
+	}
 	return p.h.ResmapFactory().FromSecretArgs(
 		kv.NewLoader(p.h.Loader(), p.h.Validator()),
 		&p.GeneratorOptions, p.SecretArgs)
@@ -16,6 +16,11 @@ type Filter struct {
 
 	// FsSlice contains the FieldSpecs to locate the namespace field
 	FsSlice types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// UnsetOnly if true only applies the Namespace to the inputs which
+	// don't have a namespace, and only to the RoleBinding and
+	// ClusterRoleBinding subjects in the default namespace.
+	UnsetOnly bool `yaml:"unsetOnly,omitempty"`
 }
 
 var _ kio.Filter = Filter{}
@@ -26,6 +31,17 @@ func (ns Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 
 // Run runs the filter on a single node rather than a slice
 func (ns Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	if ns.UnsetOnly {
+		meta, err := node.GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		if meta.Namespace != "" {
+			// keep the namespace of the input
+			return node, nil
+		}
+	}
+
 	// hacks for hardcoded types -- :(
 	if err := ns.hacks(node); err != nil {
 		return nil, err
@@ -125,6 +141,16 @@ func (ns Filter) roleBindingHack(obj *yaml.RNode, meta yaml.ResourceMeta) error
 		if err != nil || yaml.IsEmpty(name) {
 			return err
 		}
+		if ns.UnsetOnly {
+			// only move the subjects pointing at the default namespace
+			namespace, err := o.Pipe(yaml.Lookup("namespace"))
+			if err != nil {
+				return err
+			}
+			if v := yaml.GetValue(namespace); v != "" && v != "default" {
+				return nil
+			}
+		}
 
 		// set the namespace for the default account
 		v := yaml.NewScalarRNode(ns.Namespace)
@@ -212,6 +212,87 @@ subjects:
 		filter: namespace.Filter{Namespace: "bar"},
 	},
 
+	{
+		// only the inputs without a namespace are changed, and only their
+		// subjects in the default namespace
+		name: "unset-only",
+		input: `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: unset
+---
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: set
+  namespace: bar
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: cluster-scoped
+---
+apiVersion: example.com/v1
+kind: RoleBinding
+metadata:
+  name: set
+  namespace: bar
+subjects:
+- name: default
+---
+apiVersion: example.com/v1
+kind: ClusterRoleBinding
+metadata:
+  name: unset
+subjects:
+- name: default
+- name: default
+  namespace: default
+- name: default
+  namespace: other
+`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: unset
+  namespace: foo
+---
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: set
+  namespace: bar
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: cluster-scoped
+---
+apiVersion: example.com/v1
+kind: RoleBinding
+metadata:
+  name: set
+  namespace: bar
+subjects:
+- name: default
+---
+apiVersion: example.com/v1
+kind: ClusterRoleBinding
+metadata:
+  name: unset
+subjects:
+- name: default
+  namespace: foo
+- name: default
+  namespace: foo
+- name: default
+  namespace: other
+`,
+		filter: namespace.Filter{Namespace: "foo", UnsetOnly: true},
+	},
+
 	{
 		name: "data-fieldspecs",
 		input: `
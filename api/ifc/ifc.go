@@ -24,6 +24,9 @@ type Validator interface {
 type KvLoader interface {
 	Validator() Validator
 	Load(args types.KvPairSources) (all []types.Pair, err error)
+	// LoadCommands returns the pairs whose values are the
+	// outputs of the commands of the sources, by key.
+	LoadCommands(sources map[string]string, opts *types.CommandOptions) ([]types.Pair, error)
 }
 
 // Loader interface exposes methods to read bytes.
@@ -34,6 +37,12 @@ type Loader interface {
 	New(newRoot string) (Loader, error)
 	// Load returns the bytes read from the location or an error.
 	Load(location string) ([]byte, error)
+	// IsDir returns true if the location is a local directory.
+	IsDir(location string) bool
+	// ListFiles returns the paths of the regular files in the
+	// directory at location, relative to it and in lexical order,
+	// including the files of its subdirectories if recursive.
+	ListFiles(location string, recursive bool) ([]string, error)
 	// Cleanup cleans the loader
 	Cleanup() error
 }
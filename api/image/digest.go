@@ -0,0 +1,313 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// OfflineEnv is the environment variable which, if set to "true",
+// disables the digest lookups: resolving a digest is then an error.
+const OfflineEnv = "KUSTOMIZE_OFFLINE"
+
+// manifestMediaTypes are the media types of the manifests whose
+// digest is resolved, the lists first so that multi-arch images
+// are pinned to the list rather than to one of its platforms.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// DigestResolver resolves the tags of images to the digests of
+// their manifests, by querying the registries of the images.
+// The digests are cached, so each tag is only queried once.
+type DigestResolver struct {
+	// Client is the client of the registries.
+	// Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// Offline disables the lookups, and makes Resolve fail.
+	Offline bool
+
+	// DockerConfig is the docker config file with the credentials
+	// of the registries. Defaults to config.json in $DOCKER_CONFIG,
+	// or else in ~/.docker.
+	DockerConfig string
+
+	mu     sync.Mutex
+	cache  map[string]string
+	config *dockerConfig
+}
+
+// DefaultDigestResolver is the resolver used by the builtin
+// ImageTagTransformer. It lives as long as the process, so the
+// digests are cached for the length of a `kustomize build`.
+var DefaultDigestResolver = &DigestResolver{
+	Offline: os.Getenv(OfflineEnv) == "true",
+}
+
+// Resolve returns the digest of the manifest of the image name
+// with the given tag, e.g. `sha256:24a0c4b4...`.
+func (r *DigestResolver) Resolve(name, tag string) (string, error) {
+	ref := name + ":" + tag
+	if r.Offline {
+		return "", fmt.Errorf(
+			"cannot resolve the digest of %s: offline (%s=true)", ref, OfflineEnv)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if digest, found := r.cache[ref]; found {
+		return digest, nil
+	}
+	digest, err := r.fetch(name, tag)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve the digest of %s: %v", ref, err)
+	}
+	if r.cache == nil {
+		r.cache = map[string]string{}
+	}
+	r.cache[ref] = digest
+	return digest, nil
+}
+
+// fetch queries the registry of the image for the digest of the
+// manifest with the tag, authenticating if the registry asks to.
+func (r *DigestResolver) fetch(name, tag string) (string, error) {
+	host, repo := splitRegistry(name)
+	u := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repo, tag)
+	resp, body, err := r.get(u, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		auth, err := r.authorization(host, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", err
+		}
+		resp, body, err = r.get(u, auth)
+		if err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", responseError(u, resp, body)
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(body)), nil
+}
+
+// get gets the url with the Authorization header auth, if any.
+func (r *DigestResolver) get(u, auth string) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// authorization returns the Authorization header answering the
+// WWW-Authenticate challenge of the registry at host, with the
+// credentials of the docker config.
+func (r *DigestResolver) authorization(host, challenge string) (string, error) {
+	basic, err := r.credentials(host)
+	if err != nil {
+		return "", err
+	}
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if basic == "" {
+			return "", fmt.Errorf("no credentials for %s in the docker config", host)
+		}
+		return "Basic " + basic, nil
+	case "bearer":
+		return r.token(params, basic)
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q of %s", challenge, host)
+	}
+}
+
+// token gets a token from the realm of the bearer challenge params,
+// anonymously if there aren't any basic credentials.
+func (r *DigestResolver) token(params map[string]string, basic string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid authentication realm %q", params["realm"])
+	}
+	q := realm.Query()
+	for _, k := range []string{"service", "scope"} {
+		if params[k] != "" {
+			q.Set(k, params[k])
+		}
+	}
+	realm.RawQuery = q.Encode()
+	auth := ""
+	if basic != "" {
+		auth = "Basic " + basic
+	}
+	resp, body, err := r.get(realm.String(), auth)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", responseError(realm.String(), resp, body)
+	}
+	var t struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &t); err != nil {
+		return "", fmt.Errorf("invalid token from %s: %v", realm, err)
+	}
+	if t.Token == "" {
+		t.Token = t.AccessToken
+	}
+	return "Bearer " + t.Token, nil
+}
+
+// responseError returns an error with the status and body of resp.
+func responseError(u string, resp *http.Response, body []byte) error {
+	return fmt.Errorf("GET %s: %s: %s",
+		u, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// parseChallenge returns the lowercased scheme and the parameters
+// of a WWW-Authenticate challenge, e.g.
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`.
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) == 2 {
+		for _, p := range strings.Split(parts[1], ",") {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) == 2 {
+				params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+			}
+		}
+	}
+	return strings.ToLower(parts[0]), params
+}
+
+// splitRegistry returns the registry host and the repository of
+// the image name, e.g. `registry-1.docker.io` and `library/nginx`
+// for `nginx`.
+func splitRegistry(name string) (host, repo string) {
+	i := strings.Index(name, "/")
+	if i > 0 {
+		domain := name[:i]
+		if strings.ContainsAny(domain, ".:") || domain == "localhost" {
+			host, repo = domain, name[i+1:]
+		}
+	}
+	if host == "" {
+		host, repo = "docker.io", name
+	}
+	if host == "docker.io" || host == "index.docker.io" {
+		host = "registry-1.docker.io"
+		if !strings.Contains(repo, "/") {
+			repo = "library/" + repo
+		}
+	}
+	return host, repo
+}
+
+// dockerConfig is the part of a docker config file with the
+// credentials of the registries.
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+}
+
+// credentials returns the base64 encoded `username:password` of
+// the registry at host in the docker config, or "" if there are
+// none.
+func (r *DigestResolver) credentials(host string) (string, error) {
+	if r.config == nil {
+		c, err := r.readDockerConfig()
+		if err != nil {
+			return "", err
+		}
+		r.config = c
+	}
+	keys := []string{host, "https://" + host, "http://" + host}
+	if host == "registry-1.docker.io" {
+		keys = append(keys, "https://index.docker.io/v1/", "index.docker.io", "docker.io")
+	}
+	for _, k := range keys {
+		a, found := r.config.Auths[k]
+		if !found {
+			continue
+		}
+		if a.Auth != "" {
+			return a.Auth, nil
+		}
+		if a.Username != "" {
+			return base64.StdEncoding.EncodeToString(
+				[]byte(a.Username + ":" + a.Password)), nil
+		}
+	}
+	return "", nil
+}
+
+// readDockerConfig reads the docker config, if there is one.
+func (r *DigestResolver) readDockerConfig() (*dockerConfig, error) {
+	path := r.DockerConfig
+	if path == "" {
+		dir := os.Getenv("DOCKER_CONFIG")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return &dockerConfig{}, nil
+			}
+			dir = filepath.Join(home, ".docker")
+		}
+		path = filepath.Join(dir, "config.json")
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &dockerConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &dockerConfig{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("invalid docker config %s: %v", path, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeDigest = "sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3"
+
+// makeFakeRegistry returns a registry with the tag v1 of app, which
+// requires a token granted for the credentials user:pass.
+func makeFakeRegistry(t *testing.T, requests *int) *httptest.Server {
+	var s *httptest.Server
+	s = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"errors":[{"code":"UNAUTHORIZED"}]}`)
+				return
+			}
+			assert.Equal(t, "repository:app:pull", r.URL.Query().Get("scope"))
+			fmt.Fprint(w, `{"token":"secret"}`)
+		case "/v2/app/manifests/v1", "/v2/app/manifests/v2":
+			*requests++
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+					`Bearer realm="%s/token",service="fake",scope="repository:app:pull"`, s.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if strings.HasSuffix(r.URL.Path, "v2") {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", fakeDigest)
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return s
+}
+
+func writeDockerConfig(t *testing.T, host string) string {
+	dir, err := ioutil.TempDir("", "docker-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(path, []byte(fmt.Sprintf(
+		`{"auths":{"%s":{"auth":"dXNlcjpwYXNz"}}}`, host)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDigestResolver(t *testing.T) {
+	var requests int
+	s := makeFakeRegistry(t, &requests)
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "https://")
+	config := writeDockerConfig(t, host)
+	defer os.RemoveAll(filepath.Dir(config))
+
+	r := &DigestResolver{Client: s.Client(), DockerConfig: config}
+	digest, err := r.Resolve(host+"/app", "v1")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, fakeDigest, digest)
+	// the second lookup is cached
+	digest, err = r.Resolve(host+"/app", "v1")
+	assert.NoError(t, err)
+	assert.Equal(t, fakeDigest, digest)
+	assert.Equal(t, 2, requests)
+
+	_, err = r.Resolve(host+"/app", "v2")
+	assert.EqualError(t, err, fmt.Sprintf("cannot resolve the digest of %s/app:v2: "+
+		"GET %s/v2/app/manifests/v2: 404 Not Found: "+
+		`{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`, host, s.URL))
+}
+
+func TestDigestResolverNoCredentials(t *testing.T) {
+	var requests int
+	s := makeFakeRegistry(t, &requests)
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "https://")
+	config := writeDockerConfig(t, "example.com")
+	defer os.RemoveAll(filepath.Dir(config))
+
+	r := &DigestResolver{Client: s.Client(), DockerConfig: config}
+	_, err := r.Resolve(host+"/app", "v1")
+	assert.EqualError(t, err, fmt.Sprintf("cannot resolve the digest of %s/app:v1: "+
+		"GET %s/token?scope=repository%%3Aapp%%3Apull&service=fake: 401 Unauthorized: "+
+		`{"errors":[{"code":"UNAUTHORIZED"}]}`, host, s.URL))
+}
+
+func TestDigestResolverOffline(t *testing.T) {
+	r := &DigestResolver{Offline: true}
+	_, err := r.Resolve("gcr.io/app/api", "v1.2.3")
+	assert.EqualError(t, err,
+		"cannot resolve the digest of gcr.io/app/api:v1.2.3: offline (KUSTOMIZE_OFFLINE=true)")
+}
+
+func TestSplitRegistry(t *testing.T) {
+	for in, expected := range map[string][2]string{
+		"nginx":                {"registry-1.docker.io", "library/nginx"},
+		"bitnami/redis":        {"registry-1.docker.io", "bitnami/redis"},
+		"docker.io/nginx":      {"registry-1.docker.io", "library/nginx"},
+		"gcr.io/app/api":       {"gcr.io", "app/api"},
+		"localhost:5000/app":   {"localhost:5000", "app"},
+		"localhost/app/worker": {"localhost", "app/worker"},
+	} {
+		host, repo := splitRegistry(in)
+		assert.Equal(t, expected, [2]string{host, repo}, in)
+	}
+}
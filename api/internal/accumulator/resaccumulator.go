@@ -21,6 +21,8 @@ type ResAccumulator struct {
 	resMap  resmap.ResMap
 	tConfig *builtinconfig.TransformerConfig
 	varSet  types.VarSet
+	// replacements are applied once the names are final.
+	replacements []types.Replacement
 }
 
 func MakeEmptyAccumulator() *ResAccumulator {
@@ -41,6 +43,11 @@ func (ra *ResAccumulator) Vars() []types.Var {
 	return ra.varSet.AsSlice()
 }
 
+// Replacements returns a copy of underlying replacements.
+func (ra *ResAccumulator) Replacements() []types.Replacement {
+	return append([]types.Replacement(nil), ra.replacements...)
+}
+
 func (ra *ResAccumulator) AppendAll(
 	resources resmap.ResMap) error {
 	return ra.resMap.AppendAll(resources)
@@ -84,6 +91,12 @@ func (ra *ResAccumulator) MergeVars(incoming []types.Var) error {
 	return ra.varSet.MergeSlice(incoming)
 }
 
+// MergeReplacements appends the incoming replacements, which are
+// applied after those already accumulated.
+func (ra *ResAccumulator) MergeReplacements(incoming []types.Replacement) {
+	ra.replacements = append(ra.replacements, incoming...)
+}
+
 func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
 	err = ra.AppendAll(other.resMap)
 	if err != nil {
@@ -93,6 +106,7 @@ func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
 	if err != nil {
 		return err
 	}
+	ra.MergeReplacements(other.replacements)
 	return ra.varSet.MergeSet(other.varSet)
 }
 
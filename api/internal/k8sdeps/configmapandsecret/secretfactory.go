@@ -32,6 +32,11 @@ func (f *Factory) MakeSecret(
 	if err != nil {
 		return nil, err
 	}
+	pairs, err := f.kvLdr.LoadCommands(args.CommandSources, args.CommandOptions)
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, pairs...)
 	s := makeFreshSecret(args)
 	for _, p := range all {
 		err = f.addKvToSecret(s, p.Key, p.Value)
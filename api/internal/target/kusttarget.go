@@ -72,6 +72,11 @@ func (kt *KustTarget) Load() error {
 	return nil
 }
 
+// SortOptions returns the sortOptions of the kustomization, if any.
+func (kt *KustTarget) SortOptions() *types.SortOptions {
+	return kt.kustomization.SortOptions
+}
+
 func loadKustFile(ldr ifc.Loader) ([]byte, error) {
 	var content []byte
 	match := 0
@@ -141,6 +146,13 @@ func (kt *KustTarget) makeCustomizedResMap(
 		return nil, err
 	}
 
+	// Replacements copy the final names and values, so they
+	// are applied once the names are hashed and the vars resolved.
+	err = kt.runReplacements(ra)
+	if err != nil {
+		return nil, err
+	}
+
 	err = kt.computeInventory(ra, garbagePolicy)
 	if err != nil {
 		return nil, err
@@ -159,6 +171,24 @@ func (kt *KustTarget) addHashesToNames(
 	return ra.Transform(p)
 }
 
+func (kt *KustTarget) runReplacements(
+	ra *accumulator.ResAccumulator) error {
+	replacements := ra.Replacements()
+	if len(replacements) == 0 {
+		return nil
+	}
+	var c struct {
+		Replacements []types.Replacement
+	}
+	c.Replacements = replacements
+	p := builtins.NewReplacementTransformerPlugin()
+	err := kt.configureBuiltinPlugin(p, c, builtinhelpers.ReplacementTransformer)
+	if err != nil {
+		return err
+	}
+	return ra.Transform(p)
+}
+
 func (kt *KustTarget) computeInventory(
 	ra *accumulator.ResAccumulator, garbagePolicy types.GarbagePolicy) error {
 	inv := kt.kustomization.Inventory
@@ -232,6 +262,7 @@ func (kt *KustTarget) AccumulateTarget() (
 		return nil, errors.Wrapf(
 			err, "merging vars %v", kt.kustomization.Vars)
 	}
+	ra.MergeReplacements(kt.kustomization.Replacements)
 	return ra, nil
 }
 
@@ -362,7 +393,8 @@ func (kt *KustTarget) configureBuiltinPlugin(
 				err, "builtin %s marshal", bpt)
 		}
 	}
-	err = p.Config(resmap.NewPluginHelpers(kt.ldr, kt.validator, kt.rFactory), y)
+	err = p.Config(resmap.NewPluginHelpers(
+		kt.ldr, kt.validator, kt.rFactory, kt.pLdr.Config()), y)
 	if err != nil {
 		return errors.Wrapf(err, "builtin %s config: %v", bpt, y)
 	}
@@ -4,8 +4,11 @@
 package target
 
 import (
+	"fmt"
+
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
 )
@@ -127,9 +130,15 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		var c struct {
 			types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 			FieldSpecs       []types.FieldSpec
+			UnsetOnly        bool             `json:"unsetOnly,omitempty" yaml:"unsetOnly,omitempty"`
+			Exclude          []types.Selector `json:"exclude,omitempty" yaml:"exclude,omitempty"`
 		}
 		c.Namespace = kt.kustomization.Namespace
 		c.FieldSpecs = tc.NameSpace
+		if opts := kt.kustomization.NamespaceOptions; opts != nil {
+			c.UnsetOnly = opts.UnsetOnly
+			c.Exclude = opts.Exclude
+		}
 		p := f()
 		err = kt.configureBuiltinPlugin(p, c, bpt)
 		if err != nil {
@@ -186,14 +195,16 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			return
 		}
 		var c struct {
-			Path   string          `json:"path,omitempty" yaml:"path,omitempty"`
-			Patch  string          `json:"patch,omitempty" yaml:"patch,omitempty"`
-			Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+			Path    string          `json:"path,omitempty" yaml:"path,omitempty"`
+			Patch   string          `json:"patch,omitempty" yaml:"patch,omitempty"`
+			Target  *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+			Options map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 		}
 		for _, pc := range kt.kustomization.Patches {
 			c.Target = pc.Target
 			c.Patch = pc.Patch
 			c.Path = pc.Path
+			c.Options = pc.Options
 			p := f()
 			err = kt.configureBuiltinPlugin(p, c, bpt)
 			if err != nil {
@@ -218,6 +229,19 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			return nil, err
 		}
 		result = append(result, p)
+		for _, label := range kt.kustomization.Labels {
+			c.Labels = label.Pairs
+			c.FieldSpecs, err = labelFieldSpecs(label, tc)
+			if err != nil {
+				return nil, err
+			}
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
 		return
 	},
 	builtinhelpers.AnnotationsTransformer: func(
@@ -244,10 +268,19 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			Prefix     string
 			Suffix     string
 			FieldSpecs []types.FieldSpec
+			Include    []resid.Gvk
+			Exclude    []resid.Gvk
 		}
 		c.Prefix = kt.kustomization.NamePrefix
 		c.Suffix = kt.kustomization.NameSuffix
 		c.FieldSpecs = tc.NamePrefix
+		if nt := kt.kustomization.NameTransformations; nt != nil {
+			// Load rejects a prefix or suffix set twice.
+			c.Prefix += nt.Prefix
+			c.Suffix += nt.Suffix
+			c.Include = nt.Include
+			c.Exclude = nt.Exclude
+		}
 		p := f()
 		err = kt.configureBuiltinPlugin(p, c, bpt)
 		if err != nil {
@@ -295,3 +328,27 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return
 	},
 }
+
+// labelFieldSpecs returns the fields of an entry of the labels
+// field: its own fields and metadata/labels, and either all of the
+// fields of the commonLabels if it includes the selectors, or else
+// only the pod templates if it includes the templates.
+func labelFieldSpecs(
+	label types.Label, tc *builtinconfig.TransformerConfig) (types.FsSlice, error) {
+	fss := append(types.FsSlice{}, label.FieldSpecs...)
+	fss, err := fss.MergeOne(
+		types.FieldSpec{Path: "metadata/labels", CreateIfNotPresent: true})
+	if err != nil {
+		return nil, fmt.Errorf("labels: %v", err)
+	}
+	switch {
+	case label.IncludeSelectors:
+		fss, err = fss.MergeAll(tc.CommonLabels)
+	case label.IncludeTemplates:
+		fss, err = fss.MergeAll(tc.TemplateLabels)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("labels: %v", err)
+	}
+	return fss, nil
+}
@@ -12,6 +12,7 @@ func GetDefaultFieldSpecs() []byte {
 	configData := [][]byte{
 		[]byte(namePrefixFieldSpecs),
 		[]byte(commonLabelFieldSpecs),
+		[]byte(templateLabelFieldSpecs),
 		[]byte(commonAnnotationFieldSpecs),
 		[]byte(namespaceFieldSpecs),
 		[]byte(varReferenceFieldSpecs),
@@ -28,6 +29,7 @@ func GetDefaultFieldSpecsAsMap() map[string]string {
 	result := make(map[string]string)
 	result["nameprefix"] = namePrefixFieldSpecs
 	result["commonlabels"] = commonLabelFieldSpecs
+	result["templatelabels"] = templateLabelFieldSpecs
 	result["commonannotations"] = commonAnnotationFieldSpecs
 	result["namespace"] = namespaceFieldSpecs
 	result["varreference"] = varReferenceFieldSpecs
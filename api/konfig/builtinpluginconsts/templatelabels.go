@@ -0,0 +1,52 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package builtinpluginconsts
+
+// templateLabelFieldSpecs are the pod template labels of the
+// commonLabelFieldSpecs, i.e. the ones which aren't selectors.
+const templateLabelFieldSpecs = `
+templateLabels:` + templateLabelPaths
+
+// templateLabelPaths are the pod template labels, which are part of both
+// the commonLabelFieldSpecs and the templateLabelFieldSpecs.  The labels of
+// the volumeClaimTemplates of the StatefulSets are left out, since they are
+// immutable.
+const templateLabelPaths = `
+- path: spec/template/metadata/labels
+  create: true
+  version: v1
+  kind: ReplicationController
+
+- path: spec/template/metadata/labels
+  create: true
+  kind: Deployment
+
+- path: spec/template/metadata/labels
+  create: true
+  kind: ReplicaSet
+
+- path: spec/template/metadata/labels
+  create: true
+  kind: DaemonSet
+
+- path: spec/template/metadata/labels
+  create: true
+  group: apps
+  kind: StatefulSet
+
+- path: spec/template/metadata/labels
+  create: true
+  group: batch
+  kind: Job
+
+- path: spec/jobTemplate/metadata/labels
+  create: true
+  group: batch
+  kind: CronJob
+
+- path: spec/jobTemplate/spec/template/metadata/labels
+  create: true
+  group: batch
+  kind: CronJob
+`
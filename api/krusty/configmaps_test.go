@@ -4,8 +4,12 @@
 package krusty_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"sigs.k8s.io/kustomize/api/filesys"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
 )
 
@@ -160,6 +164,136 @@ metadata:
 `)
 }
 
+func TestGeneratorFromDirectory(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+configMapGenerator:
+- name: migrations
+  files:
+  - sql
+  fileOptions:
+    recursive: true
+    keySeparator: .
+secretGenerator:
+- name: certs
+  files:
+  - certs
+`)
+	th.WriteF("/app/sql/001-init.sql", "CREATE TABLE a;\n")
+	th.WriteF("/app/sql/002-users.sql", "CREATE TABLE b;\n")
+	th.WriteF("/app/sql/.notes", "not a migration\n")
+	th.WriteF("/app/sql/v2/001-orders.sql", "CREATE TABLE c;\n")
+	th.WriteF("/app/certs/tls.crt", "crt")
+	th.WriteF("/app/certs/tls.key", "key")
+	th.WriteF("/app/certs/old/tls.crt", "old")
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  001-init.sql: |
+    CREATE TABLE a;
+  002-users.sql: |
+    CREATE TABLE b;
+  v2.001-orders.sql: |
+    CREATE TABLE c;
+kind: ConfigMap
+metadata:
+  name: migrations-87gkhhtbbt
+---
+apiVersion: v1
+data:
+  tls.crt: Y3J0
+  tls.key: a2V5
+kind: Secret
+metadata:
+  name: certs-h7tkft2kb2
+type: Opaque
+`)
+}
+
+func TestGeneratorFromDirectoryKeyCollision(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+configMapGenerator:
+- name: migrations
+  files:
+  - sql
+  fileOptions:
+    recursive: true
+`)
+	th.WriteF("/app/sql/v2_001.sql", "CREATE TABLE a;\n")
+	th.WriteF("/app/sql/v2/001.sql", "CREATE TABLE b;\n")
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"files sql/v2/001.sql and sql/v2_001.sql have the same key v2_001.sql") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+// The hash of the secret changes with the output of its
+// commands, so that the rotation of a secret propagates.
+func TestSecretGeneratorCommands(t *testing.T) {
+	dir := makeTmpDir(t)
+	defer os.RemoveAll(dir)
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	th.WriteK(dir, `
+secretGenerator:
+- name: db
+  commands:
+    password: cat password.txt
+    user: printf admin
+`)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.EnableExec = true
+
+	th.WriteF(filepath.Join(dir, "password.txt"), "first")
+	m := th.Run(dir, opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  password: Zmlyc3Q=
+  user: YWRtaW4=
+kind: Secret
+metadata:
+  name: db-27d624g7c6
+type: Opaque
+`)
+
+	th.WriteF(filepath.Join(dir, "password.txt"), "second")
+	m = th.Run(dir, opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  password: c2Vjb25k
+  user: YWRtaW4=
+kind: Secret
+metadata:
+  name: db-55d4ff8k25
+type: Opaque
+`)
+}
+
+func TestSecretGeneratorCommandsDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+secretGenerator:
+- name: db
+  commands:
+    password: vault kv get -field=pw secret/app
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"secret db has commands, which only run if exec is enabled") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
 func TestGeneratorOverlays(t *testing.T) {
 	th := kusttest_test.MakeHarness(t)
 	th.WriteK("/app/base1", `
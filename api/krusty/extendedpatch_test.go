@@ -4,6 +4,7 @@
 package krusty_test
 
 import (
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -812,6 +813,8 @@ patches:
 - path: patch.yaml
   target:
     name: no-match
+  options:
+    allowNoTargetMatch: true
 `)
 	th.WriteF("/app/base/patch.yaml", `
 apiVersion: apps/v1beta2
@@ -1008,10 +1011,14 @@ patches:
 - path: patch.yaml
   target:
     name: no-match
+  options:
+    allowNoTargetMatch: true
 - path: patch.yaml
   target:
     name: busybox
     kind: Job
+  options:
+    allowNoTargetMatch: true
 `)
 	th.WriteF("/app/base/patch.yaml", `
 apiVersion: apps/v1beta2
@@ -1213,3 +1220,162 @@ spec:
     app: busybox
 `)
 }
+
+func makeBatchFilesForExtendedPatchTest(th kusttest_test.Harness) {
+	th.WriteF("/app/base/workloads.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  labels:
+    tier: batch
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: queue
+  labels:
+    tier: batch
+  annotations:
+    scaling: manual
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+  annotations:
+    scaling: manual
+spec:
+  replicas: 1
+`)
+}
+
+func TestExtendedPatchLabelAndAnnotationSelectors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	makeBatchFilesForExtendedPatchTest(th)
+	th.WriteK("/app/base", `
+resources:
+- workloads.yaml
+patches:
+- target:
+    labelSelector: tier=batch
+  patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: ignored
+    spec:
+      template:
+        spec:
+          tolerations:
+          - key: batch
+            effect: NoSchedule
+- target:
+    name: queue|web
+    annotationSelector: scaling=manual
+    labelSelector: tier in (batch, web)
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 3
+`)
+	m := th.Run("/app/base", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: batch
+  name: worker
+spec:
+  replicas: 1
+  template:
+    spec:
+      tolerations:
+      - effect: NoSchedule
+        key: batch
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  annotations:
+    scaling: manual
+  labels:
+    tier: batch
+  name: queue
+spec:
+  replicas: 3
+  template:
+    spec:
+      tolerations:
+      - effect: NoSchedule
+        key: batch
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    scaling: manual
+  labels:
+    tier: web
+  name: web
+spec:
+  replicas: 3
+`)
+}
+
+func TestExtendedPatchNoMatchError(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	makeBatchFilesForExtendedPatchTest(th)
+	th.WriteK("/app/base", `
+resources:
+- workloads.yaml
+patches:
+- target:
+    kind: Deployment
+    labelSelector: tier=frontend
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 3
+`)
+	err := th.RunWithErr("/app/base", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"no resource matches the target ~G_~V_Deployment labelSelector=tier=frontend") {
+		t.Fatalf("unexpected error: %q", err)
+	}
+}
+
+func TestExtendedPatchUnknownOption(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	makeBatchFilesForExtendedPatchTest(th)
+	th.WriteK("/app/base", `
+resources:
+- workloads.yaml
+patches:
+- target:
+    kind: Deployment
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 3
+  options:
+    allowNoMatch: true
+`)
+	err := th.RunWithErr("/app/base", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "unknown option allowNoMatch") {
+		t.Fatalf("unexpected error: %q", err)
+	}
+}
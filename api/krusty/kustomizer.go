@@ -79,8 +79,31 @@ func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
 	if err != nil {
 		return nil, err
 	}
-	if b.options.DoLegacyResourceSort {
-		builtins.NewLegacyOrderTransformerPlugin().Transform(m)
+	err = b.sortResources(m, kt.SortOptions())
+	if err != nil {
+		return nil, err
 	}
 	return m, nil
 }
+
+// sortResources sorts the resources per the sortOptions of the
+// top-level kustomization if it has any, and otherwise per the
+// DoLegacyResourceSort option.
+func (b *Kustomizer) sortResources(
+	m resmap.ResMap, so *types.SortOptions) error {
+	if so == nil {
+		if !b.options.DoLegacyResourceSort {
+			return nil
+		}
+		return builtins.NewLegacyOrderTransformerPlugin().Transform(m)
+	}
+	if so.Order == types.FIFOSortOrder {
+		return nil
+	}
+	p := &builtins.LegacyOrderTransformerPlugin{}
+	if so.LegacySortOptions != nil {
+		p.OrderFirst = so.LegacySortOptions.OrderFirst
+		p.OrderLast = so.LegacySortOptions.OrderLast
+	}
+	return p.Transform(m)
+}
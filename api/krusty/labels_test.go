@@ -0,0 +1,193 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const labelsResources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - name: app
+        image: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  selector:
+    app: app
+`
+
+// By default the labels are only added to the metadata.
+func TestLabelsMetadataOnly(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+labels:
+- pairs:
+    owner: alice
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", labelsResources)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    owner: alice
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - image: app
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    owner: alice
+  name: app
+spec:
+  selector:
+    app: app
+`)
+}
+
+// The templates get the labels, but the selectors don't.
+func TestLabelsIncludeTemplates(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+labels:
+- pairs:
+    owner: alice
+  includeTemplates: true
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", labelsResources)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    owner: alice
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+        owner: alice
+    spec:
+      containers:
+      - image: app
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    owner: alice
+  name: app
+spec:
+  selector:
+    app: app
+`)
+}
+
+// The entries of the labels field are independent, and including
+// the selectors is the same as commonLabels.
+func TestLabelsIncludeSelectors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+commonLabels:
+  env: dev
+labels:
+- pairs:
+    team: x
+  includeSelectors: true
+- pairs:
+    owner: alice
+  fields:
+  - path: spec/template/spec/nodeSelector
+    kind: Deployment
+    create: true
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", labelsResources)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: dev
+    owner: alice
+    team: x
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+      env: dev
+      team: x
+  template:
+    metadata:
+      labels:
+        app: app
+        env: dev
+        team: x
+    spec:
+      containers:
+      - image: app
+        name: app
+      nodeSelector:
+        owner: alice
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    env: dev
+    owner: alice
+    team: x
+  name: app
+spec:
+  selector:
+    app: app
+    env: dev
+    team: x
+`)
+}
@@ -585,3 +585,110 @@ func TestVariablesDisambiguatedWithNamespace(t *testing.T) {
 	m := th.Run("/namespaceNeedInVar/myapp", th.MakeDefaultOptions())
 	th.AssertActualEqualsExpected(m, namespaceNeedInVarExpectedOutput)
 }
+
+func TestNamespaceOptions(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namespace: prod
+namespaceOptions:
+  unsetOnly: true
+  exclude:
+  - kind: ConfigMap
+    name: shared
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unset
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shared
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote
+  namespace: monitoring
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: cluster-scoped
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: binding
+subjects:
+- kind: ServiceAccount
+  name: default
+- kind: ServiceAccount
+  name: default
+  namespace: default
+- kind: ServiceAccount
+  name: default
+  namespace: monitoring
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: remote-binding
+  namespace: monitoring
+subjects:
+- kind: ServiceAccount
+  name: default
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unset
+  namespace: prod
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shared
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote
+  namespace: monitoring
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: cluster-scoped
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: binding
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: prod
+- kind: ServiceAccount
+  name: default
+  namespace: prod
+- kind: ServiceAccount
+  name: default
+  namespace: monitoring
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: remote-binding
+  namespace: monitoring
+subjects:
+- kind: ServiceAccount
+  name: default
+`)
+}
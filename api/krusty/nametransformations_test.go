@@ -0,0 +1,163 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const nameTransformationsResources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      serviceAccountName: runner
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: config
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: runner
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`
+
+// The resources which aren't renamed keep their references.
+func TestNameTransformationsInclude(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+nameTransformations:
+  prefix: dev-
+  include:
+  - kind: Deployment
+  - kind: Service
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", nameTransformationsResources)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dev-app
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: config
+        image: app
+        name: app
+      serviceAccountName: runner
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: dev-app
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: runner
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`)
+}
+
+func TestNameTransformationsExclude(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+nameSuffix: -v2
+nameTransformations:
+  exclude:
+  - kind: ServiceAccount
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", nameTransformationsResources)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-v2
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: config-v2
+        image: app
+        name: app
+      serviceAccountName: runner
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app-v2
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: runner
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-v2
+`)
+}
+
+func TestNameTransformationsConflict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: dev-
+nameTransformations:
+  prefix: test-
+  include:
+  - kind: Deployment
+  exclude:
+  - kind: Deployment
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", nameTransformationsResources)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, msg := range []string{
+		"namePrefix and nameTransformations.prefix can't both be set",
+		"nameTransformations: ~G_~V_Deployment is both included and excluded",
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
@@ -16,6 +16,8 @@ type Options struct {
 	// per a particular sort order.  When false, don't do the
 	// sort, and instead respect the depth-first resource input
 	// order as specified by the kustomization file(s).
+	// The sortOptions of the top-level kustomization, if
+	// it has any, win over this option.
 	DoLegacyResourceSort bool
 
 	// Restrictions on what can be loaded from the file system.
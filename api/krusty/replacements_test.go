@@ -0,0 +1,223 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeReplacementsBase(th kusttest_test.Harness) {
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1.0
+        env:
+        - name: CONFIG
+          value: config
+      - name: sidecar
+        image: sidecar:1.0
+---
+apiVersion: example.com/v1
+kind: Job
+metadata:
+  name: job
+spec:
+  configName: config
+`)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - tag=2.0
+`)
+}
+
+// Copy the hashed name of a generated ConfigMap into fields
+// the name references don't know about, and a value of the
+// ConfigMap into a part of an image.
+func TestReplacements(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeReplacementsBase(th)
+	th.WriteK("/app/overlay", `
+namePrefix: dev-
+resources:
+- ../base
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: config
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].env[name=CONFIG].value
+  - objref:
+      group: example.com
+      kind: Job
+      name: job
+    fieldrefs:
+    - spec.configName
+- source:
+    objref:
+      kind: ConfigMap
+      name: config
+    fieldref: data.tag
+  target:
+    objref:
+      kind: Deployment
+      name: app
+    fieldrefs:
+    - spec.template.spec.containers[name=app].image
+    options:
+      delimiter: ':'
+      index: 1
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dev-app
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: CONFIG
+          value: dev-config-29kgh9kd56
+        image: app:2.0
+        name: app
+      - image: sidecar:1.0
+        name: sidecar
+---
+apiVersion: example.com/v1
+kind: Job
+metadata:
+  name: dev-job
+spec:
+  configName: dev-config-29kgh9kd56
+---
+apiVersion: v1
+data:
+  tag: "2.0"
+kind: ConfigMap
+metadata:
+  name: dev-config-29kgh9kd56
+`)
+}
+
+// The replacements of the bases are applied with those of the
+// overlay, once the names are final.
+func TestReplacementsInBase(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeReplacementsBase(th)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - tag=2.0
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: config
+  targets:
+  - objref:
+      kind: Job
+    fieldrefs:
+    - spec.configName
+  - objref:
+      kind: Job
+    fieldrefs:
+    - spec.missing.configName
+  - objref:
+      kind: Job
+    fieldrefs:
+    - spec.created.configName
+    options:
+      create: true
+`)
+	th.WriteK("/app/overlay", `
+namePrefix: dev-
+resources:
+- ../base
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dev-app
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: CONFIG
+          value: config
+        image: app:1.0
+        name: app
+      - image: sidecar:1.0
+        name: sidecar
+---
+apiVersion: example.com/v1
+kind: Job
+metadata:
+  name: dev-job
+spec:
+  configName: dev-config-29kgh9kd56
+  created:
+    configName: dev-config-29kgh9kd56
+---
+apiVersion: v1
+data:
+  tag: "2.0"
+kind: ConfigMap
+metadata:
+  name: dev-config-29kgh9kd56
+`)
+}
+
+func TestReplacementsMissingSourceField(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeReplacementsBase(th)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: config
+    fieldref: data.missing
+  targets:
+  - objref:
+      kind: Job
+    fieldrefs:
+    - spec.configName
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"replacement source data.missing not found in ~G_v1_ConfigMap|~X|config-hb7mk5dk2k") {
+		t.Fatalf("unexpected error: %q", err)
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// writeSortOptionsBase writes a base whose own sortOptions, which
+// would put the Deployments first, are ignored by its overlays.
+func writeSortOptionsBase(th kusttest_test.Harness) {
+	th.WriteK("/base", `
+sortOptions:
+  order: legacy
+  legacySortOptions:
+    orderFirst:
+    - Deployment
+resources:
+- resources.yaml
+configMapGenerator:
+- name: base-config
+  literals:
+  - FOO=bar
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	th.WriteF("/base/resources.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+`)
+}
+
+// writeSortOptionsOverlay writes an overlay of the base with the
+// sortOptions, which adds a CRD and generates a ConfigMap.
+func writeSortOptionsOverlay(th kusttest_test.Harness, sortOptions string) {
+	th.WriteK("/overlay", sortOptions+`
+resources:
+- ../base
+- crd.yaml
+configMapGenerator:
+- name: overlay-config
+  literals:
+  - BAR=baz
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	th.WriteF("/overlay/crd.yaml", `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.example.com
+`)
+}
+
+// Without sortOptions the option of the build applies.
+func TestSortOptionsDefault(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsBase(th)
+	writeSortOptionsOverlay(th, "")
+	opts := th.MakeDefaultOptions()
+	opts.DoLegacyResourceSort = true
+	m := th.Run("/overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.example.com
+---
+apiVersion: v1
+data:
+  FOO: bar
+kind: ConfigMap
+metadata:
+  name: base-config
+---
+apiVersion: v1
+data:
+  BAR: baz
+kind: ConfigMap
+metadata:
+  name: overlay-config
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`)
+}
+
+// The resources are in their declaration order, depth-first, and
+// the generated ConfigMaps follow the resources of their
+// kustomization.
+func TestSortOptionsFIFO(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsBase(th)
+	writeSortOptionsOverlay(th, `
+sortOptions:
+  order: fifo
+`)
+	m := th.Run("/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+---
+apiVersion: v1
+data:
+  FOO: bar
+kind: ConfigMap
+metadata:
+  name: base-config
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.example.com
+---
+apiVersion: v1
+data:
+  BAR: baz
+kind: ConfigMap
+metadata:
+  name: overlay-config
+`)
+}
+
+// The sortOptions win over the option of the build.
+func TestSortOptionsLegacyOrderFirstAndLast(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsBase(th)
+	writeSortOptionsOverlay(th, `
+sortOptions:
+  order: legacy
+  legacySortOptions:
+    orderFirst:
+    - Namespace
+    - CustomResourceDefinition
+    orderLast:
+    - Service
+`)
+	opts := th.MakeDefaultOptions()
+	opts.DoLegacyResourceSort = false
+	m := th.Run("/overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.example.com
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+data:
+  FOO: bar
+kind: ConfigMap
+metadata:
+  name: base-config
+---
+apiVersion: v1
+data:
+  BAR: baz
+kind: ConfigMap
+metadata:
+  name: overlay-config
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`)
+}
+
+func TestSortOptionsInvalid(t *testing.T) {
+	for sortOptions, expected := range map[string]string{
+		`
+sortOptions:
+  order: alphabetical
+`: `sortOptions.order must be legacy or fifo, got "alphabetical"`,
+		`
+sortOptions:
+  order: fifo
+  legacySortOptions:
+    orderFirst:
+    - Namespace
+`: "sortOptions.legacySortOptions can't be set with sortOptions.order fifo",
+		`
+sortOptions:
+  legacySortOptions:
+    orderFirst:
+    - Namespace
+    orderLast:
+    - Namespace
+`: "sortOptions.legacySortOptions: Namespace is in both orderFirst and orderLast",
+	} {
+		th := kusttest_test.MakeHarness(t)
+		writeSortOptionsBase(th)
+		writeSortOptionsOverlay(th, sortOptions)
+		err := th.RunWithErr("/overlay", th.MakeDefaultOptions())
+		if err == nil {
+			t.Fatalf("expected an error %s", expected)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected an error %s, got %v", expected, err)
+		}
+	}
+}
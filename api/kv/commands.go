@@ -0,0 +1,121 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// defaultCommandTimeoutSeconds is the default of
+// CommandOptions.TimeoutSeconds.
+const defaultCommandTimeoutSeconds = 30
+
+// LoadCommands runs the command of each key in the root of
+// the loader, in the order of the keys, and returns its
+// standard output as the value of the key.
+func (kvl *loader) LoadCommands(
+	sources map[string]string, opts *types.CommandOptions) ([]types.Pair, error) {
+	if opts == nil {
+		opts = &types.CommandOptions{}
+	}
+	timeout := defaultCommandTimeoutSeconds * time.Second
+	if opts.TimeoutSeconds > 0 {
+		timeout = time.Duration(opts.TimeoutSeconds) * time.Second
+	}
+	var keys []string
+	for k := range sources {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var kvs []types.Pair
+	for _, k := range keys {
+		out, err := runCommand(kvl.ldr.Root(), sources[k], opts.Shell, timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "command of key %s", k)
+		}
+		kvs = append(kvs, types.Pair{Key: k, Value: out})
+	}
+	return kvs, nil
+}
+
+// runCommand runs command in dir, killing it after timeout,
+// and returns its standard output.  The errors include the
+// standard error, but never the standard output.
+func runCommand(dir, command string, shell bool, timeout time.Duration) (string, error) {
+	args := []string{"sh", "-c", command}
+	if !shell {
+		var err error
+		if args, err = splitCommand(command); err != nil {
+			return "", err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("%q timed out after %v", command, timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf(
+			"%q failed: %v: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// splitCommand splits command into its arguments on white
+// space, which may be quoted with ' or ", e.g.
+// `sops -d --extract '["pw"]' secrets.yaml`.
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	for _, c := range command {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case unicode.IsSpace(c):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c in command %q", quote, command)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
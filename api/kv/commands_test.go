@@ -0,0 +1,124 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	ldr "sigs.k8s.io/kustomize/api/loader"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestLoadCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kv-commands")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := ldr.NewLoader(ldr.RestrictionRootOnly, dir, filesys.MakeFsOnDisk())
+	if err != nil {
+		t.Fatal(err)
+	}
+	kvl := &loader{ldr: l, validator: valtest_test.MakeFakeValidator()}
+
+	tests := []struct {
+		description   string
+		sources       map[string]string
+		options       *types.CommandOptions
+		expectedPairs []types.Pair
+		expectedErr   string
+	}{
+		{
+			description: "output is kept verbatim, in the order of the keys",
+			sources: map[string]string{
+				"password": `printf 'p@ss word\n'`,
+				"dir":      "pwd",
+			},
+			expectedPairs: []types.Pair{
+				{Key: "dir", Value: dir + "\n"},
+				{Key: "password", Value: "p@ss word\n"},
+			},
+		},
+		{
+			description: "no shell interpretation by default",
+			sources:     map[string]string{"sum": "echo $((1+2)) | wc"},
+			expectedPairs: []types.Pair{
+				{Key: "sum", Value: "$((1+2)) | wc\n"},
+			},
+		},
+		{
+			description: "shell",
+			sources:     map[string]string{"sum": "echo $((1+2)) | tr -d '\\n'"},
+			options:     &types.CommandOptions{Shell: true},
+			expectedPairs: []types.Pair{
+				{Key: "sum", Value: "3"},
+			},
+		},
+		{
+			description: "failure",
+			sources:     map[string]string{"password": "sh -c 'echo denied >&2; exit 2'"},
+			expectedErr: `command of key password: "sh -c 'echo denied >&2; exit 2'" ` +
+				"failed: exit status 2: denied",
+		},
+		{
+			description: "timeout",
+			sources:     map[string]string{"password": "sleep 5"},
+			options:     &types.CommandOptions{TimeoutSeconds: 1},
+			expectedErr: `command of key password: "sleep 5" timed out after 1s`,
+		},
+		{
+			description: "unterminated quote",
+			sources:     map[string]string{"password": `echo "secret`},
+			expectedErr: `command of key password: unterminated " in command "echo \"secret"`,
+		},
+		{
+			description: "empty command",
+			sources:     map[string]string{"password": " "},
+			expectedErr: "command of key password: empty command",
+		},
+	}
+	for _, tc := range tests {
+		pairs, err := kvl.LoadCommands(tc.sources, tc.options)
+		if tc.expectedErr != "" {
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Fatalf("in testcase: %q expected error %q, got %v",
+					tc.description, tc.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("in testcase: %q unexpected error %v", tc.description, err)
+		}
+		if !reflect.DeepEqual(pairs, tc.expectedPairs) {
+			t.Fatalf("in testcase: %q expected %v, got %v",
+				tc.description, tc.expectedPairs, pairs)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	args, err := splitCommand(`sops -d --extract '["pw"]'  "my secrets.yaml"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"sops", "-d", "--extract", `["pw"]`, "my secrets.yaml"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("expected %v, got %v", expected, args)
+	}
+	if _, err := splitCommand(`echo 'a`); err == nil ||
+		!strings.Contains(err.Error(), "unterminated '") {
+		t.Fatalf("expected an unterminated quote error, got %v", err)
+	}
+}
@@ -55,7 +55,7 @@ func (kvl *loader) Load(
 	}
 	all = append(all, pairs...)
 
-	pairs, err = kvl.keyValuesFromFileSources(args.FileSources)
+	pairs, err = kvl.keyValuesFromFileSources(args.FileSources, args.FileOptions)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(
 			"file sources: %v", args.FileSources))
@@ -75,22 +75,80 @@ func keyValuesFromLiteralSources(sources []string) ([]types.Pair, error) {
 	return kvs, nil
 }
 
-func (kvl *loader) keyValuesFromFileSources(sources []string) ([]types.Pair, error) {
+func (kvl *loader) keyValuesFromFileSources(
+	sources []string, opts *types.FileSourceOptions) ([]types.Pair, error) {
+	if opts == nil {
+		opts = &types.FileSourceOptions{}
+	}
 	var kvs []types.Pair
+	// paths of the files by key, to report the collisions
+	paths := map[string]string{}
+	add := func(k, fPath string) error {
+		if other, found := paths[k]; found {
+			return fmt.Errorf(
+				"files %s and %s have the same key %s", other, fPath, k)
+		}
+		paths[k] = fPath
+		content, err := kvl.ldr.Load(fPath)
+		if err != nil {
+			return err
+		}
+		kvs = append(kvs, types.Pair{Key: k, Value: trimTrailingSpacesInLines(string(content))})
+		return nil
+	}
 	for _, s := range sources {
 		k, fPath, err := parseFileSource(s)
 		if err != nil {
 			return nil, err
 		}
-		content, err := kvl.ldr.Load(fPath)
+		if !kvl.ldr.IsDir(fPath) {
+			if err := add(k, fPath); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.Contains(s, "=") {
+			return nil, fmt.Errorf(
+				"key name %s can't be given for directory %s", k, fPath)
+		}
+		files, err := kvl.ldr.ListFiles(fPath, opts.Recursive)
 		if err != nil {
 			return nil, err
 		}
-		kvs = append(kvs, types.Pair{Key: k, Value: trimTrailingSpacesInLines(string(content))})
+		for _, f := range files {
+			if !opts.IncludeHidden && isHidden(f) {
+				continue
+			}
+			if err := add(keyFromPath(f, opts), path.Join(fPath, f)); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return kvs, nil
 }
 
+// isHidden returns true if the file, or one of its directories,
+// in the slash separated path starts with a ".".
+func isHidden(p string) bool {
+	for _, name := range strings.Split(p, "/") {
+		if strings.HasPrefix(name, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFromPath returns the key of the file at the slash separated
+// path below a directory source, joining its directories with
+// the KeySeparator.
+func keyFromPath(p string, opts *types.FileSourceOptions) string {
+	sep := opts.KeySeparator
+	if sep == "" {
+		sep = "_"
+	}
+	return strings.ReplaceAll(p, "/", sep)
+}
+
 // trimTrailingSpacesInLines takes string with multiple lines and trims the trailing white spaces and tabs from each line.
 func trimTrailingSpacesInLines(str string) string {
 	re := regexp.MustCompile(`[ \t]*\n`)
@@ -68,6 +68,7 @@ func TestKeyValuesFromFileSources(t *testing.T) {
 	tests := []struct {
 		description string
 		sources     []string
+		options     *types.FileSourceOptions
 		expected    []types.Pair
 	}{
 		{
@@ -80,13 +81,55 @@ func TestKeyValuesFromFileSources(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "create kvs from a directory",
+			sources:     []string{"sql"},
+			expected: []types.Pair{
+				{
+					Key:   "001.sql",
+					Value: "CREATE TABLE a;",
+				},
+				{
+					Key:   "002.sql",
+					Value: "CREATE TABLE b;",
+				},
+			},
+		},
+		{
+			description: "create kvs from a directory recursively",
+			sources:     []string{"sql"},
+			options: &types.FileSourceOptions{
+				Recursive: true, KeySeparator: ".", IncludeHidden: true},
+			expected: []types.Pair{
+				{
+					Key:   ".hidden.sql",
+					Value: "DROP TABLE a;",
+				},
+				{
+					Key:   "001.sql",
+					Value: "CREATE TABLE a;",
+				},
+				{
+					Key:   "002.sql",
+					Value: "CREATE TABLE b;",
+				},
+				{
+					Key:   "v2.001.sql",
+					Value: "CREATE TABLE c;",
+				},
+			},
+		},
 	}
 
 	fSys := filesys.MakeFsInMemory()
 	fSys.WriteFile("/files/app-init.ini", []byte("FOO=bar"))
+	fSys.WriteFile("/sql/001.sql", []byte("CREATE TABLE a;"))
+	fSys.WriteFile("/sql/002.sql", []byte("CREATE TABLE b;"))
+	fSys.WriteFile("/sql/.hidden.sql", []byte("DROP TABLE a;"))
+	fSys.WriteFile("/sql/v2/001.sql", []byte("CREATE TABLE c;"))
 	kvl := makeKvLoader(fSys)
 	for _, tc := range tests {
-		kvs, err := kvl.keyValuesFromFileSources(tc.sources)
+		kvs, err := kvl.keyValuesFromFileSources(tc.sources, tc.options)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -96,6 +139,45 @@ func TestKeyValuesFromFileSources(t *testing.T) {
 	}
 }
 
+func TestKeyValuesFromFileSourcesErrors(t *testing.T) {
+	tests := []struct {
+		description string
+		sources     []string
+		options     *types.FileSourceOptions
+		expectedErr string
+	}{
+		{
+			description: "keys collide after flattening",
+			sources:     []string{"sql"},
+			options:     &types.FileSourceOptions{Recursive: true},
+			expectedErr: "files sql/v2/001.sql and sql/v2_001.sql have the same key v2_001.sql",
+		},
+		{
+			description: "key collides with a file",
+			sources:     []string{"v2_001.sql=files/app-init.ini", "sql"},
+			options:     &types.FileSourceOptions{Recursive: true},
+			expectedErr: "files files/app-init.ini and sql/v2/001.sql have the same key v2_001.sql",
+		},
+		{
+			description: "key name for a directory",
+			sources:     []string{"migrations=sql"},
+			expectedErr: "key name migrations can't be given for directory sql",
+		},
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/files/app-init.ini", []byte("FOO=bar"))
+	fSys.WriteFile("/sql/v2_001.sql", []byte("CREATE TABLE a;"))
+	fSys.WriteFile("/sql/v2/001.sql", []byte("CREATE TABLE b;"))
+	kvl := makeKvLoader(fSys)
+	for _, tc := range tests {
+		_, err := kvl.keyValuesFromFileSources(tc.sources, tc.options)
+		if err == nil || err.Error() != tc.expectedErr {
+			t.Fatalf("in testcase: %q expected error %q, got %v", tc.description, tc.expectedErr, err)
+		}
+	}
+}
+
 func TestTrimTrailingSpacesInLines(t *testing.T) {
 	input := "\"fooKey\": \"fooValue\"   \t\n \t\t  \n\t\"barKey\": \"barValue\""
 	expected := "\"fooKey\": \"fooValue\"\n\n\t\"barKey\": \"barValue\""
@@ -9,6 +9,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -344,6 +345,58 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 	return fl.fSys.ReadFile(path)
 }
 
+// IsDir returns true if the path is a local directory.
+func (fl *fileLoader) IsDir(path string) bool {
+	if u, err := url.Parse(path); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return false
+	}
+	if !filepath.IsAbs(path) {
+		path = fl.root.Join(path)
+	}
+	return fl.fSys.IsDir(path)
+}
+
+// ListFiles returns the paths of the regular files in the directory
+// at path, relative to it, slash separated and in lexical order.
+// The files of the subdirectories are only listed if recursive.
+// Each file is subject to the load restrictions of Load.
+func (fl *fileLoader) ListFiles(path string, recursive bool) ([]string, error) {
+	if !filepath.IsAbs(path) {
+		path = fl.root.Join(path)
+	}
+	if !fl.fSys.IsDir(path) {
+		return nil, fmt.Errorf("'%s' must be a directory", path)
+	}
+	var files []string
+	err := fl.fSys.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel != "." && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if _, err := fl.loadRestrictor(fl.fSys, fl.root, p); err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
 // Cleanup runs the cleaner.
 func (fl *fileLoader) Cleanup() error {
 	return fl.cleaner()
@@ -96,6 +96,32 @@ func TestLoaderLoad(t *testing.T) {
 	}
 }
 
+func TestLoaderListFiles(t *testing.T) {
+	l1 := makeLoader()
+	if !l1.IsDir("foo/project") || l1.IsDir("foo/project/fileA.yaml") {
+		t.Fatalf("expected only foo/project to be a directory")
+	}
+	files, err := l1.ListFiles("foo/project", false)
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	if !reflect.DeepEqual([]string{"fileA.yaml", "fileD.yaml"}, files) {
+		t.Fatalf("unexpected files: %v\n", files)
+	}
+	files, err = l1.ListFiles("foo/project", true)
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	expected := []string{
+		"fileA.yaml", "fileD.yaml", "subdir1/fileB.yaml", "subdir2/fileC.yaml"}
+	if !reflect.DeepEqual(expected, files) {
+		t.Fatalf("unexpected files: %v\n", files)
+	}
+	if _, err = l1.ListFiles("foo/project/fileA.yaml", false); err == nil {
+		t.Fatalf("expected error listing a file")
+	}
+}
+
 func TestLoaderNewSubDir(t *testing.T) {
 	l1, err := makeLoader().New("foo/project")
 	if err != nil {
@@ -104,27 +104,42 @@ var orderFirst = []string{
 var orderLast = []string{
 	"ValidatingWebhookConfiguration",
 }
-var typeOrders = func() map[string]int {
-	m := map[string]int{}
-	for i, n := range orderFirst {
-		m[n] = -len(orderFirst) + i
-	}
-	for i, n := range orderLast {
+var legacyKindOrder = NewKindOrder(orderFirst, orderLast)
+
+// KindOrder orders Gvks by their kinds.  The kinds of its
+// first list come first, in that order, and the kinds of its
+// last list come last, in that order.  The other kinds are in
+// between, and Gvks of the same rank are ordered by String.
+type KindOrder map[string]int
+
+// NewKindOrder returns the KindOrder with the kinds of first
+// first and the kinds of last last.
+func NewKindOrder(first, last []string) KindOrder {
+	m := KindOrder{}
+	for i, n := range first {
+		m[n] = -len(first) + i
+	}
+	for i, n := range last {
 		m[n] = 1 + i
 	}
 	return m
-}()
+}
 
-// IsLessThan returns true if self is less than the argument.
-func (x Gvk) IsLessThan(o Gvk) bool {
-	indexI := typeOrders[x.Kind]
-	indexJ := typeOrders[o.Kind]
+// IsLessThan returns true if x is ordered before o.
+func (k KindOrder) IsLessThan(x, o Gvk) bool {
+	indexI := k[x.Kind]
+	indexJ := k[o.Kind]
 	if indexI != indexJ {
 		return indexI < indexJ
 	}
 	return x.String() < o.String()
 }
 
+// IsLessThan returns true if self is less than the argument.
+func (x Gvk) IsLessThan(o Gvk) bool {
+	return legacyKindOrder.IsLessThan(x, o)
+}
+
 // IsSelected returns true if `selector` selects `x`; otherwise, false.
 // If `selector` and `x` are the same, return true.
 // If `selector` is nil, it is considered a wildcard match, returning true.
@@ -38,8 +38,10 @@ type Configurable interface {
 }
 
 // NewPluginHelpers makes an instance of PluginHelpers.
-func NewPluginHelpers(ldr ifc.Loader, v ifc.Validator, rf *Factory) *PluginHelpers {
-	return &PluginHelpers{ldr: ldr, v: v, rf: rf}
+// The plugin config pc may be nil.
+func NewPluginHelpers(
+	ldr ifc.Loader, v ifc.Validator, rf *Factory, pc *types.PluginConfig) *PluginHelpers {
+	return &PluginHelpers{ldr: ldr, v: v, rf: rf, pc: pc}
 }
 
 // PluginHelpers holds things that any or all plugins might need.
@@ -49,6 +51,7 @@ type PluginHelpers struct {
 	ldr ifc.Loader
 	v   ifc.Validator
 	rf  *Factory
+	pc  *types.PluginConfig
 }
 
 func (c *PluginHelpers) Loader() ifc.Loader {
@@ -63,6 +66,12 @@ func (c *PluginHelpers) Validator() ifc.Validator {
 	return c.v
 }
 
+// ExecEnabled returns true if the plugins may run
+// the commands of the secrets.
+func (c *PluginHelpers) ExecEnabled() bool {
+	return c.pc != nil && c.pc.EnableExec
+}
+
 type GeneratorPlugin interface {
 	Generator
 	Configurable
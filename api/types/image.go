@@ -18,4 +18,9 @@ type Image struct {
 	// Digest is the value used to replace the original image tag.
 	// If digest is present NewTag value is ignored.
 	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+
+	// ResolveDigest if true replaces the tag by the digest of its
+	// manifest, which is queried from the registry of the image.
+	// Ignored if Digest is present.
+	ResolveDigest bool `json:"resolveDigest,omitempty" yaml:"resolveDigest,omitempty"`
 }
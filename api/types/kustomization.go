@@ -24,12 +24,23 @@ type Kustomization struct {
 	// file including generated configmaps and secrets.
 	NameSuffix string `json:"nameSuffix,omitempty" yaml:"nameSuffix,omitempty"`
 
+	// NameTransformations scope the renaming of the resources
+	// to some kinds, instead of NamePrefix and NameSuffix.
+	NameTransformations *NameTransformations `json:"nameTransformations,omitempty" yaml:"nameTransformations,omitempty"`
+
 	// Namespace to add to all objects.
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 
+	// NamespaceOptions modify how the Namespace is set.
+	NamespaceOptions *NamespaceOptions `json:"namespaceOptions,omitempty" yaml:"namespaceOptions,omitempty"`
+
 	// CommonLabels to add to all objects and selectors.
 	CommonLabels map[string]string `json:"commonLabels,omitempty" yaml:"commonLabels,omitempty"`
 
+	// Labels to add to all objects, and optionally to the selectors
+	// and the templates.
+	Labels []Label `json:"labels,omitempty" yaml:"labels,omitempty"`
+
 	// CommonAnnotations to add to all objects.
 	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty" yaml:"commonAnnotations,omitempty"`
 
@@ -67,6 +78,12 @@ type Kustomization struct {
 	// value of the specified field has been determined.
 	Vars []Var `json:"vars,omitempty" yaml:"vars,omitempty"`
 
+	// Replacements copy the value of a field of one resource, or a
+	// literal value, into fields of other resources.  They are
+	// applied after the names have been hashed and the vars resolved,
+	// so the final names are copied.
+	Replacements []Replacement `json:"replacements,omitempty" yaml:"replacements,omitempty"`
+
 	//
 	// Operands - what kustomize operates on.
 	//
@@ -120,6 +137,10 @@ type Kustomization struct {
 	// Inventory appends an object that contains the record
 	// of all other objects, which can be used in apply, prune and delete
 	Inventory *Inventory `json:"inventory,omitempty" yaml:"inventory,omitempty"`
+
+	// SortOptions set the order of the resources in the output,
+	// and only apply to the top-level kustomization of a build.
+	SortOptions *SortOptions `json:"sortOptions,omitempty" yaml:"sortOptions,omitempty"`
 }
 
 // FixKustomizationPostUnmarshalling fixes things
@@ -145,5 +166,11 @@ func (k *Kustomization) EnforceFields() []string {
 	if k.Kind != "" && k.Kind != KustomizationKind {
 		errs = append(errs, "kind should be "+KustomizationKind)
 	}
+	if k.NameTransformations != nil {
+		errs = append(errs, k.NameTransformations.enforceFields(k.NamePrefix, k.NameSuffix)...)
+	}
+	if k.SortOptions != nil {
+		errs = append(errs, k.SortOptions.enforceFields()...)
+	}
 	return errs
 }
@@ -17,11 +17,15 @@ type KvPairSources struct {
 	// path's basename. If they "key=" part is present,
 	// it becomes the key (replacing the basename).
 	// In either case, the value is the file contents.
-	// Specifying a directory will iterate each named
-	// file in the directory whose basename is a
-	// valid configmap key.
+	// Specifying a directory, without a "key=" part,
+	// includes each regular file in the directory,
+	// keyed by its basename. See FileOptions.
 	FileSources []string `json:"files,omitempty" yaml:"files,omitempty"`
 
+	// FileOptions modify how the directories
+	// of FileSources are read.
+	FileOptions *FileSourceOptions `json:"fileOptions,omitempty" yaml:"fileOptions,omitempty"`
+
 	// EnvSources is a list of file paths.
 	// The contents of each file should be one
 	// key=value pair per line, e.g. a Docker
@@ -29,3 +33,20 @@ type KvPairSources struct {
 	// (wikipedia.org/wiki/INI_file)
 	EnvSources []string `json:"envs,omitempty" yaml:"envs,omitempty"`
 }
+
+// FileSourceOptions modify how the directories of FileSources are read.
+type FileSourceOptions struct {
+	// Recursive includes the files of the subdirectories too,
+	// keyed by their path below the directory with each "/"
+	// replaced by the KeySeparator, e.g. `sql_001.sql` for
+	// the file `sql/001.sql`.
+	Recursive bool `json:"recursive,omitempty" yaml:"recursive,omitempty"`
+
+	// KeySeparator joins the subdirectory names into the
+	// keys of the Recursive files. Defaults to "_".
+	KeySeparator string `json:"keySeparator,omitempty" yaml:"keySeparator,omitempty"`
+
+	// IncludeHidden includes the files, and the subdirectories,
+	// whose name starts with a ".". They are skipped by default.
+	IncludeHidden bool `json:"includeHidden,omitempty" yaml:"includeHidden,omitempty"`
+}
@@ -0,0 +1,28 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Label is an entry of the labels field of a kustomization, i.e.
+// labels which, unlike the CommonLabels, are only added to the
+// selectors if IncludeSelectors is set.
+type Label struct {
+	// Pairs are the labels to add.
+	Pairs map[string]string `json:"pairs,omitempty" yaml:"pairs,omitempty"`
+
+	// IncludeSelectors adds the labels to the selectors and the
+	// templates too, like the CommonLabels.  The selectors of
+	// existing workloads are immutable, so changing them prevents
+	// the workloads from being applied.
+	IncludeSelectors bool `json:"includeSelectors,omitempty" yaml:"includeSelectors,omitempty"`
+
+	// IncludeTemplates adds the labels to the pod templates of the
+	// workloads, but not to their selectors, if IncludeSelectors
+	// isn't set.
+	IncludeTemplates bool `json:"includeTemplates,omitempty" yaml:"includeTemplates,omitempty"`
+
+	// FieldSpecs are the fields the labels are added to, in
+	// addition to metadata/labels and the fields selected by
+	// IncludeSelectors and IncludeTemplates.
+	FieldSpecs []FieldSpec `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
@@ -0,0 +1,18 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// NamespaceOptions modify how the namespace of a kustomization
+// is set on its resources.
+type NamespaceOptions struct {
+	// UnsetOnly if true only sets the namespace on the resources
+	// which don't have one, e.g. to keep the namespace of the
+	// resources of a remote base.  The subjects of the RoleBindings
+	// and ClusterRoleBindings are then only set if they are in the
+	// default namespace.
+	UnsetOnly bool `json:"unsetOnly,omitempty" yaml:"unsetOnly,omitempty"`
+
+	// Exclude selects the resources whose namespace is never set.
+	Exclude []Selector `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
@@ -0,0 +1,52 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+// NameTransformations scope the prefix and the suffix added to the
+// names of the resources to some kinds of resources.
+//
+// The references to the resources which aren't renamed are left
+// unchanged.  The CustomResourceDefinitions and the APIServices
+// are never renamed.
+type NameTransformations struct {
+	// Prefix is added to the names, as NamePrefix.
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+
+	// Suffix is added to the names, as NameSuffix.
+	Suffix string `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+
+	// Include if not empty selects the only resources which are renamed.
+	Include []resid.Gvk `json:"include,omitempty" yaml:"include,omitempty"`
+
+	// Exclude selects the resources which aren't renamed.
+	Exclude []resid.Gvk `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// enforceFields returns the errors of a NameTransformations used
+// along with the namePrefix and nameSuffix of a kustomization.
+func (nt *NameTransformations) enforceFields(prefix, suffix string) []string {
+	var errs []string
+	if nt.Prefix != "" && prefix != "" {
+		errs = append(errs, "namePrefix and nameTransformations.prefix can't both be set")
+	}
+	if nt.Suffix != "" && suffix != "" {
+		errs = append(errs, "nameSuffix and nameTransformations.suffix can't both be set")
+	}
+	for _, in := range nt.Include {
+		for _, ex := range nt.Exclude {
+			if in.Equals(ex) {
+				errs = append(errs, fmt.Sprintf(
+					"nameTransformations: %s is both included and excluded", in))
+				break
+			}
+		}
+	}
+	return errs
+}
@@ -3,6 +3,10 @@
 
 package types
 
+// AllowNoTargetMatch is the option of a Patch which allows its Target
+// to match no resources, which is an error otherwise.
+const AllowNoTargetMatch = "allowNoTargetMatch"
+
 // Patch represent either a Strategic Merge Patch or a JSON patch
 // and its targets.
 // The content of the patch can either be from a file
@@ -16,4 +20,7 @@ type Patch struct {
 
 	// Target points to the resources that the patch is applied to
 	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Options are the options of the patch, e.g. AllowNoTargetMatch.
+	Options map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 }
@@ -28,4 +28,9 @@ type PluginConfig struct {
 	// PluginRestrictions defines the plugin restriction state.
 	// See type for more information.
 	PluginRestrictions PluginRestrictions
+
+	// EnableExec allows the secret generators to run the
+	// commands of their CommandSources.  A secret with
+	// commands is an error if it is false.
+	EnableExec bool
 }
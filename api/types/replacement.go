@@ -7,7 +7,21 @@ package types
 // where it is from and where it is to.
 type Replacement struct {
 	Source *ReplSource `json:"source" yaml:"source"`
-	Target *ReplTarget `json:"target" yaml:"target"`
+
+	// Target is a single target, kept for compatibility;
+	// it is applied before the Targets.
+	Target *ReplTarget `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Targets are the targets the substitution is made in.
+	Targets []*ReplTarget `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// AllTargets returns the Target, if any, followed by the Targets.
+func (r Replacement) AllTargets() []*ReplTarget {
+	if r.Target == nil {
+		return r.Targets
+	}
+	return append([]*ReplTarget{r.Target}, r.Targets...)
 }
 
 // ReplSource defines where a substitution is from
@@ -16,12 +30,29 @@ type Replacement struct {
 //  - from a string
 type ReplSource struct {
 	ObjRef   *Target `json:"objref,omitempty" yaml:"objref,omitempty"`
-	FieldRef string  `json:"fieldref,omitempty" yaml:"fiedldref,omitempty"`
+	FieldRef string  `json:"fieldref,omitempty" yaml:"fieldref,omitempty"`
 	Value    string  `json:"value,omitempty" yaml:"value,omitempty"`
 }
 
 // ReplTarget defines where a substitution is to.
 type ReplTarget struct {
-	ObjRef    *Selector `json:"objref,omitempty" yaml:"objref,omitempty"`
-	FieldRefs []string  `json:"fieldrefs,omitempty" yaml:"fieldrefs,omitempty"`
+	ObjRef    *Selector    `json:"objref,omitempty" yaml:"objref,omitempty"`
+	FieldRefs []string     `json:"fieldrefs,omitempty" yaml:"fieldrefs,omitempty"`
+	Options   *ReplOptions `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ReplOptions defines how a target field is substituted.
+type ReplOptions struct {
+	// Delimiter if set splits the value of the target field,
+	// and only the part at Index is substituted, e.g. the tag
+	// of an image with a ':' Delimiter and an Index of 1.
+	Delimiter string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+
+	// Index is the part of the target field which is substituted
+	// when the value is split by the Delimiter.
+	Index int `json:"index,omitempty" yaml:"index,omitempty"`
+
+	// Create if set creates the target field when it is missing.
+	// Targets without the field are skipped otherwise.
+	Create bool `json:"create,omitempty" yaml:"create,omitempty"`
 }
@@ -16,4 +16,27 @@ type SecretArgs struct {
 	// If type is "kubernetes.io/tls", then "literals" or "files" must have exactly two
 	// keys: "tls.key" and "tls.crt"
 	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// CommandSources maps keys to commands whose standard
+	// output is the value of the key, verbatim, e.g.
+	// `password: vault kv get -field=pw secret/app`.
+	// The commands run in the kustomization directory,
+	// and only if exec is enabled (PluginConfig.EnableExec).
+	CommandSources map[string]string `json:"commands,omitempty" yaml:"commands,omitempty"`
+
+	// CommandOptions modify how the CommandSources run.
+	CommandOptions *CommandOptions `json:"commandOptions,omitempty" yaml:"commandOptions,omitempty"`
+}
+
+// CommandOptions modify how the CommandSources of a secret run.
+type CommandOptions struct {
+	// TimeoutSeconds is the time each command may run
+	// before it is killed. Defaults to 30.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
+
+	// Shell if true runs the commands with `sh -c`, so that
+	// they may use pipes, variables, etc. The commands are
+	// otherwise split into arguments on white space, which
+	// may be quoted with ' or ", and run directly.
+	Shell bool `json:"shell,omitempty" yaml:"shell,omitempty"`
 }
@@ -4,6 +4,8 @@
 package types
 
 import (
+	"strings"
+
 	"sigs.k8s.io/kustomize/api/resid"
 )
 
@@ -25,3 +27,20 @@ type Selector struct {
 	// It matches with the resource labels.
 	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
 }
+
+// String returns the conditions of the Selector which are set,
+// e.g. `~G_~V_Deployment name=app labelSelector=tier=batch`.
+func (s Selector) String() string {
+	result := []string{s.Gvk.String()}
+	for _, c := range []struct{ name, value string }{
+		{"namespace", s.Namespace},
+		{"name", s.Name},
+		{"annotationSelector", s.AnnotationSelector},
+		{"labelSelector", s.LabelSelector},
+	} {
+		if c.value != "" {
+			result = append(result, c.name+"="+c.value)
+		}
+	}
+	return strings.Join(result, " ")
+}
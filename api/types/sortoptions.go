@@ -0,0 +1,73 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "fmt"
+
+// SortOrder is the order of the resources in the output of a build.
+type SortOrder string
+
+const (
+	// LegacySortOrder sorts the resources by their kinds, e.g. the
+	// Namespaces first, with the LegacyOrderTransformer.
+	LegacySortOrder SortOrder = "legacy"
+
+	// FIFOSortOrder keeps the resources in the order they're declared,
+	// depth-first, with the outputs of the generators appended in the
+	// order they're generated.
+	FIFOSortOrder SortOrder = "fifo"
+)
+
+// SortOptions set the order of the resources in the output of a
+// build.  Only the SortOptions of the top-level kustomization
+// apply: the ones of its bases are ignored.
+type SortOptions struct {
+	// Order is the order of the resources.  Defaults to legacy.
+	Order SortOrder `json:"order,omitempty" yaml:"order,omitempty"`
+
+	// LegacySortOptions replace the kinds which are sorted first
+	// and last by the legacy order.
+	LegacySortOptions *LegacySortOptions `json:"legacySortOptions,omitempty" yaml:"legacySortOptions,omitempty"`
+}
+
+// LegacySortOptions are the kinds sorted first and last by the
+// legacy order, instead of its builtin lists.  The other kinds
+// are sorted by their group, version and kind in between.
+type LegacySortOptions struct {
+	// OrderFirst are the kinds sorted first, in order.
+	OrderFirst []string `json:"orderFirst,omitempty" yaml:"orderFirst,omitempty"`
+
+	// OrderLast are the kinds sorted last, in order.
+	OrderLast []string `json:"orderLast,omitempty" yaml:"orderLast,omitempty"`
+}
+
+// enforceFields returns the errors of the SortOptions.
+func (so *SortOptions) enforceFields() []string {
+	var errs []string
+	switch so.Order {
+	case "", LegacySortOrder:
+	case FIFOSortOrder:
+		if so.LegacySortOptions != nil {
+			errs = append(errs,
+				"sortOptions.legacySortOptions can't be set with sortOptions.order fifo")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf(
+			"sortOptions.order must be %s or %s, got %q",
+			LegacySortOrder, FIFOSortOrder, so.Order))
+	}
+	if so.LegacySortOptions != nil {
+		first := map[string]bool{}
+		for _, k := range so.LegacySortOptions.OrderFirst {
+			first[k] = true
+		}
+		for _, k := range so.LegacySortOptions.OrderLast {
+			if first[k] {
+				errs = append(errs, fmt.Sprintf(
+					"sortOptions.legacySortOptions: %s is in both orderFirst and orderLast", k))
+			}
+		}
+	}
+	return errs
+}
@@ -6,6 +6,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -14,6 +15,7 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/setters"
 	"sigs.k8s.io/kustomize/kyaml/setters2/settersutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 // NewSetRunner returns a command runner.
@@ -21,7 +23,7 @@ func NewSetRunner(parent string) *SetRunner {
 	r := &SetRunner{}
 	c := &cobra.Command{
 		Use:     "set DIR NAME [VALUE]",
-		Args:    cobra.MinimumNArgs(3),
+		Args:    r.args,
 		Short:   commands.SetShort,
 		Long:    commands.SetLong,
 		Example: commands.SetExamples,
@@ -34,6 +36,8 @@ func NewSetRunner(parent string) *SetRunner {
 		"annotate the field with who set it")
 	c.Flags().StringVar(&r.Perform.Description, "description", "",
 		"annotate the field with a description of its value")
+	c.Flags().StringVar(&r.ValuesFile, "values", "",
+		"set the setters to the values in a yaml file of NAME: VALUE fields")
 	c.Flags().StringVar(&setterVersion, "version", "",
 		"use this version of the setter format")
 	c.Flags().MarkHidden("version")
@@ -53,6 +57,27 @@ type SetRunner struct {
 	Perform     setters.PerformSetters
 	Set         settersutil.FieldSetter
 	OpenAPIFile string
+
+	// ValuesFile is a yaml file of setter names and values to set
+	ValuesFile string
+
+	// Setters are the setters set at once, from the ValuesFile and the
+	// NAME=VALUE arguments
+	Setters settersutil.FieldSetters
+}
+
+// args validates the arguments: either DIR NAME VALUE [LIST_VALUE...], or DIR and
+// any number of NAME=VALUE arguments when setting several setters at once.
+func (r *SetRunner) args(c *cobra.Command, args []string) error {
+	if r.isMultiSet(args) {
+		return cobra.MinimumNArgs(1)(c, args)
+	}
+	return cobra.MinimumNArgs(3)(c, args)
+}
+
+// isMultiSet returns true if several setters are set at once.
+func (r *SetRunner) isMultiSet(args []string) bool {
+	return r.ValuesFile != "" || (len(args) > 1 && strings.Contains(args[1], "="))
 }
 
 func initSetterVersion(c *cobra.Command, args []string) error {
@@ -75,6 +100,9 @@ func initSetterVersion(c *cobra.Command, args []string) error {
 }
 
 func (r *SetRunner) preRunE(c *cobra.Command, args []string) error {
+	if r.isMultiSet(args) {
+		return r.preRunMultiSet(c, args)
+	}
 	if len(args) > 1 {
 		r.Perform.Name = args[1]
 		r.Lookup.Name = args[1]
@@ -110,7 +138,89 @@ func (r *SetRunner) preRunE(c *cobra.Command, args []string) error {
 	return nil
 }
 
+// preRunMultiSet initializes the Setters from the ValuesFile and the NAME=VALUE
+// arguments.  The arguments override the values in the file.
+func (r *SetRunner) preRunMultiSet(c *cobra.Command, args []string) error {
+	if setterVersion == "" {
+		if err := initSetterVersion(c, args); err != nil {
+			return err
+		}
+	}
+	if setterVersion != "v2" {
+		return fmt.Errorf("several setters may only be set at once with setters v2")
+	}
+
+	index := map[string]int{}
+	add := func(s settersutil.FieldSetter) {
+		s.Description = r.Perform.Description
+		s.SetBy = r.Perform.SetBy
+		if i, found := index[s.Name]; found {
+			r.Setters[i] = s
+			return
+		}
+		index[s.Name] = len(r.Setters)
+		r.Setters = append(r.Setters, s)
+	}
+
+	if r.ValuesFile != "" {
+		values, err := yaml.ReadFile(r.ValuesFile)
+		if err != nil {
+			return err
+		}
+		err = values.VisitFields(func(node *yaml.MapNode) error {
+			s := settersutil.FieldSetter{Name: node.Key.YNode().Value}
+			switch node.Value.YNode().Kind {
+			case yaml.ScalarNode:
+				s.Value = node.Value.YNode().Value
+			case yaml.SequenceNode:
+				// the first element is the value of an array setter, as on the
+				// command line
+				elements, err := node.Value.Elements()
+				if err != nil {
+					return err
+				}
+				for i := range elements {
+					if i == 0 {
+						s.Value = elements[i].YNode().Value
+						continue
+					}
+					s.ListValues = append(s.ListValues, elements[i].YNode().Value)
+				}
+			default:
+				return fmt.Errorf("value of setter %s in %s must be a scalar or a list",
+					s.Name, r.ValuesFile)
+			}
+			add(s)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, arg := range args[1:] {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid setter %q, must be NAME=VALUE", arg)
+		}
+		add(settersutil.FieldSetter{Name: parts[0], Value: parts[1]})
+	}
+
+	var err error
+	r.OpenAPIFile, err = ext.GetOpenAPIFile(args)
+	return err
+}
+
 func (r *SetRunner) runE(c *cobra.Command, args []string) error {
+	if r.isMultiSet(args) {
+		if err := r.Setters.Set(r.OpenAPIFile, args[0]); err != nil {
+			return handleError(c, err)
+		}
+		for i := range r.Setters {
+			fmt.Fprintf(c.OutOrStdout(), "set %d fields for %s\n",
+				r.Setters[i].Count, r.Setters[i].Name)
+		}
+		return nil
+	}
 	if setterVersion == "v2" {
 		count, err := r.Set.Set(r.OpenAPIFile, args[0])
 		fmt.Fprintf(c.OutOrStdout(), "set %d fields\n", count)
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -275,3 +276,153 @@ spec:
 		})
 	}
 }
+
+func TestSetCommand_multiple(t *testing.T) {
+	inputOpenAPI := `apiVersion: v1alpha1
+kind: Example
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      maximum: 10
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+    io.k8s.cli.setters.image:
+      x-k8s-cli:
+        setter:
+          name: image
+          value: "nginx"
+    io.k8s.cli.setters.tag:
+      x-k8s-cli:
+        setter:
+          name: tag
+          value: "1.7.9"
+`
+	input := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3 # {"$ref":"#/definitions/io.k8s.cli.setters.replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx # {"$ref":"#/definitions/io.k8s.cli.setters.image"}
+      - name: sidecar
+        image: nginx # {"$ref":"#/definitions/io.k8s.cli.setters.image"}
+`
+	var tests = []struct {
+		name              string
+		values            string
+		args              []string
+		out               string
+		err               string
+		expectedResources string
+	}{
+		{
+			name:   "values-and-args",
+			values: "replicas: 4\nimage: ubuntu\n",
+			args:   []string{"image=envoy", "tag=1.8"},
+			out:    "set 1 fields for replicas\nset 2 fields for image\nset 0 fields for tag\n",
+			expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 4 # {"$ref":"#/definitions/io.k8s.cli.setters.replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: envoy # {"$ref":"#/definitions/io.k8s.cli.setters.image"}
+      - name: sidecar
+        image: envoy # {"$ref":"#/definitions/io.k8s.cli.setters.image"}
+`,
+		},
+		{
+			name:              "invalid-value",
+			args:              []string{"image=envoy", "replicas=20"},
+			err:               "20 is greater than the maximum 10 for replicas",
+			expectedResources: input,
+		},
+		{
+			name:              "missing-setter",
+			values:            "image: envoy\nport: 80\n",
+			err:               "no setter port found",
+			expectedResources: input,
+		},
+		{
+			name:              "invalid-arg",
+			args:              []string{"image=envoy", "replicas"},
+			err:               `invalid setter "replicas", must be NAME=VALUE`,
+			expectedResources: input,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			// reset the openAPI afterward
+			openapi.ResetOpenAPI()
+			defer openapi.ResetOpenAPI()
+
+			dir, err := ioutil.TempDir("", "k8s-cli-")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+			openAPIFile := filepath.Join(dir, "Krmfile")
+			if !assert.NoError(t, ioutil.WriteFile(openAPIFile, []byte(inputOpenAPI), 0600)) {
+				t.FailNow()
+			}
+			old := ext.GetOpenAPIFile
+			defer func() { ext.GetOpenAPIFile = old }()
+			ext.GetOpenAPIFile = func(args []string) (s string, err error) {
+				return openAPIFile, nil
+			}
+			resources := filepath.Join(dir, "deployment.yaml")
+			if !assert.NoError(t, ioutil.WriteFile(resources, []byte(input), 0600)) {
+				t.FailNow()
+			}
+
+			args := []string{dir}
+			if test.values != "" {
+				values := filepath.Join(dir, "values.txt")
+				if !assert.NoError(t, ioutil.WriteFile(values, []byte(test.values), 0600)) {
+					t.FailNow()
+				}
+				args = append(args, "--values", values)
+			}
+			runner := commands.NewSetRunner("")
+			out := &bytes.Buffer{}
+			runner.Command.SetOut(out)
+			runner.Command.SetArgs(append(args, test.args...))
+			err = runner.Command.Execute()
+			if test.err != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), test.err)
+
+				// nothing is written if any of the setters fail
+				actualOpenAPI, err := ioutil.ReadFile(openAPIFile)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				assert.Equal(t, inputOpenAPI, string(actualOpenAPI))
+			} else {
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				assert.Equal(t, test.out, out.String())
+			}
+
+			actualResources, err := ioutil.ReadFile(resources)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, test.expectedResources, string(actualResources))
+		})
+	}
+}
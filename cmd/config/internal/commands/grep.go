@@ -5,7 +5,6 @@ package commands
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -64,39 +63,11 @@ func (r *GrepRunner) preRunE(c *cobra.Command, args []string) error {
 
 		return qa.Cmp(qb), err
 	}
-	parts, err := parseFieldPath(args[0])
+	f, err := filters.ParseGrepQuery(args[0])
 	if err != nil {
 		return err
 	}
-
-	var last []string
-	if strings.Contains(parts[len(parts)-1], ">=") {
-		last = strings.Split(parts[len(parts)-1], ">=")
-		r.MatchType = filters.GreaterThanEq
-	} else if strings.Contains(parts[len(parts)-1], "<=") {
-		last = strings.Split(parts[len(parts)-1], "<=")
-		r.MatchType = filters.LessThanEq
-	} else if strings.Contains(parts[len(parts)-1], ">") {
-		last = strings.Split(parts[len(parts)-1], ">")
-		r.MatchType = filters.GreaterThan
-	} else if strings.Contains(parts[len(parts)-1], "<") {
-		last = strings.Split(parts[len(parts)-1], "<")
-		r.MatchType = filters.LessThan
-	} else {
-		last = strings.Split(parts[len(parts)-1], "=")
-		r.MatchType = filters.Regexp
-	}
-	if len(last) > 2 {
-		return fmt.Errorf(
-			"ambiguous match -- multiple of ['<', '>', '<=', '>=', '=' in final path element: %s",
-			parts[len(parts)-1])
-	}
-
-	if len(last) > 1 {
-		r.Value = last[1]
-	}
-
-	r.Path = append(parts[:len(parts)-1], last[0])
+	r.Path, r.Value, r.MatchType = f.Path, f.Value, f.MatchType
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -268,3 +269,72 @@ spec:
 		return
 	}
 }
+
+// TestGrepCmd_operators verifies the grep command compares quantities and matches
+// regular expressions and existing fields
+func TestGrepCmd_operators(t *testing.T) {
+	in := `kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: hpa
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: gcr.io/project/app:v1
+        resources:
+          limits:
+            memory: 1Gi
+---
+kind: Deployment
+metadata:
+  name: bar
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: docker.io/app:v1
+        resources:
+          limits:
+            memory: 512Mi
+`
+	var tests = []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "quantity",
+			query:    "spec.template.spec.containers[name=app].resources.limits.memory>600Mi",
+			expected: "foo",
+		},
+		{
+			name:     "regexp",
+			query:    `spec.template.spec.containers[name=app].image=~^docker\.io/`,
+			expected: "bar",
+		},
+		{
+			name:     "exists",
+			query:    "metadata.annotations.scaler",
+			expected: "foo",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			r := commands.GetGrepRunner("")
+			r.Command.SetArgs([]string{test.query})
+			r.Command.SetOut(b)
+			r.Command.SetIn(bytes.NewBufferString(in))
+			if !assert.NoError(t, r.Command.Execute()) {
+				t.FailNow()
+			}
+			assert.Contains(t, b.String(), "name: "+test.expected+"\n")
+			assert.Equal(t, 1, strings.Count(b.String(), "kind: Deployment"))
+		})
+	}
+}
@@ -4,10 +4,14 @@
 package commands
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
 )
 
 func GetMergeRunner(name string) *MergeRunner {
@@ -23,6 +27,8 @@ func GetMergeRunner(name string) *MergeRunner {
 	r.Command = c
 	r.Command.Flags().BoolVar(&r.InvertOrder, "invert-order", false,
 		"if true, merge Resources in the reverse order")
+	r.Command.Flags().StringSliceVar(&r.Schemas, "schema", nil,
+		"OpenAPI or CustomResourceDefinition files with the merge keys of the lists of Resources")
 	return r
 }
 
@@ -34,9 +40,14 @@ func MergeCommand(name string) *cobra.Command {
 type MergeRunner struct {
 	Command     *cobra.Command
 	InvertOrder bool
+	Schemas     []string
 }
 
 func (r *MergeRunner) runE(c *cobra.Command, args []string) error {
+	if err := addSchemas(r.Schemas); err != nil {
+		return err
+	}
+
 	var inputs []kio.Reader
 	// add the packages in reverse order -- the arg list should be highest precedence first
 	// e.g. merge from -> to, but the MergeFilter is highest precedence last
@@ -66,3 +77,37 @@ func (r *MergeRunner) runE(c *cobra.Command, args []string) error {
 	filters := []kio.Filter{filters.MergeFilter{}, filters.FormatFilter{}}
 	return handleError(c, kio.Pipeline{Inputs: inputs, Filters: filters, Outputs: outputs}.Execute())
 }
+
+// addSchemas adds the schemas of the files at paths to the global OpenAPI schema, so
+// that the lists of Resources are merged using their `x-kubernetes-patch-merge-key`
+// extensions.  The files contain CustomResourceDefinitions, or OpenAPI definitions
+// either in the `openAPI` field or as the whole document.
+func addSchemas(paths []string) error {
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		nodes, err := (&kio.ByteReader{Reader: f, OmitReaderAnnotations: true}).Read()
+		f.Close()
+		if err != nil {
+			return errors.WrapPrefixf(err, "failed to read schema %s", path)
+		}
+		for i := range nodes {
+			meta, _ := nodes[i].GetMeta()
+			switch {
+			case meta.Kind == "CustomResourceDefinition":
+				err = openapi.AddSchemaFromCRD(nodes[i])
+			case nodes[i].Field(openapi.SupplementaryOpenAPIFieldName) != nil:
+				err = openapi.AddSchemaFromRNodeUsingField(
+					nodes[i], openapi.SupplementaryOpenAPIFieldName)
+			default:
+				err = openapi.AddSchemaFromRNodeUsingField(nodes[i], "")
+			}
+			if err != nil {
+				return errors.WrapPrefixf(err, "failed to add schema %s", path)
+			}
+		}
+	}
+	return nil
+}
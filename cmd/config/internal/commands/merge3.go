@@ -27,6 +27,10 @@ func GetMerge3Runner(name string) *Merge3Runner {
 		"Path to destination package")
 	c.Flags().BoolVar(&r.path, "path-merge-key", false,
 		"Use the path as part of the merge key when merging resources")
+	c.Flags().BoolVar(&r.keepDeleted, "keep-deleted", false,
+		"Keep the resources deleted in the updated package in the destination package")
+	c.Flags().StringSliceVar(&r.schemas, "schema", nil,
+		"OpenAPI or CustomResourceDefinition files with the merge keys of the lists of resources")
 
 	r.Command = c
 	return r
@@ -43,14 +47,21 @@ type Merge3Runner struct {
 	fromDir  string
 	toDir    string
 	path     bool
+
+	keepDeleted bool
+	schemas     []string
 }
 
 func (r *Merge3Runner) runE(c *cobra.Command, args []string) error {
+	if err := addSchemas(r.schemas); err != nil {
+		return err
+	}
 	err := filters.Merge3{
 		OriginalPath: r.ancestor,
 		UpdatedPath:  r.fromDir,
 		DestPath:     r.toDir,
 		MergeOnPath:  r.path,
+		KeepDeleted:  r.keepDeleted,
 	}.Merge()
 	if err != nil {
 		return err
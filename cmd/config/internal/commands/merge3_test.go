@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/cmd/config/internal/commands"
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
 )
 
 // TestMerge3Command verifies the merge3 correctly applies the diff between 2 sets of resources into another
@@ -234,3 +235,115 @@ spec:
 		t.FailNow()
 	}
 }
+
+const routerCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: routers.example.com
+spec:
+  group: example.com
+  names:
+    kind: Router
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              routes:
+                type: array
+                x-kubernetes-patch-merge-key: host
+                x-kubernetes-patch-strategy: merge
+                items:
+                  type: object
+`
+
+// TestMerge3Command_schema verifies the lists of custom resources are merged using the
+// merge keys of the --schema CRDs
+func TestMerge3Command_schema(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	dir, err := ioutil.TempDir("", "test-data")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"crd.yaml": routerCRD,
+		"original/router.yaml": `apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 80
+  - host: b.example.com
+    port: 80
+`,
+		"updated/router.yaml": `apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 8080
+  - host: b.example.com
+    port: 80
+`,
+		"dest/router.yaml": `apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 80
+  - host: b.example.com
+    port: 80
+    timeout: 10s
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700)) {
+			t.FailNow()
+		}
+		if !assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600)) {
+			t.FailNow()
+		}
+	}
+
+	r := commands.GetMerge3Runner("")
+	r.Command.SetArgs([]string{
+		"--ancestor", filepath.Join(dir, "original"),
+		"--from", filepath.Join(dir, "updated"),
+		"--to", filepath.Join(dir, "dest"),
+		"--schema", filepath.Join(dir, "crd.yaml"),
+	})
+	if !assert.NoError(t, r.Command.Execute()) {
+		t.FailNow()
+	}
+
+	// the routes are merged by host rather than replaced by the updated routes
+	b, err := ioutil.ReadFile(filepath.Join(dir, "dest", "router.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 8080
+  - host: b.example.com
+    port: 80
+    timeout: 10s
+`, string(b))
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/cmd/config/internal/commands"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+// TestMergeCommand_schema verifies the lists of custom resources are merged using the
+// merge keys of the --schema CRDs
+func TestMergeCommand_schema(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	dir, err := ioutil.TempDir("", "test-data")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	if !assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "crd.yaml"), []byte(routerCRD), 0600)) {
+		t.FailNow()
+	}
+
+	r := commands.GetMergeRunner("")
+	r.Command.SetArgs([]string{"--schema", filepath.Join(dir, "crd.yaml")})
+	r.Command.SetIn(bytes.NewBufferString(`apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 80
+    timeout: 10s
+  - host: b.example.com
+    port: 80
+---
+apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 8080
+  - host: c.example.com
+    port: 80
+`))
+	out := &bytes.Buffer{}
+	r.Command.SetOut(out)
+	if !assert.NoError(t, r.Command.Execute()) {
+		t.FailNow()
+	}
+
+	// the routes are merged by host rather than replaced
+	assert.Equal(t, `apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+  annotations:
+    config.kubernetes.io/index: '1'
+spec:
+  routes:
+  - port: 8080
+    host: a.example.com
+    timeout: 10s
+  - port: 80
+    host: b.example.com
+  - port: 80
+    host: c.example.com
+`, out.String())
+}
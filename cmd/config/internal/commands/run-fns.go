@@ -6,7 +6,9 @@ package commands
 import (
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
@@ -33,6 +35,10 @@ func GetRunFnRunner(name string) *RunFnRunner {
 	r.Command = c
 	r.Command.Flags().BoolVar(
 		&r.DryRun, "dry-run", false, "print results to stdout")
+	r.Command.Flags().BoolVar(
+		&r.Diff, "diff", false,
+		"print a diff of the files which would change instead of writing them, "+
+			"and exit non-zero if any would change.")
 	r.Command.Flags().BoolVar(
 		&r.GlobalScope, "global-scope", false, "set global scope for functions.")
 	r.Command.Flags().StringSliceVar(
@@ -41,6 +47,15 @@ func GetRunFnRunner(name string) *RunFnRunner {
 	r.Command.Flags().StringVar(
 		&r.Image, "image", "",
 		"run this image as a function instead of discovering them.")
+	r.Command.Flags().StringVar(
+		&r.ExecPath, "exec-path", "",
+		"run this executable as a function instead of discovering them.")
+	r.Command.Flags().BoolVar(
+		&r.EnableExec, "enable-exec", false,
+		"enable running executables outside of the package as functions.")
+	r.Command.Flags().StringVar(
+		&r.FnConfigPath, "fn-config", "",
+		"read the functionConfig of the commandline function from this file instead of the package.")
 	r.Command.Flags().BoolVar(
 		&r.EnableStar, "enable-star", false, "enable support for starlark functions.")
 	r.Command.Flags().MarkHidden("enable-star")
@@ -50,6 +65,10 @@ func GetRunFnRunner(name string) *RunFnRunner {
 	r.Command.Flags().StringVar(
 		&r.StarName, "star-name", "", "name of starlark program.")
 	r.Command.Flags().MarkHidden("star-name")
+	r.Command.Flags().DurationVar(
+		&r.StarTimeout, "star-timeout", time.Minute,
+		"max time each starlark function may run for, 0 for no limit.")
+	r.Command.Flags().MarkHidden("star-timeout")
 
 	r.Command.Flags().BoolVar(
 		&r.Network, "network", false, "enable network access for functions that declare it")
@@ -58,6 +77,18 @@ func GetRunFnRunner(name string) *RunFnRunner {
 	r.Command.Flags().StringArrayVar(
 		&r.Mounts, "mount", []string{},
 		"a list of storage options read from the filesystem")
+	r.Command.Flags().BoolVar(
+		&r.EnableExternalMounts, "enable-external-mounts", false,
+		"enable functions to bind mount host paths outside of the package.")
+	r.Command.Flags().StringArrayVar(
+		&r.Env, "env", []string{},
+		"a list of environment variables to set in the function containers, as NAME=VALUE or NAME")
+	r.Command.Flags().IntVar(
+		&r.Parallel, "parallel", 0,
+		"run the functions of each package under DIR separately, this many packages at a time.")
+	r.Command.Flags().BoolVar(
+		&r.FailFast, "fail-fast", false,
+		"don't run any more packages after a package fails, with --parallel.")
 	return r
 }
 
@@ -67,19 +98,28 @@ func RunFnCommand(name string) *cobra.Command {
 
 // RunFnRunner contains the run function
 type RunFnRunner struct {
-	IncludeSubpackages bool
-	Command            *cobra.Command
-	DryRun             bool
-	GlobalScope        bool
-	FnPaths            []string
-	Image              string
-	EnableStar         bool
-	StarPath           string
-	StarName           string
-	RunFns             runfn.RunFns
-	Network            bool
-	NetworkName        string
-	Mounts             []string
+	IncludeSubpackages   bool
+	Command              *cobra.Command
+	DryRun               bool
+	Diff                 bool
+	GlobalScope          bool
+	FnPaths              []string
+	Image                string
+	ExecPath             string
+	EnableExec           bool
+	FnConfigPath         string
+	EnableStar           bool
+	StarPath             string
+	StarName             string
+	StarTimeout          time.Duration
+	RunFns               runfn.RunFns
+	Network              bool
+	NetworkName          string
+	Mounts               []string
+	EnableExternalMounts bool
+	Env                  []string
+	Parallel             int
+	FailFast             bool
 }
 
 func (r *RunFnRunner) runE(c *cobra.Command, args []string) error {
@@ -90,7 +130,7 @@ func (r *RunFnRunner) runE(c *cobra.Command, args []string) error {
 // Functions to run.
 func (r *RunFnRunner) getContainerFunctions(c *cobra.Command, args, dataItems []string) (
 	[]*yaml.RNode, error) {
-	if r.Image == "" && r.StarPath == "" {
+	if r.Image == "" && r.StarPath == "" && r.ExecPath == "" {
 		return nil, nil
 	}
 
@@ -119,6 +159,23 @@ func (r *RunFnRunner) getContainerFunctions(c *cobra.Command, args, dataItems []
 				return nil, err
 			}
 		}
+	} else if r.ExecPath != "" {
+		// create the function spec to set as an annotation
+		fn, err = yaml.Parse(`exec: {}`)
+		if err != nil {
+			return nil, err
+		}
+		// relative paths are relative to the working directory rather than the package
+		path, err := filepath.Abs(r.ExecPath)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		err = fn.PipeE(
+			yaml.Lookup("exec"),
+			yaml.SetField("path", yaml.NewScalarRNode(path)))
+		if err != nil {
+			return nil, err
+		}
 	} else if r.EnableStar && r.StarPath != "" {
 		// create the function spec to set as an annotation
 		fn, err = yaml.Parse(`starlark: {}`)
@@ -217,7 +274,7 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 		return errors.Errorf("must specify --star-path with --enable-star")
 	}
 
-	if c.ArgsLenAtDash() >= 0 && r.Image == "" &&
+	if c.ArgsLenAtDash() >= 0 && r.Image == "" && r.ExecPath == "" &&
 		!(r.EnableStar && r.StarPath != "") {
 		return errors.Errorf("must specify --image")
 	}
@@ -230,6 +287,29 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	if len(args) > 1 {
 		return errors.Errorf("0 or 1 arguments supported, function arguments go after '--'")
 	}
+	if r.Diff && len(args) == 0 {
+		return errors.Errorf("--diff may only be used with a DIR")
+	}
+	if r.Diff && r.DryRun {
+		return errors.Errorf("--diff can't be used with --dry-run")
+	}
+	if r.Parallel < 0 {
+		return errors.Errorf("--parallel must not be negative")
+	}
+	if r.Parallel > 0 && len(args) == 0 {
+		return errors.Errorf("--parallel may only be used with a DIR")
+	}
+	if r.FailFast && r.Parallel == 0 {
+		return errors.Errorf("--fail-fast may only be used with --parallel")
+	}
+	if r.FnConfigPath != "" {
+		if r.Image == "" && r.ExecPath == "" && r.StarPath == "" {
+			return errors.Errorf("must specify --image, --exec-path or --star-path with --fn-config")
+		}
+		if len(dataItems) > 0 {
+			return errors.Errorf("function arguments after '--' can't be used with --fn-config")
+		}
+	}
 
 	fns, err := r.getContainerFunctions(c, args, dataItems)
 	if err != nil {
@@ -257,16 +337,28 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	storageMounts := toStorageMounts(r.Mounts)
 
 	r.RunFns = runfn.RunFns{
-		FunctionPaths:  r.FnPaths,
-		GlobalScope:    r.GlobalScope,
-		Functions:      fns,
-		Output:         output,
-		Input:          input,
-		Path:           path,
-		Network:        r.Network,
-		NetworkName:    r.NetworkName,
-		EnableStarlark: r.EnableStar,
-		StorageMounts:  storageMounts,
+		FunctionPaths:        r.FnPaths,
+		GlobalScope:          r.GlobalScope,
+		Functions:            fns,
+		FnConfigPath:         r.FnConfigPath,
+		Output:               output,
+		Diff:                 r.Diff,
+		DiffOutput:           c.OutOrStdout(),
+		ResultsOutput:        c.ErrOrStderr(),
+		WarningOutput:        c.ErrOrStderr(),
+		Stderr:               c.ErrOrStderr(),
+		Input:                input,
+		Path:                 path,
+		Network:              r.Network,
+		NetworkName:          r.NetworkName,
+		EnableStarlark:       r.EnableStar,
+		StarlarkTimeout:      r.StarTimeout,
+		EnableExec:           r.EnableExec,
+		StorageMounts:        storageMounts,
+		Env:                  r.Env,
+		EnableExternalMounts: r.EnableExternalMounts,
+		Parallel:             r.Parallel,
+		FailFast:             r.FailFast,
 	}
 
 	// don't consider args for the function
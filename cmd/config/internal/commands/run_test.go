@@ -4,10 +4,14 @@
 package commands
 
 import (
+	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +32,13 @@ func TestRunFnCommand_preRunE(t *testing.T) {
 		network       bool
 		networkName   string
 		mount         []string
+		fnConfigPath  string
+		starTimeout   time.Duration
+		env           []string
+		externalMount bool
+		diff          bool
+		parallel      int
+		failFast      bool
 	}{
 		{
 			name: "config map",
@@ -76,6 +87,32 @@ kind: ConfigMap
 apiVersion: v1
 `,
 		},
+		{
+			name: "config map diff",
+			args: []string{"run", "dir", "--image", "foo:bar", "--diff", "--", "a=b", "c=d", "e=f"},
+			diff: true,
+			path: "dir",
+			expected: `
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      container: {image: 'foo:bar'}
+data: {a: b, c: d, e: f}
+kind: ConfigMap
+apiVersion: v1
+`,
+		},
+		{
+			name: "diff without dir",
+			args: []string{"run", "--image", "foo:bar", "--diff"},
+			err:  "--diff may only be used with a DIR",
+		},
+		{
+			name: "diff with dry-run",
+			args: []string{"run", "dir", "--image", "foo:bar", "--diff", "--dry-run"},
+			err:  "--diff can't be used with --dry-run",
+		},
 		{
 			name: "config map no args",
 			args: []string{"run", "dir", "--image", "foo:bar"},
@@ -174,6 +211,16 @@ kind: Foo
 apiVersion: v1
 `,
 		},
+		{
+			name: "star-timeout",
+			args: []string{"run", "dir",
+				"--enable-star",
+				"--star-path", "a/b/c",
+				"--star-name", "foo",
+				"--star-timeout", "5s"},
+			path:        "dir",
+			starTimeout: 5 * time.Second,
+		},
 		{
 			name: "star-not-enabled",
 			args: []string{"run", "dir",
@@ -216,6 +263,35 @@ kind: Foo
 apiVersion: v1
 `,
 		},
+		{
+			name: "env and external mounts",
+			args: []string{
+				"run", "dir", "--env", "TOKEN", "--env", "REGISTRY=example.com",
+				"--mount", "type=bind,src=/tmp/out,dst=/out,rw=true",
+				"--enable-external-mounts",
+				"--image", "foo:bar"},
+			path:          "dir",
+			mount:         []string{"type=bind,src=/tmp/out,dst=/out,rw=true"},
+			env:           []string{"TOKEN", "REGISTRY=example.com"},
+			externalMount: true,
+		},
+		{
+			name:     "parallel",
+			args:     []string{"run", "dir", "--parallel", "4", "--fail-fast"},
+			path:     "dir",
+			parallel: 4,
+			failFast: true,
+		},
+		{
+			name: "parallel without dir",
+			args: []string{"run", "--parallel", "4"},
+			err:  "--parallel may only be used with a DIR",
+		},
+		{
+			name: "fail-fast without parallel",
+			args: []string{"run", "dir", "--fail-fast"},
+			err:  "--fail-fast may only be used with --parallel",
+		},
 		{
 			name: "custom kind with storage mounts",
 			args: []string{
@@ -236,6 +312,68 @@ kind: Foo
 apiVersion: v1
 `,
 		},
+		{
+			name:         "fn-config",
+			args:         []string{"run", "dir", "--image", "foo:bar", "--fn-config", "fn-config.yaml"},
+			path:         "dir",
+			fnConfigPath: "fn-config.yaml",
+			expected: `
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      container: {image: 'foo:bar'}
+data: {}
+kind: ConfigMap
+apiVersion: v1
+`,
+		},
+		{
+			name: "fn-config star",
+			args: []string{"run", "dir",
+				"--enable-star",
+				"--star-path", "a/b/c",
+				"--star-name", "foo",
+				"--fn-config", "fn-config.yaml"},
+			path:         "dir",
+			fnConfigPath: "fn-config.yaml",
+			expected: `
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      starlark: {path: a/b/c, name: foo}
+data: {}
+kind: ConfigMap
+apiVersion: v1
+`,
+		},
+		{
+			name: "exec",
+			args: []string{"run", "dir", "--exec-path", "/fn/scaler", "--", "a=b"},
+			path: "dir",
+			expected: `
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      exec: {path: /fn/scaler}
+data: {a: b}
+kind: ConfigMap
+apiVersion: v1
+`,
+		},
+		{
+			name: "fn-config without image",
+			args: []string{"run", "dir", "--fn-config", "fn-config.yaml"},
+			err:  "must specify --image, --exec-path or --star-path with --fn-config",
+		},
+		{
+			name: "fn-config with data",
+			args: []string{"run", "dir", "--image", "foo:bar", "--fn-config", "fn-config.yaml",
+				"--", "a=b"},
+			err: "function arguments after '--' can't be used with --fn-config",
+		},
 		{
 			name: "config map multi args",
 			args: []string{"run", "dir", "dir2", "--image", "foo:bar", "--", "a=b", "c=d", "e=f"},
@@ -296,6 +434,11 @@ apiVersion: v1
 				t.FailNow()
 			}
 
+			// check if Diff was set
+			if !assert.Equal(t, tt.diff, r.RunFns.Diff) {
+				t.FailNow()
+			}
+
 			// check if Path was set
 			if !assert.Equal(t, tt.path, r.RunFns.Path) {
 				t.FailNow()
@@ -328,6 +471,40 @@ apiVersion: v1
 				t.FailNow()
 			}
 
+			// check if Env was set
+			if tt.env == nil {
+				// make Equal work against flag default
+				tt.env = []string{}
+			}
+			if !assert.Equal(t, tt.env, r.RunFns.Env) {
+				t.FailNow()
+			}
+			if !assert.Equal(t, tt.externalMount, r.RunFns.EnableExternalMounts) {
+				t.FailNow()
+			}
+
+			// check if Parallel and FailFast were set
+			if !assert.Equal(t, tt.parallel, r.RunFns.Parallel) {
+				t.FailNow()
+			}
+			if !assert.Equal(t, tt.failFast, r.RunFns.FailFast) {
+				t.FailNow()
+			}
+
+			// check if FnConfigPath was set
+			if !assert.Equal(t, tt.fnConfigPath, r.RunFns.FnConfigPath) {
+				t.FailNow()
+			}
+
+			// check if StarlarkTimeout was set
+			if tt.starTimeout == 0 {
+				// make Equal work against flag default
+				tt.starTimeout = time.Minute
+			}
+			if !assert.Equal(t, tt.starTimeout, r.RunFns.StarlarkTimeout) {
+				t.FailNow()
+			}
+
 			// check if Functions were set
 			if tt.expected != "" {
 				if !assert.Len(t, r.RunFns.Functions, 1) {
@@ -343,3 +520,194 @@ apiVersion: v1
 	}
 
 }
+
+// TestRunFnCommand_fnConfig verifies that a function run from the commandline is
+// given the functionConfig from --fn-config, and that it isn't written to the package.
+func TestRunFnCommand_fnConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-run-fn-config")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	pkg := filepath.Join(dir, "pkg")
+	if !assert.NoError(t, os.Mkdir(pkg, 0700)) {
+		t.FailNow()
+	}
+
+	files := map[string]string{
+		filepath.Join(pkg, "deployment.yaml"): `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+		filepath.Join(dir, "annotate.star"): `
+def run(items, value):
+  for item in items:
+    item["metadata"]["annotations"]["foo"] = value
+
+run(ctx.resource_list["items"], ctx.resource_list["functionConfig"]["spec"]["value"])
+`,
+		filepath.Join(dir, "fn-config.yaml"): `apiVersion: example.com/v1
+kind: AnnotationSetter
+spec:
+  value: bar
+`,
+	}
+	for path, data := range files {
+		if !assert.NoError(t, ioutil.WriteFile(path, []byte(data), 0600)) {
+			t.FailNow()
+		}
+	}
+
+	r := GetRunFnRunner("kustomize")
+	r.Command.SetArgs([]string{pkg,
+		"--enable-star",
+		"--star-path", filepath.Join(dir, "annotate.star"),
+		"--fn-config", filepath.Join(dir, "fn-config.yaml")})
+	if !assert.NoError(t, r.Command.Execute()) {
+		t.FailNow()
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(pkg, "deployment.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    foo: bar
+`, string(b))
+
+	fis, err := ioutil.ReadDir(pkg)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, fis, 1)
+}
+
+// TestRunFnCommand_execFnConfig verifies that an executable run from the commandline
+// is given the functionConfig from --fn-config, and is only run outside of the
+// package with --enable-exec.
+func TestRunFnCommand_execFnConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-run-exec")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	pkg := filepath.Join(dir, "pkg")
+	if !assert.NoError(t, os.Mkdir(pkg, 0700)) {
+		t.FailNow()
+	}
+
+	files := map[string]string{
+		filepath.Join(pkg, "deployment.yaml"): `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+		filepath.Join(dir, "fn.sh"): `#!/bin/sh
+input=$(cat)
+echo "$input" | grep -q "value: bar" || { echo "missing functionConfig" >&2; exit 1; }
+echo "$input" | sed 's/kind: Deployment/kind: StatefulSet/'
+`,
+		filepath.Join(dir, "fn-config.yaml"): `apiVersion: example.com/v1
+kind: Example
+spec:
+  value: bar
+`,
+	}
+	for path, data := range files {
+		if !assert.NoError(t, ioutil.WriteFile(path, []byte(data), 0700)) {
+			t.FailNow()
+		}
+	}
+	args := []string{pkg,
+		"--exec-path", filepath.Join(dir, "fn.sh"),
+		"--fn-config", filepath.Join(dir, "fn-config.yaml")}
+
+	// the executable is outside of the package
+	r := GetRunFnRunner("kustomize")
+	r.Command.SilenceErrors = true
+	r.Command.SilenceUsage = true
+	r.Command.SetArgs(args)
+	err = r.Command.Execute()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "enable it with --enable-exec")
+	}
+
+	r = GetRunFnRunner("kustomize")
+	r.Command.SetArgs(append(args, "--enable-exec"))
+	if !assert.NoError(t, r.Command.Execute()) {
+		t.FailNow()
+	}
+	b, err := ioutil.ReadFile(filepath.Join(pkg, "deployment.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: foo
+`, string(b))
+}
+
+// TestRunFnCommand_diff verifies that --diff prints the diff of the files which
+// would change without writing them, and fails if any would change.
+func TestRunFnCommand_diff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-run-diff")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`
+	files := map[string]string{
+		"deployment.yaml": deployment,
+		"fn.sh":           "#!/bin/sh\nsed 's/kind: Deployment/kind: StatefulSet/'\n",
+		"noop.sh":         "#!/bin/sh\ncat\n",
+	}
+	for path, data := range files {
+		if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, path), []byte(data), 0700)) {
+			t.FailNow()
+		}
+	}
+
+	out := &bytes.Buffer{}
+	r := GetRunFnRunner("kustomize")
+	r.Command.SilenceErrors = true
+	r.Command.SilenceUsage = true
+	r.Command.SetOut(out)
+	r.Command.SetArgs([]string{dir, "--diff", "--exec-path", filepath.Join(dir, "fn.sh")})
+	err = r.Command.Execute()
+	if assert.Error(t, err) {
+		assert.Equal(t, "functions would change 1 files: deployment.yaml", err.Error())
+	}
+	assert.Equal(t, `--- a/deployment.yaml
++++ b/deployment.yaml
+@@ -1,4 +1,4 @@
+ apiVersion: apps/v1
+-kind: Deployment
++kind: StatefulSet
+ metadata:
+   name: foo
+`, out.String())
+	b, err := ioutil.ReadFile(filepath.Join(dir, "deployment.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, deployment, string(b))
+
+	// nothing would change
+	out.Reset()
+	r = GetRunFnRunner("kustomize")
+	r.Command.SetOut(out)
+	r.Command.SetArgs([]string{dir, "--diff", "--exec-path", filepath.Join(dir, "noop.sh")})
+	assert.NoError(t, r.Command.Execute())
+	assert.Empty(t, out.String())
+}
@@ -12,7 +12,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/kyaml/kio"
-	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func GetTreeRunner(name string) *TreeRunner {
@@ -92,57 +91,57 @@ func (r *TreeRunner) runE(c *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		fields = append(fields, newField(path...))
+		fields = append(fields, kio.NewTreeWriterField(path...))
 	}
 
 	if r.name || (r.all && !c.Flag("name").Changed) {
 		fields = append(fields,
-			newField("spec", "containers", "[name=.*]", "name"),
-			newField("spec", "template", "spec", "containers", "[name=.*]", "name"),
+			kio.NewTreeWriterField("spec", "containers", "[name=.*]", "name"),
+			kio.NewTreeWriterField("spec", "template", "spec", "containers", "[name=.*]", "name"),
 		)
 	}
 	if r.images || (r.all && !c.Flag("image").Changed) {
 		fields = append(fields,
-			newField("spec", "containers", "[name=.*]", "image"),
-			newField("spec", "template", "spec", "containers", "[name=.*]", "image"),
+			kio.NewTreeWriterField("spec", "containers", "[name=.*]", "image"),
+			kio.NewTreeWriterField("spec", "template", "spec", "containers", "[name=.*]", "image"),
 		)
 	}
 
 	if r.cmd || (r.all && !c.Flag("command").Changed) {
 		fields = append(fields,
-			newField("spec", "containers", "[name=.*]", "command"),
-			newField("spec", "template", "spec", "containers", "[name=.*]", "command"),
+			kio.NewTreeWriterField("spec", "containers", "[name=.*]", "command"),
+			kio.NewTreeWriterField("spec", "template", "spec", "containers", "[name=.*]", "command"),
 		)
 	}
 	if r.args || (r.all && !c.Flag("args").Changed) {
 		fields = append(fields,
-			newField("spec", "containers", "[name=.*]", "args"),
-			newField("spec", "template", "spec", "containers", "[name=.*]", "args"),
+			kio.NewTreeWriterField("spec", "containers", "[name=.*]", "args"),
+			kio.NewTreeWriterField("spec", "template", "spec", "containers", "[name=.*]", "args"),
 		)
 	}
 	if r.env || (r.all && !c.Flag("env").Changed) {
 		fields = append(fields,
-			newField("spec", "containers", "[name=.*]", "env"),
-			newField("spec", "template", "spec", "containers", "[name=.*]", "env"),
+			kio.NewTreeWriterField("spec", "containers", "[name=.*]", "env"),
+			kio.NewTreeWriterField("spec", "template", "spec", "containers", "[name=.*]", "env"),
 		)
 	}
 
 	if r.replicas || (r.all && !c.Flag("replicas").Changed) {
 		fields = append(fields,
-			newField("spec", "replicas"),
+			kio.NewTreeWriterField("spec", "replicas"),
 		)
 	}
 	if r.resources || (r.all && !c.Flag("resources").Changed) {
 		fields = append(fields,
-			newField("spec", "containers", "[name=.*]", "resources"),
-			newField("spec", "template", "spec", "containers", "[name=.*]", "resources"),
+			kio.NewTreeWriterField("spec", "containers", "[name=.*]", "resources"),
+			kio.NewTreeWriterField("spec", "template", "spec", "containers", "[name=.*]", "resources"),
 		)
 	}
 	if r.ports || (r.all && !c.Flag("ports").Changed) {
 		fields = append(fields,
-			newField("spec", "containers", "[name=.*]", "ports"),
-			newField("spec", "template", "spec", "containers", "[name=.*]", "ports"),
-			newField("spec", "ports"),
+			kio.NewTreeWriterField("spec", "containers", "[name=.*]", "ports"),
+			kio.NewTreeWriterField("spec", "template", "spec", "containers", "[name=.*]", "ports"),
+			kio.NewTreeWriterField("spec", "ports"),
 		)
 	}
 
@@ -162,26 +161,3 @@ func (r *TreeRunner) runE(c *cobra.Command, args []string) error {
 			Structure: kio.TreeStructure(r.structure)}},
 	}.Execute())
 }
-
-func newField(val ...string) kio.TreeWriterField {
-	if strings.HasPrefix(strings.Join(val, "."), "spec.template.spec.containers") {
-		return kio.TreeWriterField{
-			Name:        "spec.template.spec.containers",
-			PathMatcher: yaml.PathMatcher{Path: val, StripComments: true},
-			SubName:     val[len(val)-1],
-		}
-	}
-
-	if strings.HasPrefix(strings.Join(val, "."), "spec.containers") {
-		return kio.TreeWriterField{
-			Name:        "spec.containers",
-			PathMatcher: yaml.PathMatcher{Path: val, StripComments: true},
-			SubName:     val[len(val)-1],
-		}
-	}
-
-	return kio.TreeWriterField{
-		Name:        strings.Join(val, "."),
-		PathMatcher: yaml.PathMatcher{Path: val, StripComments: true},
-	}
-}
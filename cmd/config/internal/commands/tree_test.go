@@ -346,3 +346,64 @@ spec:
 		return
 	}
 }
+
+// TestTreeCommand_fields verifies the tree command displays the values of the fields,
+// and omits the fields which are missing
+func TestTreeCommand_fields(t *testing.T) {
+	b := &bytes.Buffer{}
+	r := commands.GetTreeRunner("")
+	r.Command.SetArgs([]string{
+		"--field", "spec.replicas",
+		"--field", "metadata.annotations.scaler",
+		"--field", "spec.template.spec.containers[name=app].image",
+		"--field", "spec.ports[name=http].port",
+	})
+	r.Command.SetIn(bytes.NewBufferString(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+    config.kubernetes.io/path: f1.yaml
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+      - name: sidecar
+        image: envoy:v1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+  annotations:
+    scaler:
+    config.kubernetes.io/path: f1.yaml
+spec:
+  ports:
+  - name: http
+    port: 80
+`))
+	r.Command.SetOut(b)
+	if !assert.NoError(t, r.Command.Execute()) {
+		return
+	}
+
+	if !assert.Equal(t, `.
+├── [f1.yaml]  Deployment foo
+│   ├── metadata.annotations.scaler: "3"
+│   ├── spec.replicas: 3
+│   └── spec.template.spec.containers
+│       └── 0
+│           └── image: app:v1
+└── [f1.yaml]  Service foo
+    └── spec.ports
+        └── 0
+            └── port: 80
+`, b.String()) {
+		return
+	}
+}
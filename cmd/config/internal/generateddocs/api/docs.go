@@ -447,6 +447,17 @@ var Merge2Long = `# Merge (2-way)
     - if present only in the src, it is added to the dest list
     - if the field is present in both the src and dest, the value is recursively merged
 
+  ### Patch Directives
+
+  The src may contain strategic merge patch directives in the ` + "`" + `$patch` + "`" + ` field, which are
+  removed from the result:
+
+  - ` + "`" + `$patch: delete` + "`" + ` on a map removes it from the dest, and on an associative list element
+    removes the matching element from the dest list, keeping the order of the other elements
+  - ` + "`" + `$patch: replace` + "`" + ` on a map, or as a list element containing only the field, replaces the
+    dest map or list with the src instead of merging them
+  - deleting a map or element missing from the dest doesn't do anything
+
   ### Associative Keys
 
   Associative keys are used to identify "same" elements within 2 different lists, and merge them.
@@ -543,6 +554,16 @@ var Merge3Long = `# Merge (3-way)
     - if not-present in the dest, add the delta between original-updated as a field
     - otherwise recursively merge the value between original, updated, dest
 
+### Patch Directives
+
+  The updated source may contain strategic merge patch directives in the ` + "`" + `$patch` + "`" + ` field, which
+  are removed from the result:
+
+  - ` + "`" + `$patch: delete` + "`" + ` on a map removes it from the dest, and on an associative list element
+    removes the matching element from the dest list, keeping the order of the other elements
+  - ` + "`" + `$patch: replace` + "`" + ` on a map, or as a list element containing only the field, replaces the
+    dest map or list with the updated source instead of merging them
+
 ### Associative Keys
 
   Associative keys are used to identify "same" elements within 2 different lists, and merge them.
@@ -140,6 +140,17 @@ var GrepLong = `
     The value to match is expressed as '=value'
     '.' as part of a key or value can be escaped as '\.'
 
+    Operators:
+      '=value'   the field matches the regular expression value, in which
+                 '\.' is unescaped
+      '=~value'  the field matches the regular expression value, which is
+                 taken as is
+      '>value', '>=value', '<value', '<=value'
+                 the field compares with the quantity value.  Fields are
+                 compared by their value, so the string "5" and the int 5 are
+                 equal, and fields which aren't quantities don't match.
+      none       the field exists, with any value
+
   DIR:
     Path to local directory.
 `
@@ -154,7 +165,16 @@ var GrepExamples = `
     kustomize config grep "metadata.name=nginx" my-dir/ | kustomize config tree
 
     # look for Resources matching a specific container image
-    kustomize config grep "spec.template.spec.containers[name=nginx].image=nginx:1\.7\.9" my-dir/ | kustomize config tree`
+    kustomize config grep "spec.template.spec.containers[name=nginx].image=nginx:1\.7\.9" my-dir/ | kustomize config tree
+
+    # find Resources with more than 5 replicas
+    kustomize config grep "spec.replicas>5" my-dir/
+
+    # find Resources with images from a registry
+    kustomize config grep "spec.template.spec.containers[name=app].image=~^gcr\.io/" my-dir/
+
+    # find Resources with a scaler annotation
+    kustomize config grep "metadata.annotations.scaler" my-dir/`
 
 var ListSettersShort = `[Alpha] List setters for Resources.`
 var ListSettersLong = `
@@ -188,12 +208,19 @@ these are missing, merge will default the missing values to empty.
 Resources specified later are high-precedence (the source) and Resources specified
 earlier are lower-precedence (the destination).
 
+The elements of lists are merged by the merge keys of their schema, e.g. containers by name.  The
+schemas of custom Resources may be read from CustomResourceDefinition or OpenAPI files with --schema,
+so that their lists are merged by the ` + "`" + `x-kubernetes-patch-merge-key` + "`" + ` of the schema rather than replaced.
+
 For information on merge rules, run:
 
 	kustomize config docs merge
 `
 var MergeExamples = `
-    cat resources_and_patches.yaml | kustomize config merge > merged_resources.yaml`
+    cat resources_and_patches.yaml | kustomize config merge > merged_resources.yaml
+
+    # merge the lists of custom resources using the merge keys of their CRDs
+    cat resources_and_patches.yaml | kustomize config merge --schema crds.yaml > merged_resources.yaml`
 
 var Merge3Short = `[Alpha] Merge diff of Resource configuration files into a destination (3-way)`
 var Merge3Long = `
@@ -207,12 +234,23 @@ set of Resources that was forked and an updated set of those Resources, then app
 If a field value differs between the ORIGINAL_DIR and UPDATED_DIR, the value from the UPDATED_DIR is taken and applied
 to the Resource in the DEST_DIR.
 
+Resources which are in the ORIGINAL_DIR but were deleted from the UPDATED_DIR are deleted from the DEST_DIR, unless
+they were modified in the DEST_DIR, in which case merge3 fails with a conflict listing them and nothing is written.
+Resources added to the DEST_DIR are always kept.  Deleted Resources may be kept in the DEST_DIR with --keep-deleted.
+
+The elements of lists are merged by the merge keys of their schema, e.g. containers by name.  The schemas of custom
+Resources may be read from CustomResourceDefinition or OpenAPI files with --schema, so that their lists are merged by
+the ` + "`" + `x-kubernetes-patch-merge-key` + "`" + ` of the schema rather than replaced.
+
 For information on merge rules, run:
 
 	kustomize config docs-merge3
 `
 var Merge3Examples = `
-    kustomize config merge3 --ancestor a/ --from b/ --to c/`
+    kustomize config merge3 --ancestor a/ --from b/ --to c/
+
+    # merge the lists of custom resources using the merge keys of their CRDs
+    kustomize config merge3 --ancestor a/ --from b/ --to c/ --schema crds.yaml`
 
 var RunFnsShort = `[Alpha] Reoncile config functions to Resources.`
 var RunFnsLong = `
@@ -227,6 +265,11 @@ directory.  To clearly specify an ordering of functions, multiple functions may
 declared in the same file, separated by '---' (the functions will be invoked in the
 order they appear in the file).
 
+Alternatively, functions may declare an integer config.kubernetes.io/function-order
+annotation.  Functions with lower values are invoked first, and functions without the
+annotation have the order 0.  Each function is invoked with the Resources written by
+the previous function.  A warning is printed if several functions declare the same order.
+
 #### Arguments:
 
   DIR:
@@ -259,9 +302,77 @@ order they appear in the file).
   file contents.
 
   See ` + "`" + `kustomize help config docs-fn` + "`" + ` for more details on writing functions.
+
+  Containers are given the environment variables of the run process and may declare
+  more, either as NAME=VALUE or as NAME to forward the variable.  They may also declare
+  mounts, which are read-only unless rw is set:
+
+	metadata:
+	  annotations:
+	    config.kubernetes.io/function: |
+	      container:
+	        image: gcr.io/example/examplefunction:v1.0.1
+	        envs: [TOKEN, REGISTRY=gcr.io]
+	        mounts: [{type: bind, src: ./schemas, dst: /schemas}]
+
+  Bind mount sources are relative to the package directory, and sources outside of the
+  package are only mounted with --enable-external-mounts.  --env and --mount add variables
+  and mounts to all containers, replacing those with the same name or destination.  The
+  sources of --mount are checked the same as those of the functions.
+
+  Functions may also be local executables, which read the ResourceList from stdin and
+  write it to stdout:
+
+	metadata:
+	  annotations:
+	    config.kubernetes.io/function: |
+	      exec:
+	        # relative to the package directory
+	        path: ./fn/scaler
+
+  An executable may also be given with --exec-path.  Executables outside of the package
+  directory are only run with --enable-exec.  If the executable exits non-zero, run
+  fails with its stderr.
+
+  With --enable-star, functions may also be starlark scripts, given by a path relative
+  to the package directory or inline as a program:
+
+	metadata:
+	  annotations:
+	    config.kubernetes.io/function: |
+	      starlark:
+	        name: scaler
+	        path: fn/scaler.star
+
+  The script reads and modifies ctx.resource_list, and may use get_field and set_field to
+  read and set nested fields, e.g. set_field(r, "spec.replicas", 3).  Scripts can't access
+  the file system or the network.  run fails if a script runs for longer than
+  --star-timeout (default 1m).
+
+  A function given with --image, --exec-path or --star-path may read its functionConfig from a file
+  outside of the package with --fn-config.  The functionConfig is not written to the
+  package, and must not also be provided in the package.
+
+  With --diff, run doesn't write to the directory.  Instead it prints a unified diff of
+  the files which the functions would change, create or delete, and exits non-zero if any
+  file would change.  Files are compared after formatting, so the diff only contains the
+  changes made by the functions.
+
+  With --parallel N, the functions of each package under DIR are run separately, N packages
+  at a time.  Packages are the directories containing a Krmfile, or function configs in the
+  directory or its functions directory.  Each package only reads and writes its own files,
+  excluding its nested packages.  The stderr of the functions, the results and the warnings
+  are prefixed with the path of the package.  A package failing doesn't stop the other
+  packages unless --fail-fast is given, and run fails with a summary of the failed packages.
 `
 var RunFnsExamples = `
-kustomize config run example/`
+kustomize config run example/
+
+kustomize config run example/ --image gcr.io/example/examplefunction:v1.0.1 --fn-config fn-config.yaml
+
+kustomize config run example/ --diff
+
+kustomize config run monorepo/ --parallel 8`
 
 var SetShort = `[Alpha] Set values on Resources fields values.`
 var SetLong = `
@@ -300,6 +411,11 @@ To print the possible setters for the Resources in a directory, run
 
 The description and setBy fields are left unmodified unless specified with flags.
 
+Several setters may be set at once, either as NAME=VALUE arguments or from a yaml file of
+NAME: VALUE fields with ` + "`" + `--values` + "`" + `.  The values are validated against the setter definitions,
+e.g. their enumValues, minimum and maximum, before anything is written, and nothing is written if
+any of them fails.  The NAME=VALUE arguments override the values in the file.
+
 To create a custom setter for a field see: ` + "`" + `kustomize help config create-setter` + "`" + `
 `
 var SetExamples = `
@@ -327,6 +443,12 @@ var SetExamples = `
     $ kustomize config set DIR/ name-prefix "test" --description "test environment" --set-by "dev"
     set 2 values
 
+  Perform set: set several setters at once
+
+    $ kustomize config set DIR/ --values prod-values.yaml replicas=5
+    set 1 fields for name-prefix
+    set 3 fields for replicas
+
   List setters: Show the new values
 
     $ config list-setters DIR/
@@ -394,7 +516,10 @@ Resource fields may be printed as part of the Resources by specifying the fields
 kustomize config tree has build-in support for printing common fields, such as replicas, container images,
 container names, etc.
 
-kustomize config tree supports printing arbitrary fields using the '--field' flag.
+kustomize config tree supports printing arbitrary fields using the '--field' flag. The flag may be repeated, and
+takes a dotted field path, e.g. 'metadata.annotations.scaler'.  List elements are matched as
+'list[field=value]', and the fields under them are printed under the list field for each matching
+element.  Fields which are missing or null are omitted.
 
 By default, kustomize config tree uses Resource graph structure if any relationships between resources (ownerReferences)
 are detected, as is typically the case when printing from a cluster. Otherwise, directory graph structure is used. The
@@ -413,6 +538,11 @@ var TreeExamples = `
     # print the "foo"" annotation
     kustomize config tree my-dir/ --field "metadata.annotations.foo"
 
+    # print the replicas, the image of the app container and the scaler annotation
+    kustomize config tree my-dir/ --field spec.replicas \
+      --field "spec.template.spec.containers[name=app].image" \
+      --field "metadata.annotations.scaler"
+
     # print the "foo"" annotation
     kubectl get all -o yaml | kustomize config tree \
       --field="status.conditions[type=Completed].status"
@@ -0,0 +1,10 @@
+module sigs.k8s.io/kustomize/functions/examples/oam-trait
+
+go 1.13
+
+require (
+	github.com/pmezard/go-difflib v1.0.0
+	sigs.k8s.io/kustomize/kyaml v0.1.3
+)
+
+replace sigs.k8s.io/kustomize/kyaml => ../../../../kyaml
@@ -0,0 +1,454 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main implements an injection function for the replicas of OAM traits and
+// is run with `kustomize config run -- DIR/`, or directly with `oam-trait DIR/`.
+//
+// The ApplicationConfigurations are validated by scaler.ValidateFilter, and the
+// replicas are injected by scaler.ScalerFilter.  With `--require-replicas` the
+// injected traits are validated by scaler.RequireReplicasFilter.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/functions/examples/oam-trait/pkg/scaler"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false,
+		"report the replicas which would be set without setting them")
+	keepReaderAnnotations := flag.Bool("keep-reader-annotations", false,
+		"keep the reader annotations when the input isn't a ResourceList")
+	annotationPrefix := flag.String("annotation-prefix", "",
+		"prefix of the annotation read for the replicas, e.g. oam.dev/")
+	logLevel := flag.String("log-level", "",
+		"lowest level of the results written to stderr, one of debug, info or warn "+
+			"(defaults to the functionConfig log-level annotation or $LOG_LEVEL, else info)")
+	output := flag.String("output", yamlOutput,
+		"format the Resources are written to stdout in, one of yaml or json")
+	failOnNoMatch := flag.Bool("fail-on-no-match", false,
+		"fail if a Resource has the annotation but no trait with replicas")
+	requireReplicas := flag.Bool("require-replicas", false,
+		"fail if a trait with replicas doesn't have them after the injection")
+	injectWorkloads := flag.Bool("inject-workloads", false,
+		"set the spec.replicas of the workloads embedded in the components, e.g. Deployments, "+
+			"instead of the replicas of their traits")
+	concurrency := flag.Int("concurrency", 1,
+		"number of Resources which are injected in parallel")
+	valuesFile := flag.String("values", "",
+		"yaml file mapping component names to their replicas, which override the annotations")
+	printDiff := flag.Bool("print-diff", false,
+		"write a diff of each changed Resource to stderr")
+	namespaces := flag.String("namespaces", "",
+		"comma separated namespaces of the Resources which are injected (defaults to all)")
+	traitAPIVersions := flag.String("trait-api-versions", "",
+		"comma separated apiVersions of the traits which are injected "+
+			"(defaults to core.oam.dev/v1alpha2,core.oam.dev/v1beta1)")
+	resultsFile := flag.String("results-file", "",
+		"file the results are written to as a yaml list, as well as to the ResourceList")
+	printExample := flag.Bool(exampleFlag, false, "")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *printExample {
+		fmt.Fprint(os.Stdout, example)
+		return
+	}
+
+	var validators []kio.Filter
+	if *requireReplicas {
+		validators = append(validators, scaler.RequireReplicasFilter{
+			TraitAPIVersions: splitList(*traitAPIVersions)})
+	}
+
+	f := scaler.NewScalerFilter("")
+	f.DryRun = *dryRun
+	f.AnnotationPrefix = *annotationPrefix
+	f.FailOnNoMatch = *failOnNoMatch
+	f.InjectWorkloads = *injectWorkloads
+	f.Concurrency = *concurrency
+	f.Namespaces = splitList(*namespaces)
+	f.TraitAPIVersions = splitList(*traitAPIVersions)
+	f.Logger = &framework.Logger{Level: *logLevel, Writer: os.Stderr}
+	f.Summary = os.Stderr
+	if *printDiff {
+		f.Diff = os.Stderr
+	}
+	values, err := readValues(*valuesFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	f.Values = values
+	if err = scaler.ValidateLogLevel(*logLevel); *logLevel != "" && err != nil {
+		// err reports the invalid --log-level
+	} else if *output != yamlOutput && *output != jsonOutput {
+		err = fmt.Errorf("--output must be %s or %s, got %q", yamlOutput, jsonOutput, *output)
+	} else if flag.NArg() > 0 && *output != yamlOutput {
+		err = fmt.Errorf("--output may only be set when reading from stdin")
+	} else if flag.NArg() > 0 && *resultsFile != "" {
+		err = fmt.Errorf("--results-file may only be set when reading from stdin")
+	} else if flag.NArg() > 0 {
+		// read and write the Resources in the DIR argument
+		err = runDir(flag.Arg(0), *f, validators...)
+	} else {
+		err = runStdin(*f, runOptions{
+			keepReaderAnnotations: *keepReaderAnnotations, output: *output,
+			validators: validators}, *resultsFile)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// exampleFlag is the hidden flag which writes the example to stdout.
+const exampleFlag = "example"
+
+// example is a minimal input whose replicas are injected by the function, for
+// `oam-trait --example | oam-trait`: the ManualScalerTrait is set to the 3
+// replicas of the scaler annotation.
+const example = `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`
+
+// usage writes the usage of the flags, except of the hidden exampleFlag.
+func usage() {
+	visible := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	visible.SetOutput(flag.CommandLine.Output())
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Name != exampleFlag {
+			visible.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	fmt.Fprintf(visible.Output(), "Usage of %s:\n", os.Args[0])
+	visible.PrintDefaults()
+}
+
+// runStdin runs the function over stdin and stdout, writing the results to
+// resultsFile if it is set.
+func runStdin(f scaler.ScalerFilter, opts runOptions, resultsFile string) error {
+	if resultsFile == "" {
+		return run(os.Stdin, os.Stdout, f, opts)
+	}
+	file, err := os.Create(resultsFile)
+	if err != nil {
+		return err
+	}
+	opts.results = file
+	err = run(os.Stdin, os.Stdout, f, opts)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Formats the Resources are written in by run.
+const (
+	yamlOutput = "yaml"
+	jsonOutput = "json"
+)
+
+// runOptions configure how run validates and writes the Resources.
+type runOptions struct {
+	// keepReaderAnnotations if set will keep the reader annotations when
+	// the input isn't a ResourceList.
+	keepReaderAnnotations bool
+
+	// output is the format the Resources are written in.  Defaults to
+	// yamlOutput.
+	output string
+
+	// validators are run after the replicas are injected, and fail the
+	// function rather than modifying the Resources.
+	validators []kio.Filter
+
+	// results if set is where the results are also written, as a yaml list.
+	// The error of the function is written as an error result.
+	results io.Writer
+}
+
+// run reads the Resources from in, injects the replicas using f and
+// writes the Resources to out, with a framework.ResourceListProcessor.
+// f is configured from the functionConfig by configure, and the results of
+// its Logger are written to the ResourceList.
+//
+// When the input is a ResourceList the function is run as part of a
+// pipeline, and the reader annotations are kept for the next function.
+// Otherwise they are cleared, unless keepReaderAnnotations is set.
+func run(in io.Reader, out io.Writer, f scaler.ScalerFilter, opts runOptions) error {
+	in, err := normalizeInput(in)
+	if err != nil {
+		return err
+	}
+	config := &functionConfig{}
+	p := framework.ResourceListProcessor{
+		Reader:                in,
+		Writer:                out,
+		FunctionConfig:        config,
+		KeepReaderAnnotations: opts.keepReaderAnnotations,
+		ResultsWriter:         opts.results,
+		Logger:                f.Logger,
+		Filter: kio.FilterFunc(func(items []*yaml.RNode) ([]*yaml.RNode, error) {
+			// fail on malformed ApplicationConfigurations before injecting them
+			items, err := scaler.ValidateFilter{}.Filter(items)
+			if err != nil {
+				return nil, err
+			}
+			if err := configure(&f, config.node); err != nil {
+				return nil, err
+			}
+			if items, err = f.Filter(items); err != nil {
+				return nil, err
+			}
+			// validate the injected inputs before they are written
+			for i := range opts.validators {
+				if items, err = opts.validators[i].Filter(items); err != nil {
+					return nil, err
+				}
+			}
+			return items, nil
+		}),
+	}
+	if opts.output != jsonOutput {
+		return p.Execute()
+	}
+	var buff bytes.Buffer
+	p.Writer = &buff
+	if err := p.Execute(); err != nil {
+		return err
+	}
+	return writeJSON(out, &buff)
+}
+
+// utf8BOM is the byte order mark which editors on Windows may write at the
+// start of a UTF-8 file.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// normalizeInput returns the content of in without a leading utf8BOM and with
+// the CRLF line endings converted to LF.  Otherwise the ByteReader doesn't split
+// the documents on their `---` separators, and misreads the first document.
+func normalizeInput(in io.Reader) (io.Reader, error) {
+	b, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimPrefix(b, utf8BOM)
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	return bytes.NewReader(b), nil
+}
+
+// functionConfig declares the fields of the functionConfig which are read by
+// configure, so that the ResourceListProcessor reports the other fields as
+// warnings.
+type functionConfig struct {
+	Data map[string]interface{} `yaml:"data,omitempty"`
+	Spec map[string]interface{} `yaml:"spec,omitempty"`
+
+	// node is the functionConfig, which configure reads the fields from.
+	node *yaml.RNode
+}
+
+func (c *functionConfig) UnmarshalYAML(node *yaml.Node) error {
+	c.node = yaml.NewRNode(node)
+	type fields functionConfig
+	return node.Decode((*fields)(c))
+}
+
+// writeJSON converts the yaml documents read from in to json, and writes them
+// to out with one object per line -- e.g. a single ResourceList if the input
+// was a ResourceList.
+func writeJSON(out io.Writer, in io.Reader) error {
+	decoder := yaml.NewDecoder(in)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+	}
+}
+
+// runDir reads the Resources from the files in dir, injects the replicas
+// using f, validates them with the validators and writes the Resources back
+// to their files.
+// The files aren't written if f is a dry-run.
+func runDir(dir string, f scaler.ScalerFilter, validators ...kio.Filter) error {
+	if f.Logger != nil && f.Logger.Level == "" {
+		level, err := framework.LogLevel(nil)
+		if err != nil {
+			return err
+		}
+		f.Logger.Level = level
+	}
+	var outputs []kio.Writer
+	if !f.DryRun {
+		outputs = append(outputs, kio.LocalPackageWriter{PackagePath: dir})
+	}
+	return kio.Pipeline{
+		Inputs:  []kio.Reader{kio.LocalPackageReader{PackagePath: dir}},
+		Filters: append([]kio.Filter{scaler.ValidateFilter{}, f}, validators...),
+		Outputs: outputs,
+	}.Execute()
+}
+
+// readValues reads the values file at path, which maps component names to their
+// replicas, e.g. `frontend: 5`.  Returns nil if path is empty.
+func readValues(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("values file %s %v", path, err)
+	}
+	return values, nil
+}
+
+// splitList returns the comma separated values in s, e.g. the namespaces
+// `tenant-a, tenant-b`.  Returns nil if s is empty.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// configure overrides the fields of f from the functionConfig `data` fields
+// which are set:
+// `annotationKey`, `annotationPrefix`, `selector`, `createIfMissing`, `injectWorkloads`,
+// `traitAPIVersions` (comma separated), `defaultReplicas`, `minReplicas` and
+// `maxReplicas`.
+// The functionConfig `spec.rules` if set are the Rules of f, followed by the
+// Rules of the functionConfig `spec.traits` fields.
+func configure(f *scaler.ScalerFilter, functionConfig *yaml.RNode) error {
+	if functionConfig == nil {
+		return nil
+	}
+	if err := decodeSpec(functionConfig, "rules", &f.Rules); err != nil {
+		return err
+	}
+	var traits []scaler.TraitField
+	if err := decodeSpec(functionConfig, "traits", &traits); err != nil {
+		return err
+	}
+	for i := range traits {
+		f.Rules = append(f.Rules, traits[i].Rule())
+	}
+
+	data, err := functionConfig.Pipe(yaml.Lookup("data"))
+	if err != nil || data == nil {
+		return err
+	}
+
+	if key := data.Field("annotationKey"); key != nil && yaml.GetValue(key.Value) != "" {
+		f.AnnotationKey = yaml.GetValue(key.Value)
+	}
+	if prefix := data.Field("annotationPrefix"); prefix != nil {
+		f.AnnotationPrefix = yaml.GetValue(prefix.Value)
+	}
+	if selector := data.Field("selector"); selector != nil {
+		f.Selector = yaml.GetValue(selector.Value)
+	}
+	if create := data.Field("createIfMissing"); create != nil {
+		value, err := strconv.ParseBool(yaml.GetValue(create.Value))
+		if err != nil {
+			return fmt.Errorf("functionConfig createIfMissing must be true or false, got %q",
+				yaml.GetValue(create.Value))
+		}
+		f.CreateIfMissing = value
+	}
+	if inject := data.Field("injectWorkloads"); inject != nil {
+		value, err := strconv.ParseBool(yaml.GetValue(inject.Value))
+		if err != nil {
+			return fmt.Errorf("functionConfig injectWorkloads must be true or false, got %q",
+				yaml.GetValue(inject.Value))
+		}
+		f.InjectWorkloads = value
+	}
+	if versions := data.Field("traitAPIVersions"); versions != nil {
+		f.TraitAPIVersions = splitList(yaml.GetValue(versions.Value))
+	}
+	for _, bound := range []struct {
+		name  string
+		value **int
+	}{
+		{"defaultReplicas", &f.DefaultReplicas},
+		{"minReplicas", &f.MinReplicas},
+		{"maxReplicas", &f.MaxReplicas},
+	} {
+		field := data.Field(bound.name)
+		if field == nil {
+			continue
+		}
+		n, err := strconv.Atoi(yaml.GetValue(field.Value))
+		if err != nil || n < 0 {
+			return fmt.Errorf("functionConfig %s must be a non-negative integer, got %q",
+				bound.name, yaml.GetValue(field.Value))
+		}
+		*bound.value = &n
+	}
+	return nil
+}
+
+// decodeSpec decodes the functionConfig `spec` field into value, if the field is set.
+// Unknown fields fail rather than being ignored, so that misspelled fields are
+// reported.
+func decodeSpec(functionConfig *yaml.RNode, field string, value interface{}) error {
+	node, err := functionConfig.Pipe(yaml.Lookup("spec", field))
+	if err != nil || node == nil {
+		return err
+	}
+	s, err := node.String()
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(strings.NewReader(s))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("functionConfig spec.%s %v", field, err)
+	}
+	return nil
+}
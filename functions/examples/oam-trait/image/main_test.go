@@ -0,0 +1,1562 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/functions/examples/oam-trait/pkg/scaler"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// The example is injected when it is fed back in.
+func TestRun_example(t *testing.T) {
+	var out, results bytes.Buffer
+	if err := run(bytes.NewBufferString(example), &out, scaler.ScalerFilter{Results: &results},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(example, "replicaCount: 1", "replicaCount: 3", 1)
+	if out.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+	}
+	expectedResults := "[info] ApplicationConfiguration /example-appconfig: set replicaCount " +
+		"of ManualScalerTrait example-appconfig-trait in component example-component to 3\n"
+	if results.String() != expectedResults {
+		t.Fatalf("expected results %s\nbut got %s\n", expectedResults, results.String())
+	}
+}
+
+// TestRun_windowsInput tests that inputs with CRLF line endings or a leading
+// UTF-8 BOM are injected like the same inputs with LF line endings.
+func TestRun_windowsInput(t *testing.T) {
+	second := strings.Replace(example, "example-appconfig", "second-appconfig", -1)
+	input := example + "---\n" + second
+	expected := strings.Replace(example, "replicaCount: 1", "replicaCount: 3", 1) + "---\n" +
+		strings.Replace(second, "replicaCount: 1", "replicaCount: 3", 1)
+	crlf := strings.Replace(input, "\n", "\r\n", -1)
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "crlf", input: crlf},
+		{name: "bom", input: "\xef\xbb\xbf" + input},
+		{name: "bom-crlf", input: "\xef\xbb\xbf" + crlf},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := run(bytes.NewBufferString(test.input), &out, scaler.ScalerFilter{},
+				runOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != expected {
+				t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+			}
+		})
+	}
+
+	// a BOM doesn't hide json input
+	var out bytes.Buffer
+	if err := run(bytes.NewBufferString("\xef\xbb\xbf"+`{"apiVersion": "core.oam.dev/v1alpha2", `+
+		`"kind": "ApplicationConfiguration", "metadata": {"name": "example-appconfig", `+
+		`"annotations": {"scaler": "3"}}, "spec": {"components": [{"componentName": "c", `+
+		`"traits": [{"trait": {"apiVersion": "core.oam.dev/v1alpha2", `+
+		`"kind": "ManualScalerTrait"}}]}]}}`+"\r\n"), &out, scaler.ScalerFilter{},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"replicaCount": 3`) {
+		t.Fatalf("expected the json trait to be injected\nbut got %s\n", out.String())
+	}
+}
+
+func TestUsage_hidesExample(t *testing.T) {
+	var out bytes.Buffer
+	flag.CommandLine.SetOutput(&out)
+	defer flag.CommandLine.SetOutput(nil)
+	flag.Bool("visible", false, "a visible flag")
+	flag.Bool(exampleFlag, false, "")
+	usage()
+	if !strings.Contains(out.String(), "-visible") || strings.Contains(out.String(), "-"+exampleFlag) {
+		t.Fatalf("expected only the visible flag, got %s", out.String())
+	}
+}
+
+func TestRun_idempotent(t *testing.T) {
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: changed
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: changed-trait
+        spec:
+          replicaCount: 1
+  - componentName: quoted
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: quoted-trait
+        spec:
+          replicaCount: "3"
+  - componentName: unchanged
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: unchanged-trait
+        spec:
+          replicaCount: 3
+`
+	// the first run only changes the traits without the replicas
+	var out, results bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{Results: &results}, runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "[info] ApplicationConfiguration /example-appconfig: " +
+		"set replicaCount of ManualScalerTrait changed-trait in component changed to 3\n" +
+		"[info] ApplicationConfiguration /example-appconfig: " +
+		"set replicaCount of ManualScalerTrait quoted-trait in component quoted to 3\n"
+	if results.String() != expected {
+		t.Fatalf("expected results %s\nbut got %s\n", expected, results.String())
+	}
+
+	// the second run doesn't change anything
+	first := out.String()
+	out.Reset()
+	results.Reset()
+	if err := run(bytes.NewBufferString(first), &out, scaler.ScalerFilter{Results: &results}, runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if results.Len() != 0 {
+		t.Fatalf("expected no changes\nbut got %s\n", results.String())
+	}
+	if out.String() != first {
+		t.Fatalf("expected unmodified output %s\nbut got %s\n", first, out.String())
+	}
+}
+
+func TestRun_annotationKey(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "2"
+      oam.dev/replicas: "3"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: example-appconfig-trait
+          spec:
+            replicaCount: 1
+`
+	tests := []struct {
+		name           string
+		functionConfig string
+		expected       string
+	}{
+		{
+			name:     "default",
+			expected: "replicaCount: 2",
+		},
+		{
+			name: "default-empty-data",
+			functionConfig: `functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data: {}
+`,
+			expected: "replicaCount: 2",
+		},
+		{
+			name: "override",
+			functionConfig: `functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    annotationKey: oam.dev/replicas
+`,
+			expected: "replicaCount: 3",
+		},
+		{
+			name: "prefix",
+			functionConfig: `functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    annotationPrefix: oam.dev/
+    annotationKey: replicas
+`,
+			expected: "replicaCount: 3",
+		},
+		{
+			// the v1alpha2 trait isn't injected
+			name: "trait-api-versions",
+			functionConfig: `functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    traitAPIVersions: core.oam.dev/v1beta1, example.com/v1
+`,
+			expected: "replicaCount: 1",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := run(bytes.NewBufferString(input+test.functionConfig), &out,
+				scaler.ScalerFilter{}, runOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), test.expected) {
+				t.Fatalf("expected %s in output\nbut got %s\n", test.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestRun_rules(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "3"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: example-appconfig-trait
+          spec:
+            replicaCount: 1
+functionConfig:
+  apiVersion: example.com/v1
+  kind: TraitInjector
+  spec:
+    rules:
+    - traitApiVersion: core.oam.dev/v1alpha2
+      traitKind: ManualScalerTrait
+      targetPath: spec.replicaCount
+      source:
+        annotation: scaler
+`
+	var out, results bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{Results: &results},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// the rule reproduces the scaler, and only the Resources are written to stdout
+	if !strings.Contains(out.String(), "replicaCount: 3") {
+		t.Fatalf("expected replicaCount: 3 in output\nbut got %s\n", out.String())
+	}
+	if strings.Contains(out.String(), "[info]") {
+		t.Fatalf("unexpected result in the output: %s", out.String())
+	}
+	expected := "[info] ApplicationConfiguration /example-appconfig: set spec.replicaCount " +
+		"of ManualScalerTrait example-appconfig-trait in component example-component to 3\n"
+	if results.String() != expected {
+		t.Fatalf("expected results %s\nbut got %s\n", expected, results.String())
+	}
+
+	// misspelled rule fields fail rather than being ignored
+	err := run(bytes.NewBufferString(strings.Replace(input, "traitKind", "traitKnd", 1)),
+		&out, scaler.ScalerFilter{}, runOptions{})
+	if err == nil || !strings.Contains(err.Error(), "field traitKnd not found") {
+		t.Fatalf("expected an error for the misspelled field\nbut got %v\n", err)
+	}
+}
+
+func TestRun_traits(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "3"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: HorizontalPodAutoscalerTrait
+          metadata:
+            name: example-appconfig-hpa
+          spec:
+            minReplicas: 1
+functionConfig:
+  apiVersion: example.com/v1
+  kind: TraitInjector
+  spec:
+    traits:
+    - kind: "*"
+      fieldPath: spec.minReplicas
+      value: "2"
+`
+	var out, results bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{Results: &results},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// the custom field path is set rather than the replicas of the scaler
+	if !strings.Contains(out.String(), "minReplicas: 2") {
+		t.Fatalf("expected minReplicas: 2 in output\nbut got %s\n", out.String())
+	}
+	expected := "[info] ApplicationConfiguration /example-appconfig: set spec.minReplicas " +
+		"of HorizontalPodAutoscalerTrait example-appconfig-hpa in component " +
+		"example-component to 2\n"
+	if results.String() != expected {
+		t.Fatalf("expected results %s\nbut got %s\n", expected, results.String())
+	}
+
+	err := run(bytes.NewBufferString(strings.Replace(input, "fieldPath", "path", 1)),
+		&out, scaler.ScalerFilter{}, runOptions{})
+	if err == nil || !strings.Contains(err.Error(), "functionConfig spec.traits") {
+		t.Fatalf("expected an error for the misspelled field\nbut got %v\n", err)
+	}
+}
+
+func TestRun_requireReplicas(t *testing.T) {
+	input := func(annotations string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    ` + annotations + `
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec: {}
+`
+	}
+	opts := runOptions{validators: []kio.Filter{scaler.RequireReplicasFilter{}}}
+
+	// the replicas are validated after they are injected
+	var out bytes.Buffer
+	if err := run(bytes.NewBufferString(input(`scaler: "3"`)), &out,
+		scaler.ScalerFilter{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "replicaCount: 3") {
+		t.Fatalf("expected replicaCount: 3 in output\nbut got %s\n", out.String())
+	}
+
+	out.Reset()
+	err := run(bytes.NewBufferString(input(`other: "3"`)), &out, scaler.ScalerFilter{}, opts)
+	expected := "ApplicationConfiguration /example-appconfig: ManualScalerTrait " +
+		"example-appconfig-trait in component example-component is missing replicaCount"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %s\nbut got %v\n", expected, err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no output\nbut got %s\n", out.String())
+	}
+}
+
+func TestRun_createIfMissing(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "3"
+      config.kubernetes.io/path: app.yaml
+  spec:
+    components:
+    - componentName: example-component
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    createIfMissing: "true"
+`
+	expected := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "3"
+      config.kubernetes.io/path: app.yaml
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          spec:
+            replicaCount: 3
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    createIfMissing: "true"
+`
+	var out bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+	}
+
+	// running the function again doesn't add another trait
+	var again bytes.Buffer
+	if err := run(bytes.NewBufferString(out.String()), &again, scaler.ScalerFilter{},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, again.String())
+	}
+
+	err := run(bytes.NewBufferString(strings.Replace(input, `"true"`, "yes", 1)), &out,
+		scaler.ScalerFilter{}, runOptions{})
+	if err == nil || err.Error() != `functionConfig createIfMissing must be true or false, got "yes"` {
+		t.Fatalf("expected an error for the invalid createIfMissing\nbut got %v\n", err)
+	}
+}
+
+func TestRun_injectWorkloads(t *testing.T) {
+	input := func(injectWorkloads string) string {
+		return `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "3"
+  spec:
+    components:
+    - componentName: example-component
+      workload:
+        apiVersion: apps/v1
+        kind: Deployment
+        metadata:
+          name: web
+        spec:
+          replicas: 1
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          spec:
+            replicaCount: 1
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    injectWorkloads: "` + injectWorkloads + `"
+`
+	}
+	// the Deployment is scaled instead of the trait
+	var out bytes.Buffer
+	if err := run(bytes.NewBufferString(input("true")), &out, scaler.ScalerFilter{},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(input("true"), "replicas: 1", "replicas: 3", 1)
+	if out.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+	}
+
+	err := run(bytes.NewBufferString(input("yes please")), &out, scaler.ScalerFilter{},
+		runOptions{})
+	expectedErr := `functionConfig injectWorkloads must be true or false, got "yes please"`
+	if err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error %s\nbut got %v\n", expectedErr, err)
+	}
+}
+
+func TestRun_clamp(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "500"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: example-appconfig-trait
+          spec:
+            replicaCount: 1
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    minReplicas: "%s"
+    maxReplicas: "10"
+`
+	tests := []struct {
+		name            string
+		minReplicas     string
+		expected        string
+		expectedResults string
+		expectedErr     string
+	}{
+		{
+			name:        "clamped",
+			minReplicas: "2",
+			expected:    "replicaCount: 10",
+			expectedResults: "[warning] ApplicationConfiguration /example-appconfig: " +
+				"clamped replicas of component example-component from 500 to 10\n",
+		},
+		{
+			name:        "invalid",
+			minReplicas: "two",
+			expectedErr: `functionConfig minReplicas must be a non-negative integer, got "two"`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var out, results bytes.Buffer
+			err := run(bytes.NewBufferString(fmt.Sprintf(input, test.minReplicas)), &out,
+				scaler.ScalerFilter{Results: &results}, runOptions{})
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error %s\nbut got %v\n", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), test.expected) {
+				t.Fatalf("expected %s in output\nbut got %s\n", test.expected, out.String())
+			}
+			if !strings.HasPrefix(results.String(), test.expectedResults) {
+				t.Fatalf("expected results %s\nbut got %s\n", test.expectedResults, results.String())
+			}
+		})
+	}
+}
+
+func TestRun_defaultReplicas(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: example-appconfig-trait
+          spec:
+            replicaCount: 1
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    defaultReplicas: "2"
+`
+	var out bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{}, runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "replicaCount: 2") {
+		t.Fatalf("expected %s in output\nbut got %s\n", "replicaCount: 2", out.String())
+	}
+}
+
+func TestRun_selector(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "3"
+  spec:
+    components:
+    - componentName: frontend
+      workload:
+        metadata:
+          labels:
+            app: frontend
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: frontend-trait
+          spec:
+            replicaCount: 1
+    - componentName: backend
+      workload:
+        metadata:
+          labels:
+            app: backend
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: backend-trait
+          spec:
+            replicaCount: 2
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    selector: app=backend
+`
+	var out bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{}, runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"replicaCount: 1", "replicaCount: 3"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("expected %s in output\nbut got %s\n", expected, out.String())
+		}
+	}
+}
+
+func TestRun_logLevel(t *testing.T) {
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: changed
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: changed-trait
+        spec:
+          replicaCount: 1
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: IngressTrait
+        metadata:
+          name: ingress-trait
+  - componentName: unchanged
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: unchanged-trait
+        spec:
+          replicaCount: 3
+`
+	tests := []struct {
+		logLevel string
+		// lines is the number of lines written to the results
+		lines int
+	}{
+		// 2 components, 3 traits, 1 change and whether the Resource changed
+		{logLevel: scaler.LogLevelDebug, lines: 7},
+		// 1 change
+		{logLevel: scaler.LogLevelInfo, lines: 1},
+		{logLevel: scaler.LogLevelWarn, lines: 0},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.logLevel, func(t *testing.T) {
+			var out, results bytes.Buffer
+			err := run(bytes.NewBufferString(input), &out,
+				scaler.ScalerFilter{Results: &results, LogLevel: test.logLevel}, runOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lines := strings.Count(results.String(), "\n"); lines != test.lines {
+				t.Fatalf("expected %d lines\nbut got %s\n", test.lines, results.String())
+			}
+		})
+	}
+
+	err := run(bytes.NewBufferString(input), &bytes.Buffer{},
+		scaler.ScalerFilter{LogLevel: "verbose"}, runOptions{})
+	expected := `log level must be one of debug, info or warn, got "verbose"`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %s\nbut got %v\n", expected, err)
+	}
+}
+
+// TestRun_invalid tests that malformed ApplicationConfigurations fail before
+// any of the Resources are injected.
+func TestRun_logger(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    namespace: default
+    annotations:
+      scaler: "5"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    annotations:
+      config.kubernetes.io/log-level: warning
+  data:
+    maxReplicas: "3"
+`
+	expected := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    namespace: default
+    annotations:
+      scaler: "5"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          spec:
+            replicaCount: 3
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    annotations:
+      config.kubernetes.io/log-level: warning
+  data:
+    maxReplicas: "3"
+results:
+- severity: warning
+  message: clamped replicas of component example-component from 5 to 3
+  resourceRef:
+    name: example-appconfig
+    namespace: default
+    apiVersion: core.oam.dev/v1alpha2
+    kind: ApplicationConfiguration
+`
+	var out, log bytes.Buffer
+	logger := &framework.Logger{Writer: &log}
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{Logger: logger},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// the warnings are written to the ResourceList as well as logged, and the
+	// functionConfig annotation selects the level of the logged lines
+	if out.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+	}
+	expectedLog := "[warning] core.oam.dev/v1alpha2/ApplicationConfiguration default/example-appconfig " +
+		"clamped replicas of component example-component from 5 to 3\n"
+	if log.String() != expectedLog {
+		t.Fatalf("expected %s\nbut got %s\n", expectedLog, log.String())
+	}
+}
+
+// TestRun_resourceListResults tests that the results read from a ResourceList
+// are written back followed by the results of the function, and that the
+// results are written to opts.results as well.
+func TestRun_resourceListResults(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    namespace: default
+    annotations:
+      scaler: "5"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: scaler-config
+  data:
+    maxReplicas: "3"
+  spc: {}
+results:
+- severity: info
+  message: result of a previous function
+`
+	results := `- severity: warning
+  message: unknown functionConfig field spc
+  resourceRef:
+    name: scaler-config
+    apiVersion: v1
+    kind: ConfigMap
+  field: spc
+- severity: warning
+  message: clamped replicas of component example-component from 5 to 3
+  resourceRef:
+    name: example-appconfig
+    namespace: default
+    apiVersion: core.oam.dev/v1alpha2
+    kind: ApplicationConfiguration
+`
+	expected := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    namespace: default
+    annotations:
+      scaler: "5"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          spec:
+            replicaCount: 3
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: scaler-config
+  data:
+    maxReplicas: "3"
+  spc: {}
+results:
+- severity: info
+  message: result of a previous function
+` + results
+	var out, resultsOut, log bytes.Buffer
+	logger := &framework.Logger{Writer: &log}
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{Logger: logger},
+		runOptions{results: &resultsOut}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+	}
+	expectedResults := "- severity: info\n  message: result of a previous function\n" + results
+	if resultsOut.String() != expectedResults {
+		t.Fatalf("expected results %s\nbut got %s\n", expectedResults, resultsOut.String())
+	}
+
+	// the output is a valid input of the next function
+	out2 := bytes.Buffer{}
+	if err := run(bytes.NewBufferString(out.String()), &out2, scaler.ScalerFilter{},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out2.String(), "results:\n"+expectedResults) {
+		t.Fatalf("expected the results to be kept\nbut got %s\n", out2.String())
+	}
+
+	// the error is written as an error result, and the ResourceList isn't written
+	out.Reset()
+	resultsOut.Reset()
+	err := run(bytes.NewBufferString(strings.Replace(input, `maxReplicas: "3"`,
+		`maxReplicas: "-1"`, 1)), &out, scaler.ScalerFilter{}, runOptions{results: &resultsOut})
+	expectedErr := `functionConfig maxReplicas must be a non-negative integer, got "-1"`
+	if err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error %s\nbut got %v\n", expectedErr, err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no output\nbut got %s\n", out.String())
+	}
+	expectedResults = "- severity: info\n  message: result of a previous function\n" +
+		results[:strings.Index(results, "- severity: warning\n  message: clamped")] +
+		"- severity: error\n  message: " + expectedErr + "\n"
+	if resultsOut.String() != expectedResults {
+		t.Fatalf("expected results %s\nbut got %s\n", expectedResults, resultsOut.String())
+	}
+}
+
+func TestRun_invalid(t *testing.T) {
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: valid-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components: []
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: invalid-appconfig
+  annotations:
+    scaler: "3"
+`
+	var out, results bytes.Buffer
+	err := run(bytes.NewBufferString(input), &out,
+		scaler.ScalerFilter{Results: &results}, runOptions{})
+	expected := "ApplicationConfiguration /invalid-appconfig: spec must be a mapping"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %s\nbut got %v\n", expected, err)
+	}
+	if out.Len() != 0 || results.Len() != 0 {
+		t.Fatalf("expected no output\nbut got %s%s\n", out.String(), results.String())
+	}
+}
+
+func TestRun_results(t *testing.T) {
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  namespace: example-namespace
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`
+	var out, results bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{Results: &results}, runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// stdout must only contain the Resources
+	nodes, err := (&kio.ByteReader{Reader: &out, OmitReaderAnnotations: true}).Read()
+	if err != nil {
+		t.Fatalf("expected valid yaml output: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("expected 1 Resource in the output but got %d", len(nodes))
+	}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, "[") {
+			t.Fatalf("unexpected result in the output: %s", line)
+		}
+	}
+
+	expected := "[info] ApplicationConfiguration example-namespace/example-appconfig: " +
+		"set replicaCount of ManualScalerTrait example-appconfig-trait in component " +
+		"example-component to 3\n"
+	if results.String() != expected {
+		t.Fatalf("expected results %s\nbut got %s\n", expected, results.String())
+	}
+}
+
+func TestRun_summary(t *testing.T) {
+	appConfig := func(name, replicas string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: ` + name + `
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: ` + replicas + `
+`
+	}
+	input := appConfig("changed", "1") + "---\n" + appConfig("unchanged", "3") + `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example-deployment
+`
+	var out, stderr bytes.Buffer
+	f := scaler.ScalerFilter{Results: &stderr, Summary: &stderr}
+	if err := run(bytes.NewBufferString(input), &out, f, runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the summary is the last line of stderr, and isn't written to stdout
+	lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	var scanned, changed, skipped int
+	if _, err := fmt.Sscanf(lines[len(lines)-1], "scanned=%d changed=%d skipped=%d",
+		&scanned, &changed, &skipped); err != nil {
+		t.Fatalf("expected a summary in %s\nbut got %v\n", stderr.String(), err)
+	}
+	if scanned != 3 || changed != 1 || skipped != 2 {
+		t.Fatalf("expected scanned=3 changed=1 skipped=2\nbut got scanned=%d changed=%d skipped=%d\n",
+			scanned, changed, skipped)
+	}
+	if strings.Contains(out.String(), "scanned=") {
+		t.Fatalf("unexpected summary in the output: %s", out.String())
+	}
+}
+
+func TestRun_dryRun(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      config.kubernetes.io/index: '0'
+      config.kubernetes.io/path: 'example.yaml'
+      scaler: "3"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: example-appconfig-trait
+          spec:
+            replicaCount: 1
+`
+	var out, results bytes.Buffer
+	err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{Results: &results, DryRun: true}, runOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != input {
+		t.Fatalf("expected unmodified output %s\nbut got %s\n", input, out.String())
+	}
+
+	expected := "[info] ApplicationConfiguration /example-appconfig: " +
+		"would set replicaCount of ManualScalerTrait example-appconfig-trait in component " +
+		"example-component to 3\n"
+	if results.String() != expected {
+		t.Fatalf("expected results %s\nbut got %s\n", expected, results.String())
+	}
+}
+
+func TestRunDir(t *testing.T) {
+	files := map[string]string{
+		"appconfig.yaml": `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`,
+		"component.yaml": `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: example-component
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+`,
+	}
+	expected := map[string]string{
+		"appconfig.yaml": strings.Replace(files["appconfig.yaml"],
+			"replicaCount: 1", "replicaCount: 3", 1),
+		"component.yaml": files["component.yaml"],
+	}
+
+	tests := []struct {
+		name     string
+		dryRun   bool
+		expected map[string]string
+	}{
+		{name: "set", expected: expected},
+		{name: "dry-run", dryRun: true, expected: files},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "oam-trait-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			for name, content := range files {
+				err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var results bytes.Buffer
+			if err := runDir(dir, scaler.ScalerFilter{Results: &results, DryRun: test.dryRun}); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range test.expected {
+				b, err := ioutil.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != content {
+					t.Fatalf("expected %s to contain %s\nbut got %s\n", name, content, string(b))
+				}
+			}
+			if !strings.Contains(results.String(), "replicaCount of ManualScalerTrait") {
+				t.Fatalf("expected the replicaCount in the results\nbut got %s\n", results.String())
+			}
+		})
+	}
+}
+
+func TestRun_readerAnnotations(t *testing.T) {
+	resource := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    config.kubernetes.io/index: '0'
+    config.kubernetes.io/path: 'example.yaml'
+spec:
+  components: []
+`
+	resourceList := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      config.kubernetes.io/index: '0'
+      config.kubernetes.io/path: 'example.yaml'
+  spec:
+    components: []
+`
+	tests := []struct {
+		name     string
+		input    string
+		keep     bool
+		expected string
+	}{
+		{
+			name:  "standalone",
+			input: resource,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components: []
+`,
+		},
+		{
+			name:     "standalone-keep",
+			input:    resource,
+			keep:     true,
+			expected: resource,
+		},
+		{
+			name:     "pipeline",
+			input:    resourceList,
+			expected: resourceList,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := run(bytes.NewBufferString(test.input), &out, scaler.ScalerFilter{},
+				runOptions{keepReaderAnnotations: test.keep}); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expected {
+				t.Fatalf("expected %s\nbut got %s\n", test.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestRun_jsonOutput(t *testing.T) {
+	appConfig := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`
+	tests := []struct {
+		name  string
+		input string
+		lines int
+	}{
+		{
+			name: "resources",
+			input: appConfig + `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: example-service
+`,
+			lines: 2,
+		},
+		{
+			name: "resource-list",
+			input: `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- ` + strings.Replace(strings.TrimSpace(appConfig), "\n", "\n  ", -1) + `
+functionConfig:
+  apiVersion: v1
+  kind: ConfigMap
+  data:
+    minReplicas: "2"
+`,
+			lines: 1,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var yamlOut, jsonOut bytes.Buffer
+			if err := run(bytes.NewBufferString(test.input), &yamlOut,
+				scaler.ScalerFilter{}, runOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := run(bytes.NewBufferString(test.input), &jsonOut,
+				scaler.ScalerFilter{}, runOptions{output: jsonOutput}); err != nil {
+				t.Fatal(err)
+			}
+
+			// each object is written on its own line
+			if lines := strings.Count(jsonOut.String(), "\n"); lines != test.lines {
+				t.Fatalf("expected %d lines\nbut got %s\n", test.lines, jsonOut.String())
+			}
+			if !strings.Contains(jsonOut.String(), `"replicaCount":3`) {
+				t.Fatalf("expected %s in output\nbut got %s\n", `"replicaCount":3`, jsonOut.String())
+			}
+
+			// the json is read back as the same Resources as the yaml
+			var expected, actual []interface{}
+			yamlDecoder := yaml.NewDecoder(&yamlOut)
+			for {
+				var value interface{}
+				if err := yamlDecoder.Decode(&value); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				// convert the yaml values to the types decoded from json
+				b, err := json.Marshal(value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := json.Unmarshal(b, &value); err != nil {
+					t.Fatal(err)
+				}
+				expected = append(expected, value)
+			}
+			jsonDecoder := json.NewDecoder(&jsonOut)
+			for jsonDecoder.More() {
+				var value interface{}
+				if err := jsonDecoder.Decode(&value); err != nil {
+					t.Fatal(err)
+				}
+				actual = append(actual, value)
+			}
+			if !reflect.DeepEqual(expected, actual) {
+				t.Fatalf("expected %v\nbut got %v\n", expected, actual)
+			}
+		})
+	}
+}
+
+func TestRun_values(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oam-trait-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "values.yaml")
+	if err := ioutil.WriteFile(path, []byte("frontend: 5\nbackend: \"7\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	values, err := readValues(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"frontend": "5", "backend": "7"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values %v\nbut got %v\n", expected, values)
+	}
+
+	// the values override the annotations, and the components which aren't in the
+	// values fall back to the annotations
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+    scaler.oam.dev/frontend: "2"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+  - componentName: worker
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`
+	expected := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+    scaler.oam.dev/frontend: "2"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 5
+  - componentName: worker
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+`
+	var out bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out, scaler.ScalerFilter{Values: values},
+		runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+	}
+
+	if err := ioutil.WriteFile(path, []byte("- frontend\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readValues(path); err == nil ||
+		!strings.HasPrefix(err.Error(), "values file "+path+" ") {
+		t.Fatalf("expected an error for the invalid values file\nbut got %v\n", err)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	for s, expected := range map[string][]string{
+		"":                     nil,
+		" , ":                  nil,
+		"tenant-a":             {"tenant-a"},
+		"tenant-a, tenant-b,,": {"tenant-a", "tenant-b"},
+	} {
+		if actual := splitList(s); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected values %v for %q\nbut got %v\n", expected, s, actual)
+		}
+	}
+}
+
+func TestRun_namespaces(t *testing.T) {
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-a-appconfig
+  namespace: tenant-a
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-b-appconfig
+  namespace: tenant-b
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`
+	expected := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-a-appconfig
+  namespace: tenant-a
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-b-appconfig
+  namespace: tenant-b
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`
+	var out bytes.Buffer
+	f := scaler.ScalerFilter{Namespaces: splitList("tenant-a")}
+	if err := run(bytes.NewBufferString(input), &out, f, runOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Traits may share their fields with yaml anchors and aliases, e.g.
+//
+//	traits:
+//	- trait: &scaler
+//	    kind: ManualScalerTrait
+//	    spec:
+//	      replicaCount: 1
+//	- trait: *scaler
+//
+// The aliases are resolved when the traits are read.  Only the trait which is
+// injected is changed, so the anchors aren't written through their aliases, and
+// the aliases of an anchor which is written are replaced by copies of it first.
+
+// resolveAlias returns the node which node is an alias of, or node if it isn't an
+// alias.
+func resolveAlias(node *yaml.RNode) *yaml.RNode {
+	if node == nil || node.YNode().Kind != yaml.AliasNode {
+		return node
+	}
+	return yaml.NewRNode(node.YNode().Alias)
+}
+
+// lookup returns the field at path of node, resolving the aliases along the path,
+// or nil if the field doesn't exist.
+func lookup(node *yaml.RNode, path ...string) (*yaml.RNode, error) {
+	for _, field := range path {
+		var err error
+		if node, err = resolveAlias(node).Pipe(yaml.Lookup(field)); err != nil || node == nil {
+			return nil, err
+		}
+	}
+	return resolveAlias(node), nil
+}
+
+// withAlias calls fn with node if it isn't an alias.  Otherwise fn is called with a
+// copy of the anchor of node, which replaces node in r if fn changes it.
+func withAlias(r, node *yaml.RNode, fn func(node *yaml.RNode) error) error {
+	if node.YNode().Kind != yaml.AliasNode {
+		return fn(node)
+	}
+	c := yaml.NewRNode(copyNode(node.YNode()))
+	before, err := c.String()
+	if err != nil {
+		return err
+	}
+	if err := fn(c); err != nil {
+		return err
+	}
+	after, err := c.String()
+	if err != nil || after == before {
+		return err
+	}
+	detach(r, node)
+	*node.YNode() = *c.YNode()
+	return nil
+}
+
+// detachPath detaches node and each of the fields along path of node in r, so
+// that they may be written without changing any anchors or aliases.  See detach.
+func detachPath(r, node *yaml.RNode, path []string) {
+	detach(r, node)
+	for _, name := range path {
+		field := node.Field(name)
+		if field == nil {
+			return
+		}
+		node = field.Value
+		detach(r, node)
+	}
+}
+
+// detach replaces node with a copy of its anchor if it is an alias, and replaces
+// the aliases in r of node, or of the anchors containing node, with copies, so that
+// node may be written without changing the aliases.
+func detach(r, node *yaml.RNode) {
+	n := node.YNode()
+	if n.Kind == yaml.AliasNode {
+		*n = *copyNode(n)
+	}
+	for _, anchor := range anchorsContaining(r.YNode(), n) {
+		expandAliases(r.YNode(), anchor)
+	}
+}
+
+// anchorsContaining returns the anchors in root which contain target, including
+// target itself.  Aliases aren't followed.
+func anchorsContaining(root, target *yaml.Node) []*yaml.Node {
+	if root == target {
+		if root.Anchor != "" {
+			return []*yaml.Node{root}
+		}
+		return []*yaml.Node{}
+	}
+	if root.Kind == yaml.AliasNode {
+		return nil
+	}
+	for _, n := range root.Content {
+		if anchors := anchorsContaining(n, target); anchors != nil {
+			if root.Anchor != "" {
+				anchors = append(anchors, root)
+			}
+			return anchors
+		}
+	}
+	return nil
+}
+
+// expandAliases replaces each alias of anchor in root with a copy of anchor.
+func expandAliases(root, anchor *yaml.Node) {
+	if root.Kind == yaml.AliasNode {
+		if root.Alias == anchor {
+			*root = *copyNode(anchor)
+		}
+		return
+	}
+	for _, n := range root.Content {
+		expandAliases(n, anchor)
+	}
+}
+
+// copyNode returns a deep copy of node with the aliases resolved and without the
+// anchors.
+func copyNode(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return copyNode(node.Alias)
+	}
+	c := *node
+	c.Anchor = ""
+	c.Content = nil
+	for _, n := range node.Content {
+		c.Content = append(c.Content, copyNode(n))
+	}
+	return &c
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kustomize/functions/examples/oam-trait/pkg/scaler"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// goldenFilter configures the ScalerFilter of a golden test case.
+type goldenFilter struct {
+	AnnotationKey    string `yaml:"annotationKey,omitempty"`
+	AnnotationPrefix string `yaml:"annotationPrefix,omitempty"`
+	DryRun           bool   `yaml:"dryRun,omitempty"`
+	DefaultReplicas  *int   `yaml:"defaultReplicas,omitempty"`
+	Selector         string `yaml:"selector,omitempty"`
+	CreateIfMissing  bool   `yaml:"createIfMissing,omitempty"`
+	LogLevel         string `yaml:"logLevel,omitempty"`
+}
+
+// TestScalerFilter_golden pipes the input.yaml of each directory in
+// testdata/golden through a ScalerFilter configured by its optional filter.yaml,
+// and compares the output Resources, the results and the error with its
+// expected.yaml, results.txt and error.txt.  The missing golden files are
+// expected to be empty.  Run `go test ./pkg/scaler -run golden -update` to
+// rewrite the golden files from the actual output.
+func TestScalerFilter_golden(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "golden", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) == 0 {
+		t.Fatal("expected golden test cases in testdata/golden")
+	}
+	for i := range dirs {
+		dir := dirs[i]
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			input, err := ioutil.ReadFile(filepath.Join(dir, "input.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var config goldenFilter
+			if b, err := readGolden(dir, "filter.yaml"); err != nil {
+				t.Fatal(err)
+			} else if err := yaml.Unmarshal(b, &config); err != nil {
+				t.Fatalf("filter.yaml: %v", err)
+			}
+
+			var out, results bytes.Buffer
+			err = kio.Pipeline{
+				Inputs: []kio.Reader{&kio.ByteReader{Reader: bytes.NewReader(input)}},
+				Filters: []kio.Filter{scaler.ScalerFilter{
+					AnnotationKey:    config.AnnotationKey,
+					AnnotationPrefix: config.AnnotationPrefix,
+					DryRun:           config.DryRun,
+					DefaultReplicas:  config.DefaultReplicas,
+					Selector:         config.Selector,
+					CreateIfMissing:  config.CreateIfMissing,
+					LogLevel:         config.LogLevel,
+					Results:          &results,
+				}},
+				Outputs: []kio.Writer{&kio.ByteWriter{Writer: &out}},
+			}.Execute()
+			var errMsg []byte
+			if err != nil {
+				errMsg = []byte(err.Error() + "\n")
+			}
+
+			actual := map[string][]byte{
+				"expected.yaml": out.Bytes(),
+				"results.txt":   results.Bytes(),
+				"error.txt":     errMsg,
+			}
+			for _, name := range []string{"expected.yaml", "results.txt", "error.txt"} {
+				if *update {
+					if err := writeGolden(dir, name, actual[name]); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				expected, err := readGolden(dir, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(expected) != string(actual[name]) {
+					t.Errorf("%s: expected\n%s\nbut got\n%s\n", name, expected, actual[name])
+				}
+			}
+		})
+	}
+}
+
+// readGolden returns the contents of the golden file name in dir, which are
+// empty if it doesn't exist.
+func readGolden(dir, name string) ([]byte, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// writeGolden writes the golden file name in dir, or removes it if b is empty.
+func writeGolden(dir, name string, b []byte) error {
+	path := filepath.Join(dir, name)
+	if len(b) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
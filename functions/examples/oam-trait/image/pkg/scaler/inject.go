@@ -0,0 +1,549 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Options configure Inject.
+type Options struct {
+	// AnnotationKey is the annotation read for the replicas.
+	// Defaults to DefaultAnnotationKey if unset.
+	// A component with an `<AnnotationKey>: <replicas>` field, or named by an
+	// `<name of the AnnotationKey>.oam.dev/<componentName>: <replicas>`
+	// annotation, overrides the annotation, and the field wins over the
+	// component annotation.  Resources without a metadata.annotations map, or
+	// with a null one, are handled as not annotated.
+	AnnotationKey string
+
+	// AnnotationPrefix if set is prefixed to the AnnotationKey of the
+	// annotation, e.g. `oam.dev/`.  The component field isn't prefixed.
+	AnnotationPrefix string
+
+	// DryRun if set will report the replicas which would be set
+	// without setting them.
+	DryRun bool
+
+	// DefaultReplicas if set are the replicas injected into the components of
+	// ApplicationConfigurations without the AnnotationKey annotation.  If it is
+	// unset, only the components which override the replicas are injected.
+	DefaultReplicas *int
+
+	// Values if set map component names to their replicas, e.g. read from an
+	// environment-specific values file, and win over the component field and
+	// the annotations.  Components which aren't in Values fall back to them.
+	Values map[string]string
+
+	// MinReplicas and MaxReplicas if set bound the replicas which are set.
+	// Replicas outside of the bounds are clamped, and a warning is reported.
+	MinReplicas *int
+	MaxReplicas *int
+
+	// Selector if set is a label selector, and only the components whose
+	// workload labels match it are injected.  See ScalerFilter.Selector.
+	Selector string
+
+	// Components are the Component Resources whose workloads are matched
+	// against the Selector, for components which don't embed their workload.
+	// The components whose Component Resource has a true SkipAnnotation aren't
+	// injected.
+	Components []*yaml.RNode
+
+	// FailOnNoMatch if set returns an error for Resources with the annotation
+	// which don't have any traits with replicas, which usually means the kind
+	// of the trait is misspelled.
+	FailOnNoMatch bool
+
+	// CreateIfMissing if set adds a ManualScalerTrait with the replicas to the
+	// components which are injected but don't have any traits with replicas.
+	// The `traits` field of the component is created if it is missing.
+	CreateIfMissing bool
+
+	// TraitAPIVersions if set are the apiVersions of the traits with replicas
+	// which are injected.  Defaults to DefaultTraitAPIVersions.  The trait
+	// added by CreateIfMissing has the first of them.
+	TraitAPIVersions []string
+
+	// InjectWorkloads if set injects the replicas into the `spec.replicas` of
+	// the workload embedded in the component's `workload` field -- e.g. a
+	// Deployment -- instead of into its traits.  Only the kinds of workloads in
+	// workloadSetters are injected.  The workloads of Component Resources aren't
+	// injected, since they aren't part of the injected Resource.
+	InjectWorkloads bool
+
+	// Report if set is called with each change which is made, and with the
+	// other results, e.g. the replicas which are clamped.  An empty
+	// spec.components, and components with traits with replicas but nothing to
+	// inject them from, are reported.  Component annotations naming a component
+	// without any traits with replicas are reported as warnings.
+	Report func(Result)
+}
+
+// Result is reported by Inject for the injected Resource.
+type Result struct {
+	// Severity is one of SeverityDebug, SeverityInfo or SeverityWarning.
+	Severity string
+
+	// Message describes the result.
+	Message string
+}
+
+// validate returns an error if the options are invalid.
+func (opts Options) validate() error {
+	if opts.MinReplicas != nil && opts.MaxReplicas != nil && *opts.MinReplicas > *opts.MaxReplicas {
+		return fmt.Errorf("minReplicas %d must not be greater than maxReplicas %d",
+			*opts.MinReplicas, *opts.MaxReplicas)
+	}
+	if opts.DefaultReplicas != nil && *opts.DefaultReplicas < 0 {
+		return fmt.Errorf("defaultReplicas must be a non-negative integer, got %d",
+			*opts.DefaultReplicas)
+	}
+	if opts.Selector != "" {
+		if _, err := parseLabelSelector(opts.Selector); err != nil {
+			return fmt.Errorf("selector %q %v", opts.Selector, err)
+		}
+	}
+	return nil
+}
+
+// traitAPIVersions returns the TraitAPIVersions, or DefaultTraitAPIVersions
+// if they aren't set.
+func (opts Options) traitAPIVersions() []string {
+	if len(opts.TraitAPIVersions) == 0 {
+		return DefaultTraitAPIVersions
+	}
+	return opts.TraitAPIVersions
+}
+
+// traitSetter returns the setter of the trait with meta, if it is a kind of
+// trait with replicas and has one of the traitAPIVersions.
+func (opts Options) traitSetter(meta yaml.ResourceMeta) (traitSetter, bool) {
+	setter, found := traitSetters[meta.Kind]
+	if !found {
+		return traitSetter{}, false
+	}
+	for _, v := range opts.traitAPIVersions() {
+		if meta.APIVersion == v {
+			return setter, true
+		}
+	}
+	return traitSetter{}, false
+}
+
+// workloadSetters contains the setters for each kind of workload which has
+// replicas, and is injected if InjectWorkloads is set.
+var workloadSetters = map[string]traitSetter{
+	"Deployment":  intFieldSetter([]string{"spec", "replicas"}, ""),
+	"StatefulSet": intFieldSetter([]string{"spec", "replicas"}, ""),
+	"ReplicaSet":  intFieldSetter([]string{"spec", "replicas"}, ""),
+}
+
+// workloadSetter returns the setter of the workload with meta, if it is a kind
+// of workload with replicas.
+func workloadSetter(meta yaml.ResourceMeta) (traitSetter, bool) {
+	setter, found := workloadSetters[meta.Kind]
+	return setter, found
+}
+
+// report calls Report with a result, if it is set.
+func (opts Options) report(severity string, msg string, args ...interface{}) {
+	if opts.Report != nil {
+		opts.Report(Result{Severity: severity, Message: fmt.Sprintf(msg, args...)})
+	}
+}
+
+// Inject injects the replicas into the components of r, and returns the number
+// of traits which were changed, or which would be changed when opts.DryRun is set.
+//
+// - r is injected if it has the `<AnnotationPrefix><AnnotationKey>: <replicas>` annotation
+// - components may override the replicas of the annotation, see AnnotationKey and Values
+// - every trait with replicas of each injected component is set
+// - the replicas `-` remove the replicas field from the traits
+// - the replicas `$NAME` or `${NAME}` are read from the NAME environment variable
+// - Resources with a true SkipAnnotation are returned unchanged
+// - other kinds of Resources without the annotation are returned unchanged
+func Inject(r *yaml.RNode, opts Options) (changed int, err error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	annotationKey := opts.AnnotationKey
+	if annotationKey == "" {
+		annotationKey = DefaultAnnotationKey
+	}
+	filter, err := newComponentFilter(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	// check for the scaler annotation
+	meta, err := r.GetMeta()
+	if err != nil {
+		return 0, err
+	}
+	replicaNumber, found := meta.Annotations[opts.AnnotationPrefix+annotationKey]
+	if !found && meta.Kind != applicationConfigurationKind {
+		// not a scaled Resource, ignore it
+		return 0, nil
+	}
+	skip, err := isSkipped(meta.Annotations)
+	if err != nil {
+		return 0, err
+	}
+	if skip {
+		opts.report(SeverityDebug, "skipping a Resource with the %s annotation", SkipAnnotation)
+		return 0, nil
+	}
+	annotated := found
+	if found {
+		if replicaNumber, err = expandReplicas(replicaNumber); err != nil {
+			return 0, fmt.Errorf("%s annotation %v", opts.AnnotationPrefix+annotationKey, err)
+		}
+		if err := validateReplicas(replicaNumber); err != nil {
+			return 0, fmt.Errorf("%s annotation %v", opts.AnnotationPrefix+annotationKey, err)
+		}
+	} else if opts.DefaultReplicas != nil {
+		// use the default for the components instead of the annotation
+		replicaNumber = strconv.Itoa(*opts.DefaultReplicas)
+		found = true
+	}
+
+	components, err := lookupComponents(r, opts)
+	if err != nil || components == nil {
+		return 0, err
+	}
+
+	// visit each component and set the replicas of its traits, or of its workload
+	visit, setterOf := visitTraits, opts.traitSetter
+	if opts.InjectWorkloads {
+		visit, setterOf = visitWorkload, workloadSetter
+	}
+	componentPrefix := componentAnnotationPrefix(annotationKey)
+	matched := false
+	// visited are the components which were visited, and scalable are the visited
+	// components with traits with replicas
+	visited, scalable := map[string]bool{}, map[string]bool{}
+	err = filter.visit(components, meta.Namespace, opts, func(componentName string, node *yaml.RNode) error {
+		visited[componentName] = true
+
+		// the values override the component field, which overrides the component
+		// annotation, which overrides the annotation
+		replicaNumber := replicaNumber
+		scaled := found
+		override, err := node.Pipe(yaml.Get(annotationKey))
+		if err != nil {
+			return err
+		}
+		componentReplicas, componentAnnotated := meta.Annotations[componentPrefix+componentName]
+		valueReplicas, valued := opts.Values[componentName]
+		switch {
+		case valued:
+			replicaNumber, err = expandReplicas(valueReplicas)
+			if err != nil {
+				return fmt.Errorf("component %s values %v", componentName, err)
+			}
+			if err := validateReplicas(replicaNumber); err != nil {
+				return fmt.Errorf("component %s values %v", componentName, err)
+			}
+			scaled = true
+		case override != nil:
+			replicaNumber, err = expandReplicas(yaml.GetValue(override))
+			if err != nil {
+				return fmt.Errorf("component %s %s field %v", componentName, annotationKey, err)
+			}
+			if err := validateReplicas(replicaNumber); err != nil {
+				return fmt.Errorf("component %s %s field %v",
+					componentName, annotationKey, err)
+			}
+			scaled = true
+		case componentAnnotated:
+			replicaNumber, err = expandReplicas(componentReplicas)
+			if err != nil {
+				return fmt.Errorf("%s annotation %v", componentPrefix+componentName, err)
+			}
+			if err := validateReplicas(replicaNumber); err != nil {
+				return fmt.Errorf("%s annotation %v", componentPrefix+componentName, err)
+			}
+			scaled = true
+		}
+		if scaled {
+			replicaNumber = opts.clamp(replicaNumber, componentName)
+		}
+
+		err = visit(r, node, componentName, opts, func(
+			trait *yaml.RNode, traitMeta yaml.ResourceMeta) error {
+			setter, found := setterOf(traitMeta)
+			if !found {
+				// not a trait -- or workload -- kind with replicas, skip it
+				return nil
+			}
+			scalable[componentName] = true
+			if !scaled {
+				// component isn't scaled, skip it
+				return nil
+			}
+			matched = true
+
+			isSet, err := setter.isSet(trait, replicaNumber)
+			if err != nil {
+				s, _ := r.String()
+				return fmt.Errorf("%v: %s", err, s)
+			}
+			if isSet {
+				// already has the replicas, don't report it as changed
+				return nil
+			}
+
+			changed++
+			if replicaNumber == removeReplicas {
+				if opts.DryRun {
+					opts.report(SeverityInfo, "would remove %s of %s %s in component %s",
+						setter.field, traitMeta.Kind, traitMeta.Name, componentName)
+					return nil
+				}
+				detachPath(r, trait, setter.path)
+				if err := setter.clear(trait); err != nil {
+					s, _ := r.String()
+					return fmt.Errorf("%v: %s", err, s)
+				}
+				opts.report(SeverityInfo, "removed %s of %s %s in component %s",
+					setter.field, traitMeta.Kind, traitMeta.Name, componentName)
+				return nil
+			}
+
+			if opts.DryRun {
+				opts.report(SeverityInfo, "would set %s of %s %s in component %s to %s",
+					setter.field, traitMeta.Kind, traitMeta.Name, componentName,
+					replicaNumber)
+				return nil
+			}
+
+			detachPath(r, trait, setter.path)
+			if err := setter.set(trait, replicaNumber); err != nil {
+				s, _ := r.String()
+				return fmt.Errorf("%v: %s", err, s)
+			}
+			opts.report(SeverityInfo, "set %s of %s %s in component %s to %s",
+				setter.field, traitMeta.Kind, traitMeta.Name, componentName,
+				replicaNumber)
+			return nil
+		})
+		if err == nil && scaled && !scalable[componentName] && opts.CreateIfMissing &&
+			replicaNumber != removeReplicas {
+			matched = true
+			scalable[componentName] = true
+			changed++
+			if opts.DryRun {
+				opts.report(SeverityInfo, "would add %s with %s %s to component %s",
+					scalerTraitKind, scalerTraitField, replicaNumber, componentName)
+				return nil
+			}
+			if err := addScalerTrait(r, node, opts.traitAPIVersions()[0], replicaNumber); err != nil {
+				return err
+			}
+			opts.report(SeverityInfo, "added %s with %s %s to component %s",
+				scalerTraitKind, scalerTraitField, replicaNumber, componentName)
+			return nil
+		}
+		if err == nil && !scaled && scalable[componentName] {
+			opts.report(SeverityInfo, "component %s has traits with replicas but no %s "+
+				"or %s annotation", componentName,
+				opts.AnnotationPrefix+annotationKey, componentPrefix+componentName)
+		}
+		return err
+	})
+	if err == nil {
+		reportComponentAnnotations(meta, componentPrefix, visited, scalable, opts)
+	}
+	if err == nil && opts.FailOnNoMatch && annotated && !matched {
+		return changed, fmt.Errorf("%s annotation is set but no trait with replicas was found",
+			opts.AnnotationPrefix+annotationKey)
+	}
+	return changed, err
+}
+
+// The trait added to the components by CreateIfMissing
+const (
+	scalerTraitKind  = "ManualScalerTrait"
+	scalerTraitField = "replicaCount"
+)
+
+// addScalerTrait appends a ManualScalerTrait with the apiVersion and the replicas to
+// the traits of the component of r, creating the traits if they are missing.
+func addScalerTrait(r, component *yaml.RNode, apiVersion, replicas string) error {
+	traits, err := component.Pipe(yaml.LookupCreate(yaml.SequenceNode, "traits"))
+	if err != nil {
+		return err
+	}
+	detach(r, traits)
+	if len(traits.YNode().Content) == 0 {
+		// write an empty `traits: []` in block style with the rest of the document
+		traits.YNode().Style = 0
+	}
+	trait, err := yaml.Parse(fmt.Sprintf(`trait:
+  apiVersion: %s
+  kind: %s
+  spec:
+    %s: %s
+`, apiVersion, scalerTraitKind, scalerTraitField, replicas))
+	if err != nil {
+		return err
+	}
+	return traits.PipeE(yaml.Append(trait.YNode()))
+}
+
+// componentAnnotationPrefix returns the prefix of the component annotations for the
+// annotationKey, which is the name of the key -- without its domain -- joined with
+// componentAnnotationDomain.  The AnnotationPrefix isn't prefixed to it, since it
+// already has a domain.
+func componentAnnotationPrefix(annotationKey string) string {
+	return annotationKey[strings.LastIndex(annotationKey, "/")+1:] + componentAnnotationDomain
+}
+
+// reportComponentAnnotations reports a warning for each annotation with the
+// componentPrefix which names a component that isn't visited or doesn't have any
+// traits with replicas, since the annotation isn't injected.
+func reportComponentAnnotations(meta yaml.ResourceMeta, componentPrefix string,
+	visited, scalable map[string]bool, opts Options) {
+	var keys []string
+	for key := range meta.Annotations {
+		if strings.HasPrefix(key, componentPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		componentName := strings.TrimPrefix(key, componentPrefix)
+		switch {
+		case !visited[componentName]:
+			opts.report(SeverityWarning, "%s annotation doesn't match any injected component",
+				key)
+		case !scalable[componentName]:
+			opts.report(SeverityWarning, "%s annotation is set but component %s doesn't "+
+				"have any traits with replicas", key, componentName)
+		}
+	}
+}
+
+// lookupComponents returns the spec.components of r, or nil if r doesn't have any
+// components.  Resources without the field and with an empty field are reported
+// differently, since an empty field is usually the result of an earlier step.
+func lookupComponents(r *yaml.RNode, opts Options) (*yaml.RNode, error) {
+	components, err := r.Pipe(yaml.Lookup("spec", "components"))
+	if err != nil {
+		s, _ := r.String()
+		return nil, fmt.Errorf("%v: %s", err, s)
+	}
+	if components == nil {
+		// doesn't have components, skip the Resource
+		opts.report(SeverityDebug, "skipping a Resource without spec.components")
+		return nil, nil
+	}
+	if len(components.YNode().Content) == 0 {
+		opts.report(SeverityInfo, "no components to process")
+		return nil, nil
+	}
+	return components, nil
+}
+
+// visitTraits calls fn with each trait of the component named componentName of r,
+// and the trait's metadata.  The traits may be wrapped in a `trait` field or be
+// inlined in the `traits` list, and elements without a kind are skipped.
+// The traits may be aliases, and a trait which fn changes replaces its alias.
+func visitTraits(r, component *yaml.RNode, componentName string, opts Options,
+	fn func(trait *yaml.RNode, traitMeta yaml.ResourceMeta) error) error {
+	traits, err := component.Pipe(yaml.Lookup("traits"))
+	if err != nil {
+		s, _ := r.String()
+		return fmt.Errorf("%v: %s", err, s)
+	}
+	if traits == nil {
+		// component doesn't have traits, skip it
+		return nil
+	}
+
+	return withAlias(r, traits, func(traits *yaml.RNode) error {
+		return traits.VisitElements(func(node *yaml.RNode) error {
+			return withAlias(r, node, func(node *yaml.RNode) error {
+				trait, err := node.Pipe(yaml.Lookup("trait"))
+				if err != nil {
+					s, _ := r.String()
+					return fmt.Errorf("%v: %s", err, s)
+				}
+				if trait == nil {
+					// the trait may be inlined rather than wrapped in a trait field
+					trait = node
+				}
+				return withAlias(r, trait, func(trait *yaml.RNode) error {
+					traitMeta, err := trait.GetMeta()
+					if err != nil && err != yaml.ErrMissingMetadata {
+						return err
+					}
+					if traitMeta.Kind == "" {
+						// not a trait, skip it
+						opts.report(SeverityDebug,
+							"skipping a trait without a kind in component %s", componentName)
+						return nil
+					}
+					opts.report(SeverityDebug, "visiting %s %s in component %s",
+						traitMeta.Kind, traitMeta.Name, componentName)
+					return fn(trait, traitMeta)
+				})
+			})
+		})
+	})
+}
+
+// visitWorkload calls fn with the workload embedded in the `workload` field of the
+// component named componentName of r, and the workload's metadata, so that it is
+// injected like a trait.  Components without an embedded workload are skipped.
+func visitWorkload(r, component *yaml.RNode, componentName string, opts Options,
+	fn func(workload *yaml.RNode, workloadMeta yaml.ResourceMeta) error) error {
+	workload, err := component.Pipe(yaml.Lookup("workload"))
+	if err != nil {
+		s, _ := r.String()
+		return fmt.Errorf("%v: %s", err, s)
+	}
+	if workload == nil {
+		opts.report(SeverityDebug, "skipping component %s without a workload", componentName)
+		return nil
+	}
+	return withAlias(r, workload, func(workload *yaml.RNode) error {
+		workloadMeta, err := workload.GetMeta()
+		if err != nil && err != yaml.ErrMissingMetadata {
+			return err
+		}
+		opts.report(SeverityDebug, "visiting workload %s %s in component %s",
+			workloadMeta.Kind, workloadMeta.Name, componentName)
+		return fn(workload, workloadMeta)
+	})
+}
+
+// clamp returns the replicas clamped to MinReplicas and MaxReplicas, and reports
+// a warning for the component if they are clamped.
+func (opts Options) clamp(replicas string, componentName string) string {
+	if replicas == removeReplicas {
+		return replicas
+	}
+	// the replicas have been validated
+	n, _ := strconv.Atoi(replicas)
+	clamped := n
+	if opts.MinReplicas != nil && clamped < *opts.MinReplicas {
+		clamped = *opts.MinReplicas
+	}
+	if opts.MaxReplicas != nil && clamped > *opts.MaxReplicas {
+		clamped = *opts.MaxReplicas
+	}
+	if clamped == n {
+		return replicas
+	}
+	opts.report(SeverityWarning, "clamped replicas of component %s from %d to %d",
+		componentName, n, clamped)
+	return strconv.Itoa(clamped)
+}
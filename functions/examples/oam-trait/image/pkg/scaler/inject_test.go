@@ -0,0 +1,698 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/functions/examples/oam-trait/pkg/scaler"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestInject(t *testing.T) {
+	appConfig := func(annotation, replicas string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    ` + annotation + `
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: ` + replicas + `
+`
+	}
+	// noAnnotations removes the empty annotations of an appConfig
+	noAnnotations := func(appConfig string) string {
+		return strings.Replace(appConfig, "  annotations:\n    \n", "", 1)
+	}
+	misspelled := func(annotation, replicas string) string {
+		return strings.Replace(appConfig(annotation, replicas),
+			"kind: ManualScalerTrait", "kind: ManualScaleTrait", 1)
+	}
+	twoTraits := func(replicas string) string {
+		return appConfig(`scaler: "3"`, replicas) + `    - apiVersion: core.oam.dev/v1alpha2
+      kind: ManualScalerTrait
+      metadata:
+        name: inlined-trait
+      spec:
+        replicaCount: ` + replicas + `
+`
+	}
+	// withWorkload is an appConfig whose component embeds a workload of kind with
+	// the replicas, e.g. a Deployment
+	withWorkload := func(kind, workloadReplicas, traitReplicas string) string {
+		return strings.Replace(appConfig(`scaler: "3"`, traitReplicas),
+			"  - componentName: example-component\n", `  - componentName: example-component
+    workload:
+      apiVersion: apps/v1
+      kind: `+kind+`
+      metadata:
+        name: example-workload
+      spec:
+        replicas: `+workloadReplicas+`
+        template: {}
+`, 1)
+	}
+	twoComponents := func(annotations, frontend, backend string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    ` + annotations + `
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: ` + frontend + `
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: ` + backend + `
+  - componentName: worker
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: RouteTrait
+        metadata:
+          name: worker-route
+`
+	}
+	three := 3
+
+	tests := []struct {
+		name            string
+		opts            scaler.Options
+		env             map[string]string
+		input           string
+		expected        string
+		expectedChanged int
+		expectedResults []scaler.Result
+		expectedErr     string
+	}{
+		{
+			name:            "changed",
+			input:           appConfig(`scaler: "3"`, "1"),
+			expected:        appConfig(`scaler: "3"`, "3"),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "set replicaCount of ManualScalerTrait example-appconfig-trait " +
+					"in component example-component to 3"}},
+		},
+		{
+			name:     "unchanged",
+			input:    appConfig(`scaler: "3"`, "3"),
+			expected: appConfig(`scaler: "3"`, "3"),
+		},
+		{
+			name:     "not-annotated",
+			input:    appConfig(`other: "3"`, "1"),
+			expected: appConfig(`other: "3"`, "1"),
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "component example-component has traits with replicas but no " +
+					"scaler or scaler.oam.dev/example-component annotation"}},
+		},
+		{
+			// Resources without an annotations map are skipped as not annotated
+			name:     "nil-annotations",
+			input:    noAnnotations(appConfig("", "1")),
+			expected: noAnnotations(appConfig("", "1")),
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "component example-component has traits with replicas but no " +
+					"scaler or scaler.oam.dev/example-component annotation"}},
+		},
+		{
+			name:     "nil-annotations-not-an-appconfig",
+			input:    "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: foo\n",
+			expected: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: foo\n",
+		},
+		{
+			name: "null-annotations",
+			input: strings.Replace(noAnnotations(appConfig("", "1")),
+				"  name: example-appconfig\n", "  name: example-appconfig\n  annotations: null\n", 1),
+			expected: strings.Replace(noAnnotations(appConfig("", "1")),
+				"  name: example-appconfig\n", "  name: example-appconfig\n  annotations: null\n", 1),
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "component example-component has traits with replicas but no " +
+					"scaler or scaler.oam.dev/example-component annotation"}},
+		},
+		{
+			// the Resource would change, but isn't modified
+			name:            "dry-run",
+			opts:            scaler.Options{DryRun: true},
+			input:           appConfig(`scaler: "3"`, "1"),
+			expected:        appConfig(`scaler: "3"`, "1"),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "would set replicaCount of ManualScalerTrait example-appconfig-trait " +
+					"in component example-component to 3"}},
+		},
+		{
+			name:            "clamped",
+			opts:            scaler.Options{MaxReplicas: &three},
+			input:           appConfig(`scaler: "5"`, "1"),
+			expected:        appConfig(`scaler: "5"`, "3"),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityWarning,
+					Message: "clamped replicas of component example-component from 5 to 3"},
+				{Severity: scaler.SeverityInfo,
+					Message: "set replicaCount of ManualScalerTrait example-appconfig-trait " +
+						"in component example-component to 3"},
+			},
+		},
+		{
+			name:            "multiple-traits",
+			input:           twoTraits("1"),
+			expected:        twoTraits("3"),
+			expectedChanged: 2,
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityInfo,
+					Message: "set replicaCount of ManualScalerTrait example-appconfig-trait " +
+						"in component example-component to 3"},
+				{Severity: scaler.SeverityInfo,
+					Message: "set replicaCount of ManualScalerTrait inlined-trait " +
+						"in component example-component to 3"},
+			},
+		},
+		{
+			name:        "invalid-annotation",
+			input:       appConfig(`scaler: "three"`, "1"),
+			expectedErr: `scaler annotation must be a non-negative integer, got "three"`,
+		},
+		{
+			name:            "env",
+			env:             map[string]string{"PREVIEW_REPLICAS": "3"},
+			input:           appConfig(`scaler: "$PREVIEW_REPLICAS"`, "1"),
+			expected:        appConfig(`scaler: "$PREVIEW_REPLICAS"`, "3"),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "set replicaCount of ManualScalerTrait example-appconfig-trait " +
+					"in component example-component to 3"}},
+		},
+		{
+			name:            "env-braces",
+			env:             map[string]string{"PREVIEW_REPLICAS": "3"},
+			input:           appConfig(`scaler: "${PREVIEW_REPLICAS}"`, "1"),
+			expected:        appConfig(`scaler: "${PREVIEW_REPLICAS}"`, "3"),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "set replicaCount of ManualScalerTrait example-appconfig-trait " +
+					"in component example-component to 3"}},
+		},
+		{
+			name:  "env-unset",
+			input: appConfig(`scaler: "$PREVIEW_REPLICAS"`, "1"),
+			expectedErr: "scaler annotation references the environment variable " +
+				"PREVIEW_REPLICAS, which isn't set",
+		},
+		{
+			name:        "env-invalid",
+			env:         map[string]string{"PREVIEW_REPLICAS": "three"},
+			input:       appConfig(`scaler: "$PREVIEW_REPLICAS"`, "1"),
+			expectedErr: `scaler annotation must be a non-negative integer, got "three"`,
+		},
+		{
+			name:        "invalid-options",
+			opts:        scaler.Options{Selector: "app=,"},
+			input:       appConfig(`scaler: "3"`, "1"),
+			expectedErr: `selector "app=," invalid requirement ""`,
+		},
+		{
+			// the trait has the replicas already, but matched
+			name:     "fail-on-no-match-matched",
+			opts:     scaler.Options{FailOnNoMatch: true},
+			input:    appConfig(`scaler: "3"`, "3"),
+			expected: appConfig(`scaler: "3"`, "3"),
+		},
+		{
+			name:        "fail-on-no-match",
+			opts:        scaler.Options{FailOnNoMatch: true},
+			input:       misspelled(`scaler: "3"`, "1"),
+			expectedErr: "scaler annotation is set but no trait with replicas was found",
+		},
+		{
+			name:     "fail-on-no-match-not-annotated",
+			opts:     scaler.Options{FailOnNoMatch: true},
+			input:    misspelled(`other: "3"`, "1"),
+			expected: misspelled(`other: "3"`, "1"),
+		},
+		{
+			// an empty components list is reported, unlike a missing one
+			name: "empty-components",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components: []
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components: []
+`,
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "no components to process"}},
+		},
+		{
+			name: "missing-components",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example
+  annotations:
+    scaler: "3"
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example
+  annotations:
+    scaler: "3"
+`,
+		},
+		{
+			// the component annotation wins over the annotation
+			name:  "component-annotation",
+			input: twoComponents("scaler: \"3\"\n    scaler.oam.dev/backend: \"5\"", "1", "1"),
+			expected: twoComponents("scaler: \"3\"\n    scaler.oam.dev/backend: \"5\"",
+				"3", "5"),
+			expectedChanged: 2,
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityInfo, Message: "set replicaCount of ManualScalerTrait " +
+					"frontend-trait in component frontend to 3"},
+				{Severity: scaler.SeverityInfo, Message: "set replicaCount of ManualScalerTrait " +
+					"backend-trait in component backend to 5"},
+			},
+		},
+		{
+			// the component without any annotation is reported rather than injected
+			name:            "component-annotation-only",
+			input:           twoComponents(`scaler.oam.dev/backend: "5"`, "1", "1"),
+			expected:        twoComponents(`scaler.oam.dev/backend: "5"`, "1", "5"),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityInfo, Message: "component frontend has traits with " +
+					"replicas but no scaler or scaler.oam.dev/frontend annotation"},
+				{Severity: scaler.SeverityInfo, Message: "set replicaCount of ManualScalerTrait " +
+					"backend-trait in component backend to 5"},
+			},
+		},
+		{
+			// the components without traits with replicas aren't injected
+			name: "component-annotation-mismatch",
+			input: twoComponents("scaler: \"3\"\n    scaler.oam.dev/worker: \"5\"\n"+
+				"    scaler.oam.dev/database: \"5\"", "3", "3"),
+			expected: twoComponents("scaler: \"3\"\n    scaler.oam.dev/worker: \"5\"\n"+
+				"    scaler.oam.dev/database: \"5\"", "3", "3"),
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityWarning, Message: "scaler.oam.dev/database annotation " +
+					"doesn't match any injected component"},
+				{Severity: scaler.SeverityWarning, Message: "scaler.oam.dev/worker annotation " +
+					"is set but component worker doesn't have any traits with replicas"},
+			},
+		},
+		{
+			name:        "component-annotation-invalid",
+			input:       twoComponents(`scaler.oam.dev/backend: "five"`, "1", "1"),
+			expectedErr: `scaler.oam.dev/backend annotation must be a non-negative integer, got "five"`,
+		},
+		{
+			// the values win over the component annotation and the annotation, and the
+			// components which aren't in the values fall back to the annotations
+			name:  "values",
+			opts:  scaler.Options{Values: map[string]string{"backend": "7"}},
+			input: twoComponents("scaler: \"3\"\n    scaler.oam.dev/backend: \"5\"", "1", "1"),
+			expected: twoComponents("scaler: \"3\"\n    scaler.oam.dev/backend: \"5\"",
+				"3", "7"),
+			expectedChanged: 2,
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityInfo, Message: "set replicaCount of ManualScalerTrait " +
+					"frontend-trait in component frontend to 3"},
+				{Severity: scaler.SeverityInfo, Message: "set replicaCount of ManualScalerTrait " +
+					"backend-trait in component backend to 7"},
+			},
+		},
+		{
+			// the values are injected without any annotation
+			name:            "values-not-annotated",
+			opts:            scaler.Options{Values: map[string]string{"frontend": "2", "backend": "4"}},
+			input:           twoComponents(`other: "3"`, "1", "1"),
+			expected:        twoComponents(`other: "3"`, "2", "4"),
+			expectedChanged: 2,
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityInfo, Message: "set replicaCount of ManualScalerTrait " +
+					"frontend-trait in component frontend to 2"},
+				{Severity: scaler.SeverityInfo, Message: "set replicaCount of ManualScalerTrait " +
+					"backend-trait in component backend to 4"},
+			},
+		},
+		{
+			// the values win over the component field
+			name: "values-component-override",
+			opts: scaler.Options{Values: map[string]string{"example-component": "5"}},
+			input: strings.Replace(appConfig(`scaler: "3"`, "1"),
+				"    traits:", "    scaler: \"4\"\n    traits:", 1),
+			expected: strings.Replace(appConfig(`scaler: "3"`, "5"),
+				"    traits:", "    scaler: \"4\"\n    traits:", 1),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityInfo, Message: "set replicaCount of ManualScalerTrait " +
+					"example-appconfig-trait in component example-component to 5"},
+			},
+		},
+		{
+			name:        "values-invalid",
+			opts:        scaler.Options{Values: map[string]string{"backend": "five"}},
+			input:       twoComponents(`scaler: "3"`, "1", "1"),
+			expectedErr: `component backend values must be a non-negative integer, got "five"`,
+		},
+		{
+			// the traits are created for the component without them
+			name: "create-if-missing",
+			opts: scaler.Options{CreateIfMissing: true},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+  - componentName: backend
+    traits: []
+  - componentName: worker
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: RouteTrait
+        metadata:
+          name: worker-route
+  - componentName: database
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: database-trait
+        spec:
+          replicaCount: 3
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+  - componentName: worker
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: RouteTrait
+        metadata:
+          name: worker-route
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+  - componentName: database
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: database-trait
+        spec:
+          replicaCount: 3
+`,
+			expectedChanged: 3,
+			expectedResults: []scaler.Result{
+				{Severity: scaler.SeverityInfo,
+					Message: "added ManualScalerTrait with replicaCount 3 to component frontend"},
+				{Severity: scaler.SeverityInfo,
+					Message: "added ManualScalerTrait with replicaCount 3 to component backend"},
+				{Severity: scaler.SeverityInfo,
+					Message: "added ManualScalerTrait with replicaCount 3 to component worker"},
+			},
+		},
+		{
+			name:            "create-if-missing-dry-run",
+			opts:            scaler.Options{CreateIfMissing: true, DryRun: true},
+			input:           misspelled(`scaler: "3"`, "1"),
+			expected:        misspelled(`scaler: "3"`, "1"),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "would add ManualScalerTrait with replicaCount 3 to component " +
+					"example-component"}},
+		},
+		{
+			// the components which aren't scaled don't get a trait
+			name:     "create-if-missing-not-annotated",
+			opts:     scaler.Options{CreateIfMissing: true},
+			input:    misspelled(`other: "3"`, "1"),
+			expected: misspelled(`other: "3"`, "1"),
+		},
+		{
+			// the replicas are set on the Deployment rather than on the trait
+			name:            "workload",
+			opts:            scaler.Options{InjectWorkloads: true},
+			input:           withWorkload("Deployment", "1", "1"),
+			expected:        withWorkload("Deployment", "3", "1"),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "set replicas of Deployment example-workload " +
+					"in component example-component to 3"}},
+		},
+		{
+			name:     "workload-unchanged",
+			opts:     scaler.Options{InjectWorkloads: true},
+			input:    withWorkload("StatefulSet", "3", "1"),
+			expected: withWorkload("StatefulSet", "3", "1"),
+		},
+		{
+			name: "workload-missing-replicas",
+			opts: scaler.Options{InjectWorkloads: true},
+			input: strings.Replace(withWorkload("Deployment", "1", "1"),
+				"        replicas: 1\n", "", 1),
+			expected: strings.Replace(withWorkload("Deployment", "1", "1"),
+				"        replicas: 1\n        template: {}\n", "        template: {}\n        replicas: 3\n", 1),
+			expectedChanged: 1,
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "set replicas of Deployment example-workload " +
+					"in component example-component to 3"}},
+		},
+		{
+			// workloads without replicas aren't injected
+			name:     "workload-without-replicas",
+			opts:     scaler.Options{InjectWorkloads: true},
+			input:    withWorkload("DaemonSet", "1", "1"),
+			expected: withWorkload("DaemonSet", "1", "1"),
+		},
+		{
+			// components without an embedded workload aren't injected
+			name:     "workload-missing",
+			opts:     scaler.Options{InjectWorkloads: true},
+			input:    appConfig(`scaler: "3"`, "1"),
+			expected: appConfig(`scaler: "3"`, "1"),
+		},
+		{
+			// the Resource is skipped unless FailOnNoMatch is set
+			name:     "no-match",
+			input:    misspelled(`scaler: "3"`, "1"),
+			expected: misspelled(`scaler: "3"`, "1"),
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			for k, v := range test.env {
+				if err := os.Setenv(k, v); err != nil {
+					t.Fatal(err)
+				}
+				defer os.Unsetenv(k)
+			}
+			r := yaml.MustParse(test.input)
+			var results []scaler.Result
+			test.opts.Report = func(r scaler.Result) {
+				// ignore the components and traits which are visited
+				if r.Severity != scaler.SeverityDebug {
+					results = append(results, r)
+				}
+			}
+
+			changed, err := scaler.Inject(r, test.opts)
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %s\nbut got %v\n", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != test.expectedChanged {
+				t.Fatalf("expected changed %d\nbut got %d\n", test.expectedChanged, changed)
+			}
+			if actual := r.MustString(); actual != test.expected {
+				t.Fatalf("expected %s\nbut got %s\n", test.expected, actual)
+			}
+			if !reflect.DeepEqual(results, test.expectedResults) {
+				t.Fatalf("expected results %v\nbut got %v\n", test.expectedResults, results)
+			}
+		})
+	}
+}
+
+func TestInject_aliases(t *testing.T) {
+	appConfig := func(annotations string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    ` + annotations + `
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait: &scaler
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: shared-trait
+        spec: &spec
+          replicaCount: 3
+  - componentName: backend
+    traits:
+    - trait: *scaler
+  - componentName: worker
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: worker-trait
+        spec: *spec
+`
+	}
+
+	tests := []struct {
+		name            string
+		input           string
+		expected        string
+		expectedChanged int
+	}{
+		{
+			// the aliases are resolved, and kept if the traits aren't changed
+			name:     "unchanged",
+			input:    appConfig(`scaler: "3"`),
+			expected: appConfig(`scaler: "3"`),
+		},
+		{
+			// the aliases are replaced by copies rather than being written through
+			name:  "changed",
+			input: appConfig(`scaler: "3"` + "\n    scaler.oam.dev/backend: \"5\""),
+			expected: strings.Replace(appConfig(`scaler: "3"`+"\n    scaler.oam.dev/backend: \"5\""),
+				"    - trait: *scaler\n", `    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: shared-trait
+        spec:
+          replicaCount: 5
+`, 1),
+			expectedChanged: 1,
+		},
+		{
+			// the aliased spec is replaced rather than the anchor
+			name:  "aliased-spec-changed",
+			input: appConfig(`scaler.oam.dev/worker: "5"`),
+			expected: strings.Replace(appConfig(`scaler.oam.dev/worker: "5"`),
+				"        spec: *spec\n", "        spec:\n          replicaCount: 5\n", 1),
+			expectedChanged: 1,
+		},
+		{
+			// the anchor is written, and its aliases keep the original replicas
+			name:  "anchor-changed",
+			input: appConfig(`scaler.oam.dev/frontend: "5"`),
+			expected: strings.Replace(strings.Replace(strings.Replace(
+				appConfig(`scaler.oam.dev/frontend: "5"`),
+				"replicaCount: 3", "replicaCount: 5", 1),
+				"    - trait: *scaler\n", `    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: shared-trait
+        spec:
+          replicaCount: 3
+`, 1),
+				"        spec: *spec\n", "        spec:\n          replicaCount: 3\n", 1),
+			expectedChanged: 1,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			r := yaml.MustParse(test.input)
+			changed, err := scaler.Inject(r, scaler.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != test.expectedChanged {
+				t.Fatalf("expected changed %d\nbut got %d\n", test.expectedChanged, changed)
+			}
+			if actual := r.MustString(); actual != test.expected {
+				t.Fatalf("expected %s\nbut got %s\n", test.expected, actual)
+			}
+		})
+	}
+}
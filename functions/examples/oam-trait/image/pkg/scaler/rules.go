@@ -0,0 +1,314 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Rule injects a value into a field of the traits of a kind, and generalizes the
+// injection of the replicas -- e.g. the replicas of ManualScalerTraits are injected
+// from the `scaler` annotation by the rule:
+//
+//	traitApiVersion: core.oam.dev/v1alpha2
+//	traitKind: ManualScalerTrait
+//	targetPath: spec.replicaCount
+//	source:
+//	  annotation: scaler
+type Rule struct {
+	// TraitAPIVersion if set is the apiVersion of the traits which are injected.
+	// Traits of any apiVersion are injected if unset or `*`.
+	TraitAPIVersion string `yaml:"traitApiVersion,omitempty"`
+
+	// TraitKind is the kind of the traits which are injected.  Traits of any kind
+	// are injected if `*`.
+	TraitKind string `yaml:"traitKind,omitempty"`
+
+	// TargetPath is the path of the field of the traits which is set, with the
+	// field names separated by `.`, e.g. `spec.replicaCount`, or a JSON pointer
+	// as in the paths of JSON6902 patches, e.g. `/spec/replicaCount`.  Missing
+	// parent fields are created.
+	TargetPath string `yaml:"targetPath,omitempty"`
+
+	// Source is where the value is read from.
+	Source RuleSource `yaml:"source,omitempty"`
+}
+
+// RuleSource is where the value of a Rule is read from.  Exactly one of its fields
+// must be set.
+type RuleSource struct {
+	// Annotation is the annotation of the ApplicationConfiguration the value is
+	// read from, and is prefixed with the AnnotationPrefix.  The rule is skipped
+	// for ApplicationConfigurations without the annotation.
+	Annotation string `yaml:"annotation,omitempty"`
+
+	// Literal is the value.
+	Literal string `yaml:"literal,omitempty"`
+
+	// FieldRef is the path of a field of the ApplicationConfiguration the value is
+	// read from, e.g. `metadata.labels.replicas`.  The field must be a scalar, and
+	// the rule is skipped for ApplicationConfigurations without the field.
+	FieldRef string `yaml:"fieldRef,omitempty"`
+}
+
+// TraitField sets a field of the traits to a value, and is a shorthand for a Rule
+// with a literal source, e.g. every trait of the core.oam.dev/v1alpha2 group is
+// labeled by:
+//
+//	apiVersion: core.oam.dev/v1alpha2
+//	kind: "*"
+//	fieldPath: metadata.labels.injected
+//	value: "true"
+type TraitField struct {
+	// APIVersion if set is the apiVersion of the traits which are set.  Traits of
+	// any apiVersion are set if unset or `*`.
+	APIVersion string `yaml:"apiVersion,omitempty"`
+
+	// Kind is the kind of the traits which are set, or `*` for traits of any kind.
+	Kind string `yaml:"kind,omitempty"`
+
+	// FieldPath is the path of the field which is set, e.g. `spec.replicaCount`
+	// or the JSON pointer `/spec/replicaCount`.
+	FieldPath string `yaml:"fieldPath,omitempty"`
+
+	// Value is the value the field is set to.
+	Value string `yaml:"value,omitempty"`
+}
+
+// Rule returns the Rule which sets the field.
+func (f TraitField) Rule() Rule {
+	return Rule{TraitAPIVersion: f.APIVersion, TraitKind: f.Kind, TargetPath: f.FieldPath,
+		Source: RuleSource{Literal: f.Value}}
+}
+
+// String returns a description of the rule for the results.
+func (r Rule) String() string {
+	apiVersion := r.TraitAPIVersion
+	if apiVersion == "" {
+		apiVersion = "*"
+	}
+	return fmt.Sprintf("%s %s %s", apiVersion, r.TraitKind, r.TargetPath)
+}
+
+// validate returns an error if the rule is invalid.
+func (r Rule) validate() error {
+	if r.TraitKind == "" {
+		return fmt.Errorf("must set traitKind")
+	}
+	if _, err := r.targetPath(); err != nil {
+		return err
+	}
+	sources := 0
+	for _, s := range []string{r.Source.Annotation, r.Source.Literal, r.Source.FieldRef} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("source must set exactly one of annotation, literal or fieldRef")
+	}
+	return nil
+}
+
+// targetPath returns the field names of the TargetPath.
+func (r Rule) targetPath() ([]string, error) {
+	if strings.HasPrefix(r.TargetPath, "/") {
+		path, err := JSONPointerPath(r.TargetPath)
+		if err != nil {
+			return nil, fmt.Errorf("targetPath %v", err)
+		}
+		return path, nil
+	}
+	if r.TargetPath == "" || strings.Contains("."+r.TargetPath+".", "..") {
+		return nil, fmt.Errorf("targetPath %q must be a path of field names separated by .",
+			r.TargetPath)
+	}
+	return strings.Split(r.TargetPath, "."), nil
+}
+
+// JSONPointerPath returns the field names of the JSON pointer, for yaml.Lookup,
+// e.g. `spec`, `replicaCount` for `/spec/replicaCount`.  The `~1` and `~0` escapes
+// are unescaped to `/` and `~`.  Each reference token is a field name, since the
+// traits' fields aren't set by list index.
+func JSONPointerPath(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") || pointer == "/" {
+		return nil, fmt.Errorf("%q must be a JSON pointer to a field, e.g. /spec/replicaCount",
+			pointer)
+	}
+	path := strings.Split(pointer[1:], "/")
+	for i, token := range path {
+		if token == "" {
+			return nil, fmt.Errorf("JSON pointer %q has an empty field name", pointer)
+		}
+		if strings.Contains(strings.NewReplacer("~0", "", "~1", "").Replace(token), "~") {
+			return nil, fmt.Errorf("JSON pointer %q has an invalid escape in %q, "+
+				"~ must be followed by 0 or 1", pointer, token)
+		}
+		// ~1 is unescaped first, so that ~01 is ~1 rather than /
+		path[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return path, nil
+}
+
+// ValidateRules returns an error naming the first invalid rule, if any.
+func ValidateRules(rules []Rule) error {
+	for i := range rules {
+		if err := rules[i].validate(); err != nil {
+			return fmt.Errorf("rule %d %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// value returns the value of the rule for the ApplicationConfiguration r, or nil if r
+// doesn't have the annotation or field of the source.
+func (r Rule) value(appConfig *yaml.RNode, meta yaml.ResourceMeta, opts Options) (
+	*yaml.RNode, error) {
+	switch {
+	case r.Source.Annotation != "":
+		value, found := meta.Annotations[opts.AnnotationPrefix+r.Source.Annotation]
+		if !found {
+			return nil, nil
+		}
+		return newScalarRNode(value), nil
+	case r.Source.FieldRef != "":
+		field, err := lookup(appConfig, strings.Split(r.Source.FieldRef, ".")...)
+		if err != nil || field == nil {
+			return nil, err
+		}
+		if field.YNode().Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("fieldRef %s must be a scalar", r.Source.FieldRef)
+		}
+		value := yaml.NewScalarRNode(field.YNode().Value)
+		value.YNode().Tag = field.YNode().Tag
+		return value, nil
+	default:
+		return newScalarRNode(r.Source.Literal), nil
+	}
+}
+
+// anyTrait is the TraitAPIVersion or TraitKind of a Rule matching traits of any
+// apiVersion or kind.
+const anyTrait = "*"
+
+// matches returns true if the rule injects traits with traitMeta.
+func (r Rule) matches(traitMeta yaml.ResourceMeta) bool {
+	return (r.TraitKind == anyTrait || traitMeta.Kind == r.TraitKind) &&
+		(r.TraitAPIVersion == "" || r.TraitAPIVersion == anyTrait ||
+			traitMeta.APIVersion == r.TraitAPIVersion)
+}
+
+// InjectRules applies each of the rules in order to the matching traits of each
+// component of the ApplicationConfiguration r.  Other Resources aren't injected.
+// The DryRun, AnnotationPrefix, Selector, Components and Report options are used
+// the same way as by Inject, and the other options are ignored.  The
+// SkipAnnotation is honored the same way as by Inject.
+//
+// InjectRules returns the number of traits of r which were changed, or which would
+// be changed when opts.DryRun is set, and whether each rule matched any trait.
+// Rules whose source isn't set on r don't match any traits.
+func InjectRules(r *yaml.RNode, rules []Rule, opts Options) (
+	changed int, matched []bool, err error) {
+	matched = make([]bool, len(rules))
+	if err := ValidateRules(rules); err != nil {
+		return 0, matched, err
+	}
+	filter, err := newComponentFilter(opts)
+	if err != nil {
+		return 0, matched, err
+	}
+	meta, err := r.GetMeta()
+	if err != nil {
+		return 0, matched, err
+	}
+	if meta.Kind != applicationConfigurationKind {
+		return 0, matched, nil
+	}
+	skip, err := isSkipped(meta.Annotations)
+	if err != nil {
+		return 0, matched, err
+	}
+	if skip {
+		opts.report(SeverityDebug, "skipping a Resource with the %s annotation", SkipAnnotation)
+		return 0, matched, nil
+	}
+	components, err := lookupComponents(r, opts)
+	if err != nil || components == nil {
+		return 0, matched, err
+	}
+
+	for i, rule := range rules {
+		value, err := rule.value(r, meta, opts)
+		if err != nil {
+			return changed, matched, fmt.Errorf("rule %d %v", i+1, err)
+		}
+		if value == nil {
+			opts.report(SeverityDebug, "skipping rule %d (%s) without a value", i+1, rule)
+			continue
+		}
+		path, err := rule.targetPath()
+		if err != nil {
+			return changed, matched, fmt.Errorf("rule %d %v", i+1, err)
+		}
+
+		err = filter.visit(components, meta.Namespace, opts, func(
+			componentName string, node *yaml.RNode) error {
+			return visitTraits(r, node, componentName, opts, func(
+				trait *yaml.RNode, traitMeta yaml.ResourceMeta) error {
+				if !rule.matches(traitMeta) {
+					return nil
+				}
+				matched[i] = true
+
+				field, err := lookup(trait, path...)
+				if err != nil {
+					return err
+				}
+				if field != nil && field.YNode().Value == value.YNode().Value &&
+					field.YNode().ShortTag() == value.YNode().ShortTag() {
+					// already has the value, don't report it as changed
+					return nil
+				}
+
+				changed++
+				if opts.DryRun {
+					opts.report(SeverityInfo, "would set %s of %s %s in component %s to %s",
+						rule.TargetPath, traitMeta.Kind, traitMeta.Name, componentName,
+						value.YNode().Value)
+					return nil
+				}
+				// each trait is set to its own copy of the value
+				node := *value.YNode()
+				detachPath(r, trait, path)
+				if err := trait.PipeE(yaml.LookupCreate(yaml.MappingNode, path[:len(path)-1]...),
+					yaml.SetField(path[len(path)-1], yaml.NewRNode(&node))); err != nil {
+					return err
+				}
+				opts.report(SeverityInfo, "set %s of %s %s in component %s to %s",
+					rule.TargetPath, traitMeta.Kind, traitMeta.Name, componentName,
+					value.YNode().Value)
+				return nil
+			})
+		})
+		if err != nil {
+			return changed, matched, err
+		}
+	}
+	return changed, matched, nil
+}
+
+// newScalarRNode returns a new Scalar *RNode with the tag which value resolves to,
+// e.g. an integer for `3`, so that the value isn't quoted when it is written.
+func newScalarRNode(value string) *yaml.RNode {
+	n := yaml.NewScalarRNode(value)
+	var parsed yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &parsed); err == nil && len(parsed.Content) == 1 &&
+		parsed.Content[0].Kind == yaml.ScalarNode && parsed.Content[0].Value == value {
+		n.YNode().Tag = parsed.Content[0].Tag
+	}
+	return n
+}
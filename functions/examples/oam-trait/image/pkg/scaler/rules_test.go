@@ -0,0 +1,408 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/functions/examples/oam-trait/pkg/scaler"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestInjectRules(t *testing.T) {
+	appConfig := func(replicas, minReplicas string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  labels:
+    replicas: "4"
+  annotations:
+    scaler: "3"
+    oam.dev/scaler: "5"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: ` + replicas + `
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: HorizontalPodAutoscalerTrait
+        metadata:
+          name: example-appconfig-hpa
+        spec:
+          minReplicas: ` + minReplicas + `
+`
+	}
+	scalerRule := scaler.Rule{
+		TraitAPIVersion: "core.oam.dev/v1alpha2",
+		TraitKind:       "ManualScalerTrait",
+		TargetPath:      "spec.replicaCount",
+		Source:          scaler.RuleSource{Annotation: "scaler"},
+	}
+	hpaRule := scaler.Rule{
+		TraitKind:  "HorizontalPodAutoscalerTrait",
+		TargetPath: "spec.minReplicas",
+		Source:     scaler.RuleSource{Literal: "2"},
+	}
+	setResult := func(field, kind, name, value string) scaler.Result {
+		return scaler.Result{Severity: scaler.SeverityInfo,
+			Message: "set " + field + " of " + kind + " " + name +
+				" in component example-component to " + value}
+	}
+
+	tests := []struct {
+		name            string
+		rules           []scaler.Rule
+		opts            scaler.Options
+		input           string
+		expected        string
+		expectedChanged int
+		expectedMatched []bool
+		expectedResults []scaler.Result
+		expectedErr     string
+	}{
+		{
+			// the scaler is reproduced by a single rule
+			name:            "annotation",
+			rules:           []scaler.Rule{scalerRule},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("3", "1"),
+			expectedChanged: 1,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{setResult("spec.replicaCount",
+				"ManualScalerTrait", "example-appconfig-trait", "3")},
+		},
+		{
+			name:            "annotation-prefix",
+			rules:           []scaler.Rule{scalerRule},
+			opts:            scaler.Options{AnnotationPrefix: "oam.dev/"},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("5", "1"),
+			expectedChanged: 1,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{setResult("spec.replicaCount",
+				"ManualScalerTrait", "example-appconfig-trait", "5")},
+		},
+		{
+			name: "field-ref",
+			rules: []scaler.Rule{{TraitKind: "ManualScalerTrait", TargetPath: "spec.replicaCount",
+				Source: scaler.RuleSource{FieldRef: "metadata.labels.replicas"}}},
+			input:           appConfig("1", "1"),
+			expected:        appConfig(`"4"`, "1"),
+			expectedChanged: 1,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{setResult("spec.replicaCount",
+				"ManualScalerTrait", "example-appconfig-trait", "4")},
+		},
+		{
+			// the JSON pointer is the same path as spec.replicaCount
+			name: "json-pointer",
+			rules: []scaler.Rule{{TraitKind: "ManualScalerTrait", TargetPath: "/spec/replicaCount",
+				Source: scaler.RuleSource{Annotation: "scaler"}}},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("3", "1"),
+			expectedChanged: 1,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{setResult("/spec/replicaCount",
+				"ManualScalerTrait", "example-appconfig-trait", "3")},
+		},
+		{
+			name: "json-pointer-create-field",
+			rules: []scaler.Rule{scaler.TraitField{Kind: "HorizontalPodAutoscalerTrait",
+				FieldPath: "/metadata/labels/app.kubernetes.io~1tier", Value: "web"}.Rule()},
+			input: appConfig("1", "1"),
+			expected: strings.Replace(appConfig("1", "1"),
+				"name: example-appconfig-hpa\n", "name: example-appconfig-hpa\n"+
+					"          labels:\n            app.kubernetes.io/tier: web\n", 1),
+			expectedChanged: 1,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{setResult("/metadata/labels/app.kubernetes.io~1tier",
+				"HorizontalPodAutoscalerTrait", "example-appconfig-hpa", "web")},
+		},
+		{
+			// the rules aren't applied to a skipped ApplicationConfiguration
+			name:  "skip-annotation",
+			rules: []scaler.Rule{scalerRule},
+			input: strings.Replace(appConfig("1", "1"), "    scaler: \"3\"\n",
+				"    scaler: \"3\"\n    oam.dev/skip-scaler: \"true\"\n", 1),
+			expected: strings.Replace(appConfig("1", "1"), "    scaler: \"3\"\n",
+				"    scaler: \"3\"\n    oam.dev/skip-scaler: \"true\"\n", 1),
+			expectedMatched: []bool{false},
+		},
+		{
+			name:            "multiple-rules",
+			rules:           []scaler.Rule{scalerRule, hpaRule},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("3", "2"),
+			expectedChanged: 2,
+			expectedMatched: []bool{true, true},
+			expectedResults: []scaler.Result{
+				setResult("spec.replicaCount", "ManualScalerTrait", "example-appconfig-trait", "3"),
+				setResult("spec.minReplicas", "HorizontalPodAutoscalerTrait",
+					"example-appconfig-hpa", "2"),
+			},
+		},
+		{
+			// the rules are applied in order, so the last rule for a field wins
+			name: "rules-order",
+			rules: []scaler.Rule{scalerRule, {TraitKind: "ManualScalerTrait",
+				TargetPath: "spec.replicaCount", Source: scaler.RuleSource{Literal: "6"}}},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("6", "1"),
+			expectedChanged: 2,
+			expectedMatched: []bool{true, true},
+			expectedResults: []scaler.Result{
+				setResult("spec.replicaCount", "ManualScalerTrait", "example-appconfig-trait", "3"),
+				setResult("spec.replicaCount", "ManualScalerTrait", "example-appconfig-trait", "6"),
+			},
+		},
+		{
+			name: "create-field",
+			rules: []scaler.Rule{{TraitKind: "ManualScalerTrait", TargetPath: "spec.tier.name",
+				Source: scaler.RuleSource{Literal: "web"}}},
+			input: appConfig("1", "1"),
+			expected: appConfig(`1
+          tier:
+            name: web`, "1"),
+			expectedChanged: 1,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{setResult("spec.tier.name",
+				"ManualScalerTrait", "example-appconfig-trait", "web")},
+		},
+		{
+			// the wildcard kind sets the field of every trait
+			name: "wildcard-kind",
+			rules: []scaler.Rule{scaler.TraitField{APIVersion: "core.oam.dev/v1alpha2",
+				Kind: "*", FieldPath: "metadata.labels.tier", Value: "web"}.Rule()},
+			input: appConfig("1", "1"),
+			expected: strings.Replace(strings.Replace(appConfig("1", "1"),
+				"name: example-appconfig-trait\n", "name: example-appconfig-trait\n"+
+					"          labels:\n            tier: web\n", 1),
+				"name: example-appconfig-hpa\n", "name: example-appconfig-hpa\n"+
+					"          labels:\n            tier: web\n", 1),
+			expectedChanged: 2,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{
+				setResult("metadata.labels.tier", "ManualScalerTrait", "example-appconfig-trait", "web"),
+				setResult("metadata.labels.tier", "HorizontalPodAutoscalerTrait",
+					"example-appconfig-hpa", "web"),
+			},
+		},
+		{
+			name: "wildcard-api-version",
+			rules: []scaler.Rule{scaler.TraitField{APIVersion: "*",
+				Kind: "HorizontalPodAutoscalerTrait", FieldPath: "spec.minReplicas",
+				Value: "2"}.Rule()},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("1", "2"),
+			expectedChanged: 1,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{setResult("spec.minReplicas",
+				"HorizontalPodAutoscalerTrait", "example-appconfig-hpa", "2")},
+		},
+		{
+			name: "wildcard-other-api-version",
+			rules: []scaler.Rule{scaler.TraitField{APIVersion: "core.oam.dev/v1beta1",
+				Kind: "*", FieldPath: "spec.minReplicas", Value: "2"}.Rule()},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("1", "1"),
+			expectedMatched: []bool{false},
+		},
+		{
+			name:            "unchanged",
+			rules:           []scaler.Rule{scalerRule},
+			input:           appConfig("3", "1"),
+			expected:        appConfig("3", "1"),
+			expectedMatched: []bool{true},
+		},
+		{
+			name:            "dry-run",
+			rules:           []scaler.Rule{scalerRule},
+			opts:            scaler.Options{DryRun: true},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("1", "1"),
+			expectedChanged: 1,
+			expectedMatched: []bool{true},
+			expectedResults: []scaler.Result{{Severity: scaler.SeverityInfo,
+				Message: "would set spec.replicaCount of ManualScalerTrait " +
+					"example-appconfig-trait in component example-component to 3"}},
+		},
+		{
+			name: "no-match",
+			rules: []scaler.Rule{{TraitKind: "ManualScaleTrait", TargetPath: "spec.replicaCount",
+				Source: scaler.RuleSource{Literal: "3"}}},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("1", "1"),
+			expectedMatched: []bool{false},
+		},
+		{
+			name: "no-value",
+			rules: []scaler.Rule{{TraitKind: "ManualScalerTrait", TargetPath: "spec.replicaCount",
+				Source: scaler.RuleSource{Annotation: "other"}}},
+			input:           appConfig("1", "1"),
+			expected:        appConfig("1", "1"),
+			expectedMatched: []bool{false},
+		},
+		{
+			name: "missing-source",
+			rules: []scaler.Rule{scalerRule, {TraitKind: "ManualScalerTrait",
+				TargetPath: "spec.replicaCount"}},
+			input:       appConfig("1", "1"),
+			expectedErr: "rule 2 source must set exactly one of annotation, literal or fieldRef",
+		},
+		{
+			name: "invalid-target-path",
+			rules: []scaler.Rule{{TraitKind: "ManualScalerTrait", TargetPath: "spec..replicaCount",
+				Source: scaler.RuleSource{Literal: "3"}}},
+			input:       appConfig("1", "1"),
+			expectedErr: `rule 1 targetPath "spec..replicaCount" must be a path of field names separated by .`,
+		},
+		{
+			name: "invalid-json-pointer",
+			rules: []scaler.Rule{{TraitKind: "ManualScalerTrait", TargetPath: "/spec/replicaCount/",
+				Source: scaler.RuleSource{Literal: "3"}}},
+			input:       appConfig("1", "1"),
+			expectedErr: `rule 1 targetPath JSON pointer "/spec/replicaCount/" has an empty field name`,
+		},
+		{
+			name: "field-ref-not-scalar",
+			rules: []scaler.Rule{{TraitKind: "ManualScalerTrait", TargetPath: "spec.replicaCount",
+				Source: scaler.RuleSource{FieldRef: "metadata.labels"}}},
+			input:       appConfig("1", "1"),
+			expectedErr: "rule 1 fieldRef metadata.labels must be a scalar",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			r := yaml.MustParse(test.input)
+			var results []scaler.Result
+			test.opts.Report = func(r scaler.Result) {
+				// ignore the components and traits which are visited
+				if r.Severity != scaler.SeverityDebug {
+					results = append(results, r)
+				}
+			}
+
+			changed, matched, err := scaler.InjectRules(r, test.rules, test.opts)
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %s\nbut got %v\n", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.MustString() != test.expected {
+				t.Fatalf("expected %s\nbut got %s\n", test.expected, r.MustString())
+			}
+			if changed != test.expectedChanged {
+				t.Fatalf("expected %d changed\nbut got %d\n", test.expectedChanged, changed)
+			}
+			if !reflect.DeepEqual(matched, test.expectedMatched) {
+				t.Fatalf("expected matched %v\nbut got %v\n", test.expectedMatched, matched)
+			}
+			if !reflect.DeepEqual(results, test.expectedResults) {
+				t.Fatalf("expected results %v\nbut got %v\n", test.expectedResults, results)
+			}
+		})
+	}
+}
+
+func TestJSONPointerPath(t *testing.T) {
+	tests := []struct {
+		pointer     string
+		expected    []string
+		expectedErr string
+	}{
+		{pointer: "/spec/replicaCount", expected: []string{"spec", "replicaCount"}},
+		{pointer: "/spec", expected: []string{"spec"}},
+		{pointer: "/metadata/annotations/oam.dev~1scaler",
+			expected: []string{"metadata", "annotations", "oam.dev/scaler"}},
+		{pointer: "/a~0b/~01", expected: []string{"a~b", "~1"}},
+		{pointer: "spec/replicaCount",
+			expectedErr: `"spec/replicaCount" must be a JSON pointer to a field, e.g. /spec/replicaCount`},
+		{pointer: "/",
+			expectedErr: `"/" must be a JSON pointer to a field, e.g. /spec/replicaCount`},
+		{pointer: "/spec//replicaCount",
+			expectedErr: `JSON pointer "/spec//replicaCount" has an empty field name`},
+		{pointer: "/spec/replica~2Count", expectedErr: `JSON pointer "/spec/replica~2Count" ` +
+			`has an invalid escape in "replica~2Count", ~ must be followed by 0 or 1`},
+	}
+	for _, test := range tests {
+		path, err := scaler.JSONPointerPath(test.pointer)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Fatalf("%s: expected error %s\nbut got %v\n", test.pointer, test.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.pointer, err)
+		}
+		if !reflect.DeepEqual(path, test.expected) {
+			t.Fatalf("%s: expected %v\nbut got %v\n", test.pointer, test.expected, path)
+		}
+	}
+}
+
+func TestScalerFilter_rules(t *testing.T) {
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`
+	nodes, err := (&kio.ByteReader{Reader: bytes.NewBufferString(input),
+		OmitReaderAnnotations: true}).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results bytes.Buffer
+	f := scaler.ScalerFilter{Results: &results, Rules: []scaler.Rule{
+		{TraitKind: "ManualScalerTrait", TargetPath: "spec.replicaCount",
+			Source: scaler.RuleSource{Literal: "3"}},
+		{TraitAPIVersion: "core.oam.dev/v1alpha2", TraitKind: "ManualScaleTrait",
+			TargetPath: "spec.replicaCount", Source: scaler.RuleSource{Literal: "3"}},
+	}}
+	nodes, err = f.Filter(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the rule which doesn't match any trait is reported rather than ignored
+	expected := "[info] ApplicationConfiguration /example-appconfig: " +
+		"set spec.replicaCount of ManualScalerTrait example-appconfig-trait in " +
+		"component example-component to 3\n" +
+		"[warning] rule 2 (core.oam.dev/v1alpha2 ManualScaleTrait spec.replicaCount) " +
+		"didn't match any trait\n"
+	if results.String() != expected {
+		t.Fatalf("expected results %s\nbut got %s\n", expected, results.String())
+	}
+	if replicas := nodes[0].MustString(); !strings.Contains(replicas, "replicaCount: 3") {
+		t.Fatalf("expected replicaCount: 3\nbut got %s\n", replicas)
+	}
+}
@@ -0,0 +1,706 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package scaler implements a kio.Filter which injects the replicas of
+// OAM traits from an annotation on their ApplicationConfiguration.
+package scaler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DefaultAnnotationKey is the annotation read for the replicas if the
+// ScalerFilter doesn't specify one
+const DefaultAnnotationKey = "scaler"
+
+// componentAnnotationDomain joins the AnnotationKey and the component name of the
+// annotations read for the replicas of a single component, e.g.
+// `scaler.oam.dev/example-component`
+const componentAnnotationDomain = ".oam.dev/"
+
+// removeReplicas is the annotation or component field value which removes the
+// replicas field from the traits, so that they use their own default
+const removeReplicas = "-"
+
+// applicationConfigurationKind is the kind of the Resources containing the components
+const applicationConfigurationKind = "ApplicationConfiguration"
+
+// SkipAnnotation is the annotation of the ApplicationConfigurations and of the
+// Component Resources which aren't injected if it is true, e.g.
+// `oam.dev/skip-scaler: "true"`.  It isn't prefixed with the AnnotationPrefix.
+const SkipAnnotation = "oam.dev/skip-scaler"
+
+// Result severities
+const (
+	SeverityDebug   = "debug"
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+)
+
+// Log levels which select the results which are written
+const (
+	// LogLevelDebug writes every component and trait which is visited
+	LogLevelDebug = "debug"
+	// LogLevelInfo writes the replicas which are changed
+	LogLevelInfo = "info"
+	// LogLevelWarn only writes warnings
+	LogLevelWarn = "warn"
+)
+
+// logLevels are the lowest severity written for each log level
+var logLevels = map[string]int{LogLevelDebug: 0, LogLevelInfo: 1, LogLevelWarn: 2}
+
+// severityLevels rank the severities against logLevels
+var severityLevels = map[string]int{SeverityDebug: 0, SeverityInfo: 1, SeverityWarning: 2}
+
+// ValidateLogLevel returns an error if level isn't one of the log levels.
+func ValidateLogLevel(level string) error {
+	if _, found := logLevels[level]; !found {
+		return fmt.Errorf("log level must be one of %s, %s or %s, got %q",
+			LogLevelDebug, LogLevelInfo, LogLevelWarn, level)
+	}
+	return nil
+}
+
+// ScalerFilter implements kio.Filter, and injects the replicas into the traits
+// of Resources containing the AnnotationKey annotation using Inject.  The results
+// of Inject are written to Results, with whether each ApplicationConfiguration
+// changed.
+type ScalerFilter struct {
+	// AnnotationKey is the annotation read for the replicas.
+	// Defaults to DefaultAnnotationKey if unset.
+	AnnotationKey string
+
+	// AnnotationPrefix if set is prefixed to the AnnotationKey of the
+	// annotation, e.g. `oam.dev/`, so that the annotations of several
+	// functions don't collide.  The component field isn't prefixed.
+	AnnotationPrefix string
+
+	// DryRun if set will report the replicas which would be set
+	// without setting them.
+	DryRun bool
+
+	// DefaultReplicas if set are the replicas injected into the components of
+	// ApplicationConfigurations without the AnnotationKey annotation.
+	// Such ApplicationConfigurations are skipped if unset.
+	DefaultReplicas *int
+
+	// Values if set map component names to their replicas, which win over the
+	// component field and the annotations.  See Options.Values.
+	Values map[string]string
+
+	// MinReplicas if set is the minimum replicas which are set.  Smaller
+	// replicas are clamped to MinReplicas, and a warning is reported.
+	MinReplicas *int
+
+	// MaxReplicas if set is the maximum replicas which are set.  Larger
+	// replicas are clamped to MaxReplicas, and a warning is reported.
+	MaxReplicas *int
+
+	// Results is where results are written, so that the output only
+	// contains the Resources.  Results are discarded if nil.
+	Results io.Writer
+
+	// Logger if set writes the results instead of Results, at its own Level
+	// rather than LogLevel.  The warnings are also recorded in the Results of
+	// the Logger, e.g. in the ResourceList written by the function, so that
+	// they are reported when the function is chained with other functions.
+	Logger *framework.Logger
+
+	// Summary if set is where a summary line of the Resources counts is written
+	// after the Resources are injected, e.g. `scanned=10 changed=3 skipped=7`, so
+	// that it may be written to stderr for CI logs.  The changed Resources are the
+	// ones which would change if DryRun is set, and the skipped Resources are the
+	// unchanged ones.  The count of the failed Resources is appended if any failed.
+	Summary io.Writer
+
+	// Diff if set is where a unified diff of each changed Resource is written,
+	// e.g. to stderr for the reviewers of a change, so that the changed trait
+	// specs are shown before and after in their context.  Nothing is changed
+	// if DryRun is set, so that no diff is written.
+	Diff io.Writer
+
+	// LogLevel is the lowest level of the results which are written.
+	// Defaults to LogLevelInfo if unset.
+	LogLevel string
+
+	// Selector if set is a label selector, e.g. `app=frontend,tier!=cache`, and
+	// only the components whose workload labels match it are injected.  The
+	// workload is embedded in the component's `workload` field, or is the
+	// `spec.workload` of the Component Resource with the componentName.
+	Selector string
+
+	// Namespaces if set are the namespaces of the Resources which are injected,
+	// and the Resources in other namespaces -- or without a namespace -- are left
+	// unchanged, e.g. to only touch the ApplicationConfigurations of some tenants.
+	// All Resources are injected if it is empty.
+	Namespaces []string
+
+	// FailOnNoMatch if set fails the Resources with the annotation which don't
+	// have any traits with replicas, e.g. because the kind of the trait is
+	// misspelled.  Such Resources are skipped if unset.
+	FailOnNoMatch bool
+
+	// CreateIfMissing if set adds a ManualScalerTrait with the replicas to the
+	// scaled components which don't have any traits with replicas.
+	CreateIfMissing bool
+
+	// TraitAPIVersions if set are the apiVersions of the traits with replicas
+	// which are injected, e.g. `core.oam.dev/v1beta1` to only inject the newer
+	// traits.  Defaults to DefaultTraitAPIVersions.
+	TraitAPIVersions []string
+
+	// InjectWorkloads if set injects the replicas into the `spec.replicas` of the
+	// Deployments -- and other workloads with replicas -- embedded in the
+	// components, instead of into their traits.  See Options.InjectWorkloads.
+	InjectWorkloads bool
+
+	// Concurrency is the number of Resources which are injected in parallel.
+	// The Resources are injected one at a time if it is less than 2.  The
+	// output and the results are in the order of the input whatever it is.
+	Concurrency int
+
+	// Rules if set are applied in order by InjectRules instead of injecting the
+	// replicas with Inject, and a warning is reported for each rule which doesn't
+	// match any trait in the input.
+	Rules []Rule
+}
+
+// NewScalerFilter returns a ScalerFilter reading the replicas from the
+// annotationKey annotation, or from DefaultAnnotationKey if it is empty.
+func NewScalerFilter(annotationKey string) *ScalerFilter {
+	if annotationKey == "" {
+		annotationKey = DefaultAnnotationKey
+	}
+	return &ScalerFilter{AnnotationKey: annotationKey}
+}
+
+// Filter injects the replicas into the traits of Resources
+// containing the configured annotation.
+// The Resources in aren't modified: the changed Resources are returned as
+// injected copies, and the others are returned as is.
+// All Resources are injected even if some of them fail, and the errors
+// of the failed Resources are returned together.
+func (f ScalerFilter) Filter(in []*yaml.RNode) ([]*yaml.RNode, error) {
+	if f.LogLevel != "" {
+		if err := ValidateLogLevel(f.LogLevel); err != nil {
+			return nil, err
+		}
+	}
+	opts := f.options()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateRules(f.Rules); err != nil {
+		return nil, err
+	}
+
+	var items []*yaml.RNode
+	for _, r := range in {
+		i, err := listItems(r)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i...)
+	}
+	// the workloads of the Components are matched against the selector
+	for _, item := range items {
+		if meta, err := item.GetMeta(); err == nil && meta.Kind == componentKind {
+			opts.Components = append(opts.Components, item)
+		}
+	}
+	// an invalid skip annotation is reported once rather than for each Resource
+	if _, err := getSkippedComponents(opts.Components); err != nil {
+		return nil, err
+	}
+
+	// the items of Lists are replaced in copies of the Lists, and the other
+	// Resources are replaced in the output
+	out := append([]*yaml.RNode(nil), in...)
+	var targets []*yaml.RNode
+	outIndexes := map[int]int{}
+	for i, r := range in {
+		if !isList(r) {
+			outIndexes[len(targets)] = i
+			targets = append(targets, r)
+			continue
+		}
+		out[i] = r.Copy()
+		listItems, err := listItems(out[i])
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, listItems...)
+	}
+
+	// inject the replicas into each Resource, and report the results in the order
+	// of the Resources whatever the Concurrency
+	var errs resourceErrors
+	var changedResources int
+	rulesMatched := make([]bool, len(f.Rules))
+	for i, inj := range f.injectAll(targets, opts) {
+		for _, r := range inj.results {
+			f.report(r.Severity, inj.meta, "%s", r.Message)
+		}
+		if inj.err != nil {
+			errs = append(errs, fmt.Errorf("%s %s/%s: %v",
+				inj.meta.Kind, inj.meta.Namespace, inj.meta.Name, inj.err))
+			continue
+		}
+		for j := range inj.matched {
+			rulesMatched[j] = rulesMatched[j] || inj.matched[j]
+		}
+		if !inj.changed {
+			continue
+		}
+		changedResources++
+		if err := f.writeDiff(targets[i], inj); err != nil {
+			return nil, err
+		}
+		if j, found := outIndexes[i]; found {
+			out[j] = inj.injected
+		} else {
+			targets[i].SetYNode(inj.injected.YNode())
+		}
+	}
+	for i := range f.Rules {
+		if !rulesMatched[i] {
+			f.report(SeverityWarning, yaml.ResourceMeta{}, "rule %d (%s) didn't match any trait",
+				i+1, f.Rules[i])
+		}
+	}
+	if f.Summary != nil {
+		summary := fmt.Sprintf("scanned=%d changed=%d skipped=%d", len(targets),
+			changedResources, len(targets)-changedResources-len(errs))
+		if len(errs) > 0 {
+			summary += fmt.Sprintf(" failed=%d", len(errs))
+		}
+		fmt.Fprintln(f.Summary, summary)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return out, nil
+}
+
+// injection is the result of injecting a Resource.
+type injection struct {
+	// meta is the metadata of the Resource
+	meta yaml.ResourceMeta
+
+	// injected is the injected copy of the Resource, and changed is set if it
+	// was changed
+	injected *yaml.RNode
+	changed  bool
+
+	// matched are set for the Rules which matched a trait of the Resource
+	matched []bool
+
+	// results are reported by the injection, in order
+	results []Result
+
+	err error
+}
+
+// injectAll injects each of the items, with up to Concurrency items injected in
+// parallel, and returns the injections in the order of the items.
+func (f ScalerFilter) injectAll(items []*yaml.RNode, opts Options) []injection {
+	injections := make([]injection, len(items))
+	if f.Concurrency <= 1 {
+		for i := range items {
+			injections[i] = f.inject(items[i], opts)
+		}
+		return injections
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < f.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// each item is injected into its own copy, so the workers don't share
+			// any nodes which are modified
+			for i := range indexes {
+				injections[i] = f.inject(items[i], opts)
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return injections
+}
+
+// inject injects a copy of item with the Rules if they are set, and otherwise with
+// Inject, and returns the copy with whether it was changed -- or would be changed,
+// if DryRun is set.  item isn't modified, and is returned if it wasn't changed.
+// The results are recorded in the injection rather than reported, so that items
+// may be injected in parallel.
+func (f ScalerFilter) inject(item *yaml.RNode, opts Options) injection {
+	inj := injection{injected: item}
+	inj.meta, _ = item.GetMeta()
+	opts.Report = func(r Result) { inj.results = append(inj.results, r) }
+	if !f.allowsNamespace(inj.meta.Namespace) {
+		opts.report(SeverityDebug, "skipping a Resource in namespace %q which isn't one of %s",
+			inj.meta.Namespace, strings.Join(f.Namespaces, ","))
+		return inj
+	}
+	inj.injected = item.Copy()
+
+	var changed int
+	if len(f.Rules) == 0 {
+		changed, inj.err = Inject(inj.injected, opts)
+	} else {
+		changed, inj.matched, inj.err = InjectRules(inj.injected, f.Rules, opts)
+	}
+	if inj.err != nil {
+		return inj
+	}
+
+	// translate the change into a result for the ApplicationConfiguration
+	if inj.meta.Kind == applicationConfigurationKind {
+		switch {
+		case changed > 0 && f.DryRun:
+			opts.report(SeverityDebug, "would change %d traits", changed)
+		case changed > 0:
+			opts.report(SeverityDebug, "changed %d traits", changed)
+		default:
+			opts.report(SeverityDebug, "unchanged")
+		}
+	}
+	inj.changed = changed > 0
+	if !inj.changed {
+		inj.injected = item
+	}
+	return inj
+}
+
+// writeDiff writes a unified diff of item and its injected copy to Diff, if it
+// is set and they differ.
+func (f ScalerFilter) writeDiff(item *yaml.RNode, inj injection) error {
+	if f.Diff == nil {
+		return nil
+	}
+	before, err := item.String()
+	if err != nil {
+		return err
+	}
+	after, err := inj.injected.String()
+	if err != nil || before == after {
+		return err
+	}
+	name := fmt.Sprintf("%s %s/%s", inj.meta.Kind, inj.meta.Namespace, inj.meta.Name)
+	return difflib.WriteUnifiedDiff(f.Diff, difflib.UnifiedDiff{
+		A:        splitLines(before),
+		B:        splitLines(after),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+}
+
+// splitLines splits the yaml s into lines which end with a newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// allowsNamespace returns true if the Resources in namespace are injected.
+func (f ScalerFilter) allowsNamespace(namespace string) bool {
+	if len(f.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range f.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// options returns the Options to Inject the Resources with.
+func (f ScalerFilter) options() Options {
+	return Options{
+		AnnotationKey:    f.AnnotationKey,
+		AnnotationPrefix: f.AnnotationPrefix,
+		DryRun:           f.DryRun,
+		DefaultReplicas:  f.DefaultReplicas,
+		Values:           f.Values,
+		MinReplicas:      f.MinReplicas,
+		MaxReplicas:      f.MaxReplicas,
+		Selector:         f.Selector,
+		FailOnNoMatch:    f.FailOnNoMatch,
+		CreateIfMissing:  f.CreateIfMissing,
+		TraitAPIVersions: f.TraitAPIVersions,
+		InjectWorkloads:  f.InjectWorkloads,
+	}
+}
+
+// isList returns true if r is a List, e.g. from `kubectl get -o yaml`.
+func isList(r *yaml.RNode) bool {
+	kind, err := r.Pipe(yaml.Get("kind"))
+	return err == nil && yaml.GetValue(kind) == "List"
+}
+
+// listItems returns the items of r if it is a List, and otherwise returns r.
+func listItems(r *yaml.RNode) ([]*yaml.RNode, error) {
+	kind, err := r.Pipe(yaml.Get("kind"))
+	if err != nil {
+		return nil, err
+	}
+	if yaml.GetValue(kind) != "List" {
+		return []*yaml.RNode{r}, nil
+	}
+	items, err := r.Pipe(yaml.Lookup("items"))
+	if err != nil || items == nil {
+		return nil, err
+	}
+	return items.Elements()
+}
+
+// resourceErrors contains the errors of each Resource which failed.
+type resourceErrors []error
+
+func (e resourceErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := []string{fmt.Sprintf("%d Resources failed:", len(e))}
+	for i := range e {
+		msgs = append(msgs, e[i].Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// report writes a result for the Resource identified by meta, if its
+// severity is at least the LogLevel.  Results which aren't about a Resource
+// have an empty meta.
+func (f ScalerFilter) report(severity string, meta yaml.ResourceMeta, msg string, args ...interface{}) {
+	if f.Logger != nil {
+		f.log(severity, meta, fmt.Sprintf(msg, args...))
+		return
+	}
+	if f.Results == nil {
+		return
+	}
+	level := f.LogLevel
+	if level == "" {
+		level = LogLevelInfo
+	}
+	if severityLevels[severity] < logLevels[level] {
+		return
+	}
+	if meta.Kind == "" {
+		fmt.Fprintf(f.Results, "[%s] %s\n", severity, fmt.Sprintf(msg, args...))
+		return
+	}
+	fmt.Fprintf(f.Results, "[%s] %s %s/%s: %s\n", severity, meta.Kind,
+		meta.Namespace, meta.Name, fmt.Sprintf(msg, args...))
+}
+
+// log writes a result for the Resource identified by meta to the Logger.  The
+// warnings are recorded as kio.Results, and the other results are only logged.
+func (f ScalerFilter) log(severity string, meta yaml.ResourceMeta, msg string) {
+	if severity == SeverityWarning {
+		f.Logger.Result(kio.Result{Severity: kio.SeverityWarning, Message: msg,
+			ResourceRef: yaml.ResourceIdentifier{
+				APIVersion: meta.APIVersion, Kind: meta.Kind,
+				Namespace: meta.Namespace, Name: meta.Name}})
+		return
+	}
+	if meta.Kind == "" {
+		f.Logger.Logf(severity, "%s", msg)
+		return
+	}
+	f.Logger.Logf(severity, "%s %s/%s: %s", meta.Kind, meta.Namespace, meta.Name, msg)
+}
+
+// visitComponents calls fn with the name and node of each component.
+// components is either a sequence of components with a componentName field,
+// or a mapping of component names to components.
+func visitComponents(components *yaml.RNode, fn func(componentName string, node *yaml.RNode) error) error {
+	if components.YNode().Kind == yaml.MappingNode {
+		return components.VisitFields(func(node *yaml.MapNode) error {
+			return fn(yaml.GetValue(node.Key), node.Value)
+		})
+	}
+	return components.VisitElements(func(node *yaml.RNode) error {
+		componentName, err := node.Pipe(yaml.Get("componentName"))
+		if err != nil {
+			return err
+		}
+		return fn(yaml.GetValue(componentName), node)
+	})
+}
+
+// traitSetter sets the replicas on a kind of trait.
+type traitSetter struct {
+	// field is the name of the field which is set, and is reported in the
+	// results.
+	field string
+
+	// path is the path of the field in the trait.
+	path []string
+
+	// isSet returns true if the trait already has the replicas, or doesn't
+	// have the field if the replicas are removeReplicas.
+	isSet func(trait *yaml.RNode, replicas string) (bool, error)
+
+	// set sets the replicas on the trait, which must have been detached.
+	set func(trait *yaml.RNode, replicas string) error
+
+	// clear removes the replicas field from the trait, which must have been
+	// detached.
+	clear func(trait *yaml.RNode) error
+}
+
+// DefaultTraitAPIVersions are the apiVersions of the traits with replicas
+// which are injected if the TraitAPIVersions aren't set.
+var DefaultTraitAPIVersions = []string{"core.oam.dev/v1alpha2", "core.oam.dev/v1beta1"}
+
+// traitSetters contains the setters for each kind of trait which has replicas.
+// The traits are only set if they have one of the TraitAPIVersions.
+var traitSetters = map[string]traitSetter{
+	"ManualScalerTrait": intFieldSetter(
+		[]string{"spec", "replicaCount"}, ""),
+	"HorizontalPodAutoscalerTrait": intFieldSetter(
+		[]string{"spec", "minReplicas"}, "maxReplicas"),
+}
+
+// intFieldSetter returns a traitSetter which sets the integer field at path.
+// Only the field itself is set, so that the other fields of its parent -- e.g.
+// the rest of the trait's spec -- are kept.  The comments on an existing field
+// are kept.  A missing field is created
+// before the related field if it is present, and is otherwise added as the
+// last field.
+func intFieldSetter(path []string, related string) traitSetter {
+	name := path[len(path)-1]
+	return traitSetter{
+		field: name,
+		path:  path,
+		isSet: func(trait *yaml.RNode, replicas string) (bool, error) {
+			field, err := lookup(trait, path...)
+			if err != nil {
+				return false, err
+			}
+			if field == nil {
+				// a missing field doesn't need to be removed
+				return replicas == removeReplicas, nil
+			}
+			// a quoted value is a string, and must be replaced by the integer
+			return field.YNode().Value == replicas &&
+				field.YNode().Tag == yaml.IntTag, nil
+		},
+		set: func(trait *yaml.RNode, replicas string) error {
+			// lookup the parent of the field, creating it if it doesn't exist
+			parent, err := trait.Pipe(yaml.LookupCreate(yaml.MappingNode, path[:len(path)-1]...))
+			if err != nil {
+				return err
+			}
+			value := newIntRNode(replicas).YNode()
+
+			if field := parent.Field(name); field != nil {
+				// replace the value, keeping its comments but not its style
+				// so that the replicas aren't quoted
+				existing := field.Value.YNode()
+				value.HeadComment = existing.HeadComment
+				value.LineComment = existing.LineComment
+				value.FootComment = existing.FootComment
+				field.Value.SetYNode(value)
+				return nil
+			}
+
+			// create the field before the related field, or as the last field
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+			content := parent.YNode().Content
+			i := len(content)
+			for j := 0; j < len(content); j = yaml.IncrementFieldIndex(j) {
+				if related != "" && content[j].Value == related {
+					i = j
+					break
+				}
+			}
+			parent.YNode().Content = append(content[:i],
+				append([]*yaml.Node{key, value}, content[i:]...)...)
+			return nil
+		},
+		clear: func(trait *yaml.RNode) error {
+			parent, err := trait.Pipe(yaml.Lookup(path[:len(path)-1]...))
+			if err != nil || parent == nil {
+				return err
+			}
+			_, err = parent.Pipe(yaml.Clear(name))
+			return err
+		},
+	}
+}
+
+// expandReplicas returns the value of the environment variable referenced by value
+// if it is `$NAME` or `${NAME}`, and otherwise returns value, e.g. literal replicas.
+func expandReplicas(value string) (string, error) {
+	var name string
+	switch {
+	case strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}"):
+		name = value[2 : len(value)-1]
+	case strings.HasPrefix(value, "$"):
+		name = value[1:]
+	default:
+		return value, nil
+	}
+	env, found := os.LookupEnv(name)
+	if !found {
+		return "", fmt.Errorf("references the environment variable %s, which isn't set", name)
+	}
+	return strings.TrimSpace(env), nil
+}
+
+// validateReplicas returns an error if value isn't a non-negative integer or
+// removeReplicas.
+func validateReplicas(value string) error {
+	if value == removeReplicas {
+		return nil
+	}
+	replicas, err := strconv.Atoi(value)
+	if err != nil || replicas < 0 {
+		return fmt.Errorf("must be a non-negative integer, got %q", value)
+	}
+	return nil
+}
+
+// isSkipped returns true if annotations have a true SkipAnnotation, e.g. `true`
+// or `1` as parsed by strconv.ParseBool.
+func isSkipped(annotations map[string]string) (bool, error) {
+	value, found := annotations[SkipAnnotation]
+	if !found {
+		return false, nil
+	}
+	skip, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("%s annotation must be true or false, got %q",
+			SkipAnnotation, value)
+	}
+	return skip, nil
+}
+
+// newIntRNode returns a new Scalar *RNode tagged as an integer.
+func newIntRNode(value string) *yaml.RNode {
+	n := yaml.NewScalarRNode(value)
+	n.YNode().Tag = yaml.IntTag
+	return n
+}
@@ -0,0 +1,2910 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/functions/examples/oam-trait/pkg/scaler"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestScalerFilter(t *testing.T) {
+	tests := []struct {
+		name            string
+		filter          *scaler.ScalerFilter
+		input           string
+		expected        string
+		expectedResults string
+		expectedErr     string
+	}{
+		{
+			name:   "component-without-traits",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: with-traits
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+  - componentName: without-traits
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: with-traits
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 3
+  - componentName: without-traits
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component with-traits to 3\n",
+		},
+		{
+			name:   "trait-kinds",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: HorizontalPodAutoscalerTrait
+        metadata:
+          name: example-appconfig-hpa
+        spec:
+          minReplicas: 1
+          maxReplicas: 10
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: IngressTrait
+        metadata:
+          name: example-appconfig-ingress
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 3
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: HorizontalPodAutoscalerTrait
+        metadata:
+          name: example-appconfig-hpa
+        spec:
+          minReplicas: 3
+          maxReplicas: 10
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: IngressTrait
+        metadata:
+          name: example-appconfig-ingress
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 3\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set minReplicas of HorizontalPodAutoscalerTrait example-appconfig-hpa in component example-component to 3\n",
+		},
+		{
+			name:   "comments",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        # spec comment
+        spec:
+          # replicaCount head comment
+          replicaCount: "1" # replicaCount line comment
+          # other head comment
+          other: value # other line comment
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: HorizontalPodAutoscalerTrait
+        metadata:
+          name: example-appconfig-hpa
+        spec:
+          # maxReplicas head comment
+          maxReplicas: 10 # maxReplicas line comment
+          targetCPUUtilizationPercentage: 50
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        # spec comment
+        spec:
+          # replicaCount head comment
+          replicaCount: 3 # replicaCount line comment
+          # other head comment
+          other: value # other line comment
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: HorizontalPodAutoscalerTrait
+        metadata:
+          name: example-appconfig-hpa
+        spec:
+          minReplicas: 3
+          # maxReplicas head comment
+          maxReplicas: 10 # maxReplicas line comment
+          targetCPUUtilizationPercentage: 50
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 3\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set minReplicas of HorizontalPodAutoscalerTrait example-appconfig-hpa in component example-component to 3\n",
+		},
+		{
+			name:   "integer-replica-count",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: quoted
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: "1"
+  - componentName: missing
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec: {}
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: quoted
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+  - componentName: missing
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec: {replicaCount: 3}
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait  in component quoted to 3\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait  in component missing to 3\n",
+		},
+		{
+			name:   "annotation-key",
+			filter: scaler.NewScalerFilter("oam.dev/replicas"),
+			input: strings.Replace(`apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: with-traits
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+  - componentName: without-traits
+`, "scaler:", "oam.dev/replicas:", 1),
+			expected: strings.Replace(`apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: with-traits
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 3
+  - componentName: without-traits
+`, "scaler:", "oam.dev/replicas:", 1),
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component with-traits to 3\n",
+		},
+		{
+			name:   "annotation-key-missing",
+			filter: scaler.NewScalerFilter("oam.dev/replicas"),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: with-traits
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+  - componentName: without-traits
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: with-traits
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+  - componentName: without-traits
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"component with-traits has traits with replicas but no oam.dev/replicas or replicas.oam.dev/with-traits annotation\n",
+		},
+		{
+			name:   "annotation-prefix",
+			filter: &scaler.ScalerFilter{AnnotationPrefix: "oam.dev/"},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+    oam.dev/scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+    oam.dev/scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 3\n",
+		},
+		{
+			name:   "annotation-prefix-unprefixed",
+			filter: &scaler.ScalerFilter{AnnotationPrefix: "oam.dev/"},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"component example-component has traits with replicas but no oam.dev/scaler or scaler.oam.dev/example-component annotation\n",
+		},
+		{
+			name:   "dry-run",
+			filter: &scaler.ScalerFilter{DryRun: true},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: with-traits
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+  - componentName: without-traits
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: with-traits
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+  - componentName: without-traits
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"would set replicaCount of ManualScalerTrait example-appconfig-trait in component with-traits to 3\n",
+		},
+		{
+			name:   "remove",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "-"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+          labels:
+            app: example
+        spec:
+          replicaCount: 3 # overridden
+          workloadRef:
+            kind: ContainerizedWorkload
+            name: example-workload
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-unset
+        spec:
+          workloadRef:
+            kind: ContainerizedWorkload
+            name: example-workload
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "-"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+          labels:
+            app: example
+        spec:
+          workloadRef:
+            kind: ContainerizedWorkload
+            name: example-workload
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-unset
+        spec:
+          workloadRef:
+            kind: ContainerizedWorkload
+            name: example-workload
+`,
+			// the trait without the field isn't reported
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"removed replicaCount of ManualScalerTrait example-appconfig-trait in component example-component\n",
+		},
+		{
+			name:   "remove-component-override",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    scaler: "-"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+          workloadRef:
+            name: example-workload
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    scaler: "-"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          workloadRef:
+            name: example-workload
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"removed replicaCount of ManualScalerTrait example-appconfig-trait in component example-component\n",
+		},
+		{
+			name:   "remove-dry-run",
+			filter: &scaler.ScalerFilter{DryRun: true},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "-"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "-"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"would remove replicaCount of ManualScalerTrait example-appconfig-trait in component example-component\n",
+		},
+		{
+			name:   "component-override",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    scaler: "5"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    scaler: "2"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+  - componentName: worker
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: worker-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    scaler: "5"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 5
+  - componentName: backend
+    scaler: "2"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 2
+  - componentName: worker
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: worker-trait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait frontend-trait in component frontend to 5\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait backend-trait in component backend to 2\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait worker-trait in component worker to 3\n",
+		},
+		{
+			name:   "component-override-without-annotation",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: frontend
+    scaler: "5"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: frontend
+    scaler: "5"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 5
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait frontend-trait in component frontend to 5\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"component backend has traits with replicas but no scaler or " +
+				"scaler.oam.dev/backend annotation\n",
+		},
+		{
+			name:   "invalid-component-override",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    scaler: "five"
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedErr: `ApplicationConfiguration /example-appconfig: ` +
+				`component frontend scaler field must be a non-negative integer, got "five"`,
+		},
+		{
+			name:   "fail-on-no-match",
+			filter: &scaler.ScalerFilter{FailOnNoMatch: true},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScallerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedErr: `ApplicationConfiguration /example-appconfig: ` +
+				`scaler annotation is set but no trait with replicas was found`,
+		},
+		{
+			name:   "component-mapping",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+    frontend:
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: frontend-trait
+          spec:
+            replicaCount: 1
+    backend:
+      scaler: "5"
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: backend-trait
+          spec:
+            replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+    frontend:
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: frontend-trait
+          spec:
+            replicaCount: 3
+    backend:
+      scaler: "5"
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: backend-trait
+          spec:
+            replicaCount: 5
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait frontend-trait in component frontend to 3\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait backend-trait in component backend to 5\n",
+		},
+		{
+			name:   "below-min",
+			filter: &scaler.ScalerFilter{MinReplicas: intPtr(2), MaxReplicas: intPtr(10)},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "1"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "1"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 2
+`,
+			expectedResults: "[warning] ApplicationConfiguration /example-appconfig: " +
+				"clamped replicas of component example-component from 1 to 2\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 2\n",
+		},
+		{
+			name:   "above-max",
+			filter: &scaler.ScalerFilter{MinReplicas: intPtr(2), MaxReplicas: intPtr(10)},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "500"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "500"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 10
+`,
+			expectedResults: "[warning] ApplicationConfiguration /example-appconfig: " +
+				"clamped replicas of component example-component from 500 to 10\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 10\n",
+		},
+		{
+			name:   "in-range",
+			filter: &scaler.ScalerFilter{MinReplicas: intPtr(2), MaxReplicas: intPtr(10)},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 5
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 5\n",
+		},
+		{
+			name:   "min-greater-than-max",
+			filter: &scaler.ScalerFilter{MinReplicas: intPtr(10), MaxReplicas: intPtr(2)},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expectedErr: "minReplicas 10 must not be greater than maxReplicas 2",
+		},
+		{
+			name:   "default-with-annotation",
+			filter: &scaler.ScalerFilter{DefaultReplicas: intPtr(2)},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "5"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 5
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 5\n",
+		},
+		{
+			name:   "default-without-annotation",
+			filter: &scaler.ScalerFilter{DefaultReplicas: intPtr(2)},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 2
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 2\n",
+		},
+		{
+			name:   "no-default-without-annotation",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"component example-component has traits with replicas but no scaler or scaler.oam.dev/example-component annotation\n",
+		},
+		{
+			name:   "negative-default",
+			filter: &scaler.ScalerFilter{DefaultReplicas: intPtr(-1)},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 0
+`,
+			expectedErr: "defaultReplicas must be a non-negative integer, got -1",
+		},
+		{
+			name:   "list",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: v1
+kind: List
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "3"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: example-appconfig-trait
+          spec:
+            replicaCount: 1
+`,
+			expected: `apiVersion: v1
+kind: List
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    annotations:
+      scaler: "3"
+  spec:
+    components:
+    - componentName: example-component
+      traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: ManualScalerTrait
+          metadata:
+            name: example-appconfig-trait
+          spec:
+            replicaCount: 3
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait example-appconfig-trait in component example-component to 3\n",
+		},
+		{
+			name:   "non-numeric",
+			filter: scaler.NewScalerFilter(""),
+			input: strings.Replace(`apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  namespace: example-namespace
+  annotations:
+    scaler: "%s"
+spec:
+  components: []
+`, "%s", "three", 1),
+			expectedErr: `ApplicationConfiguration example-namespace/example-appconfig: ` +
+				`scaler annotation must be a non-negative integer, got "three"`,
+		},
+		{
+			name:   "negative",
+			filter: scaler.NewScalerFilter(""),
+			input: strings.Replace(`apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  namespace: example-namespace
+  annotations:
+    scaler: "%s"
+spec:
+  components: []
+`, "%s", "-2", 1),
+			expectedErr: `ApplicationConfiguration example-namespace/example-appconfig: ` +
+				`scaler annotation must be a non-negative integer, got "-2"`,
+		},
+		{
+			name:   "empty",
+			filter: scaler.NewScalerFilter(""),
+			input: strings.Replace(`apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  namespace: example-namespace
+  annotations:
+    scaler: "%s"
+spec:
+  components: []
+`, "%s", "", 1),
+			expectedErr: `ApplicationConfiguration example-namespace/example-appconfig: ` +
+				`scaler annotation must be a non-negative integer, got ""`,
+		},
+		{
+			name:   "multiple-errors",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: first-appconfig
+  annotations:
+    scaler: "three"
+spec:
+  components: []
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: valid-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components: []
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: second-appconfig
+  annotations:
+    scaler: "-2"
+spec:
+  components: []
+`,
+			expectedErr: `2 Resources failed:
+ApplicationConfiguration /first-appconfig: scaler annotation must be a non-negative integer, got "three"
+ApplicationConfiguration /second-appconfig: scaler annotation must be a non-negative integer, got "-2"`,
+		},
+		{
+			// traits may be inlined without the trait field
+			name:   "wrapped-and-inlined-traits",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: wrapped-trait
+        spec:
+          replicaCount: 1
+    - apiVersion: core.oam.dev/v1alpha2
+      kind: ManualScalerTrait
+      metadata:
+        name: inlined-trait
+      spec:
+        replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: wrapped-trait
+        spec:
+          replicaCount: 3
+    - apiVersion: core.oam.dev/v1alpha2
+      kind: ManualScalerTrait
+      metadata:
+        name: inlined-trait
+      spec:
+        replicaCount: 3
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait wrapped-trait in component example-component to 3\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait inlined-trait in component example-component to 3\n",
+		},
+		{
+			name:   "trait-without-kind",
+			filter: &scaler.ScalerFilter{LogLevel: scaler.LogLevelDebug},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: wrapped-trait
+        spec:
+          replicaCount: 3
+    - apiVersion: core.oam.dev/v1alpha2
+      kind: ManualScalerTrait
+      metadata:
+        name: inlined-trait
+      spec:
+        replicaCount: 1
+    - {}
+    - name: not-a-trait
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: wrapped-trait
+        spec:
+          replicaCount: 3
+    - apiVersion: core.oam.dev/v1alpha2
+      kind: ManualScalerTrait
+      metadata:
+        name: inlined-trait
+      spec:
+        replicaCount: 3
+    - {}
+    - name: not-a-trait
+`,
+			expectedResults: `[debug] ApplicationConfiguration /example-appconfig: visiting component example-component
+[debug] ApplicationConfiguration /example-appconfig: visiting ManualScalerTrait wrapped-trait in component example-component
+[debug] ApplicationConfiguration /example-appconfig: visiting ManualScalerTrait inlined-trait in component example-component
+[info] ApplicationConfiguration /example-appconfig: set replicaCount of ManualScalerTrait inlined-trait in component example-component to 3
+[debug] ApplicationConfiguration /example-appconfig: skipping a trait without a kind in component example-component
+[debug] ApplicationConfiguration /example-appconfig: skipping a trait without a kind in component example-component
+[debug] ApplicationConfiguration /example-appconfig: changed 1 traits
+`,
+		},
+		{
+			// only the replicas field is set, and the other fields of the spec are kept
+			name:   "spec-fields-kept",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-trait
+        spec:
+          selector:
+            matchLabels:
+              app: example
+          replicaCount: 1 # the replicas
+          strategy:
+            type: RollingUpdate
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: HorizontalPodAutoscalerTrait
+        metadata:
+          name: example-hpa
+        spec:
+          targetCPUUtilizationPercentage: 80
+          maxReplicas: 10
+          scaleTargetRef:
+            kind: Deployment
+            name: example
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-trait
+        spec:
+          selector:
+            matchLabels:
+              app: example
+          replicaCount: 3 # the replicas
+          strategy:
+            type: RollingUpdate
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: HorizontalPodAutoscalerTrait
+        metadata:
+          name: example-hpa
+        spec:
+          targetCPUUtilizationPercentage: 80
+          minReplicas: 3
+          maxReplicas: 10
+          scaleTargetRef:
+            kind: Deployment
+            name: example
+`,
+			expectedResults: `[info] ApplicationConfiguration /example-appconfig: set replicaCount of ManualScalerTrait example-trait in component example-component to 3
+[info] ApplicationConfiguration /example-appconfig: set minReplicas of HorizontalPodAutoscalerTrait example-hpa in component example-component to 3
+`,
+		},
+		{
+			// every matching trait of the component is injected and counted
+			name:   "multiple-traits",
+			filter: &scaler.ScalerFilter{LogLevel: scaler.LogLevelDebug},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: first-trait
+        spec:
+          replicaCount: 1
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: second-trait
+        spec:
+          replicaCount: 2
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: third-trait
+        spec:
+          replicaCount: 3
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: first-trait
+        spec:
+          replicaCount: 3
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: second-trait
+        spec:
+          replicaCount: 3
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: third-trait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: `[debug] ApplicationConfiguration /example-appconfig: visiting component example-component
+[debug] ApplicationConfiguration /example-appconfig: visiting ManualScalerTrait first-trait in component example-component
+[info] ApplicationConfiguration /example-appconfig: set replicaCount of ManualScalerTrait first-trait in component example-component to 3
+[debug] ApplicationConfiguration /example-appconfig: visiting ManualScalerTrait second-trait in component example-component
+[info] ApplicationConfiguration /example-appconfig: set replicaCount of ManualScalerTrait second-trait in component example-component to 3
+[debug] ApplicationConfiguration /example-appconfig: visiting ManualScalerTrait third-trait in component example-component
+[debug] ApplicationConfiguration /example-appconfig: changed 2 traits
+`,
+		},
+		{
+			name:   "selector",
+			filter: &scaler.ScalerFilter{Selector: "app=frontend"},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    workload:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        labels:
+          app: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    workload:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        labels:
+          app: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    workload:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        labels:
+          app: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 3
+  - componentName: backend
+    workload:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        labels:
+          app: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait frontend-trait in component frontend to 3\n",
+		},
+		{
+			// the workloads are read from the Component Resources
+			name:   "selector-components",
+			filter: &scaler.ScalerFilter{Selector: "app!=frontend", LogLevel: scaler.LogLevelDebug},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: frontend
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      labels:
+        app: frontend
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: backend
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      labels:
+        app: backend
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: frontend
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      labels:
+        app: frontend
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: backend
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      labels:
+        app: backend
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: `[debug] ApplicationConfiguration /example-appconfig: visiting component frontend
+[debug] ApplicationConfiguration /example-appconfig: skipping component frontend which doesn't match the selector
+[debug] ApplicationConfiguration /example-appconfig: visiting component backend
+[debug] ApplicationConfiguration /example-appconfig: visiting ManualScalerTrait backend-trait in component backend
+[info] ApplicationConfiguration /example-appconfig: set replicaCount of ManualScalerTrait backend-trait in component backend to 3
+[debug] ApplicationConfiguration /example-appconfig: changed 1 traits
+`,
+		},
+		{
+			// components without a workload have no labels
+			name:   "selector-without-workload",
+			filter: &scaler.ScalerFilter{Selector: "app,!tier"},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    workload:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        labels:
+          app: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    workload:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        labels:
+          app: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 3
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait frontend-trait in component frontend to 3\n",
+		},
+		{
+			name:   "invalid-selector",
+			filter: &scaler.ScalerFilter{Selector: "app=frontend,"},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    workload:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        labels:
+          app: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedErr: `selector "app=frontend," invalid requirement ""`,
+		},
+		{
+			// only the Resources in the namespaces are injected, and the others --
+			// including the ones without a namespace -- are left unchanged
+			name: "namespaces",
+			filter: &scaler.ScalerFilter{Namespaces: []string{"tenant-a", "tenant-c"},
+				LogLevel: scaler.LogLevelDebug},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-a
+  namespace: tenant-a
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-b
+  namespace: tenant-b
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: cluster
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-a
+  namespace: tenant-a
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-b
+  namespace: tenant-b
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: cluster
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[debug] ApplicationConfiguration tenant-a/tenant-a: visiting component frontend\n" +
+				"[debug] ApplicationConfiguration tenant-a/tenant-a: " +
+				"visiting ManualScalerTrait  in component frontend\n" +
+				"[info] ApplicationConfiguration tenant-a/tenant-a: " +
+				"set replicaCount of ManualScalerTrait  in component frontend to 3\n" +
+				"[debug] ApplicationConfiguration tenant-a/tenant-a: changed 1 traits\n" +
+				"[debug] ApplicationConfiguration tenant-b/tenant-b: " +
+				"skipping a Resource in namespace \"tenant-b\" which isn't one of tenant-a,tenant-c\n" +
+				"[debug] ApplicationConfiguration /cluster: " +
+				"skipping a Resource in namespace \"\" which isn't one of tenant-a,tenant-c\n",
+		},
+		{
+			name:   "namespaces-empty",
+			filter: &scaler.ScalerFilter{Namespaces: []string{}},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-a
+  namespace: tenant-a
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-b
+  namespace: tenant-b
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: cluster
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-a
+  namespace: tenant-a
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: tenant-b
+  namespace: tenant-b
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: cluster
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: "[info] ApplicationConfiguration tenant-a/tenant-a: " +
+				"set replicaCount of ManualScalerTrait  in component frontend to 3\n" +
+				"[info] ApplicationConfiguration tenant-b/tenant-b: " +
+				"set replicaCount of ManualScalerTrait  in component frontend to 3\n" +
+				"[info] ApplicationConfiguration /cluster: " +
+				"set replicaCount of ManualScalerTrait  in component frontend to 3\n",
+		},
+		{
+			// the traits of both the v1alpha2 and the v1beta1 apiVersions are
+			// injected by default, and the traits of other apiVersions are skipped
+			name:   "trait-api-versions",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: alpha
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+  - componentName: beta
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1beta1
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+  - componentName: older
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha1
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: alpha
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+  - componentName: beta
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1beta1
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+  - componentName: older
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha1
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait  in component alpha to 3\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait  in component beta to 3\n",
+		},
+		{
+			// only the traits of the TraitAPIVersions are injected
+			name:   "trait-api-versions-v1beta1",
+			filter: &scaler.ScalerFilter{TraitAPIVersions: []string{"core.oam.dev/v1beta1"}},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: alpha
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+  - componentName: beta
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1beta1
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: alpha
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+  - componentName: beta
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1beta1
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait  in component beta to 3\n",
+		},
+		{
+			// the trait added by CreateIfMissing has the first of the TraitAPIVersions
+			name: "trait-api-versions-create",
+			filter: &scaler.ScalerFilter{CreateIfMissing: true,
+				TraitAPIVersions: []string{"core.oam.dev/v1beta1"}},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1beta1
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"added ManualScalerTrait with replicaCount 3 to component frontend\n",
+		},
+		{
+			// the ApplicationConfiguration is passed through unchanged
+			name:   "skip-application",
+			filter: &scaler.ScalerFilter{LogLevel: scaler.LogLevelDebug},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+    oam.dev/skip-scaler: "true"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+    oam.dev/skip-scaler: "true"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedResults: "[debug] ApplicationConfiguration /example-appconfig: " +
+				"skipping a Resource with the oam.dev/skip-scaler annotation\n" +
+				"[debug] ApplicationConfiguration /example-appconfig: unchanged\n",
+		},
+		{
+			// only the component of the Component Resource with the annotation is skipped
+			name:   "skip-component",
+			filter: &scaler.ScalerFilter{LogLevel: scaler.LogLevelDebug},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: frontend
+  annotations:
+    oam.dev/skip-scaler: "true"
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: frontend
+  annotations:
+    oam.dev/skip-scaler: "true"
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: `[debug] ApplicationConfiguration /example-appconfig: visiting component frontend
+[debug] ApplicationConfiguration /example-appconfig: skipping component frontend with the oam.dev/skip-scaler annotation
+[debug] ApplicationConfiguration /example-appconfig: visiting component backend
+[debug] ApplicationConfiguration /example-appconfig: visiting ManualScalerTrait backend-trait in component backend
+[info] ApplicationConfiguration /example-appconfig: set replicaCount of ManualScalerTrait backend-trait in component backend to 3
+[debug] ApplicationConfiguration /example-appconfig: changed 1 traits
+`,
+		},
+		{
+			// a false annotation doesn't skip the component
+			name:   "skip-component-false",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: frontend
+  annotations:
+    oam.dev/skip-scaler: "false"
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: frontend
+  annotations:
+    oam.dev/skip-scaler: "false"
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 3
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 3
+`,
+			expectedResults: "[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait frontend-trait in component frontend to 3\n" +
+				"[info] ApplicationConfiguration /example-appconfig: " +
+				"set replicaCount of ManualScalerTrait backend-trait in component backend to 3\n",
+		},
+		{
+			name:   "skip-invalid",
+			filter: scaler.NewScalerFilter(""),
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: frontend
+  annotations:
+    oam.dev/skip-scaler: "sometimes"
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: backend-trait
+        spec:
+          replicaCount: 1
+`,
+			expectedErr: "Component /frontend " +
+				`oam.dev/skip-scaler annotation must be true or false, got "sometimes"`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var out, results bytes.Buffer
+			test.filter.Results = &results
+			err := kio.Pipeline{
+				// don't unwrap Lists so that they are read by the filter
+				Inputs: []kio.Reader{&kio.ByteReader{
+					Reader: bytes.NewBufferString(test.input), DisableUnwrapping: true}},
+				Filters: []kio.Filter{test.filter},
+				Outputs: []kio.Writer{&kio.ByteWriter{Writer: &out}},
+			}.Execute()
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %s\nbut got %v\n", test.expectedErr, err)
+				}
+				if out.Len() != 0 {
+					t.Fatalf("expected no output\nbut got %s\n", out.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expected {
+				t.Fatalf("expected %s\nbut got %s\n", test.expected, out.String())
+			}
+			if results.String() != test.expectedResults {
+				t.Fatalf("expected results %s\nbut got %s\n", test.expectedResults, results.String())
+			}
+		})
+	}
+}
+
+// TestScalerFilter_componentShapes tests that components in a sequence and in a
+// mapping are injected the same.
+func TestScalerFilter_componentShapes(t *testing.T) {
+	trait := `traits:
+      - trait:
+          apiVersion: core.oam.dev/v1alpha2
+          kind: HorizontalPodAutoscalerTrait
+          metadata:
+            name: example-appconfig-hpa
+          spec:
+            maxReplicas: 10
+`
+	inputs := map[string]string{
+		"sequence": `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+    - componentName: example-component
+      ` + trait,
+		"mapping": `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+    example-component:
+      ` + trait,
+	}
+
+	paths := map[string][]string{
+		"sequence": {"spec", "components", "[componentName=example-component]", "traits"},
+		"mapping":  {"spec", "components", "example-component", "traits"},
+	}
+	outputs := map[string]string{}
+	for name, input := range inputs {
+		var results bytes.Buffer
+		nodes, err := (&kio.ByteReader{Reader: bytes.NewBufferString(input)}).Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes, err = scaler.ScalerFilter{Results: &results}.Filter(nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// compare the traits, as the components are formatted differently
+		traits, err := nodes[0].Pipe(yaml.Lookup(paths[name]...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[name] = traits.MustString() + results.String()
+	}
+
+	expected := `- trait:
+    apiVersion: core.oam.dev/v1alpha2
+    kind: HorizontalPodAutoscalerTrait
+    metadata:
+      name: example-appconfig-hpa
+    spec:
+      minReplicas: 3
+      maxReplicas: 10
+[info] ApplicationConfiguration /example-appconfig: ` +
+		"set minReplicas of HorizontalPodAutoscalerTrait example-appconfig-hpa in component example-component to 3\n"
+	for name := range inputs {
+		if outputs[name] != expected {
+			t.Fatalf("expected %s\nbut got %s\nfor %s\n", expected, outputs[name], name)
+		}
+	}
+}
+
+func TestScalerFilter_returnsInjected(t *testing.T) {
+	appConfig := func(name, annotation, replicas string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: ` + name + `
+  annotations:
+    ` + annotation + `
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: ` + replicas + `
+`
+	}
+	list := `apiVersion: v1
+kind: List
+items:
+- ` + strings.Replace(strings.TrimSuffix(appConfig("listed", `scaler: "5"`, "1"), "\n"),
+		"\n", "\n  ", -1) + "\n"
+	inputs := []string{
+		appConfig("changed", `scaler: "3"`, "1"),
+		appConfig("unchanged", `other: "3"`, "1"),
+		list,
+	}
+	var nodes []*yaml.RNode
+	for i := range inputs {
+		nodes = append(nodes, yaml.MustParse(inputs[i]))
+	}
+
+	injected, err := scaler.ScalerFilter{}.Filter(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(injected) != len(nodes) {
+		t.Fatalf("expected %d nodes\nbut got %d\n", len(nodes), len(injected))
+	}
+	// the input nodes aren't modified
+	for i := range nodes {
+		if actual := nodes[i].MustString(); actual != inputs[i] {
+			t.Fatalf("expected the input to be unmodified %s\nbut got %s\n", inputs[i], actual)
+		}
+	}
+	// the returned nodes only differ in the injected replicas, and the unchanged node
+	// is returned as is
+	expected := []string{
+		appConfig("changed", `scaler: "3"`, "3"),
+		inputs[1],
+		strings.Replace(list, "replicaCount: 1", "replicaCount: 5", 1),
+	}
+	for i := range expected {
+		if actual := injected[i].MustString(); actual != expected[i] {
+			t.Fatalf("expected %s\nbut got %s\n", expected[i], actual)
+		}
+	}
+	if injected[1] != nodes[1] {
+		t.Fatalf("expected the unchanged node to be returned\n")
+	}
+}
+
+// appConfigs returns n ApplicationConfigurations with their own names and replicas.
+func appConfigs(n int) []*yaml.RNode {
+	var nodes []*yaml.RNode
+	for i := 0; i < n; i++ {
+		nodes = append(nodes, yaml.MustParse(fmt.Sprintf(`apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig-%d
+  annotations:
+    scaler: "%d"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 1
+`, i, i%5)))
+	}
+	return nodes
+}
+
+func TestScalerFilter_concurrency(t *testing.T) {
+	nodes := appConfigs(50)
+	// the order of the failed Resources is stable as well
+	invalid := appConfigs(50)
+	for _, i := range []int{7, 31} {
+		if err := invalid[i].PipeE(yaml.SetAnnotation("scaler", "seven")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var outputs, results []string
+	for _, concurrency := range []int{0, 1, 4, 16, 100} {
+		var buff bytes.Buffer
+		f := scaler.ScalerFilter{Results: &buff, LogLevel: scaler.LogLevelDebug,
+			Concurrency: concurrency}
+		out, err := f.Filter(nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = f.Filter(invalid)
+		if err == nil {
+			t.Fatalf("expected an error for concurrency %d\n", concurrency)
+		}
+		fmt.Fprintln(&buff, err)
+
+		var s []string
+		for i := range out {
+			s = append(s, out[i].MustString())
+		}
+		outputs = append(outputs, strings.Join(s, "---\n"))
+		results = append(results, buff.String())
+	}
+
+	for i := range outputs {
+		if outputs[i] != outputs[0] {
+			t.Fatalf("expected the output %s\nbut got %s\n", outputs[0], outputs[i])
+		}
+		if results[i] != results[0] {
+			t.Fatalf("expected the results %s\nbut got %s\n", results[0], results[i])
+		}
+	}
+	if i, j := strings.Index(outputs[0], "name: example-appconfig-48\n"),
+		strings.Index(outputs[0], "name: example-appconfig-49\n"); i < 0 || i > j {
+		t.Fatalf("expected the Resources in order\nbut got %s\n", outputs[0])
+	}
+	if !strings.Contains(results[0], "2 Resources failed:\n"+
+		"ApplicationConfiguration /example-appconfig-7: ") {
+		t.Fatalf("expected the failed Resources in order\nbut got %s\n", results[0])
+	}
+}
+
+func BenchmarkScalerFilter(b *testing.B) {
+	nodes := appConfigs(1000)
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			f := scaler.ScalerFilter{Concurrency: concurrency}
+			for i := 0; i < b.N; i++ {
+				// the input isn't modified, so it is injected again each time
+				if _, err := f.Filter(nodes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// TestScalerFilter_diff tests that a diff of each changed Resource is written to
+// the Diff, and that the output isn't affected.
+func TestScalerFilter_diff(t *testing.T) {
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  namespace: default
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec:
+          replicaCount: 1
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: unchanged-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+`
+	nodes, err := (&kio.ByteReader{Reader: bytes.NewBufferString(input),
+		OmitReaderAnnotations: true}).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diff bytes.Buffer
+	out, err := scaler.ScalerFilter{Diff: &diff}.Filter(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `--- a/ApplicationConfiguration default/example-appconfig
++++ b/ApplicationConfiguration default/example-appconfig
+@@ -15,4 +15,4 @@
+         metadata:
+           name: frontend-trait
+         spec:
+-          replicaCount: 1
++          replicaCount: 3
+`
+	if diff.String() != expected {
+		t.Fatalf("expected diff %s\nbut got %s\n", expected, diff.String())
+	}
+	injected, err := out[0].String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(injected, "replicaCount: 3") {
+		t.Fatalf("expected the injected replicaCount\nbut got %s\n", injected)
+	}
+
+	// nothing is changed by a dry-run, so that no diff is written
+	diff.Reset()
+	if _, err := (scaler.ScalerFilter{Diff: &diff, DryRun: true}).Filter(nodes); err != nil {
+		t.Fatal(err)
+	}
+	if diff.Len() != 0 {
+		t.Fatalf("expected no diff for a dry-run\nbut got %s\n", diff.String())
+	}
+}
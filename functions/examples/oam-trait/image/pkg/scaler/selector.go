@@ -0,0 +1,223 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// componentKind is the kind of the Resources containing the workloads of the components
+const componentKind = "Component"
+
+// Operators of the label requirements
+const (
+	opEquals    = "="
+	opNotEquals = "!="
+	opExists    = "exists"
+	opNotExists = "!"
+)
+
+// labelRequirement is a requirement of a labelSelector on a single label.
+type labelRequirement struct {
+	key   string
+	op    string
+	value string
+}
+
+// labelSelector is an equality-based label selector, and matches labels which
+// match all of its requirements.
+type labelSelector []labelRequirement
+
+// parseLabelSelector parses a label selector of comma separated requirements,
+// each of which is one of `key=value`, `key==value`, `key!=value`, `key` or `!key`.
+func parseLabelSelector(s string) (labelSelector, error) {
+	var selector labelSelector
+	for _, r := range strings.Split(s, ",") {
+		r = strings.TrimSpace(r)
+		var req labelRequirement
+		switch {
+		case strings.Contains(r, "!="):
+			parts := strings.SplitN(r, "!=", 2)
+			req = labelRequirement{key: parts[0], op: opNotEquals, value: parts[1]}
+		case strings.Contains(r, "=="):
+			parts := strings.SplitN(r, "==", 2)
+			req = labelRequirement{key: parts[0], op: opEquals, value: parts[1]}
+		case strings.Contains(r, "="):
+			parts := strings.SplitN(r, "=", 2)
+			req = labelRequirement{key: parts[0], op: opEquals, value: parts[1]}
+		case strings.HasPrefix(r, "!"):
+			req = labelRequirement{key: strings.TrimPrefix(r, "!"), op: opNotExists}
+		default:
+			req = labelRequirement{key: r, op: opExists}
+		}
+		req.key, req.value = strings.TrimSpace(req.key), strings.TrimSpace(req.value)
+		if req.key == "" || strings.ContainsAny(req.key+req.value, " =!") {
+			return nil, fmt.Errorf("invalid requirement %q", r)
+		}
+		selector = append(selector, req)
+	}
+	return selector, nil
+}
+
+// matches returns true if labels match all of the requirements of s.
+func (s labelSelector) matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, found := labels[req.key]
+		switch req.op {
+		case opEquals:
+			if !found || value != req.value {
+				return false
+			}
+		case opNotEquals:
+			if found && value == req.value {
+				return false
+			}
+		case opExists:
+			if !found {
+				return false
+			}
+		case opNotExists:
+			if found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// componentFilter visits the components of ApplicationConfigurations which match
+// the Selector of the Options it is created from, and which aren't skipped.
+type componentFilter struct {
+	// selector is nil if the Options don't have a Selector
+	selector  labelSelector
+	workloads componentWorkloads
+
+	// skipped are the namespaces and names of the Component Resources with a
+	// true SkipAnnotation
+	skipped map[string]bool
+}
+
+// newComponentFilter returns a componentFilter for the Selector and Components of
+// opts.
+func newComponentFilter(opts Options) (componentFilter, error) {
+	skipped, err := getSkippedComponents(opts.Components)
+	if err != nil {
+		return componentFilter{}, err
+	}
+	if opts.Selector == "" {
+		return componentFilter{skipped: skipped}, nil
+	}
+	selector, err := parseLabelSelector(opts.Selector)
+	if err != nil {
+		return componentFilter{}, fmt.Errorf("selector %q %v", opts.Selector, err)
+	}
+	workloads, err := getComponentWorkloads(opts.Components)
+	return componentFilter{selector: selector, workloads: workloads, skipped: skipped}, err
+}
+
+// getSkippedComponents returns the namespaces and names of the Components in
+// items with a true SkipAnnotation.
+func getSkippedComponents(items []*yaml.RNode) (map[string]bool, error) {
+	skipped := map[string]bool{}
+	for _, item := range items {
+		meta, err := item.GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		if meta.Kind != componentKind {
+			continue
+		}
+		skip, err := isSkipped(meta.Annotations)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s/%s %v", meta.Kind, meta.Namespace, meta.Name, err)
+		}
+		if skip {
+			skipped[meta.Namespace+"/"+meta.Name] = true
+		}
+	}
+	return skipped, nil
+}
+
+// visit calls fn with the name and node of each of the components which matches
+// the selector.  namespace is the namespace of their ApplicationConfiguration.
+func (c componentFilter) visit(components *yaml.RNode, namespace string, opts Options,
+	fn func(componentName string, node *yaml.RNode) error) error {
+	return visitComponents(components, func(componentName string, node *yaml.RNode) error {
+		opts.report(SeverityDebug, "visiting component %s", componentName)
+		if c.skipped[namespace+"/"+componentName] {
+			opts.report(SeverityDebug, "skipping component %s with the %s annotation",
+				componentName, SkipAnnotation)
+			return nil
+		}
+		if c.selector != nil {
+			labels, err := c.workloads.labels(node, namespace, componentName)
+			if err != nil {
+				return err
+			}
+			if !c.selector.matches(labels) {
+				opts.report(SeverityDebug,
+					"skipping component %s which doesn't match the selector", componentName)
+				return nil
+			}
+		}
+		return fn(componentName, node)
+	})
+}
+
+// componentWorkloads contains the workloads of the Component Resources, by
+// their namespace and name.
+type componentWorkloads map[string]*yaml.RNode
+
+// getComponentWorkloads returns the `spec.workload` of each Component in items.
+func getComponentWorkloads(items []*yaml.RNode) (componentWorkloads, error) {
+	workloads := componentWorkloads{}
+	for _, item := range items {
+		meta, err := item.GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		if meta.Kind != componentKind {
+			continue
+		}
+		workload, err := item.Pipe(yaml.Lookup("spec", "workload"))
+		if err != nil {
+			return nil, err
+		}
+		if workload != nil {
+			workloads[meta.Namespace+"/"+meta.Name] = workload
+		}
+	}
+	return workloads, nil
+}
+
+// labels returns the labels of the workload of the component, which is either
+// embedded in the component's `workload` field or is the workload of the Component
+// Resource named componentName in namespace.  A component without a workload
+// has no labels.
+func (w componentWorkloads) labels(
+	component *yaml.RNode, namespace, componentName string) (map[string]string, error) {
+	workload, err := component.Pipe(yaml.Lookup("workload"))
+	if err != nil {
+		return nil, err
+	}
+	if workload == nil {
+		workload = w[namespace+"/"+componentName]
+	}
+	if workload == nil {
+		return nil, nil
+	}
+	labels, err := workload.Pipe(yaml.Lookup("metadata", "labels"))
+	if err != nil || labels == nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	err = labels.VisitFields(func(node *yaml.MapNode) error {
+		result[yaml.GetValue(node.Key)] = yaml.GetValue(node.Value)
+		return nil
+	})
+	return result, err
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// applicationConfigurationAPIVersions are the apiVersions of the
+// ApplicationConfigurations which may be injected
+var applicationConfigurationAPIVersions = []string{"core.oam.dev/v1alpha2"}
+
+// ValidateFilter implements kio.Filter, and validates the ApplicationConfigurations
+// before they are injected by the ScalerFilter, so that malformed
+// ApplicationConfigurations fail with an error naming them.
+//
+// ApplicationConfigurations must have a supported apiVersion, and their
+// spec.components must be a list or a mapping.  Other Resources aren't validated.
+// The Resources aren't modified.
+type ValidateFilter struct{}
+
+// Filter validates the ApplicationConfigurations in the Resources, including
+// those wrapped in a List.  All Resources are validated, and the errors of
+// the invalid Resources are returned together.
+func (ValidateFilter) Filter(in []*yaml.RNode) ([]*yaml.RNode, error) {
+	var errs resourceErrors
+	for _, r := range in {
+		items, err := listItems(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			meta, err := item.GetMeta()
+			if err != nil {
+				return nil, err
+			}
+			if meta.Kind != applicationConfigurationKind {
+				continue
+			}
+			if err := validateApplicationConfiguration(item, meta); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s/%s: %v",
+					meta.Kind, meta.Namespace, meta.Name, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return in, nil
+}
+
+// validateApplicationConfiguration returns an error if r isn't a valid
+// ApplicationConfiguration.
+func validateApplicationConfiguration(r *yaml.RNode, meta yaml.ResourceMeta) error {
+	supported := false
+	for _, v := range applicationConfigurationAPIVersions {
+		supported = supported || meta.APIVersion == v
+	}
+	if !supported {
+		return fmt.Errorf("apiVersion must be %s, got %q",
+			applicationConfigurationAPIVersions[0], meta.APIVersion)
+	}
+
+	spec := r.Field("spec")
+	if spec == nil || spec.Value.YNode().Kind != yaml.MappingNode {
+		return fmt.Errorf("spec must be a mapping")
+	}
+	components := spec.Value.Field("components")
+	if components == nil {
+		return fmt.Errorf("missing spec.components")
+	}
+	if kind := components.Value.YNode().Kind; kind != yaml.SequenceNode && kind != yaml.MappingNode {
+		return fmt.Errorf("spec.components must be a list or a mapping")
+	}
+	return nil
+}
+
+// RequireReplicasFilter implements kio.Filter, and validates that every trait with
+// replicas of the ApplicationConfigurations has its replicas field, e.g. after the
+// replicas are injected by the ScalerFilter.  The Resources aren't modified.
+type RequireReplicasFilter struct {
+	// TraitAPIVersions if set are the apiVersions of the traits with replicas
+	// which are validated.  Defaults to DefaultTraitAPIVersions.
+	TraitAPIVersions []string
+}
+
+// Filter validates the ApplicationConfigurations in the Resources, including
+// those wrapped in a List, and returns the errors of the Resources with traits
+// without replicas together.
+func (f RequireReplicasFilter) Filter(in []*yaml.RNode) ([]*yaml.RNode, error) {
+	var errs resourceErrors
+	for _, r := range in {
+		items, err := listItems(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			meta, err := item.GetMeta()
+			if err != nil {
+				return nil, err
+			}
+			if meta.Kind != applicationConfigurationKind {
+				continue
+			}
+			missing, err := missingReplicas(item, Options{TraitAPIVersions: f.TraitAPIVersions})
+			if err != nil {
+				return nil, err
+			}
+			for i := range missing {
+				errs = append(errs, fmt.Errorf("%s %s/%s: %s",
+					meta.Kind, meta.Namespace, meta.Name, missing[i]))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return in, nil
+}
+
+// missingReplicas returns a description of each trait with replicas of the
+// ApplicationConfiguration r which doesn't have its replicas field.
+// Only the TraitAPIVersions of opts are used.
+func missingReplicas(r *yaml.RNode, opts Options) ([]string, error) {
+	components, err := r.Pipe(yaml.Lookup("spec", "components"))
+	if err != nil || components == nil {
+		return nil, err
+	}
+	var missing []string
+	err = visitComponents(components, func(componentName string, node *yaml.RNode) error {
+		return visitTraits(r, node, componentName, Options{}, func(
+			trait *yaml.RNode, traitMeta yaml.ResourceMeta) error {
+			setter, found := opts.traitSetter(traitMeta)
+			if !found {
+				return nil
+			}
+			field, err := lookup(trait, setter.path...)
+			if err != nil || field != nil {
+				return err
+			}
+			missing = append(missing, fmt.Sprintf("%s %s in component %s is missing %s",
+				traitMeta.Kind, traitMeta.Name, componentName, setter.field))
+			return nil
+		})
+	})
+	return missing, err
+}
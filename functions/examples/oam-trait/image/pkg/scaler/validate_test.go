@@ -0,0 +1,246 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scaler_test
+
+import (
+	"bytes"
+	"testing"
+
+	"sigs.k8s.io/kustomize/functions/examples/oam-trait/pkg/scaler"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+func TestValidateFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: example-component
+`,
+		},
+		{
+			name: "valid-component-mapping",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+    example-component: {}
+`,
+		},
+		{
+			name: "non-oam",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example-deployment
+  annotations:
+    scaler: "3"
+`,
+		},
+		{
+			name: "missing-spec",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  namespace: example
+`,
+			expectedErr: "ApplicationConfiguration example/example-appconfig: spec must be a mapping",
+		},
+		{
+			name: "missing-components",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  workloads: []
+`,
+			expectedErr: "ApplicationConfiguration /example-appconfig: missing spec.components",
+		},
+		{
+			name: "scalar-components",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components: example-component
+`,
+			expectedErr: "ApplicationConfiguration /example-appconfig: " +
+				"spec.components must be a list or a mapping",
+		},
+		{
+			name: "wrong-api-version",
+			input: `apiVersion: core.oam.dev/v1alpha1
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components: []
+`,
+			expectedErr: "ApplicationConfiguration /example-appconfig: " +
+				`apiVersion must be core.oam.dev/v1alpha2, got "core.oam.dev/v1alpha1"`,
+		},
+		{
+			name: "list",
+			input: `apiVersion: v1
+kind: List
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: first-appconfig
+  spec:
+    components: []
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: second-appconfig
+`,
+			expectedErr: "ApplicationConfiguration /second-appconfig: spec must be a mapping",
+		},
+		{
+			name: "multiple-errors",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: first-appconfig
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: second-appconfig
+spec: {}
+`,
+			expectedErr: `2 Resources failed:
+ApplicationConfiguration /first-appconfig: spec must be a mapping
+ApplicationConfiguration /second-appconfig: missing spec.components`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := kio.Pipeline{
+				// don't unwrap Lists so that they are read by the filter
+				Inputs: []kio.Reader{&kio.ByteReader{
+					Reader: bytes.NewBufferString(test.input), DisableUnwrapping: true}},
+				Filters: []kio.Filter{scaler.ValidateFilter{}},
+				Outputs: []kio.Writer{&kio.ByteWriter{Writer: &out}},
+			}.Execute()
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %s\nbut got %v\n", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the Resources are passed through unmodified
+			if out.String() != test.input {
+				t.Fatalf("expected %s\nbut got %s\n", test.input, out.String())
+			}
+		})
+	}
+}
+
+func TestRequireReplicasFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 3
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: RouteTrait
+        metadata:
+          name: example-appconfig-route
+`,
+		},
+		{
+			name: "missing-replicas",
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+spec:
+  components:
+  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: frontend-trait
+        spec: {}
+  - componentName: backend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: HorizontalPodAutoscalerTrait
+        metadata:
+          name: backend-hpa
+        spec:
+          maxReplicas: 5
+`,
+			expectedErr: `2 Resources failed:
+ApplicationConfiguration /example-appconfig: ManualScalerTrait frontend-trait in component frontend is missing replicaCount
+ApplicationConfiguration /example-appconfig: HorizontalPodAutoscalerTrait backend-hpa in component backend is missing minReplicas`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := kio.Pipeline{
+				Inputs:  []kio.Reader{&kio.ByteReader{Reader: bytes.NewBufferString(test.input)}},
+				Filters: []kio.Filter{scaler.RequireReplicasFilter{}},
+				Outputs: []kio.Writer{&kio.ByteWriter{Writer: &out}},
+			}.Execute()
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %s\nbut got %v\n", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the Resources are passed through unmodified
+			if out.String() != test.input {
+				t.Fatalf("expected %s\nbut got %s\n", test.input, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+module sigs.k8s.io/kustomize/functions/examples/validator-oam
+
+go 1.13
+
+require sigs.k8s.io/kustomize/kyaml v0.1.3
+
+replace sigs.k8s.io/kustomize/kyaml => ../../../../kyaml
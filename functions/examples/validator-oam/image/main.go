@@ -0,0 +1,39 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main implements a validator function for the consistency of OAM
+// ApplicationConfigurations and Components, and is run with
+// `kustomize config run DIR/`.
+//
+// The Resources are validated by validateFilter, which logs a result for each
+// problem to stderr with a framework.Logger, and fails if any of the results are
+// errors.  When the Resources are valid, the warnings are also written to the
+// results of the ResourceList.  The Resources aren't modified.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+)
+
+func main() {
+	if err := run(os.Stdin, os.Stdout, &framework.Logger{Writer: os.Stderr}); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run reads the Resources from in, validates them logging the results with
+// logger, and writes the Resources to out if they are valid.
+func run(in io.Reader, out io.Writer, logger *framework.Logger) error {
+	return framework.ResourceListProcessor{
+		Reader:                in,
+		Writer:                out,
+		KeepReaderAnnotations: true,
+		Logger:                logger,
+		Filter:                validateFilter{Logger: logger},
+	}.Execute()
+}
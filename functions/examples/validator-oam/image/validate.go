@@ -0,0 +1,398 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// oamGroup is the API group of the ApplicationConfigurations and Components which
+// are validated
+const oamGroup = "core.oam.dev"
+
+// Kinds of the Resources which are validated
+const (
+	applicationConfigurationKind = "ApplicationConfiguration"
+	componentKind                = "Component"
+	traitDefinitionKind          = "TraitDefinition"
+)
+
+// result is a problem with a field of a Resource.
+type result struct {
+	// Severity is kio.SeverityError or kio.SeverityWarning.  Errors fail the
+	// validation.
+	Severity string
+
+	// Resource is the metadata of the Resource with the problem.
+	Resource yaml.ResourceMeta
+
+	// Field is the path of the field with the problem, e.g.
+	// `spec.components[0].componentName`.
+	Field string
+
+	// Message describes the problem.
+	Message string
+}
+
+// kioResult returns the result as a kio.Result, with the file of the Resource
+// appended to the message if it is known.
+func (r result) kioResult() kio.Result {
+	msg := r.Message
+	if path := r.Resource.Annotations[kioutil.PathAnnotation]; path != "" {
+		msg += fmt.Sprintf(" (%s [%s])", path, r.Resource.Annotations[kioutil.IndexAnnotation])
+	}
+	return kio.Result{Severity: r.Severity, Message: msg, Field: r.Field,
+		ResourceRef: yaml.ResourceIdentifier{
+			APIVersion: r.Resource.APIVersion, Kind: r.Resource.Kind,
+			Namespace: r.Resource.Namespace, Name: r.Resource.Name}}
+}
+
+// validateFilter implements kio.Filter, and validates that the ApplicationConfigurations
+// are consistent with the Components in the Resources:
+//
+// - each component of an ApplicationConfiguration must name a Component
+// - each parameterValue of a component must be a parameter declared by the Component
+// - a Component may only be referenced once by an ApplicationConfiguration
+// - each trait of a component must apply to the workload of the Component
+//
+// The traits are only validated if their TraitDefinitions are in the Resources.
+//
+// Components must be in the namespace of the ApplicationConfigurations referencing
+// them, and references to Components in other namespaces are errors.  Resources
+// without a namespace match any namespace, since it is set when they are applied,
+// and references which only match because of it are warnings.
+//
+// The Resources aren't modified.
+type validateFilter struct {
+	// Logger if set logs each result, and records it in the results of the
+	// ResourceList which is written.
+	Logger *framework.Logger
+}
+
+// Filter validates every ApplicationConfiguration in the Resources, and returns an
+// error if there are any error results.
+func (f validateFilter) Filter(in []*yaml.RNode) ([]*yaml.RNode, error) {
+	results, err := validate(in)
+	if err != nil {
+		return nil, err
+	}
+	errors := 0
+	for i := range results {
+		if f.Logger != nil {
+			f.Logger.Result(results[i].kioResult())
+		}
+		if results[i].Severity == kio.SeverityError {
+			errors++
+		}
+	}
+	if errors > 0 {
+		return nil, fmt.Errorf("found %d errors in the ApplicationConfigurations", errors)
+	}
+	return in, nil
+}
+
+// component is a Component which may be referenced by ApplicationConfigurations.
+type component struct {
+	meta yaml.ResourceMeta
+
+	// parameters are the names of the parameters declared by the Component
+	parameters map[string]bool
+
+	// workload is the type of the workload of the Component
+	workload yaml.TypeMeta
+}
+
+// componentIndex indexes the Components by name.
+type componentIndex map[string][]component
+
+// lookup returns the Component named name which may be referenced from namespace,
+// and the namespaces of the Components named name which may not.
+func (idx componentIndex) lookup(namespace, name string) (*component, []string) {
+	var others []string
+	for i := range idx[name] {
+		c := &idx[name][i]
+		if c.meta.Namespace == namespace || c.meta.Namespace == "" || namespace == "" {
+			return c, nil
+		}
+		others = append(others, c.meta.Namespace)
+	}
+	sort.Strings(others)
+	return nil, others
+}
+
+// traitDefinitions are the workloads which each TraitDefinition applies to,
+// indexed by the name of the TraitDefinition.
+type traitDefinitions map[string][]string
+
+// validate returns the results of validating the ApplicationConfigurations in nodes.
+func validate(nodes []*yaml.RNode) ([]result, error) {
+	idx := componentIndex{}
+	defs := traitDefinitions{}
+	var appConfigs []*yaml.RNode
+	for i := range nodes {
+		meta, err := nodes[i].GetMeta()
+		if err != nil {
+			// documents which aren't Resources, e.g. values files, aren't validated
+			continue
+		}
+		if !strings.HasPrefix(meta.APIVersion, oamGroup+"/") {
+			continue
+		}
+		switch meta.Kind {
+		case applicationConfigurationKind:
+			appConfigs = append(appConfigs, nodes[i])
+		case componentKind:
+			c, err := newComponent(nodes[i], meta)
+			if err != nil {
+				return nil, err
+			}
+			idx[meta.Name] = append(idx[meta.Name], c)
+		case traitDefinitionKind:
+			appliesTo, err := newTraitDefinition(nodes[i])
+			if err != nil {
+				return nil, err
+			}
+			defs[meta.Name] = appliesTo
+		}
+	}
+
+	var results []result
+	for i := range appConfigs {
+		r, err := validateApplicationConfiguration(appConfigs[i], idx, defs)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
+
+// newComponent returns the component for the Component r.
+func newComponent(r *yaml.RNode, meta yaml.ResourceMeta) (component, error) {
+	c := component{meta: meta, parameters: map[string]bool{}}
+	workload, err := r.Pipe(yaml.Lookup("spec", "workload"))
+	if err != nil {
+		return c, err
+	}
+	if meta, err := workload.GetMeta(); err == nil {
+		c.workload = yaml.TypeMeta{APIVersion: meta.APIVersion, Kind: meta.Kind}
+	}
+	parameters, err := r.Pipe(yaml.Lookup("spec", "parameters"))
+	if err != nil || parameters == nil {
+		return c, err
+	}
+	err = parameters.VisitElements(func(node *yaml.RNode) error {
+		name, err := node.Pipe(yaml.Get("name"))
+		if err != nil {
+			return err
+		}
+		if name != nil {
+			c.parameters[yaml.GetValue(name)] = true
+		}
+		return nil
+	})
+	return c, err
+}
+
+// newTraitDefinition returns the spec.appliesToWorkloads of the TraitDefinition r.
+func newTraitDefinition(r *yaml.RNode) ([]string, error) {
+	appliesTo, err := r.Pipe(yaml.Lookup("spec", "appliesToWorkloads"))
+	if err != nil || appliesTo == nil {
+		return nil, err
+	}
+	var workloads []string
+	err = appliesTo.VisitElements(func(node *yaml.RNode) error {
+		workloads = append(workloads, yaml.GetValue(node))
+		return nil
+	})
+	return workloads, err
+}
+
+// validateApplicationConfiguration returns the results of validating the components
+// of the ApplicationConfiguration r against the Components in idx and the
+// TraitDefinitions in defs.
+func validateApplicationConfiguration(
+	r *yaml.RNode, idx componentIndex, defs traitDefinitions) ([]result, error) {
+	meta, err := r.GetMeta()
+	if err != nil {
+		return nil, err
+	}
+	var results []result
+	report := func(severity, field, msg string, args ...interface{}) {
+		results = append(results, result{Severity: severity, Resource: meta, Field: field,
+			Message: fmt.Sprintf(msg, args...)})
+	}
+
+	components, err := r.Pipe(yaml.Lookup("spec", "components"))
+	if err != nil {
+		return nil, err
+	}
+	if components == nil || components.YNode().Kind != yaml.SequenceNode {
+		report(kio.SeverityError, "spec.components", "must be a list")
+		return results, nil
+	}
+	elements, err := components.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	// referenced are the paths of the components which reference each Component
+	referenced := map[string]string{}
+	for i := range elements {
+		path := fmt.Sprintf("spec.components[%d]", i)
+		name, err := elements[i].Pipe(yaml.Get("componentName"))
+		if err != nil {
+			return nil, err
+		}
+		componentName := yaml.GetValue(name)
+		if componentName == "" {
+			report(kio.SeverityError, path+".componentName", "missing componentName")
+			continue
+		}
+		if first, found := referenced[componentName]; found {
+			report(kio.SeverityError, path+".componentName",
+				"component %q is already referenced by %s", componentName, first)
+			continue
+		}
+		referenced[componentName] = path
+
+		c, others := idx.lookup(meta.Namespace, componentName)
+		switch {
+		case c == nil && len(others) > 0:
+			report(kio.SeverityError, path+".componentName",
+				"component %q is in namespace %s rather than %s, and may only be "+
+					"referenced from its own namespace", componentName,
+				strings.Join(others, ", "), meta.Namespace)
+			continue
+		case c == nil:
+			report(kio.SeverityError, path+".componentName",
+				"component %q isn't defined by a Component", componentName)
+			continue
+		case c.meta.Namespace != meta.Namespace:
+			// only one of them has a namespace, so they must be applied to it
+			report(kio.SeverityWarning, path+".componentName",
+				"component %q is resolved assuming that the ApplicationConfiguration "+
+					"and Component are both applied to namespace %s", componentName,
+				meta.Namespace+c.meta.Namespace)
+		}
+
+		if err := validateTraits(elements[i], path, componentName, c, defs, report); err != nil {
+			return nil, err
+		}
+
+		parameterValues, err := elements[i].Pipe(yaml.Lookup("parameterValues"))
+		if err != nil {
+			return nil, err
+		}
+		if parameterValues == nil {
+			continue
+		}
+		values, err := parameterValues.Elements()
+		if err != nil {
+			return nil, err
+		}
+		for j := range values {
+			valuePath := fmt.Sprintf("%s.parameterValues[%d].name", path, j)
+			name, err := values[j].Pipe(yaml.Get("name"))
+			if err != nil {
+				return nil, err
+			}
+			switch parameter := yaml.GetValue(name); {
+			case parameter == "":
+				report(kio.SeverityError, valuePath, "missing name")
+			case !c.parameters[parameter]:
+				report(kio.SeverityError, valuePath,
+					"parameter %q isn't declared by component %q", parameter, componentName)
+			}
+		}
+	}
+	return results, nil
+}
+
+// validateTraits reports the traits of the component element at path which don't
+// apply to the workload of the Component c.  Traits whose TraitDefinition isn't in
+// defs, e.g. because it is installed on the cluster, aren't validated.
+func validateTraits(element *yaml.RNode, path, componentName string, c *component,
+	defs traitDefinitions, report func(severity, field, msg string, args ...interface{})) error {
+	traits, err := element.Pipe(yaml.Lookup("traits"))
+	if err != nil || traits == nil || c.workload.Kind == "" {
+		return err
+	}
+	elements, err := traits.Elements()
+	if err != nil {
+		return err
+	}
+	for i := range elements {
+		trait, err := elements[i].Pipe(yaml.Lookup("trait"))
+		if err != nil {
+			return err
+		}
+		if trait == nil {
+			continue
+		}
+		meta, err := trait.GetMeta()
+		if err != nil {
+			continue
+		}
+		appliesTo, found := defs[definitionName(meta.APIVersion, meta.Kind)]
+		if !found || appliesToWorkload(appliesTo, c.workload) {
+			continue
+		}
+		report(kio.SeverityError, fmt.Sprintf("%s.traits[%d].trait.kind", path, i),
+			"trait %s doesn't apply to the %s workload of component %q, only to %s",
+			meta.Kind, c.workload.Kind, componentName, strings.Join(appliesTo, ", "))
+	}
+	return nil
+}
+
+// appliesToWorkload returns true if the spec.appliesToWorkloads of a TraitDefinition
+// match the workload: they are either empty or `*`, or one of them is the name of
+// the definition of the workload, or its API group.
+func appliesToWorkload(appliesTo []string, workload yaml.TypeMeta) bool {
+	if len(appliesTo) == 0 {
+		return true
+	}
+	name := definitionName(workload.APIVersion, workload.Kind)
+	for _, w := range appliesTo {
+		if w == "*" || w == name || w == apiGroup(workload.APIVersion) {
+			return true
+		}
+	}
+	return false
+}
+
+// definitionName returns the name of the TraitDefinition or WorkloadDefinition of
+// kind, which by convention is the plural resource of kind and its group, e.g.
+// `manualscalertraits.core.oam.dev`.  The plural is guessed from the kind, since
+// the resources of the cluster aren't known.
+func definitionName(apiVersion, kind string) string {
+	name := strings.ToLower(kind)
+	switch {
+	case strings.HasSuffix(name, "s"):
+		name += "es"
+	case strings.HasSuffix(name, "y"):
+		name = strings.TrimSuffix(name, "y") + "ies"
+	default:
+		name += "s"
+	}
+	if group := apiGroup(apiVersion); group != "" {
+		name += "." + group
+	}
+	return name
+}
+
+// apiGroup returns the group of apiVersion, which is empty for the core group.
+func apiGroup(apiVersion string) string {
+	if i := strings.Index(apiVersion, "/"); i >= 0 {
+		return apiVersion[:i]
+	}
+	return ""
+}
@@ -0,0 +1,268 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+func TestValidateFilter(t *testing.T) {
+	component := func(namespace, name string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: Component
+metadata:
+  name: ` + name + `
+  namespace: ` + namespace + `
+spec:
+  workload:
+    apiVersion: core.oam.dev/v1alpha2
+    kind: ContainerizedWorkload
+  parameters:
+  - name: image
+    fieldPaths:
+    - spec.containers[0].image
+`
+	}
+	appConfig := func(namespace, components string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  namespace: ` + namespace + `
+spec:
+  components:
+` + components
+	}
+	traitDefinition := func(appliesTo ...string) string {
+		return `apiVersion: core.oam.dev/v1alpha2
+kind: TraitDefinition
+metadata:
+  name: manualscalertraits.core.oam.dev
+spec:
+  appliesToWorkloads: [` + strings.Join(appliesTo, ", ") + `]
+  definitionRef:
+    name: manualscalertraits.core.oam.dev
+`
+	}
+	traits := `  - componentName: frontend
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        spec:
+          replicaCount: 3
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: RouteTrait
+`
+
+	tests := []struct {
+		name            string
+		input           []string
+		expectedResults string
+		expectedErr     string
+	}{
+		{
+			name: "valid",
+			input: []string{component("prod", "frontend"), component("prod", "backend"),
+				appConfig("prod", `  - componentName: frontend
+    parameterValues:
+    - name: image
+      value: nginx
+  - componentName: backend
+`)},
+		},
+		{
+			name:  "missing-component",
+			input: []string{component("prod", "frontend"), appConfig("prod", "  - componentName: fronted\n")},
+			expectedResults: `[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[0].componentName: component "fronted" isn't defined by a Component
+`,
+			expectedErr: "found 1 errors in the ApplicationConfigurations",
+		},
+		{
+			name: "undeclared-parameter",
+			input: []string{component("prod", "frontend"), appConfig("prod", `  - componentName: frontend
+    parameterValues:
+    - name: image
+      value: nginx
+    - name: replicas
+      value: 3
+    - value: 3
+`)},
+			expectedResults: `[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[0].parameterValues[1].name: parameter "replicas" isn't declared by component "frontend"
+[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[0].parameterValues[2].name: missing name
+`,
+			expectedErr: "found 2 errors in the ApplicationConfigurations",
+		},
+		{
+			name: "duplicate-component",
+			input: []string{component("prod", "frontend"), appConfig("prod", `  - componentName: frontend
+  - componentName: frontend
+`)},
+			expectedResults: `[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[1].componentName: component "frontend" is already referenced by spec.components[0]
+`,
+			expectedErr: "found 1 errors in the ApplicationConfigurations",
+		},
+		{
+			name: "other-namespace",
+			input: []string{component("staging", "frontend"), component("test", "frontend"),
+				appConfig("prod", "  - componentName: frontend\n")},
+			expectedResults: `[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[0].componentName: component "frontend" is in namespace staging, test ` +
+				`rather than prod, and may only be referenced from its own namespace
+`,
+			expectedErr: "found 1 errors in the ApplicationConfigurations",
+		},
+		{
+			// the namespace of the Component is set when it is applied
+			name: "no-namespace",
+			input: []string{strings.Replace(component("", "frontend"), "  namespace: \n", "", 1),
+				appConfig("prod", "  - componentName: frontend\n")},
+			expectedResults: `[warning] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[0].componentName: component "frontend" is resolved assuming that ` +
+				`the ApplicationConfiguration and Component are both applied to namespace prod
+`,
+		},
+		{
+			name: "trait-applies-to-workload",
+			input: []string{component("prod", "frontend"),
+				traitDefinition("deployments.apps", "containerizedworkloads.core.oam.dev"),
+				appConfig("prod", traits)},
+		},
+		{
+			name: "trait-applies-to-group",
+			input: []string{component("prod", "frontend"), traitDefinition("core.oam.dev"),
+				appConfig("prod", traits)},
+		},
+		{
+			// the RouteTrait isn't validated without its TraitDefinition
+			name: "trait-doesnt-apply-to-workload",
+			input: []string{component("prod", "frontend"),
+				traitDefinition("deployments.apps", "statefulsets.apps"), appConfig("prod", traits)},
+			expectedResults: `[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[0].traits[0].trait.kind: trait ManualScalerTrait doesn't apply to the ` +
+				`ContainerizedWorkload workload of component "frontend", only to deployments.apps, statefulsets.apps
+`,
+			expectedErr: "found 1 errors in the ApplicationConfigurations",
+		},
+		{
+			// documents without metadata aren't validated
+			name: "not-a-resource",
+			input: []string{"replicas: 3\n", component("prod", "frontend"),
+				appConfig("prod", "  - componentName: fronted\n")},
+			expectedResults: `[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[0].componentName: component "fronted" isn't defined by a Component
+`,
+			expectedErr: "found 1 errors in the ApplicationConfigurations",
+		},
+		{
+			name:  "missing-components",
+			input: []string{strings.TrimSuffix(appConfig("prod", ""), "  components:\n")},
+			expectedResults: `[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components: must be a list
+`,
+			expectedErr: "found 1 errors in the ApplicationConfigurations",
+		},
+		{
+			// the results name the file of the Resource
+			name: "path",
+			input: []string{strings.Replace(appConfig("prod", "  - componentName: frontend\n"),
+				"  namespace: prod\n", `  namespace: prod
+  annotations:
+    config.kubernetes.io/path: app.yaml
+    config.kubernetes.io/index: '0'
+`, 1)},
+			expectedResults: `[error] core.oam.dev/v1alpha2/ApplicationConfiguration prod/example-appconfig ` +
+				`spec.components[0].componentName: component "frontend" isn't defined by a Component ` +
+				`(app.yaml [0])
+`,
+			expectedErr: "found 1 errors in the ApplicationConfigurations",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			input := strings.Join(test.input, "---\n")
+			var out, results bytes.Buffer
+			err := run(bytes.NewBufferString(input), &out, &framework.Logger{Writer: &results})
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %s\nbut got %v\n", test.expectedErr, err)
+				}
+				if out.Len() != 0 {
+					t.Fatalf("expected no output\nbut got %s\n", out.String())
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				// the Resources aren't modified, other than by the reader annotations
+				var expected bytes.Buffer
+				rw := &kio.ByteReadWriter{Reader: bytes.NewBufferString(input), Writer: &expected,
+					KeepReaderAnnotations: true}
+				if err := (kio.Pipeline{Inputs: []kio.Reader{rw},
+					Outputs: []kio.Writer{rw}}).Execute(); err != nil {
+					t.Fatal(err)
+				}
+				if out.String() != expected.String() {
+					t.Fatalf("expected %s\nbut got %s\n", expected.String(), out.String())
+				}
+			}
+			if results.String() != test.expectedResults {
+				t.Fatalf("expected results %s\nbut got %s\n", test.expectedResults, results.String())
+			}
+		})
+	}
+}
+
+func TestValidateFilter_resourceList(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: core.oam.dev/v1alpha2
+  kind: Component
+  metadata:
+    name: frontend
+- apiVersion: core.oam.dev/v1alpha2
+  kind: ApplicationConfiguration
+  metadata:
+    name: example-appconfig
+    namespace: prod
+  spec:
+    components:
+    - componentName: frontend
+`
+	expected := input + `results:
+- severity: warning
+  message: component "frontend" is resolved assuming that the ApplicationConfiguration
+    and Component are both applied to namespace prod
+  resourceRef:
+    name: example-appconfig
+    namespace: prod
+    apiVersion: core.oam.dev/v1alpha2
+    kind: ApplicationConfiguration
+  field: spec.components[0].componentName
+`
+	var out, results bytes.Buffer
+	if err := run(bytes.NewBufferString(input), &out,
+		&framework.Logger{Writer: &results}); err != nil {
+		t.Fatal(err)
+	}
+	// the warnings are written to the ResourceList for the next function
+	if out.String() != expected {
+		t.Fatalf("expected %s\nbut got %s\n", expected, out.String())
+	}
+	if !strings.HasPrefix(results.String(), "[warning] ") {
+		t.Fatalf("expected the warning to be logged\nbut got %s\n", results.String())
+	}
+}
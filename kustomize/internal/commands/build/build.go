@@ -4,8 +4,11 @@
 package build
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -23,6 +26,8 @@ import (
 type Options struct {
 	kustomizationPath string
 	outputPath        string
+	outputPattern     string
+	pruneOutput       bool
 	outOrder          reorderOutput
 }
 
@@ -31,6 +36,7 @@ func NewOptions(p, o string) *Options {
 	return &Options{
 		kustomizationPath: p,
 		outputPath:        o,
+		outputPattern:     defaultOutputPattern,
 	}
 }
 
@@ -72,9 +78,22 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVarP(
 		&o.outputPath,
 		"output", "o", "",
-		"If specified, write the build output to this path.")
+		"If specified, write the build output to this path. "+
+			"If the path is a directory, or ends with a /, each resource "+
+			"is written to its own file in it.")
+	cmd.Flags().StringVar(
+		&o.outputPattern,
+		"output-pattern", defaultOutputPattern,
+		"The path of the file of each resource in the -o directory, "+
+			"expanding {namespace}, {group}, {version}, {kind} and {name}. "+
+			"The {namespace} of cluster-scoped resources is "+clusterDir+".")
+	cmd.Flags().BoolVar(
+		&o.pruneOutput,
+		"prune-output", false,
+		"Remove the .yaml files of the -o directory which aren't written by this build.")
 	addFlagLoadRestrictor(cmd.Flags())
 	addFlagEnablePlugins(cmd.Flags())
+	addFlagEnableExec(cmd.Flags())
 	addFlagReorderOutput(cmd.Flags())
 	cmd.AddCommand(NewCmdBuildPrune(out))
 	return cmd
@@ -115,6 +134,7 @@ func (o *Options) makeOptions() *krusty.Options {
 	} else {
 		opts.PluginConfig = konfig.DisabledPluginConfig()
 	}
+	opts.PluginConfig.EnableExec = isFlagEnableExecSet()
 	return opts
 }
 
@@ -142,8 +162,13 @@ func (o *Options) RunBuildPrune(out io.Writer) error {
 
 func (o *Options) emitResources(
 	out io.Writer, fSys filesys.FileSystem, m resmap.ResMap) error {
-	if o.outputPath != "" && fSys.IsDir(o.outputPath) {
-		return writeIndividualFiles(fSys, o.outputPath, m)
+	if o.outputPath != "" &&
+		(strings.HasSuffix(o.outputPath, "/") || fSys.IsDir(o.outputPath)) {
+		return writeIndividualFiles(
+			fSys, o.outputPath, o.outputPattern, o.pruneOutput, m)
+	}
+	if o.pruneOutput {
+		return errors.New("--prune-output requires -o to be a directory")
 	}
 	res, err := m.AsYaml()
 	if err != nil {
@@ -174,40 +199,107 @@ func NewCmdBuildPrune(out io.Writer) *cobra.Command {
 	return cmd
 }
 
+// defaultOutputPattern is the default of --output-pattern.
+const defaultOutputPattern = "{namespace}/{kind}_{name}.yaml"
+
+// clusterDir is the {namespace} of the cluster-scoped resources.
+const clusterDir = "_cluster"
+
+// writeIndividualFiles writes each resource of m to its own file
+// below folderPath, at the path given by expanding pattern.  The
+// .yaml files of a previous build which aren't written again are
+// removed if prune is set.
 func writeIndividualFiles(
-	fSys filesys.FileSystem, folderPath string, m resmap.ResMap) error {
-	byNamespace := m.GroupedByCurrentNamespace()
-	for namespace, resList := range byNamespace {
-		for _, res := range resList {
-			fName := fileName(res)
-			if len(byNamespace) > 1 {
-				fName = strings.ToLower(namespace) + "_" + fName
-			}
-			err := writeFile(fSys, folderPath, fName, res)
-			if err != nil {
-				return err
-			}
+	fSys filesys.FileSystem, folderPath, pattern string,
+	prune bool, m resmap.ResMap) error {
+	files := map[string]*resource.Resource{}
+	var fNames []string
+	for _, res := range m.Resources() {
+		fName, err := outputFileName(pattern, res)
+		if err != nil {
+			return err
+		}
+		if other, found := files[fName]; found {
+			return fmt.Errorf(
+				"resources %s and %s would both be written to %s",
+				other.CurId(), res.CurId(), fName)
+		}
+		files[fName] = res
+		fNames = append(fNames, fName)
+	}
+	for _, fName := range fNames {
+		err := writeFile(fSys, folderPath, fName, files[fName])
+		if err != nil {
+			return err
 		}
 	}
-	for _, res := range m.NonNamespaceable() {
-		err := writeFile(fSys, folderPath, fileName(res), res)
+	if !prune {
+		return nil
+	}
+	dir, _, err := fSys.CleanedAbs(folderPath)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	err = fSys.Walk(dir.String(), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(p) != ".yaml" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir.String(), p)
 		if err != nil {
 			return err
 		}
+		if _, found := files[filepath.ToSlash(rel)]; !found {
+			stale = append(stale, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, p := range stale {
+		if err := fSys.RemoveAll(p); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func fileName(res *resource.Resource) string {
-	return strings.ToLower(res.GetGvk().String()) +
-		"_" + strings.ToLower(res.GetName()) + ".yaml"
+// outputFileName expands the {namespace}, {group}, {version},
+// {kind} and {name} of the resource in pattern, lowercased.
+// The {namespace} of the cluster-scoped resources is _cluster.
+func outputFileName(pattern string, res *resource.Resource) (string, error) {
+	id := res.CurId()
+	namespace := clusterDir
+	if id.IsNamespaceableKind() {
+		namespace = id.EffectiveNamespace()
+	}
+	fName := path.Clean(strings.NewReplacer(
+		"{namespace}", strings.ToLower(namespace),
+		"{group}", strings.ToLower(id.Group),
+		"{version}", strings.ToLower(id.Version),
+		"{kind}", strings.ToLower(id.Kind),
+		"{name}", strings.ToLower(res.GetName()),
+	).Replace(pattern))
+	if path.IsAbs(fName) || fName == ".." || strings.HasPrefix(fName, "../") {
+		return "", fmt.Errorf(
+			"the file %s of %s is outside of the output directory", fName, id)
+	}
+	return fName, nil
 }
 
 func writeFile(
-	fSys filesys.FileSystem, path, fName string, res *resource.Resource) error {
+	fSys filesys.FileSystem, dir, fName string, res *resource.Resource) error {
 	out, err := yaml.Marshal(res.Map())
 	if err != nil {
 		return err
 	}
-	return fSys.WriteFile(filepath.Join(path, fName), out)
+	fPath := filepath.Join(dir, filepath.FromSlash(fName))
+	if err := fSys.MkdirAll(filepath.Dir(fPath)); err != nil {
+		return err
+	}
+	return fSys.WriteFile(fPath, out)
 }
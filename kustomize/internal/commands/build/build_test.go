@@ -4,10 +4,15 @@
 package build
 
 import (
+	"path/filepath"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	resmaptest_test "sigs.k8s.io/kustomize/api/testutils/resmaptest"
 )
 
 func TestNewOptionsToSilenceCodeInspectionError(t *testing.T) {
@@ -52,3 +57,111 @@ func TestBuildValidate(t *testing.T) {
 		}
 	}
 }
+
+func makeOutputResMap(t *testing.T) resmap.ResMap {
+	rf := resource.NewFactory(kunstruct.NewKunstructuredFactoryImpl())
+	return resmaptest_test.NewRmBuilder(t, rf).
+		Add(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "app",
+				"namespace": "prod",
+			},
+		}).
+		Add(map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name": "App",
+			},
+		}).
+		Add(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata": map[string]interface{}{
+				"name": "prod",
+			},
+		}).ResMap()
+}
+
+func TestWriteIndividualFiles(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	stale := filepath.Join("out", "prod", "secret_old.yaml")
+	if err := fSys.WriteFile(stale, []byte("kind: Secret\n")); err != nil {
+		t.Fatal(err)
+	}
+	readme := filepath.Join("out", "README.md")
+	if err := fSys.WriteFile(readme, []byte("generated\n")); err != nil {
+		t.Fatal(err)
+	}
+	o := NewOptions(filesys.SelfDir, "out/")
+	o.pruneOutput = true
+	if err := o.emitResources(nil, fSys, makeOutputResMap(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		filepath.Join("out", "prod", "configmap_app.yaml"): `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: prod
+`,
+		filepath.Join("out", "default", "deployment_app.yaml"): `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: App
+`,
+		filepath.Join("out", "_cluster", "namespace_prod.yaml"): `apiVersion: v1
+kind: Namespace
+metadata:
+  name: prod
+`,
+	}
+	for fName, content := range expected {
+		b, err := fSys.ReadFile(fName)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(b) != content {
+			t.Errorf("%s: expected\n%s\ngot\n%s", fName, content, b)
+		}
+	}
+	if fSys.Exists(stale) {
+		t.Errorf("expected %s to be pruned", stale)
+	}
+	if !fSys.Exists(readme) {
+		t.Errorf("expected %s to be kept", readme)
+	}
+}
+
+func TestWriteIndividualFilesErrors(t *testing.T) {
+	var cases = []struct {
+		name    string
+		pattern string
+		out     string
+		erMsg   string
+	}{
+		{"collision", "{name}.yaml", "out/",
+			"resources ~G_v1_ConfigMap|prod|app and apps_v1_Deployment|~X|App " +
+				"would both be written to app.yaml"},
+		{"outside", "../{kind}_{name}.yaml", "out/",
+			"the file ../configmap_app.yaml of ~G_v1_ConfigMap|prod|app " +
+				"is outside of the output directory"},
+		{"prune", defaultOutputPattern, "out.yaml",
+			"--prune-output requires -o to be a directory"},
+	}
+	for _, mycase := range cases {
+		o := NewOptions(filesys.SelfDir, mycase.out)
+		o.outputPattern = mycase.pattern
+		o.pruneOutput = true
+		err := o.emitResources(nil, filesys.MakeFsInMemory(), makeOutputResMap(t))
+		if err == nil {
+			t.Errorf("%s: expected an error %v", mycase.name, mycase.erMsg)
+			continue
+		}
+		if err.Error() != mycase.erMsg {
+			t.Errorf("%s: expected error %s, but got %v", mycase.name, mycase.erMsg, err)
+		}
+	}
+}
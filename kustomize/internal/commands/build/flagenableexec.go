@@ -0,0 +1,30 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEnableExecName = "enable_exec"
+	flagEnableExecHelp = `enable the commands of the secretGenerators.
+The commands run in the directory of their kustomization,
+including the kustomizations of remote bases.
+`
+)
+
+var (
+	flagExecEnabledValue = false
+)
+
+func addFlagEnableExec(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagExecEnabledValue, flagEnableExecName,
+		false, flagEnableExecHelp)
+}
+
+func isFlagEnableExecSet() bool {
+	return flagExecEnabledValue
+}
@@ -26,7 +26,8 @@ var (
 	flagReorderOutputValue = legacy.String()
 	flagReorderOutputHelp  = "Reorder the resources just before output. " +
 		"Use '" + legacy.String() + "' to apply a legacy reordering (Namespaces first, Webhooks last, etc). " +
-		"Use '" + none.String() + "' to suppress a final reordering."
+		"Use '" + none.String() + "' to suppress a final reordering. " +
+		"The sortOptions of the kustomization, if any, win over this flag."
 )
 
 func addFlagReorderOutput(set *pflag.FlagSet) {
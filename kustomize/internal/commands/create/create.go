@@ -5,6 +5,7 @@ package create
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,6 +27,7 @@ type createFlags struct {
 	suffix          string
 	detectResources bool
 	detectRecursive bool
+	force           bool
 	path            string
 }
 
@@ -43,11 +45,15 @@ func NewCmdCreate(fSys filesys.FileSystem, uf ifc.KunstructuredFactory) *cobra.C
 	# Create a new kustomization detecting resources in the current directory.
 	kustomize create --autodetect
 
+	# Create a kustomization in the current directory and in each of its
+	# subdirectories with resources, which refer to their subdirectories.
+	kustomize create --autodetect --recursive
+
 	# Create a new kustomization with multiple resources and fields set.
 	kustomize create --resources deployment.yaml,service.yaml,../base --namespace staging --nameprefix acme-
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCreate(opts, fSys, uf)
+			return runCreate(opts, fSys, uf, cmd.OutOrStderr())
 		},
 	}
 	c.Flags().StringVar(
@@ -89,11 +95,18 @@ func NewCmdCreate(fSys filesys.FileSystem, uf ifc.KunstructuredFactory) *cobra.C
 		&opts.detectRecursive,
 		"recursive",
 		false,
-		"Enable recursive directory searching for resource auto-detection.")
+		"Enable recursive directory searching for resource auto-detection, "+
+			"creating a kustomization file in each subdirectory with resources.")
+	c.Flags().BoolVar(
+		&opts.force,
+		"force",
+		false,
+		"Overwrite the kustomization files of the subdirectories with --recursive, "+
+			"instead of adding the subdirectories as they are.")
 	return c
 }
 
-func runCreate(opts createFlags, fSys filesys.FileSystem, uf ifc.KunstructuredFactory) error {
+func runCreate(opts createFlags, fSys filesys.FileSystem, uf ifc.KunstructuredFactory, out io.Writer) error {
 	var resources []string
 	var err error
 	if opts.resources != "" {
@@ -106,7 +119,7 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, uf ifc.KunstructuredFa
 		return fmt.Errorf("kustomization file already exists")
 	}
 	if opts.detectResources {
-		detected, err := detectResources(fSys, uf, opts.path, opts.detectRecursive)
+		detected, err := detectResources(fSys, uf, out, opts.path, opts.detectRecursive, opts.force)
 		if err != nil {
 			return err
 		}
@@ -147,7 +160,13 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, uf ifc.KunstructuredFa
 	return mf.Write(m)
 }
 
-func detectResources(fSys filesys.FileSystem, uf ifc.KunstructuredFactory, base string, recursive bool) ([]string, error) {
+// detectResources returns the paths of the kubernetes resources in base.
+// The files which aren't kubernetes resources are noticed on out.
+// If recursive is set, a kustomization file is created in each subdirectory
+// with resources and the subdirectory is returned instead of its resources.
+// The paths are relative to base then.  Subdirectories which already have a
+// kustomization file are returned as they are, unless force is set.
+func detectResources(fSys filesys.FileSystem, uf ifc.KunstructuredFactory, out io.Writer, base string, recursive, force bool) ([]string, error) {
 	var paths []string
 	err := fSys.Walk(base, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -156,29 +175,121 @@ func detectResources(fSys filesys.FileSystem, uf ifc.KunstructuredFactory, base
 		if path == base {
 			return nil
 		}
+		// the paths are relative to the kustomization files created with recursive
+		entry := path
+		if recursive {
+			if entry, err = filepath.Rel(base, path); err != nil {
+				return err
+			}
+		}
 		if info.IsDir() {
 			if !recursive {
 				return filepath.SkipDir
 			}
 			// If a sub-directory contains an existing kustomization file add the
 			// directory as a resource and do not decend into it.
-			for _, kfilename := range konfig.RecognizedKustomizationFileNames() {
-				if fSys.Exists(filepath.Join(path, kfilename)) {
-					paths = append(paths, path)
-					return filepath.SkipDir
-				}
+			if !force && hasKustomizationFile(fSys, path) {
+				paths = append(paths, entry)
+				return filepath.SkipDir
+			}
+			created, err := createSubKustomization(fSys, uf, out, path, force)
+			if err != nil {
+				return err
+			}
+			if created {
+				paths = append(paths, entry)
 			}
+			return filepath.SkipDir
+		}
+		if isKustomizationFile(path) {
 			return nil
 		}
 		fContents, err := fSys.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		if _, err := uf.SliceFromBytes(fContents); err != nil {
+		if !isResource(uf, fContents) {
+			if isManifestFile(path) {
+				fmt.Fprintf(out, "skipping %s: not a kubernetes resource with an apiVersion and a kind\n", path)
+			}
 			return nil
 		}
-		paths = append(paths, path)
+		paths = append(paths, entry)
 		return nil
 	})
 	return paths, err
 }
+
+// createSubKustomization detects the resources in the subdirectory dir, and
+// writes them to a new kustomization file in dir, replacing the existing one.
+// Returns false if dir doesn't have any resources, in which case it is left
+// as it is.
+func createSubKustomization(fSys filesys.FileSystem, uf ifc.KunstructuredFactory, out io.Writer, dir string, force bool) (bool, error) {
+	resources, err := detectResources(fSys, uf, out, dir, true, force)
+	if err != nil || len(resources) == 0 {
+		return false, err
+	}
+	for _, kfilename := range konfig.RecognizedKustomizationFileNames() {
+		if path := filepath.Join(dir, kfilename); fSys.Exists(path) {
+			if err := fSys.RemoveAll(path); err != nil {
+				return false, err
+			}
+		}
+	}
+	f, err := fSys.Create(filepath.Join(dir, konfig.DefaultKustomizationFileName()))
+	if err != nil {
+		return false, err
+	}
+	f.Close()
+	mf, err := kustfile.NewKustomizationFileInDir(fSys, dir)
+	if err != nil {
+		return false, err
+	}
+	m, err := mf.Read()
+	if err != nil {
+		return false, err
+	}
+	m.Resources = resources
+	return true, mf.Write(m)
+}
+
+// hasKustomizationFile returns true if dir contains a kustomization file.
+func hasKustomizationFile(fSys filesys.FileSystem, dir string) bool {
+	for _, kfilename := range konfig.RecognizedKustomizationFileNames() {
+		if fSys.Exists(filepath.Join(dir, kfilename)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKustomizationFile returns true if path is the name of a kustomization file,
+// which is never a resource.
+func isKustomizationFile(path string) bool {
+	return kustfile.StringInSlice(filepath.Base(path), konfig.RecognizedKustomizationFileNames())
+}
+
+// isResource returns true if content holds one or more kubernetes resources,
+// each with an apiVersion and a kind.
+func isResource(uf ifc.KunstructuredFactory, content []byte) bool {
+	objs, err := uf.SliceFromBytes(content)
+	if err != nil || len(objs) == 0 {
+		return false
+	}
+	for _, obj := range objs {
+		if gvk := obj.GetGvk(); gvk.Version == "" || gvk.Kind == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isManifestFile returns true if path has the extension of a file
+// which may hold kubernetes resources, so that skipping it is noticed.
+func isManifestFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
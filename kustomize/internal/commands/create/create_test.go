@@ -4,11 +4,15 @@
 package create
 
 import (
+	"bytes"
+	"io/ioutil"
 	"reflect"
+	"sort"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
+	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/kustfile"
 )
@@ -26,6 +30,19 @@ func readKustomizationFS(t *testing.T, fSys filesys.FileSystem) *types.Kustomiza
 	}
 	return m
 }
+
+func readKustomizationInDir(t *testing.T, fSys filesys.FileSystem, dir string) *types.Kustomization {
+	kf, err := kustfile.NewKustomizationFileInDir(fSys, dir)
+	if err != nil {
+		t.Fatalf("unexpected new error %v", err)
+	}
+	m, err := kf.Read()
+	if err != nil {
+		t.Fatalf("unexpected read error %v", err)
+	}
+	return m
+}
+
 func TestCreateNoArgs(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	cmd := NewCmdCreate(fSys, factory)
@@ -41,7 +58,7 @@ func TestCreateWithResources(t *testing.T) {
 	fSys.WriteFile("foo.yaml", []byte(""))
 	fSys.WriteFile("bar.yaml", []byte(""))
 	opts := createFlags{resources: "foo.yaml,bar.yaml"}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, ioutil.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -56,7 +73,7 @@ func TestCreateWithNamespace(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	want := "foo"
 	opts := createFlags{namespace: want}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, ioutil.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -70,7 +87,7 @@ func TestCreateWithNamespace(t *testing.T) {
 func TestCreateWithLabels(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	opts := createFlags{labels: "foo:bar"}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, ioutil.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -84,7 +101,7 @@ func TestCreateWithLabels(t *testing.T) {
 func TestCreateWithAnnotations(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	opts := createFlags{annotations: "foo:bar"}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, ioutil.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -99,7 +116,7 @@ func TestCreateWithNamePrefix(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	want := "foo-"
 	opts := createFlags{prefix: want}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, ioutil.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -113,7 +130,7 @@ func TestCreateWithNamePrefix(t *testing.T) {
 func TestCreateWithNameSuffix(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	opts := createFlags{suffix: "-foo"}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, ioutil.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -168,7 +185,8 @@ func TestCreateWithDetect(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	writeDetectContent(fSys)
 	opts := createFlags{path: "/", detectResources: true}
-	err := runCreate(opts, fSys, factory)
+	var out bytes.Buffer
+	err := runCreate(opts, fSys, factory, &out)
 	if err != nil {
 		t.Fatalf("unexpected cmd error: %v", err)
 	}
@@ -177,18 +195,106 @@ func TestCreateWithDetect(t *testing.T) {
 	if !reflect.DeepEqual(m.Resources, expected) {
 		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
 	}
+	notice := "skipping /non-k8s.yaml: not a kubernetes resource with an apiVersion and a kind\n"
+	if out.String() != notice {
+		t.Fatalf("expected %q but got %q", notice, out.String())
+	}
 }
 
 func TestCreateWithDetectRecursive(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	writeDetectContent(fSys)
 	opts := createFlags{path: "/", detectResources: true, detectRecursive: true}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, ioutil.Discard)
 	if err != nil {
 		t.Fatalf("unexpected cmd error: %v", err)
 	}
 	m := readKustomizationFS(t, fSys)
-	expected := []string{"/overlay", "/sub/test.yaml", "/test.yaml"}
+	expected := []string{"overlay", "sub", "test.yaml"}
+	if !reflect.DeepEqual(m.Resources, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
+	}
+	// the resources of the created kustomization file are relative to it too
+	m = readKustomizationInDir(t, fSys, "/sub")
+	expected = []string{"test.yaml"}
+	if !reflect.DeepEqual(m.Resources, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
+	}
+	// the existing kustomization file isn't overwritten
+	content, err := fSys.ReadFile("/overlay/kustomization.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "\nresources:\n- test.yaml" {
+		t.Fatalf("expected /overlay/kustomization.yaml to be unchanged, got %s", content)
+	}
+}
+
+func TestCreateWithDetectRecursiveNested(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeDetectContent(fSys)
+	fSys.MkdirAll("/sub/nested/empty")
+	fSys.WriteFile("/sub/nested/deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test4`))
+	fSys.WriteFile("/sub/nested/empty/non-k8s.yaml", []byte("other: yaml\n"))
+	opts := createFlags{path: "/", detectResources: true, detectRecursive: true}
+	if err := runCreate(opts, fSys, factory, ioutil.Discard); err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	m := readKustomizationInDir(t, fSys, "/sub")
+	expected := []string{"nested", "test.yaml"}
+	if !reflect.DeepEqual(m.Resources, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
+	}
+	m = readKustomizationInDir(t, fSys, "/sub/nested")
+	expected = []string{"deployment.yaml"}
+	if !reflect.DeepEqual(m.Resources, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
+	}
+	// directories without resources don't get a kustomization file
+	if fSys.Exists("/sub/nested/empty/kustomization.yaml") {
+		t.Fatalf("unexpected kustomization file in /sub/nested/empty")
+	}
+
+	// the tree of loose manifests is now a base which can be built
+	rm, err := krusty.MakeKustomizer(fSys, krusty.MakeDefaultOptions()).Run("/")
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	var names []string
+	for _, r := range rm.Resources() {
+		names = append(names, r.GetName())
+	}
+	sort.Strings(names)
+	expected = []string{"test", "test2", "test3", "test4"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, names)
+	}
+}
+
+func TestCreateWithDetectRecursiveForce(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeDetectContent(fSys)
+	fSys.WriteFile("/overlay/other.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other`))
+	opts := createFlags{path: "/", detectResources: true, detectRecursive: true, force: true}
+	if err := runCreate(opts, fSys, factory, ioutil.Discard); err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	m := readKustomizationFS(t, fSys)
+	expected := []string{"overlay", "sub", "test.yaml"}
+	if !reflect.DeepEqual(m.Resources, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
+	}
+	// the existing kustomization file is overwritten with the detected resources
+	m = readKustomizationInDir(t, fSys, "/overlay")
+	expected = []string{"other.yaml", "test.yaml"}
 	if !reflect.DeepEqual(m.Resources, expected) {
 		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
 	}
@@ -5,6 +5,7 @@ package add
 
 import (
 	"errors"
+	"fmt"
 	"log"
 
 	"github.com/spf13/cobra"
@@ -16,6 +17,13 @@ import (
 
 type addPatchOptions struct {
 	patchFilePaths []string
+
+	// patch selects the entry of the patches field which is added,
+	// instead of the patchFilePaths.
+	patch patch.Options
+
+	// force adds the entry even if its patch file doesn't exist.
+	force bool
 }
 
 // newCmdAddPatch adds the name of a file containing a patch to the kustomization file.
@@ -26,7 +34,13 @@ func newCmdAddPatch(fSys filesys.FileSystem) *cobra.Command {
 		Use:   "patch",
 		Short: "Add the name of a file containing a patch to the kustomization file.",
 		Example: `
-		add patch {filepath}`,
+		add patch {filepath}
+
+		# adds an entry with a target to the patches field
+		add patch --path {filepath} --group apps --version v1 --kind Deployment --name web
+
+		# adds an entry with an inline patch
+		add patch --patch '[{"op": "replace", "path": "/spec/replicas", "value": 3}]' --kind Deployment`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
@@ -35,11 +49,20 @@ func newCmdAddPatch(fSys filesys.FileSystem) *cobra.Command {
 			return o.RunAddPatch(fSys)
 		},
 	}
+	o.patch.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.force, "force", false,
+		"Add the patch even if the file of --path doesn't exist.")
 	return cmd
 }
 
 // Validate validates addPatch command.
 func (o *addPatchOptions) Validate(args []string) error {
+	if o.patch.IsSet() {
+		if len(args) > 0 {
+			return errors.New("patch files can't be specified with the --path or --patch flags")
+		}
+		return o.patch.Validate()
+	}
 	if len(args) == 0 {
 		return errors.New("must specify a patch file")
 	}
@@ -49,6 +72,9 @@ func (o *addPatchOptions) Validate(args []string) error {
 
 // RunAddPatch runs addPatch command (do real work).
 func (o *addPatchOptions) RunAddPatch(fSys filesys.FileSystem) error {
+	if o.patch.IsSet() {
+		return o.addPatchEntry(fSys)
+	}
 	patches, err := util.GlobPatterns(fSys, o.patchFilePaths)
 	if err != nil {
 		return err
@@ -77,3 +103,31 @@ func (o *addPatchOptions) RunAddPatch(fSys filesys.FileSystem) error {
 
 	return mf.Write(m)
 }
+
+// addPatchEntry adds the entry selected by the patch flags to the patches
+// field, failing if there is an identical entry.
+func (o *addPatchOptions) addPatchEntry(fSys filesys.FileSystem) error {
+	p := o.patch.Entry()
+	if p.Path != "" && !o.force && !fSys.Exists(p.Path) {
+		return fmt.Errorf("patch file %s doesn't exist, use --force to add it anyway", p.Path)
+	}
+
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range m.Patches {
+		if patch.Equal(existing, p) {
+			return fmt.Errorf("patch %s already in kustomization file", patch.String(p))
+		}
+	}
+	m.Patches = append(m.Patches, p)
+
+	return mf.Write(m)
+}
@@ -74,3 +74,145 @@ func TestAddPatchNoArgs(t *testing.T) {
 		t.Errorf("incorrect error: %v", err.Error())
 	}
 }
+
+func TestAddPatchEntry(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	fSys.WriteFile(patchFileName, []byte(patchFileContent))
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+# the patches of the web Deployment
+patches:
+- path: other.yaml
+`))
+
+	cmd := newCmdAddPatch(fSys)
+	for flag, value := range map[string]string{
+		"path": patchFileName, "group": "apps", "version": "v1", "kind": "Deployment", "name": "web",
+	} {
+		if err := cmd.Flags().Set(flag, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	expected := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+# the patches of the web Deployment
+patches:
+- path: other.yaml
+- path: ` + patchFileName + `
+  target:
+    group: apps
+    kind: Deployment
+    name: web
+    version: v1
+`
+	if string(content) != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s\n", expected, content)
+	}
+
+	// an identical entry is rejected
+	err = cmd.RunE(cmd, nil)
+	if err == nil || err.Error() != "patch "+patchFileName+
+		" with target apps_v1_Deployment name=web already in kustomization file" {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+}
+
+func TestAddPatchEntryInline(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+`))
+
+	cmd := newCmdAddPatch(fSys)
+	cmd.Flags().Set("patch", `[{"op": "replace", "path": "/spec/replicas", "value": 3}]`)
+	cmd.Flags().Set("kind", "Deployment")
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	expected := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+patches:
+- patch: '[{"op": "replace", "path": "/spec/replicas", "value": 3}]'
+  target:
+    kind: Deployment
+`
+	if string(content) != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s\n", expected, content)
+	}
+}
+
+func TestAddPatchEntryMissingFile(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+
+	cmd := newCmdAddPatch(fSys)
+	cmd.Flags().Set("path", patchFileName)
+	err := cmd.RunE(cmd, nil)
+	if err == nil || err.Error() != "patch file "+patchFileName+
+		" doesn't exist, use --force to add it anyway" {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+
+	cmd.Flags().Set("force", "true")
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if !strings.Contains(string(content), "patches:\n- path: "+patchFileName+"\n") {
+		t.Errorf("expected patch path in kustomization")
+	}
+}
+
+func TestAddPatchEntryInvalidFlags(t *testing.T) {
+	for _, test := range []struct {
+		flags    map[string]string
+		args     []string
+		expected string
+	}{
+		{
+			flags:    map[string]string{"path": patchFileName, "patch": "{}"},
+			expected: "must specify only one of --path and --patch",
+		},
+		{
+			flags:    map[string]string{"kind": "Deployment"},
+			expected: "must specify one of --path and --patch",
+		},
+		{
+			flags:    map[string]string{"path": patchFileName},
+			args:     []string{patchFileName},
+			expected: "patch files can't be specified with the --path or --patch flags",
+		},
+	} {
+		fSys := filesys.MakeEmptyDirInMemory()
+		cmd := newCmdAddPatch(fSys)
+		for flag, value := range test.flags {
+			cmd.Flags().Set(flag, value)
+		}
+		err := cmd.RunE(cmd, test.args)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("expected error %s but got %v", test.expected, err)
+		}
+	}
+}
@@ -30,6 +30,7 @@ func NewCmdAdd(
 
 	# Adds a patch to the kustomization
 	kustomize edit add patch <filepath>
+	kustomize edit add patch --path <filepath> --kind Deployment --name web
 
 	# Adds one or more base directories to the kustomization
 	kustomize edit add base <filepath>
@@ -0,0 +1,108 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package patch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// Options are the flags of the add and remove patch commands which
+// select an entry of the patches field, i.e. its patch and its target.
+type Options struct {
+	Path               string
+	Patch              string
+	Group              string
+	Version            string
+	Kind               string
+	Name               string
+	Namespace          string
+	LabelSelector      string
+	AnnotationSelector string
+}
+
+// AddFlags adds the flags of o to cmd.
+func (o *Options) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Path, "path", "", "Path to the patch file.")
+	cmd.Flags().StringVar(&o.Patch, "patch", "", "Inline content of the patch.")
+	cmd.Flags().StringVar(&o.Group, "group", "", "API group of the target resources.")
+	cmd.Flags().StringVar(&o.Version, "version", "", "API version of the target resources.")
+	cmd.Flags().StringVar(&o.Kind, "kind", "", "Kind of the target resources.")
+	cmd.Flags().StringVar(&o.Name, "name", "", "Name of the target resources.")
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "", "Namespace of the target resources.")
+	cmd.Flags().StringVar(&o.LabelSelector, "label-selector", "",
+		"Label selector of the target resources.")
+	cmd.Flags().StringVar(&o.AnnotationSelector, "annotation-selector", "",
+		"Annotation selector of the target resources.")
+}
+
+// IsSet returns true if any of the flags of o are set.
+func (o Options) IsSet() bool {
+	return o != Options{}
+}
+
+// Validate returns an error unless exactly one of Path and Patch is set.
+func (o Options) Validate() error {
+	if o.Path == "" && o.Patch == "" {
+		return fmt.Errorf("must specify one of --path and --patch")
+	}
+	if o.Path != "" && o.Patch != "" {
+		return fmt.Errorf("must specify only one of --path and --patch")
+	}
+	return nil
+}
+
+// Entry returns the entry of the patches field selected by o.
+// Its target is nil if none of the target flags are set.
+func (o Options) Entry() types.Patch {
+	p := types.Patch{Path: o.Path, Patch: o.Patch}
+	target := types.Selector{
+		Gvk:                resid.Gvk{Group: o.Group, Version: o.Version, Kind: o.Kind},
+		Name:               o.Name,
+		Namespace:          o.Namespace,
+		LabelSelector:      o.LabelSelector,
+		AnnotationSelector: o.AnnotationSelector,
+	}
+	if target != (types.Selector{}) {
+		p.Target = &target
+	}
+	return p
+}
+
+// Equal returns true if a and b are the same entry of the patches field.
+// An empty target is the same as a nil target.
+func Equal(a, b types.Patch) bool {
+	if a.Path != b.Path || a.Patch != b.Patch || len(a.Options) != len(b.Options) {
+		return false
+	}
+	for k, v := range a.Options {
+		if w, found := b.Options[k]; !found || v != w {
+			return false
+		}
+	}
+	var at, bt types.Selector
+	if a.Target != nil {
+		at = *a.Target
+	}
+	if b.Target != nil {
+		bt = *b.Target
+	}
+	return at == bt
+}
+
+// String returns a description of the entry p for the messages of the
+// commands, e.g. `patch.yaml with target apps_v1_Deployment name=web`.
+func String(p types.Patch) string {
+	s := p.Path
+	if s == "" {
+		s = "inline patch"
+	}
+	if p.Target != nil {
+		s += " with target " + p.Target.String()
+	}
+	return s
+}
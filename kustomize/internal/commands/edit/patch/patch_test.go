@@ -0,0 +1,46 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package patch
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestEntry(t *testing.T) {
+	p := Options{Path: "patch.yaml"}.Entry()
+	if p.Path != "patch.yaml" || p.Target != nil {
+		t.Fatalf("expected an entry without a target, got %v", p)
+	}
+
+	p = Options{Patch: "{}", Kind: "Deployment", Name: "web"}.Entry()
+	expected := types.Selector{Gvk: resid.Gvk{Kind: "Deployment"}, Name: "web"}
+	if p.Patch != "{}" || p.Target == nil || *p.Target != expected {
+		t.Fatalf("expected an entry with target %v, got %v", expected, p)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	web := &types.Selector{Gvk: resid.Gvk{Kind: "Deployment"}, Name: "web"}
+	for _, test := range []struct {
+		a, b     types.Patch
+		expected bool
+	}{
+		{types.Patch{Path: "a.yaml"}, types.Patch{Path: "a.yaml"}, true},
+		{types.Patch{Path: "a.yaml"}, types.Patch{Path: "b.yaml"}, false},
+		{types.Patch{Path: "a.yaml"}, types.Patch{Patch: "a.yaml"}, false},
+		{types.Patch{Path: "a.yaml", Target: web}, types.Patch{Path: "a.yaml",
+			Target: &types.Selector{Gvk: resid.Gvk{Kind: "Deployment"}, Name: "web"}}, true},
+		{types.Patch{Path: "a.yaml", Target: web}, types.Patch{Path: "a.yaml"}, false},
+		{types.Patch{Path: "a.yaml", Target: &types.Selector{}}, types.Patch{Path: "a.yaml"}, true},
+		{types.Patch{Path: "a.yaml", Options: map[string]bool{types.AllowNoTargetMatch: true}},
+			types.Patch{Path: "a.yaml"}, false},
+	} {
+		if Equal(test.a, test.b) != test.expected {
+			t.Errorf("expected Equal(%v, %v) to be %v", test.a, test.b, test.expected)
+		}
+	}
+}
@@ -24,6 +24,7 @@ func NewCmdRemove(
 
 	# Removes one or more patches from the kustomization file
 	kustomize edit remove patch <filepath>
+	kustomize edit remove patch --path <filepath> --kind Deployment --name web
 
 	# Removes one or more commonLabels from the kustomization file
 	kustomize edit remove label {labelKey1},{labelKey2}
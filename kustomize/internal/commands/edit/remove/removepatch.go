@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/patch"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/kustfile"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/util"
@@ -17,6 +18,10 @@ import (
 
 type removePatchOptions struct {
 	patchFilePaths []string
+
+	// patch selects the entry of the patches field which is removed,
+	// instead of the patchFilePaths.
+	patch patch.Options
 }
 
 // newCmdRemovePatch removes the name of a file containing a patch from the kustomization file.
@@ -28,7 +33,10 @@ func newCmdRemovePatch(fSys filesys.FileSystem) *cobra.Command {
 		Short: "Removes one or more patches from " +
 			konfig.DefaultKustomizationFileName(),
 		Example: `
-		remove patch {filepath}`,
+		remove patch {filepath}
+
+		# removes the entry of the patches field with the same patch and target
+		remove patch --path {filepath} --group apps --version v1 --kind Deployment --name web`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
@@ -37,11 +45,18 @@ func newCmdRemovePatch(fSys filesys.FileSystem) *cobra.Command {
 			return o.RunRemovePatch(fSys)
 		},
 	}
+	o.patch.AddFlags(cmd)
 	return cmd
 }
 
 // Validate validates removePatch command.
 func (o *removePatchOptions) Validate(args []string) error {
+	if o.patch.IsSet() {
+		if len(args) > 0 {
+			return errors.New("patch files can't be specified with the --path or --patch flags")
+		}
+		return o.patch.Validate()
+	}
 	if len(args) == 0 {
 		return errors.New("must specify a patch file")
 	}
@@ -51,6 +66,9 @@ func (o *removePatchOptions) Validate(args []string) error {
 
 // RunRemovePatch runs removePatch command (do real work).
 func (o *removePatchOptions) RunRemovePatch(fSys filesys.FileSystem) error {
+	if o.patch.IsSet() {
+		return o.removePatchEntry(fSys)
+	}
 	patches, err := util.GlobPatterns(fSys, o.patchFilePaths)
 	if err != nil {
 		return err
@@ -81,3 +99,32 @@ func (o *removePatchOptions) RunRemovePatch(fSys filesys.FileSystem) error {
 
 	return mf.Write(m)
 }
+
+// removePatchEntry removes the entries of the patches field which are the
+// same as the one selected by the patch flags.
+func (o *removePatchOptions) removePatchEntry(fSys filesys.FileSystem) error {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+
+	p := o.patch.Entry()
+	var patches []types.Patch
+	for _, existing := range m.Patches {
+		if !patch.Equal(existing, p) {
+			patches = append(patches, existing)
+		}
+	}
+	if len(patches) == len(m.Patches) {
+		log.Printf("patch %s doesn't exist in kustomization file", patch.String(p))
+		return nil
+	}
+	m.Patches = patches
+
+	return mf.Write(m)
+}
@@ -135,3 +135,57 @@ func TestRemovePatchNoArgs(t *testing.T) {
 		t.Errorf("incorrect error: %v", err.Error())
 	}
 }
+
+func TestRemovePatchEntry(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+# the patches of the web Deployment
+patches:
+- path: patch1.yaml
+  target:
+    kind: Deployment
+    name: web
+- path: patch1.yaml
+- patch: '{}'
+  target:
+    kind: Deployment
+`))
+
+	cmd := newCmdRemovePatch(fSys)
+	cmd.Flags().Set("path", "patch1.yaml")
+	cmd.Flags().Set("kind", "Deployment")
+	cmd.Flags().Set("name", "web")
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	// only the entry with the same patch and target is removed
+	expected := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+# the patches of the web Deployment
+patches:
+- path: patch1.yaml
+- patch: '{}'
+  target:
+    kind: Deployment
+`
+	if string(content) != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s\n", expected, content)
+	}
+
+	// removing a missing entry leaves the kustomization file unchanged
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	content, err = testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if string(content) != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s\n", expected, content)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strings"
@@ -42,9 +43,12 @@ func determineFieldOrder() []string {
 		"Bases",
 		"NamePrefix",
 		"NameSuffix",
+		"NameTransformations",
 		"Namespace",
+		"NamespaceOptions",
 		"Crds",
 		"CommonLabels",
+		"Labels",
 		"CommonAnnotations",
 		"PatchesStrategicMerge",
 		"PatchesJson6902",
@@ -53,12 +57,14 @@ func determineFieldOrder() []string {
 		"SecretGenerator",
 		"GeneratorOptions",
 		"Vars",
+		"Replacements",
 		"Images",
 		"Replicas",
 		"Configurations",
 		"Generators",
 		"Transformers",
 		"Inventory",
+		"SortOptions",
 	}
 
 	// Add deprecated fields here.
@@ -101,6 +107,7 @@ func squash(x [][]byte) []byte {
 }
 
 type kustomizationFile struct {
+	dir            string
 	path           string
 	fSys           filesys.FileSystem
 	originalFields []*commentedField
@@ -108,7 +115,13 @@ type kustomizationFile struct {
 
 // NewKustomizationFile returns a new instance.
 func NewKustomizationFile(fSys filesys.FileSystem) (*kustomizationFile, error) { // nolint
-	mf := &kustomizationFile{fSys: fSys}
+	return NewKustomizationFileInDir(fSys, "")
+}
+
+// NewKustomizationFileInDir returns a new instance for the kustomization
+// file in dir, rather than in the current directory.
+func NewKustomizationFileInDir(fSys filesys.FileSystem, dir string) (*kustomizationFile, error) { // nolint
+	mf := &kustomizationFile{fSys: fSys, dir: dir}
 	err := mf.validate()
 	if err != nil {
 		return nil, err
@@ -120,9 +133,9 @@ func (mf *kustomizationFile) validate() error {
 	match := 0
 	var path []string
 	for _, kfilename := range konfig.RecognizedKustomizationFileNames() {
-		if mf.fSys.Exists(kfilename) {
+		if mf.fSys.Exists(filepath.Join(mf.dir, kfilename)) {
 			match += 1
-			path = append(path, kfilename)
+			path = append(path, filepath.Join(mf.dir, kfilename))
 		}
 	}
 
@@ -22,9 +22,12 @@ func TestFieldOrder(t *testing.T) {
 		"Bases",
 		"NamePrefix",
 		"NameSuffix",
+		"NameTransformations",
 		"Namespace",
+		"NamespaceOptions",
 		"Crds",
 		"CommonLabels",
+		"Labels",
 		"CommonAnnotations",
 		"PatchesStrategicMerge",
 		"PatchesJson6902",
@@ -33,12 +36,14 @@ func TestFieldOrder(t *testing.T) {
 		"SecretGenerator",
 		"GeneratorOptions",
 		"Vars",
+		"Replacements",
 		"Images",
 		"Replicas",
 		"Configurations",
 		"Generators",
 		"Transformers",
 		"Inventory",
+		"SortOptions",
 	}
 	actual := determineFieldOrder()
 	if len(expected) != len(actual) {
@@ -0,0 +1,58 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package framework contains a framework for writing functions in go.
+//
+// A function declares a struct for its functionConfig, and a kio.Filter
+// which is run over the items of the ResourceList:
+//
+//	type ScalerConfig struct {
+//		Spec struct {
+//			Replicas int `yaml:"replicas" framework:"required"`
+//		} `yaml:"spec"`
+//	}
+//
+//	func main() {
+//		config := &ScalerConfig{}
+//		p := framework.ResourceListProcessor{
+//			FunctionConfig: config,
+//			Filter: kio.FilterFunc(func(items []*yaml.RNode) ([]*yaml.RNode, error) {
+//				// use config.Spec.Replicas to modify the items
+//				return items, nil
+//			}),
+//		}
+//		if err := p.Execute(); err != nil {
+//			fmt.Fprintln(os.Stderr, err)
+//			os.Exit(1)
+//		}
+//	}
+//
+// ResourceListProcessor reads the ResourceList from stdin, decodes its
+// functionConfig into the struct, runs the Filter and writes the
+// ResourceList to stdout.  Fields of the functionConfig which aren't in
+// the struct are reported as warnings in the results of the ResourceList,
+// and fields tagged with `framework:"required"` which aren't set are
+// returned as an error.
+//
+// Tests may set the Reader and Writer of the ResourceListProcessor to run
+// the function in memory.  Its ResultsWriter if set is where the results are
+// also written on their own, including the error if the function fails, e.g.
+// to a results file.
+//
+// Functions must not write anything other than the Resources to stdout, since a
+// function's stdout is the input of the next function in a pipeline.  A Logger
+// writes log lines to stderr instead, and records notable events as results in
+// the ResourceList which is written:
+//
+//	logger := &framework.Logger{}
+//	p := framework.ResourceListProcessor{Logger: logger, Filter: ...}
+//
+//	// in the Filter
+//	logger.Debugf("visiting %s", name)
+//	logger.Result(kio.Result{Severity: kio.SeverityWarning, Message: "...",
+//		ResourceRef: ref, Field: "spec.replicas"})
+//
+// The lines below the log level aren't written.  The log level is set by the
+// `config.kubernetes.io/log-level` annotation of the functionConfig, or by the
+// LOG_LEVEL environment variable, and defaults to info.
+package framework
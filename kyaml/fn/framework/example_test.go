@@ -0,0 +1,68 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func Example() {
+	input := bytes.NewBufferString(`apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: nginx
+functionConfig:
+  apiVersion: example.com/v1
+  kind: Annotator
+  spec:
+    value: bar
+`)
+
+	config := &struct {
+		Spec struct {
+			Value string `yaml:"value" framework:"required"`
+		} `yaml:"spec"`
+	}{}
+	err := framework.ResourceListProcessor{
+		Reader:         input,
+		Writer:         os.Stdout,
+		FunctionConfig: config,
+		Filter: kio.FilterFunc(func(items []*yaml.RNode) ([]*yaml.RNode, error) {
+			for i := range items {
+				if err := items[i].PipeE(yaml.SetAnnotation("foo", config.Spec.Value)); err != nil {
+					return nil, err
+				}
+			}
+			return items, nil
+		}),
+	}.Execute()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// apiVersion: config.kubernetes.io/v1alpha1
+	// kind: ResourceList
+	// items:
+	// - apiVersion: apps/v1
+	//   kind: Deployment
+	//   metadata:
+	//     name: nginx
+	//     annotations:
+	//       foo: 'bar'
+	// functionConfig:
+	//   apiVersion: example.com/v1
+	//   kind: Annotator
+	//   spec:
+	//     value: bar
+}
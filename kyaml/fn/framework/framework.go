@@ -0,0 +1,336 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ResourceListProcessor reads a ResourceList, decodes its functionConfig into
+// FunctionConfig, runs Filter over its items and writes the ResourceList.
+type ResourceListProcessor struct {
+	// FunctionConfig if set is a pointer to the value which the functionConfig
+	// is decoded into, e.g. a pointer to a struct with yaml tags.
+	//
+	// Fields of the functionConfig which aren't in the struct are reported as
+	// warnings.  Fields of the struct tagged with `framework:"required"` must
+	// be set by the functionConfig.
+	FunctionConfig interface{}
+
+	// Filter is run over the items of the ResourceList after the functionConfig
+	// has been decoded.  The items are written unmodified if it is nil.
+	Filter kio.Filter
+
+	// Reader is where the ResourceList is read from.  Defaults to os.Stdin.
+	Reader io.Reader
+
+	// Writer is where the ResourceList is written.  Defaults to os.Stdout.
+	Writer io.Writer
+
+	// KeepReaderAnnotations if set will keep the Reader specific annotations
+	// when the input isn't a ResourceList.  They are always kept for a
+	// ResourceList, so that the function can be run in a pipeline.
+	// Otherwise the config.kubernetes.io/index and config.kubernetes.io/path
+	// annotations are cleared.
+	KeepReaderAnnotations bool
+
+	// ResultsWriter if set is where the results are also written, as a yaml
+	// list, e.g. to a results file which can be read without decoding the
+	// ResourceList.  If the function fails, its error is written as an error
+	// result.
+	ResultsWriter io.Writer
+
+	// Logger if set is configured before the Filter is run: its Results default
+	// to the results of the ResourceList which is written, and its Level defaults
+	// to the LogLevel of the functionConfig.
+	Logger *Logger
+}
+
+// Execute reads the ResourceList, runs the Filter and writes the ResourceList.
+func (p ResourceListProcessor) Execute() error {
+	rw := &kio.ByteReadWriter{Reader: p.Reader, Writer: p.Writer}
+	if rw.Reader == nil {
+		rw.Reader = os.Stdin
+	}
+	if rw.Writer == nil {
+		rw.Writer = os.Stdout
+	}
+
+	err := p.execute(rw)
+	if p.ResultsWriter == nil {
+		return err
+	}
+	results := rw.Results
+	if err != nil {
+		results = append(results, kio.Result{
+			Severity: kio.SeverityError, Message: err.Error()})
+	}
+	if werr := writeResults(p.ResultsWriter, results); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// execute reads the ResourceList with rw, runs the Filter and writes the
+// ResourceList with rw.
+func (p ResourceListProcessor) execute(rw *kio.ByteReadWriter) error {
+	items, err := rw.Read()
+	if err != nil {
+		return err
+	}
+	rw.KeepReaderAnnotations = p.KeepReaderAnnotations ||
+		rw.WrappingKind == kio.ResourceListKind
+
+	if p.Logger != nil {
+		if p.Logger.Results == nil {
+			p.Logger.Results = &rw.Results
+		}
+		if p.Logger.Level == "" {
+			if p.Logger.Level, err = LogLevel(rw.FunctionConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.FunctionConfig != nil {
+		warnings, err := decode(rw.FunctionConfig, p.FunctionConfig)
+		if err != nil {
+			return err
+		}
+		rw.Results = append(rw.Results, warnings...)
+	}
+
+	if p.Filter != nil {
+		items, err = p.Filter.Filter(items)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	if !rw.KeepReaderAnnotations {
+		// the ByteWriter only clears the index annotation
+		for i := range items {
+			if err := items[i].PipeE(yaml.ClearAnnotation(kioutil.PathAnnotation)); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+	}
+	return rw.Write(items)
+}
+
+// writeResults writes results to w as a yaml list.  Nothing is written if
+// there aren't any results.
+func writeResults(w io.Writer, results kio.Results) error {
+	if len(results) == 0 {
+		return nil
+	}
+	b, err := yaml.Marshal(results)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	// reformat the list as the results of a ResourceList are indented
+	node, err := yaml.Parse(string(b))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	s, err := node.String()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	_, err = io.WriteString(w, s)
+	return errors.Wrap(err)
+}
+
+// decode decodes functionConfig into value, and returns warnings for the fields
+// of functionConfig which aren't fields of value.
+func decode(functionConfig *yaml.RNode, value interface{}) (kio.Results, error) {
+	var node *yaml.Node
+	var ref yaml.ResourceIdentifier
+	if !yaml.IsMissingOrNull(functionConfig) {
+		node = functionConfig.YNode()
+		if meta, err := functionConfig.GetMeta(); err == nil {
+			ref = yaml.ResourceIdentifier{
+				Name:       meta.Name,
+				Namespace:  meta.Namespace,
+				APIVersion: meta.APIVersion,
+				Kind:       meta.Kind,
+			}
+		}
+	}
+
+	c := &fieldChecker{}
+	c.check(node, reflect.TypeOf(value), nil, true)
+	if len(c.missing) == 1 {
+		return nil, errors.Errorf("functionConfig missing required field %s", c.missing[0])
+	}
+	if len(c.missing) > 1 {
+		return nil, errors.Errorf("functionConfig missing required fields %s",
+			strings.Join(c.missing, ", "))
+	}
+
+	if node != nil {
+		if err := node.Decode(value); err != nil {
+			return nil, errors.WrapPrefixf(err, "decoding functionConfig")
+		}
+	}
+
+	var warnings kio.Results
+	for i := range c.unknown {
+		warnings = append(warnings, kio.Result{
+			Severity:    kio.SeverityWarning,
+			Message:     fmt.Sprintf("unknown functionConfig field %s", c.unknown[i]),
+			ResourceRef: ref,
+			Field:       c.unknown[i],
+		})
+	}
+	return warnings, nil
+}
+
+// objectFields are the fields of the functionConfig which aren't reported as
+// unknown if they aren't fields of the value it is decoded into.
+var objectFields = map[string]bool{"apiVersion": true, "kind": true, "metadata": true}
+
+// fieldChecker compares a node with the type it is decoded into.
+type fieldChecker struct {
+	// unknown are the paths of the fields of the node which aren't in the type
+	unknown []string
+
+	// missing are the paths of the required fields of the type which aren't
+	// in the node
+	missing []string
+}
+
+// check compares node with t.  path is the path to node, and root is set for
+// the functionConfig itself.
+func (c *fieldChecker) check(node *yaml.Node, t reflect.Type, path []string, root bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node != nil && node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := structFields(t)
+		known := map[string]bool{}
+		for i := range fields {
+			known[fields[i].name] = true
+		}
+		values := map[string]*yaml.Node{}
+		if node != nil {
+			for i := 0; i < len(node.Content); i = yaml.IncrementFieldIndex(i) {
+				key := node.Content[i].Value
+				values[key] = node.Content[i+1]
+				if !known[key] && !(root && objectFields[key]) {
+					c.unknown = append(c.unknown, fieldPath(path, key))
+				}
+			}
+		}
+		for _, f := range fields {
+			value, found := values[f.name]
+			if !found || value.Tag == yaml.NullNodeTag {
+				if f.required {
+					c.missing = append(c.missing, fieldPath(path, f.name))
+				} else if f.typ.Kind() == reflect.Struct {
+					// the required fields of a struct which isn't a pointer
+					// must be set even if the struct isn't
+					c.check(nil, f.typ, append(path, f.name), false)
+				}
+				continue
+			}
+			c.check(value, f.typ, append(path, f.name), false)
+		}
+	case reflect.Map:
+		if node == nil || node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i < len(node.Content); i = yaml.IncrementFieldIndex(i) {
+			c.check(node.Content[i+1], t.Elem(), append(path, node.Content[i].Value), false)
+		}
+	case reflect.Slice, reflect.Array:
+		if node == nil || node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i := range node.Content {
+			// index the last field of the path, e.g. spec.items[0]
+			p := append([]string{}, path...)
+			if len(p) > 0 {
+				p[len(p)-1] = fmt.Sprintf("%s[%d]", p[len(p)-1], i)
+			}
+			c.check(node.Content[i], t.Elem(), p, false)
+		}
+	}
+}
+
+// fieldPath returns the path to the field name under path.
+func fieldPath(path []string, name string) string {
+	return strings.Join(append(append([]string{}, path...), name), ".")
+}
+
+// structField is a field of a struct which is decoded from yaml.
+type structField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+}
+
+// structFields returns the fields of struct t which are decoded from yaml in
+// the order they are declared, including the fields of inlined structs.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// unexported fields aren't decoded
+			continue
+		}
+		name, inline := yamlName(f)
+		if name == "-" {
+			continue
+		}
+		if inline {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, structFields(ft)...)
+			}
+			continue
+		}
+		fields = append(fields, structField{
+			name:     name,
+			typ:      f.Type,
+			required: f.Tag.Get("framework") == "required",
+		})
+	}
+	return fields
+}
+
+// yamlName returns the name of field f when it is decoded from yaml, and
+// whether it is inlined.
+func yamlName(f reflect.StructField) (string, bool) {
+	parts := strings.Split(f.Tag.Get("yaml"), ",")
+	for _, flag := range parts[1:] {
+		if flag == "inline" {
+			return "", true
+		}
+	}
+	if parts[0] != "" {
+		return parts[0], false
+	}
+	return strings.ToLower(f.Name), false
+}
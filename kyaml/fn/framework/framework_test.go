@@ -0,0 +1,332 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type scalerConfig struct {
+	Spec scalerSpec `yaml:"spec"`
+}
+
+type scalerSpec struct {
+	Replicas   int               `yaml:"replicas" framework:"required"`
+	Components []scalerComponent `yaml:"components"`
+	Labels     map[string]string `yaml:"labels"`
+}
+
+type scalerComponent struct {
+	Name string `yaml:"name" framework:"required"`
+}
+
+func TestResourceListProcessor_Execute(t *testing.T) {
+	in := bytes.NewBufferString(`apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: foo
+    annotations:
+      config.kubernetes.io/path: foo.yaml
+      config.kubernetes.io/index: '0'
+functionConfig:
+  apiVersion: example.com/v1
+  kind: Scaler
+  metadata:
+    name: scaler
+  spec:
+    replicas: 3
+`)
+	out := &bytes.Buffer{}
+	config := &scalerConfig{}
+	err := framework.ResourceListProcessor{
+		Reader:         in,
+		Writer:         out,
+		FunctionConfig: config,
+		Filter: kio.FilterFunc(func(items []*yaml.RNode) ([]*yaml.RNode, error) {
+			for i := range items {
+				err := items[i].PipeE(yaml.LookupCreate(yaml.MappingNode, "spec"),
+					yaml.SetField("replicas", yaml.NewScalarRNode(
+						fmt.Sprintf("%d", config.Spec.Replicas))))
+				if err != nil {
+					return nil, err
+				}
+			}
+			return items, nil
+		}),
+	}.Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 3, config.Spec.Replicas)
+	assert.Equal(t, `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: foo
+    annotations:
+      config.kubernetes.io/path: foo.yaml
+      config.kubernetes.io/index: '0'
+  spec:
+    replicas: 3
+functionConfig:
+  apiVersion: example.com/v1
+  kind: Scaler
+  metadata:
+    name: scaler
+  spec:
+    replicas: 3
+`, out.String())
+}
+
+func TestResourceListProcessor_Execute_unknownFields(t *testing.T) {
+	in := bytes.NewBufferString(`apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items: []
+functionConfig:
+  apiVersion: example.com/v1
+  kind: Scaler
+  metadata:
+    name: scaler
+  spec:
+    replicas: 3
+    replica: 4
+    components:
+    - name: a
+      image: b
+    labels:
+      app: foo
+  status: {}
+`)
+	out := &bytes.Buffer{}
+	err := framework.ResourceListProcessor{
+		Reader:         in,
+		Writer:         out,
+		FunctionConfig: &scalerConfig{},
+	}.Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, out.String(), `results:
+- severity: warning
+  message: unknown functionConfig field status
+  resourceRef:
+    name: scaler
+    apiVersion: example.com/v1
+    kind: Scaler
+  field: status
+- severity: warning
+  message: unknown functionConfig field spec.replica
+  resourceRef:
+    name: scaler
+    apiVersion: example.com/v1
+    kind: Scaler
+  field: spec.replica
+- severity: warning
+  message: unknown functionConfig field spec.components[0].image
+  resourceRef:
+    name: scaler
+    apiVersion: example.com/v1
+    kind: Scaler
+  field: spec.components[0].image
+`)
+}
+
+func TestResourceListProcessor_Execute_requiredFields(t *testing.T) {
+	tests := []struct {
+		name           string
+		functionConfig string
+		expectedErr    string
+	}{
+		{
+			name: "missing",
+			functionConfig: `
+  apiVersion: example.com/v1
+  kind: Scaler
+  spec:
+    labels:
+      app: foo
+`,
+			expectedErr: "functionConfig missing required field spec.replicas",
+		},
+		{
+			name: "null",
+			functionConfig: `
+  apiVersion: example.com/v1
+  kind: Scaler
+  spec:
+    replicas: null
+`,
+			expectedErr: "functionConfig missing required field spec.replicas",
+		},
+		{
+			name: "multiple",
+			functionConfig: `
+  apiVersion: example.com/v1
+  kind: Scaler
+  spec:
+    components:
+    - name: a
+    - {}
+`,
+			expectedErr: "functionConfig missing required fields spec.replicas, " +
+				"spec.components[1].name",
+		},
+		{
+			name:           "no-function-config",
+			functionConfig: " null",
+			expectedErr:    "functionConfig missing required field spec.replicas",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			in := bytes.NewBufferString(`apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items: []
+functionConfig:` + test.functionConfig)
+			err := framework.ResourceListProcessor{
+				Reader:         in,
+				Writer:         &bytes.Buffer{},
+				FunctionConfig: &scalerConfig{},
+			}.Execute()
+			if assert.Error(t, err) {
+				assert.Equal(t, test.expectedErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestResourceListProcessor_Execute_readerAnnotations(t *testing.T) {
+	input := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`
+	out := &bytes.Buffer{}
+	err := framework.ResourceListProcessor{
+		Reader: bytes.NewBufferString(input),
+		Writer: out,
+	}.Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, input, out.String())
+
+	// the path annotation is cleared as well as the index annotation
+	out.Reset()
+	err = framework.ResourceListProcessor{
+		Reader: bytes.NewBufferString(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    config.kubernetes.io/path: foo.yaml
+`),
+		Writer: out,
+	}.Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, input, out.String())
+
+	out.Reset()
+	err = framework.ResourceListProcessor{
+		Reader:                bytes.NewBufferString(input),
+		Writer:                out,
+		KeepReaderAnnotations: true,
+	}.Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    config.kubernetes.io/index: '0'
+`, out.String())
+}
+
+func TestResourceListProcessor_Execute_resultsWriter(t *testing.T) {
+	input := `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: foo
+functionConfig:
+  apiVersion: example.com/v1
+  kind: Scaler
+  metadata:
+    name: scaler
+  spec:
+    replicas: 3
+  status: {}
+`
+	expectedResults := `- severity: warning
+  message: unknown functionConfig field status
+  resourceRef:
+    name: scaler
+    apiVersion: example.com/v1
+    kind: Scaler
+  field: status
+`
+	out, results := &bytes.Buffer{}, &bytes.Buffer{}
+	err := framework.ResourceListProcessor{
+		Reader:         bytes.NewBufferString(input),
+		Writer:         out,
+		ResultsWriter:  results,
+		FunctionConfig: &scalerConfig{},
+	}.Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the results are written to the ResourceList as well
+	assert.Contains(t, out.String(), "results:\n"+expectedResults)
+	assert.Equal(t, expectedResults, results.String())
+
+	// the error is written as an error result, and the ResourceList isn't written
+	out.Reset()
+	results.Reset()
+	err = framework.ResourceListProcessor{
+		Reader:         bytes.NewBufferString(input),
+		Writer:         out,
+		ResultsWriter:  results,
+		FunctionConfig: &scalerConfig{},
+		Filter: kio.FilterFunc(func([]*yaml.RNode) ([]*yaml.RNode, error) {
+			return nil, fmt.Errorf("cannot scale foo")
+		}),
+	}.Execute()
+	assert.EqualError(t, err, "cannot scale foo")
+	assert.Empty(t, out.String())
+	assert.Equal(t, expectedResults+`- severity: error
+  message: cannot scale foo
+`, results.String())
+
+	// nothing is written without results
+	out.Reset()
+	results.Reset()
+	err = framework.ResourceListProcessor{
+		Reader:        bytes.NewBufferString("apiVersion: apps/v1\nkind: Deployment\n"),
+		Writer:        out,
+		ResultsWriter: results,
+	}.Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, results.String())
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Log levels of a Logger, which are also the severities of the lines it logs.
+// The levels other than LogLevelDebug are the severities of kio.Results.
+const (
+	LogLevelDebug   = "debug"
+	LogLevelInfo    = kio.SeverityInfo
+	LogLevelWarning = kio.SeverityWarning
+	LogLevelError   = kio.SeverityError
+)
+
+// LogLevelAnnotation is the annotation of a functionConfig which sets the log
+// level of the function, e.g. `config.kubernetes.io/log-level: debug`.
+const LogLevelAnnotation = "config.kubernetes.io/log-level"
+
+// LogLevelEnv is the environment variable which sets the log level of a function
+// if its functionConfig doesn't.
+const LogLevelEnv = "LOG_LEVEL"
+
+// logLevels rank the log levels.  `warn` is accepted for LogLevelWarning.
+var logLevels = map[string]int{
+	LogLevelDebug: 0, LogLevelInfo: 1, LogLevelWarning: 2, "warn": 2, LogLevelError: 3,
+}
+
+// Logger logs the lines of a function to stderr rather than to stdout, where they
+// would corrupt the Resources written by the function, and records results in
+// the ResourceList which is written.
+type Logger struct {
+	// Level is the lowest level of the lines which are written, and is one of
+	// LogLevelDebug, LogLevelInfo, LogLevelWarning or LogLevelError.  Defaults to
+	// LogLevelInfo.
+	Level string
+
+	// Writer is where the lines are written.  Defaults to os.Stderr.
+	Writer io.Writer
+
+	// Results if set are appended the results recorded by Result, e.g. the
+	// Results of the kio.ByteReadWriter writing the ResourceList.
+	Results *kio.Results
+}
+
+// LogLevel returns the log level set by the LogLevelAnnotation of functionConfig,
+// or else by the LogLevelEnv environment variable, or else LogLevelInfo.
+func LogLevel(functionConfig *yaml.RNode) (string, error) {
+	level := os.Getenv(LogLevelEnv)
+	if !yaml.IsMissingOrNull(functionConfig) {
+		if meta, err := functionConfig.GetMeta(); err == nil &&
+			meta.Annotations[LogLevelAnnotation] != "" {
+			level = meta.Annotations[LogLevelAnnotation]
+		}
+	}
+	if level == "" {
+		return LogLevelInfo, nil
+	}
+	if _, found := logLevels[level]; !found {
+		return "", errors.Errorf("log level must be one of %s, %s, %s or %s, got %q",
+			LogLevelDebug, LogLevelInfo, LogLevelWarning, LogLevelError, level)
+	}
+	return level, nil
+}
+
+// Enabled returns true if lines with severity are written.
+func (l *Logger) Enabled(severity string) bool {
+	level := l.Level
+	if level == "" {
+		level = LogLevelInfo
+	}
+	return logLevels[severity] >= logLevels[level]
+}
+
+// Logf writes a line with severity, if it is enabled.
+func (l *Logger) Logf(severity, format string, args ...interface{}) {
+	if !l.Enabled(severity) {
+		return
+	}
+	w := l.Writer
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "[%s] %s\n", severity, fmt.Sprintf(format, args...))
+}
+
+// Debugf writes a line with LogLevelDebug.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.Logf(LogLevelDebug, format, args...)
+}
+
+// Infof writes a line with LogLevelInfo.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Logf(LogLevelInfo, format, args...)
+}
+
+// Warningf writes a line with LogLevelWarning.
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.Logf(LogLevelWarning, format, args...)
+}
+
+// Result records r in the Results, and writes it as a line with its severity.
+// The results are recorded whatever the Level is.
+func (l *Logger) Result(r kio.Result) {
+	if l.Results != nil {
+		*l.Results = append(*l.Results, r)
+	}
+	msg := r.Message
+	if r.Field != "" {
+		msg = r.Field + ": " + msg
+	}
+	if resource := r.Resource(); resource != "" {
+		msg = resource + " " + msg
+	}
+	l.Logf(r.Severity, "%s", msg)
+}
@@ -0,0 +1,141 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestLogger(t *testing.T) {
+	var out bytes.Buffer
+	var results kio.Results
+	l := &framework.Logger{Writer: &out, Level: framework.LogLevelInfo, Results: &results}
+	l.Debugf("visiting %s", "foo")
+	l.Infof("set %s to %d", "replicas", 3)
+	l.Warningf("clamped replicas")
+	l.Result(kio.Result{Severity: kio.SeverityError, Message: "must be an integer",
+		Field: "spec.replicas", ResourceRef: yaml.ResourceIdentifier{
+			APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "foo"}})
+	// results below the level are recorded, but not written
+	l.Result(kio.Result{Severity: framework.LogLevelDebug, Message: "unchanged"})
+
+	assert.Equal(t, `[info] set replicas to 3
+[warning] clamped replicas
+[error] apps/v1/Deployment default/foo spec.replicas: must be an integer
+`, out.String())
+	assert.Equal(t, kio.Results{
+		{Severity: kio.SeverityError, Message: "must be an integer", Field: "spec.replicas",
+			ResourceRef: yaml.ResourceIdentifier{
+				APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "foo"}},
+		{Severity: framework.LogLevelDebug, Message: "unchanged"},
+	}, results)
+}
+
+func TestLogger_Enabled(t *testing.T) {
+	l := &framework.Logger{}
+	assert.False(t, l.Enabled(framework.LogLevelDebug))
+	assert.True(t, l.Enabled(framework.LogLevelInfo))
+
+	l.Level = "warn"
+	assert.False(t, l.Enabled(framework.LogLevelInfo))
+	assert.True(t, l.Enabled(framework.LogLevelWarning))
+	assert.True(t, l.Enabled(framework.LogLevelError))
+}
+
+func TestLogLevel(t *testing.T) {
+	functionConfig := yaml.MustParse(`apiVersion: example.com/v1
+kind: Scaler
+metadata:
+  name: scaler
+  annotations:
+    config.kubernetes.io/log-level: debug
+`)
+
+	level, err := framework.LogLevel(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, framework.LogLevelInfo, level)
+
+	// the functionConfig wins over the environment variable
+	assert.NoError(t, os.Setenv(framework.LogLevelEnv, framework.LogLevelWarning))
+	defer os.Unsetenv(framework.LogLevelEnv)
+	level, err = framework.LogLevel(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, framework.LogLevelWarning, level)
+	level, err = framework.LogLevel(functionConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, framework.LogLevelDebug, level)
+
+	assert.NoError(t, os.Setenv(framework.LogLevelEnv, "verbose"))
+	_, err = framework.LogLevel(nil)
+	assert.EqualError(t, err,
+		`log level must be one of debug, info, warning or error, got "verbose"`)
+}
+
+func TestResourceListProcessor_Execute_logger(t *testing.T) {
+	in := bytes.NewBufferString(`apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: foo
+functionConfig:
+  apiVersion: example.com/v1
+  kind: Scaler
+  metadata:
+    name: scaler
+    annotations:
+      config.kubernetes.io/log-level: warning
+`)
+	var out, log bytes.Buffer
+	logger := &framework.Logger{Writer: &log}
+	err := framework.ResourceListProcessor{
+		Reader: in,
+		Writer: &out,
+		Logger: logger,
+		Filter: kio.FilterFunc(func(items []*yaml.RNode) ([]*yaml.RNode, error) {
+			logger.Infof("visiting %d items", len(items))
+			logger.Result(kio.Result{Severity: kio.SeverityWarning, Message: "missing replicas",
+				ResourceRef: yaml.ResourceIdentifier{
+					APIVersion: "apps/v1", Kind: "Deployment", Name: "foo"}})
+			return items, nil
+		}),
+	}.Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, framework.LogLevelWarning, logger.Level)
+
+	// the lines are only written to the log, and the results to the ResourceList
+	assert.Equal(t, "[warning] apps/v1/Deployment foo missing replicas\n", log.String())
+	assert.Equal(t, `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: foo
+functionConfig:
+  apiVersion: example.com/v1
+  kind: Scaler
+  metadata:
+    name: scaler
+    annotations:
+      config.kubernetes.io/log-level: warning
+results:
+- severity: warning
+  message: missing replicas
+  resourceRef:
+    name: foo
+    apiVersion: apps/v1
+    kind: Deployment
+`, out.String())
+}
@@ -5,6 +5,7 @@ package kio
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"sort"
@@ -20,7 +21,19 @@ const (
 	ResourceListAPIVersion = "config.kubernetes.io/v1alpha1"
 )
 
+// Formats which Resources may be read and written in.
+const (
+	YAMLFormat = "yaml"
+	JSONFormat = "json"
+)
+
 // ByteReadWriter reads from an input and writes to an output.
+//
+// Functions use a ByteReadWriter to read their input from stdin and to write
+// their output to stdout.  Nothing else may be written to stdout, since it is
+// read by the next function in a pipeline: functions should log to stderr --
+// e.g. with a framework.Logger -- and report results by adding them to Results,
+// which are written to the ResourceList.
 type ByteReadWriter struct {
 	// Reader is where ResourceNodes are decoded from.
 	Reader io.Reader
@@ -39,8 +52,24 @@ type ByteReadWriter struct {
 	// Style is a style that is set on the Resource Node Document.
 	Style yaml.Style
 
+	// Format if set forces the format the Resources are written in.
+	// See ByteWriter.Format.
+	Format string
+
+	// SortByID if set, will sort the Resources by namespace, kind and name
+	// when writing them.  See ByteWriter.SortByID.
+	SortByID bool
+
+	// NamespacesAndCRDsFirst if set with SortByID, will write Namespaces and
+	// CustomResourceDefinitions before the other Resources.
+	NamespacesAndCRDsFirst bool
+
 	FunctionConfig *yaml.RNode
 
+	// Results are the results read from and written to a ResourceList.
+	// Functions may add Results to report them along with the Resources.
+	Results Results
+
 	WrappingAPIVersion string
 	WrappingKind       string
 }
@@ -52,6 +81,7 @@ func (rw *ByteReadWriter) Read() ([]*yaml.RNode, error) {
 	}
 	val, err := b.Read()
 	rw.FunctionConfig = b.FunctionConfig
+	rw.Results = b.Results
 	rw.WrappingAPIVersion = b.WrappingAPIVersion
 	rw.WrappingKind = b.WrappingKind
 	return val, errors.Wrap(err)
@@ -59,18 +89,27 @@ func (rw *ByteReadWriter) Read() ([]*yaml.RNode, error) {
 
 func (rw *ByteReadWriter) Write(nodes []*yaml.RNode) error {
 	return ByteWriter{
-		Writer:                rw.Writer,
-		KeepReaderAnnotations: rw.KeepReaderAnnotations,
-		Style:                 rw.Style,
-		FunctionConfig:        rw.FunctionConfig,
-		WrappingAPIVersion:    rw.WrappingAPIVersion,
-		WrappingKind:          rw.WrappingKind,
+		Writer:                 rw.Writer,
+		KeepReaderAnnotations:  rw.KeepReaderAnnotations,
+		Style:                  rw.Style,
+		Format:                 rw.Format,
+		SortByID:               rw.SortByID,
+		NamespacesAndCRDsFirst: rw.NamespacesAndCRDsFirst,
+		FunctionConfig:         rw.FunctionConfig,
+		Results:                rw.Results,
+		WrappingAPIVersion:     rw.WrappingAPIVersion,
+		WrappingKind:           rw.WrappingKind,
 	}.Write(nodes)
 }
 
 // ByteReader decodes ResourceNodes from bytes.
 // By default, Read will set the config.kubernetes.io/index annotation on each RNode as it
 // is read so they can be written back in the same order.
+//
+// Input starting with '{' or '[' is read as a stream of json values, and the
+// Resources read from it have the config.kubernetes.io/format annotation set
+// to json, so that ByteWriter writes them as json.  The elements of a json
+// array are read as Resources.
 type ByteReader struct {
 	// Reader is where ResourceNodes are decoded from.
 	Reader io.Reader
@@ -85,9 +124,16 @@ type ByteReader struct {
 
 	FunctionConfig *yaml.RNode
 
+	// Results is set by Read(), and is the results field of a ResourceList.
+	Results Results
+
 	// DisableUnwrapping prevents Resources in Lists and ResourceLists from being unwrapped
 	DisableUnwrapping bool
 
+	// DisableJSON prevents json input from being read as json, so that it is
+	// read as yaml instead.
+	DisableJSON bool
+
 	// WrappingAPIVersion is set by Read(), and is the apiVersion of the object that
 	// the read objects were originally wrapped in.
 	WrappingAPIVersion string
@@ -109,12 +155,23 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 	if err != nil {
 		return nil, errors.Wrap(err)
 	}
-	values := strings.Split(input.String(), "\n---\n")
+	var values []string
+	fromJSON := !r.DisableJSON && isJSON(input.Bytes())
+	if fromJSON {
+		values, err = splitJSON(input.Bytes())
+		if err != nil {
+			// not json -- e.g. a yaml flow map
+			fromJSON = false
+		}
+	}
+	if !fromJSON {
+		values = strings.Split(input.String(), "\n---\n")
+	}
 
 	index := 0
 	for i := range values {
 		decoder := yaml.NewDecoder(bytes.NewBufferString(values[i]))
-		node, err := r.decode(index, decoder)
+		node, err := r.decode(index, decoder, fromJSON)
 		if err == io.EOF {
 			continue
 		}
@@ -146,12 +203,24 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 			if fc != nil {
 				r.FunctionConfig = fc.Value
 			}
+			if results := node.Field("results"); results != nil {
+				if err := results.Value.YNode().Decode(&r.Results); err != nil {
+					return nil, errors.WrapPrefixf(err, "results")
+				}
+			}
 
 			items := node.Field("items")
 			if items != nil {
 				for i := range items.Value.Content() {
 					// add items
-					output = append(output, yaml.NewRNode(items.Value.Content()[i]))
+					item := yaml.NewRNode(items.Value.Content()[i])
+					if fromJSON && !r.OmitReaderAnnotations {
+						err := item.PipeE(yaml.SetAnnotation(kioutil.FormatAnnotation, JSONFormat))
+						if err != nil {
+							return nil, errors.Wrap(err)
+						}
+					}
+					output = append(output, item)
 				}
 			}
 			continue
@@ -172,7 +241,55 @@ func isEmptyDocument(node *yaml.Node) bool {
 		node.Content[0].Tag == yaml.NullNodeTag
 }
 
-func (r *ByteReader) decode(index int, decoder *yaml.Decoder) (*yaml.RNode, error) {
+// isJSON returns true if the input starts with a json object or array.
+func isJSON(input []byte) bool {
+	input = bytes.TrimSpace(input)
+	return len(input) > 0 && (input[0] == '{' || input[0] == '[')
+}
+
+// splitJSON splits a stream of json values into the values, splitting arrays
+// into their elements.
+func splitJSON(input []byte) ([]string, error) {
+	var values []string
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	for {
+		var value json.RawMessage
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if bytes.HasPrefix(value, []byte("[")) {
+			var elements []json.RawMessage
+			if err := json.Unmarshal(value, &elements); err != nil {
+				return nil, errors.Wrap(err)
+			}
+			for i := range elements {
+				values = append(values, string(elements[i]))
+			}
+			continue
+		}
+		values = append(values, string(value))
+	}
+}
+
+// clearJSONStyle clears the flow and quoted styles of nodes parsed from json,
+// so they are written in the block style if written as yaml.
+func clearJSONStyle(node *yaml.Node) {
+	// keep the quotes for strings which would be parsed as non-strings by
+	// yaml 1.1 if unquoted
+	if node.Kind != yaml.ScalarNode || node.Tag != yaml.StringTag ||
+		!yaml.IsYaml1_1NonString(node) {
+		node.Style = 0
+	}
+	for i := range node.Content {
+		clearJSONStyle(node.Content[i])
+	}
+}
+
+func (r *ByteReader) decode(index int, decoder *yaml.Decoder, fromJSON bool) (*yaml.RNode, error) {
 	node := &yaml.Node{}
 	err := decoder.Decode(node)
 	if err == io.EOF {
@@ -185,6 +302,9 @@ func (r *ByteReader) decode(index int, decoder *yaml.Decoder) (*yaml.RNode, erro
 	if isEmptyDocument(node) {
 		return nil, nil
 	}
+	if fromJSON {
+		clearJSONStyle(node)
+	}
 
 	// set annotations on the read Resources
 	// sort the annotations by key so the output Resources is consistent (otherwise the
@@ -207,5 +327,11 @@ func (r *ByteReader) decode(index int, decoder *yaml.Decoder) (*yaml.RNode, erro
 			return nil, errors.Wrap(err)
 		}
 	}
+	if fromJSON && !r.OmitReaderAnnotations {
+		_, err = n.Pipe(yaml.SetAnnotation(kioutil.FormatAnnotation, JSONFormat))
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
 	return yaml.NewRNode(node), nil
 }
@@ -307,3 +307,188 @@ metadata:
 		}
 	}
 }
+
+func TestByteReadWriter_json(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		format   string
+		expected string
+	}{
+		{
+			name: "json-stream",
+			input: `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {"name": "b"},
+  "data": {"replicas": "3", "enabled": "on", "count": 10, "ratio": 0.5, "flag": true, "nothing": null}
+}
+{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}, "data": {"list": [1, "two"]}}
+`,
+			expected: `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "b"
+  },
+  "data": {
+    "replicas": "3",
+    "enabled": "on",
+    "count": 10,
+    "ratio": 0.5,
+    "flag": true,
+    "nothing": null
+  }
+}
+{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "a"
+  },
+  "data": {
+    "list": [
+      1,
+      "two"
+    ]
+  }
+}
+`,
+		},
+		{
+			name: "json-to-yaml",
+			input: `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {"name": "b"},
+  "data": {"replicas": "3", "enabled": "on", "count": 10, "list": [1, "two"]}
+}
+`,
+			format: YAMLFormat,
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+data:
+  replicas: "3"
+  enabled: "on"
+  count: 10
+  list:
+  - 1
+  - two
+`,
+		},
+		{
+			name:  "json-array",
+			input: `[{"kind": "ConfigMap", "metadata": {"name": "a"}}, {"kind": "Secret", "metadata": {"name": "b"}}]`,
+			expected: `{
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "a"
+  }
+}
+{
+  "kind": "Secret",
+  "metadata": {
+    "name": "b"
+  }
+}
+`,
+		},
+		{
+			name: "json-list",
+			input: `{"apiVersion": "v1", "kind": "List", "items": [
+  {"kind": "ConfigMap", "metadata": {"name": "a"}},
+  {"kind": "Secret", "metadata": {"name": "b"}}
+]}`,
+			expected: `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "kind": "ConfigMap",
+      "metadata": {
+        "name": "a"
+      }
+    },
+    {
+      "kind": "Secret",
+      "metadata": {
+        "name": "b"
+      }
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "json-list-to-yaml",
+			input: `{"apiVersion": "v1", "kind": "List", "items": [
+  {"kind": "ConfigMap", "metadata": {"name": "a"}}
+]}`,
+			format: YAMLFormat,
+			expected: `apiVersion: v1
+kind: List
+items:
+- kind: ConfigMap
+  metadata:
+    name: a
+`,
+		},
+		{
+			name: "yaml-to-json",
+			input: `kind: ConfigMap
+metadata:
+  name: a
+data:
+  count: 0x1F
+  enabled: "true"
+`,
+			format: JSONFormat,
+			expected: `{
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "a"
+  },
+  "data": {
+    "count": 31,
+    "enabled": "true"
+  }
+}
+`,
+		},
+		{
+			name: "yaml-flow",
+			input: `{kind: ConfigMap, metadata: {name: a}}
+`,
+			expected: `{kind: ConfigMap, metadata: {name: a}}
+`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			rw := &ByteReadWriter{
+				Reader: bytes.NewBufferString(test.input),
+				Writer: &out,
+				Format: test.format,
+			}
+			nodes, err := rw.Read()
+			if !assert.NoError(t, err) {
+				return
+			}
+			if !assert.NoError(t, rw.Write(nodes)) {
+				return
+			}
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestByteWriter_Write_unsupportedFormat(t *testing.T) {
+	err := ByteWriter{Writer: &bytes.Buffer{}, Format: "toml"}.Write(nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, `unsupported format "toml": may be one of: [yaml,json]`, err.Error())
+	}
+}
@@ -4,6 +4,8 @@
 package kio
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
@@ -30,6 +32,10 @@ type ByteWriter struct {
 	// wrap the results in an ResourceList.
 	FunctionConfig *yaml.RNode
 
+	// Results are written to the results field if the Resources are wrapped in
+	// a ResourceList.
+	Results Results
+
 	// WrappingKind if set will cause ByteWriter to wrap the Resources in
 	// an 'items' field in this kind.  e.g. if WrappingKind is 'List',
 	// ByteWriter will wrap the Resources in a List .items field.
@@ -40,17 +46,42 @@ type ByteWriter struct {
 
 	// Sort if set, will cause ByteWriter to sort the the nodes before writing them.
 	Sort bool
+
+	// SortByID if set, will cause ByteWriter to sort the nodes by namespace, kind
+	// and name before writing them.  Nodes with the config.kubernetes.io/path
+	// annotation keep their order within their files.
+	// See kioutil.SortNodesByID.
+	SortByID bool
+
+	// NamespacesAndCRDsFirst if set with SortByID, will cause ByteWriter to write
+	// Namespaces and then CustomResourceDefinitions before the other nodes.
+	NamespacesAndCRDsFirst bool
+
+	// Format if set forces the format the Resources are written in, and may be
+	// either YAMLFormat or JSONFormat.  Otherwise the Resources are written as
+	// json if they were read from json, and as yaml if they were not.
+	Format string
 }
 
 var _ Writer = ByteWriter{}
 
 func (w ByteWriter) Write(nodes []*yaml.RNode) error {
+	format, err := w.format(nodes)
+	if err != nil {
+		return err
+	}
+
 	yaml.DoSerializationHacksOnNodes(nodes)
 	if w.Sort {
 		if err := kioutil.SortNodes(nodes); err != nil {
 			return errors.Wrap(err)
 		}
 	}
+	if w.SortByID {
+		if err := kioutil.SortNodesByID(nodes, w.NamespacesAndCRDsFirst); err != nil {
+			return errors.Wrap(err)
+		}
+	}
 
 	encoder := yaml.NewEncoder(w.Writer)
 	defer encoder.Close()
@@ -61,6 +92,10 @@ func (w ByteWriter) Write(nodes []*yaml.RNode) error {
 			if err != nil {
 				return errors.Wrap(err)
 			}
+			_, err = nodes[i].Pipe(yaml.ClearAnnotation(kioutil.FormatAnnotation))
+			if err != nil {
+				return errors.Wrap(err)
+			}
 		}
 		for _, a := range w.ClearAnnotations {
 			_, err := nodes[i].Pipe(yaml.ClearAnnotation(a))
@@ -85,6 +120,12 @@ func (w ByteWriter) Write(nodes []*yaml.RNode) error {
 		}
 	}
 
+	if format == JSONFormat {
+		err := w.writeJSON(nodes)
+		yaml.UndoSerializationHacksOnNodes(nodes)
+		return err
+	}
+
 	// don't wrap the elements
 	if w.WrappingKind == "" {
 		for i := range nodes {
@@ -95,7 +136,136 @@ func (w ByteWriter) Write(nodes []*yaml.RNode) error {
 		}
 		return nil
 	}
-	// wrap the elements in a list
+	doc, err := w.wrap(nodes)
+	if err == nil {
+		err = errors.Wrap(encoder.Encode(doc))
+	}
+	yaml.UndoSerializationHacksOnNodes(nodes)
+	return err
+}
+
+// format returns the format to write the nodes in.
+func (w ByteWriter) format(nodes []*yaml.RNode) (string, error) {
+	switch w.Format {
+	case YAMLFormat, JSONFormat:
+		return w.Format, nil
+	case "":
+	default:
+		return "", errors.Errorf("unsupported format %q: may be one of: [%s,%s]",
+			w.Format, YAMLFormat, JSONFormat)
+	}
+	for i := range nodes {
+		format, err := nodes[i].Pipe(yaml.GetAnnotation(kioutil.FormatAnnotation))
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		if yaml.GetValue(format) == JSONFormat {
+			return JSONFormat, nil
+		}
+	}
+	return YAMLFormat, nil
+}
+
+// writeJSON writes the nodes as a stream of json values, or as a single json
+// value if they are wrapped.
+func (w ByteWriter) writeJSON(nodes []*yaml.RNode) error {
+	doc, err := w.wrap(nodes)
+	if err != nil {
+		return err
+	}
+	values := []*yaml.Node{doc}
+	if w.WrappingKind == "" {
+		values = nil
+		for i := range nodes {
+			values = append(values, nodes[i].YNode())
+		}
+	}
+	for i := range values {
+		b, err := toJSON(values[i])
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, b, "", "  "); err != nil {
+			return errors.Wrap(err)
+		}
+		out.WriteString("\n")
+		if _, err := w.Writer.Write(out.Bytes()); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// toJSON encodes the node as json, keeping the order of the fields.
+func toJSON(node *yaml.Node) ([]byte, error) {
+	var b bytes.Buffer
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return toJSON(node.Content[0])
+	case yaml.AliasNode:
+		return toJSON(node.Alias)
+	case yaml.MappingNode:
+		b.WriteString("{")
+		for i := 0; i < len(node.Content); i += 2 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			value, err := toJSON(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			b.Write(key)
+			b.WriteString(":")
+			b.Write(value)
+		}
+		b.WriteString("}")
+	case yaml.SequenceNode:
+		b.WriteString("[")
+		for i := range node.Content {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			value, err := toJSON(node.Content[i])
+			if err != nil {
+				return nil, err
+			}
+			b.Write(value)
+		}
+		b.WriteString("]")
+	case yaml.ScalarNode:
+		return scalarToJSON(node)
+	}
+	return b.Bytes(), nil
+}
+
+// scalarToJSON encodes the scalar node as a json value of the type of its tag.
+func scalarToJSON(node *yaml.Node) ([]byte, error) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	switch value.(type) {
+	case nil, bool, string:
+	case int, int64, uint64, float64:
+		if err := json.Unmarshal([]byte(node.Value), new(json.Number)); err == nil {
+			// keep the formatting of the number
+			return []byte(node.Value), nil
+		}
+	default:
+		// e.g. a timestamp
+		value = node.Value
+	}
+	b, err := json.Marshal(value)
+	return b, errors.Wrap(err)
+}
+
+// wrap wraps the nodes in a list of WrappingKind.
+func (w ByteWriter) wrap(nodes []*yaml.RNode) (*yaml.Node, error) {
 	items := &yaml.Node{Kind: yaml.SequenceNode}
 	list := &yaml.Node{
 		Kind:  yaml.MappingNode,
@@ -112,13 +282,23 @@ func (w ByteWriter) Write(nodes []*yaml.RNode) error {
 			&yaml.Node{Kind: yaml.ScalarNode, Value: "functionConfig"},
 			w.FunctionConfig.YNode())
 	}
+	if w.WrappingKind == ResourceListKind && len(w.Results) > 0 {
+		b, err := yaml.Marshal(w.Results)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		results, err := yaml.Parse(string(b))
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		list.Content = append(list.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "results"}, results.YNode())
+	}
 	doc := &yaml.Node{
 		Kind:    yaml.DocumentNode,
 		Content: []*yaml.Node{list}}
 	for i := range nodes {
 		items.Content = append(items.Content, nodes[i].YNode())
 	}
-	err := errors.Wrap(encoder.Encode(doc))
-	yaml.UndoSerializationHacksOnNodes(nodes)
-	return err
+	return doc, nil
 }
@@ -286,3 +286,51 @@ metadata:
     config.kubernetes.io/path: "a/b/a_test.yaml"
 `, buff.String())
 }
+
+// TestByteWriter_Write_sortByID tests:
+// - Resource Config is sorted by namespace, kind and name if SortByID is set
+func TestByteWriter_Write_sortByID(t *testing.T) {
+	node1, err := yaml.Parse(`kind: Service
+metadata:
+  name: a
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	node2, err := yaml.Parse(`kind: Deployment
+metadata:
+  name: b
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	node3, err := yaml.Parse(`kind: Namespace
+metadata:
+  name: a
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	buff := &bytes.Buffer{}
+	err = ByteWriter{
+		SortByID:               true,
+		NamespacesAndCRDsFirst: true,
+		Writer:                 buff}.
+		Write([]*yaml.RNode{node1, node2, node3})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `kind: Namespace
+metadata:
+  name: a
+---
+kind: Deployment
+metadata:
+  name: b
+---
+kind: Service
+metadata:
+  name: a
+`, buff.String())
+}
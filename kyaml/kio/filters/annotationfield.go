@@ -0,0 +1,158 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// AnnotationToField sets a field of the Resources from one of their annotations,
+// e.g. the `spec.replicas` of a Deployment from its `scaler` annotation:
+//
+//	AnnotationToField{Annotation: "scaler", Path: []string{"spec", "replicas"}}
+//
+// The value of the annotation is written as a plain scalar, so that `3` is set
+// as an int.  Resources without the annotation are left unchanged.
+type AnnotationToField struct {
+	// Annotation is the annotation which is read for the value.
+	Annotation string `yaml:"annotation,omitempty"`
+
+	// Path is the path of the field which is set.  Each part is either a field,
+	// or `[key=value]` to select every element of a list whose key field has the
+	// value, e.g. `spec`, `traits`, `[kind=ManualScalerTrait]`, `spec`, `replicaCount`.
+	//
+	// Missing fields are created, but missing list elements aren't: if no element
+	// matches, the Resource is left unchanged.
+	Path []string `yaml:"path,omitempty"`
+
+	// Selector selects the Resources which are set by their kind and apiVersion.
+	// An empty Kind or APIVersion matches any.
+	Selector yaml.TypeMeta `yaml:"selector,omitempty"`
+
+	// Overwrite if set replaces the fields which already have a value.  Fields
+	// with a value are left unchanged if unset.
+	Overwrite bool `yaml:"overwrite,omitempty"`
+}
+
+var _ kio.Filter = AnnotationToField{}
+
+func (f AnnotationToField) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	if f.Annotation == "" || len(f.Path) == 0 {
+		return nil, errors.Errorf("AnnotationToField requires an annotation and a path")
+	}
+	for i := range input {
+		meta, err := input[i].GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		if !f.matches(meta) {
+			continue
+		}
+		value, found := meta.Annotations[f.Annotation]
+		if !found {
+			continue
+		}
+		if err := f.set(input[i], 0, value); err != nil {
+			return nil, errors.Errorf("%s %s: %s annotation: %v",
+				meta.Kind, meta.Name, f.Annotation, err)
+		}
+	}
+	return input, nil
+}
+
+// matches returns true if the Resource with meta is selected by the Selector.
+func (f AnnotationToField) matches(meta yaml.ResourceMeta) bool {
+	return (f.Selector.Kind == "" || f.Selector.Kind == meta.Kind) &&
+		(f.Selector.APIVersion == "" || f.Selector.APIVersion == meta.APIVersion)
+}
+
+// set sets the field at Path[i:] under node to value, creating the missing fields.
+func (f AnnotationToField) set(node *yaml.RNode, i int, value string) error {
+	part := f.Path[i]
+	if yaml.IsListIndex(part) {
+		return f.setElements(node, i, value)
+	}
+	if node.YNode().Kind != yaml.MappingNode {
+		// the Resource itself is always a mapping, so node is the value of Path[i-1]
+		return errors.Errorf("%s: expected a mapping, got a %s", f.field(i-1), kindName(node))
+	}
+
+	field := node.Field(part)
+	if i == len(f.Path)-1 {
+		if field != nil && !yaml.IsMissingOrNull(field.Value) {
+			if field.Value.YNode().Kind != yaml.ScalarNode {
+				return errors.Errorf("%s: expected a scalar, got a %s",
+					f.field(i), kindName(field.Value))
+			}
+			if !f.Overwrite {
+				return nil
+			}
+		}
+		return node.PipeE(yaml.SetField(part, yaml.NewScalarRNode(value)))
+	}
+
+	if field != nil && !yaml.IsMissingOrNull(field.Value) {
+		return f.set(field.Value, i+1, value)
+	}
+	if yaml.IsListIndex(f.Path[i+1]) {
+		// there aren't any elements to select
+		return nil
+	}
+	child := yaml.NewRNode(&yaml.Node{Kind: yaml.MappingNode})
+	if err := node.PipeE(yaml.SetField(part, child)); err != nil {
+		return err
+	}
+	return f.set(child, i+1, value)
+}
+
+// setElements sets the field at Path[i+1:] under each element of the list node
+// which is selected by Path[i].
+func (f AnnotationToField) setElements(node *yaml.RNode, i int, value string) error {
+	key, match, err := yaml.SplitIndexNameValue(f.Path[i])
+	if err != nil || key == "" || i == len(f.Path)-1 {
+		return errors.Errorf("%s: expected [key=value] followed by a field", f.field(i))
+	}
+	if node.YNode().Kind != yaml.SequenceNode {
+		return errors.Errorf("%s: expected a list, got a %s", f.field(i), kindName(node))
+	}
+	return node.VisitElements(func(elem *yaml.RNode) error {
+		if elem.YNode().Kind != yaml.MappingNode {
+			return nil
+		}
+		if field := elem.Field(key); field == nil || yaml.GetValue(field.Value) != match {
+			return nil
+		}
+		return f.set(elem, i+1, value)
+	})
+}
+
+// field returns the path of the field at Path[i], e.g. `spec.traits[kind=X]`.
+func (f AnnotationToField) field(i int) string {
+	var s strings.Builder
+	for j, part := range f.Path[:i+1] {
+		if j > 0 && !yaml.IsListIndex(part) {
+			s.WriteString(".")
+		}
+		s.WriteString(part)
+	}
+	return s.String()
+}
+
+// kindName returns the name of the kind of node for the errors.
+func kindName(node *yaml.RNode) string {
+	switch node.YNode().Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	case yaml.ScalarNode:
+		return "scalar"
+	default:
+		return "node"
+	}
+}
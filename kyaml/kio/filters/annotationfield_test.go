@@ -0,0 +1,290 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	. "sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestAnnotationToField_Filter(t *testing.T) {
+	var tests = []struct {
+		name        string
+		filter      AnnotationToField
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			// the existing value is kept without Overwrite
+			name:   "keep-existing",
+			filter: AnnotationToField{Annotation: "scaler", Path: []string{"spec", "replicas"}},
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  replicas: 1
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  replicas: 1
+`,
+		},
+		{
+			name: "overwrite",
+			filter: AnnotationToField{Annotation: "scaler", Path: []string{"spec", "replicas"},
+				Overwrite: true},
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  replicas: 1
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  replicas: 3
+`,
+		},
+		{
+			name:   "missing-annotation",
+			filter: AnnotationToField{Annotation: "scaler", Path: []string{"spec", "replicas"}},
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+		},
+		{
+			name: "create",
+			filter: AnnotationToField{Annotation: "scaler",
+				Path: []string{"spec", "template", "replicas"}},
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  template:
+    replicas: 3
+`,
+		},
+		{
+			name: "selector",
+			filter: AnnotationToField{Annotation: "scaler", Path: []string{"spec", "replicas"},
+				Selector: yaml.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"}},
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: bar
+  annotations:
+    scaler: "3"
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: bar
+  annotations:
+    scaler: "3"
+spec:
+  replicas: 3
+`,
+		},
+		{
+			// only the matching elements are set, and the missing fields are created
+			name: "elements",
+			filter: AnnotationToField{Annotation: "scaler",
+				Path: []string{"spec", "traits", "[kind=ManualScalerTrait]", "spec", "replicaCount"}},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  traits:
+  - kind: ManualScalerTrait
+  - kind: RouteTrait
+  - kind: ManualScalerTrait
+    spec:
+      replicaCount: 1
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  traits:
+  - kind: ManualScalerTrait
+    spec:
+      replicaCount: 3
+  - kind: RouteTrait
+  - kind: ManualScalerTrait
+    spec:
+      replicaCount: 1
+`,
+		},
+		{
+			// the missing list isn't created, since there aren't any elements to set
+			name: "missing-elements",
+			filter: AnnotationToField{Annotation: "scaler",
+				Path: []string{"spec", "traits", "[kind=ManualScalerTrait]", "spec", "replicaCount"}},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec: {}
+`,
+			expected: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec: {}
+`,
+		},
+		{
+			name: "not-a-scalar",
+			filter: AnnotationToField{Annotation: "scaler", Path: []string{"spec", "replicas"},
+				Overwrite: true},
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  replicas:
+    count: 1
+`,
+			expectedErr: "Deployment foo: scaler annotation: spec.replicas: expected a scalar, got a mapping",
+		},
+		{
+			name:   "not-a-mapping",
+			filter: AnnotationToField{Annotation: "scaler", Path: []string{"spec", "replicas"}},
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+- replicas
+`,
+			expectedErr: "Deployment foo: scaler annotation: spec: expected a mapping, got a list",
+		},
+		{
+			name: "not-a-list",
+			filter: AnnotationToField{Annotation: "scaler",
+				Path: []string{"spec", "traits", "[kind=ManualScalerTrait]", "replicaCount"}},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  traits:
+    kind: ManualScalerTrait
+`,
+			expectedErr: "ApplicationConfiguration foo: scaler annotation: " +
+				"spec.traits[kind=ManualScalerTrait]: expected a list, got a mapping",
+		},
+		{
+			name: "invalid-element",
+			filter: AnnotationToField{Annotation: "scaler",
+				Path: []string{"spec", "traits", "[ManualScalerTrait]", "replicaCount"}},
+			input: `apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+spec:
+  traits: []
+`,
+			expectedErr: "ApplicationConfiguration foo: scaler annotation: " +
+				"spec.traits[ManualScalerTrait]: expected [key=value] followed by a field",
+		},
+		{
+			name:        "missing-path",
+			filter:      AnnotationToField{Annotation: "scaler"},
+			input:       "kind: Deployment\n",
+			expectedErr: "AnnotationToField requires an annotation and a path",
+		},
+	}
+
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := kio.Pipeline{
+				Inputs: []kio.Reader{&kio.ByteReader{Reader: bytes.NewBufferString(test.input),
+					OmitReaderAnnotations: true}},
+				Filters: []kio.Filter{test.filter},
+				Outputs: []kio.Writer{kio.ByteWriter{Writer: out}},
+			}.Execute()
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
@@ -6,6 +6,7 @@ package filters
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
@@ -137,6 +138,12 @@ type ContainerFilter struct {
 	// StorageMounts is a list of storage options that the container will have mounted.
 	StorageMounts []StorageMount `yaml:"mounts,omitempty"`
 
+	// Env is a list of environment variables set in the container, in addition
+	// to the variables of the parent process.  Variables given as NAME=VALUE
+	// are set to VALUE, and variables given as NAME are forwarded from the
+	// parent process.
+	Env []string `yaml:"envs,omitempty"`
+
 	// Config is the API configuration for the container and passed through the
 	// API_CONFIG env var to the container.
 	// Typically a Kubernetes style Resource Config.
@@ -146,6 +153,14 @@ type ContainerFilter struct {
 	// nodes instead of only nodes scoped under the function.
 	GlobalScope bool
 
+	// Results is set by Filter, and is the results field of the ResourceList
+	// output by the function.
+	Results kio.Results `yaml:"-"`
+
+	// Stderr if set is where the stderr of the container is written instead of
+	// os.Stderr
+	Stderr io.Writer `yaml:"-"`
+
 	// args may be specified by tests to override how a container is spawned
 	args []string
 
@@ -157,7 +172,11 @@ func (c ContainerFilter) String() string {
 }
 
 func (s *StorageMount) String() string {
-	return fmt.Sprintf("type=%s,src=%s,dst=%s:ro", s.MountType, s.Src, s.DstPath)
+	m := fmt.Sprintf("type=%s,src=%s,dst=%s", s.MountType, s.Src, s.DstPath)
+	if !s.ReadWriteMode {
+		m += ",readonly"
+	}
+	return m
 }
 
 func StringToStorageMount(s string) StorageMount {
@@ -165,6 +184,10 @@ func StringToStorageMount(s string) StorageMount {
 	options := strings.Split(s, ",")
 	for _, option := range options {
 		keyVal := strings.SplitN(option, "=", 2)
+		if len(keyVal) == 1 {
+			// flags such as rw may be given without a value
+			keyVal = append(keyVal, "true")
+		}
 		m[keyVal[0]] = keyVal[1]
 	}
 	var sm StorageMount
@@ -176,6 +199,8 @@ func StringToStorageMount(s string) StorageMount {
 			sm.Src = value
 		case key == "dst":
 			sm.DstPath = value
+		case key == "rw":
+			sm.ReadWriteMode = value == "true"
 		}
 	}
 	return sm
@@ -303,6 +328,7 @@ func (c *ContainerFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.Results = r.Results
 
 	// annotate any generated Resources with a path and index if they don't already have one
 	if err := kioutil.DefaultPathAnnotation(functionDir, output); err != nil {
@@ -349,6 +375,11 @@ func (c *ContainerFilter) getArgs() []string {
 		}
 		args = append(args, "-e", tokens[0])
 	}
+	// the variables of the function are set after the parent process variables
+	// so that they take precedence
+	for _, e := range c.Env {
+		args = append(args, "-e", e)
+	}
 	return append(args, c.Image)
 }
 
@@ -372,6 +403,9 @@ func (c *ContainerFilter) getCommand() (*exec.Cmd, error) {
 
 	cmd := exec.Command(c.args[0], c.args[1:]...)
 	cmd.Stderr = os.Stderr
+	if c.Stderr != nil {
+		cmd.Stderr = c.Stderr
+	}
 	cmd.Env = os.Environ()
 
 	// set stderr for err messaging
@@ -70,13 +70,14 @@ metadata:
 	if !assert.NoError(t, err) {
 		return
 	}
-	bindMount := StorageMount{"bind", "/mount/path", "/local/"}
-	localVol := StorageMount{"volume", "myvol", "/local/"}
-	tmpfs := StorageMount{"tmpfs", "", "/local/"}
+	bindMount := StorageMount{"bind", "/mount/path", "/local/", false}
+	localVol := StorageMount{"volume", "myvol", "/local/", false}
+	tmpfs := StorageMount{"tmpfs", "", "/local/", false}
+	rwMount := StorageMount{"bind", "/mount/path", "/output/", true}
 	instance := &ContainerFilter{
 		Image:         "example.com:version",
 		Config:        cfg,
-		StorageMounts: []StorageMount{bindMount, localVol, tmpfs},
+		StorageMounts: []StorageMount{bindMount, localVol, tmpfs, rwMount},
 	}
 	cmd, err := instance.getCommand()
 	if !assert.NoError(t, err) {
@@ -90,9 +91,10 @@ metadata:
 		"--network", "none",
 		"--user", "nobody",
 		"--security-opt=no-new-privileges",
-		"--mount", fmt.Sprintf("type=%s,src=%s,dst=%s:ro", "bind", "/mount/path", "/local/"),
-		"--mount", fmt.Sprintf("type=%s,src=%s,dst=%s:ro", "volume", "myvol", "/local/"),
-		"--mount", fmt.Sprintf("type=%s,src=%s,dst=%s:ro", "tmpfs", "", "/local/"),
+		"--mount", fmt.Sprintf("type=%s,src=%s,dst=%s,readonly", "bind", "/mount/path", "/local/"),
+		"--mount", fmt.Sprintf("type=%s,src=%s,dst=%s,readonly", "volume", "myvol", "/local/"),
+		"--mount", fmt.Sprintf("type=%s,src=%s,dst=%s,readonly", "tmpfs", "", "/local/"),
+		"--mount", fmt.Sprintf("type=%s,src=%s,dst=%s", "bind", "/mount/path", "/output/"),
 	}
 	for _, e := range os.Environ() {
 		// the process env
@@ -141,6 +143,42 @@ metadata:
 	assert.Equal(t, expected, cmd.Args)
 }
 
+func TestFilter_command_env(t *testing.T) {
+	cfg, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	instance := &ContainerFilter{
+		Image:  "example.com:version",
+		Config: cfg,
+		Env:    []string{"TOKEN", "SCHEMAS=/schemas"},
+	}
+	cmd, err := instance.getCommand()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the function variables follow the process variables
+	args := cmd.Args[len(cmd.Args)-5:]
+	assert.Equal(t, []string{"-e", "TOKEN", "-e", "SCHEMAS=/schemas", "example.com:version"}, args)
+}
+
+func TestStringToStorageMount(t *testing.T) {
+	assert.Equal(t,
+		StorageMount{MountType: "bind", Src: "/schemas", DstPath: "/schemas"},
+		StringToStorageMount("type=bind,src=/schemas,dst=/schemas"))
+	assert.Equal(t,
+		StorageMount{MountType: "bind", Src: "/out", DstPath: "/out", ReadWriteMode: true},
+		StringToStorageMount("type=bind,src=/out,dst=/out,rw=true"))
+	assert.Equal(t,
+		StorageMount{MountType: "bind", Src: "/out", DstPath: "/out", ReadWriteMode: true},
+		StringToStorageMount("type=bind,src=/out,dst=/out,rw"))
+}
+
 func TestFilter_Filter(t *testing.T) {
 	cfg, err := yaml.Parse(`apiVersion: apps/v1
 kind: Deployment
@@ -396,6 +434,33 @@ container:
 `,
 		},
 
+		{
+			name: "envs and rw mounts",
+			resource: `
+apiVersion: v1beta1
+kind: Example
+metadata:
+  annotations:
+    config.kubernetes.io/function: |-
+      container:
+        image: foo:v1.0.0
+        envs: [TOKEN, SCHEMAS=/schemas]
+        mounts: [{type: bind, src: ./schemas, dst: /schemas, rw: true}]
+`,
+			expectedFn: `
+container:
+    image: foo:v1.0.0
+    mounts:
+      - type: bind
+        src: ./schemas
+        dst: /schemas
+        rw: true
+    envs:
+      - TOKEN
+      - SCHEMAS=/schemas
+`,
+		},
+
 		{
 			name: "network",
 			resource: `
@@ -1179,6 +1244,60 @@ metadata:
 `, b.String())
 }
 
+func TestFilter_Filter_results(t *testing.T) {
+	cfg, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    config.kubernetes.io/path: 'foo/bar.yaml'
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	c := &ContainerFilter{
+		Image:  "example.com:version",
+		Config: cfg,
+		args: []string{"echo", `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: deployment-foo
+results:
+- severity: error
+  message: replicas must be positive
+  resourceRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: deployment-foo
+  field: spec.replicas
+- severity: info
+  message: checked 1 Resource
+`},
+	}
+	result, err := c.Filter(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, result, 1)
+	assert.Equal(t, kio.Results{
+		{
+			Severity: kio.SeverityError,
+			Message:  "replicas must be positive",
+			ResourceRef: yaml.ResourceIdentifier{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "deployment-foo",
+			},
+			Field: "spec.replicas",
+		},
+		{Severity: kio.SeverityInfo, Message: "checked 1 Resource"},
+	}, c.Results)
+}
+
 func TestContainerFilter_scope(t *testing.T) {
 	cf := &ContainerFilter{}
 
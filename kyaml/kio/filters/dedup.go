@@ -0,0 +1,46 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DedupFilter de-duplicates the Resources with the same
+// apiVersion/kind/namespace/name, e.g. the Resources emitted by several
+// pipelines which are read together.  The last occurrence of each Resource
+// is kept, at its position in the input, and the others are dropped.
+//
+// Unlike the MergeFilter, the Resources aren't merged: the kept Resource is
+// written as is.
+type DedupFilter struct{}
+
+var _ kio.Filter = DedupFilter{}
+
+func (DedupFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	keys := make([]mergeKey, len(input))
+	last := map[mergeKey]int{}
+	for i := range input {
+		meta, err := input[i].GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = mergeKey{
+			apiVersion: meta.APIVersion,
+			kind:       meta.Kind,
+			namespace:  meta.Namespace,
+			name:       meta.Name,
+		}
+		last[keys[i]] = i
+	}
+
+	var output []*yaml.RNode
+	for i := range input {
+		if last[keys[i]] == i {
+			output = append(output, input[i])
+		}
+	}
+	return output, nil
+}
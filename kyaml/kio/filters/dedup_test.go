@@ -0,0 +1,82 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	. "sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+func TestDedupFilter_Filter(t *testing.T) {
+	// the same Application is read from two pipelines
+	first := `apiVersion: app.k8s.io/v1beta1
+kind: Application
+metadata:
+  name: foo
+  namespace: default
+spec:
+  descriptor:
+    version: "1"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: default
+`
+	second := `apiVersion: app.k8s.io/v1beta1
+kind: Application
+metadata:
+  name: foo
+  namespace: default
+spec:
+  descriptor:
+    version: "2"
+---
+apiVersion: app.k8s.io/v1beta1
+kind: Application
+metadata:
+  name: foo
+  namespace: other
+`
+	out := &bytes.Buffer{}
+	err := kio.Pipeline{
+		Inputs: []kio.Reader{
+			&kio.ByteReader{Reader: bytes.NewBufferString(first), OmitReaderAnnotations: true},
+			&kio.ByteReader{Reader: bytes.NewBufferString(second), OmitReaderAnnotations: true},
+		},
+		Filters: []kio.Filter{DedupFilter{}},
+		Outputs: []kio.Writer{kio.ByteWriter{Writer: out}},
+	}.Execute()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// only the last Application in the default namespace is kept
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: default
+---
+apiVersion: app.k8s.io/v1beta1
+kind: Application
+metadata:
+  name: foo
+  namespace: default
+spec:
+  descriptor:
+    version: "2"
+---
+apiVersion: app.k8s.io/v1beta1
+kind: Application
+metadata:
+  name: foo
+  namespace: other
+`, out.String())
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ExecFilter filters Resources using a local executable.
+// The executable is run the same as the process of a ContainerFilter -- it reads
+// a ResourceList from stdin and writes the filtered ResourceList to stdout.
+//
+// The Resources are scoped to the function the same as for a ContainerFilter.
+// If the executable exits non-zero, the error contains its stderr.
+type ExecFilter struct {
+	// Path is the path to the executable.
+	Path string `yaml:"path,omitempty"`
+
+	// Config is the functionConfig of the ResourceList.
+	Config *yaml.RNode `yaml:"config,omitempty"`
+
+	// GlobalScope will cause the function to be run against all input
+	// nodes instead of only nodes scoped under the function.
+	GlobalScope bool
+
+	// Results is set by Filter, and is the results field of the ResourceList
+	// output by the executable.
+	Results kio.Results `yaml:"-"`
+
+	// Stderr if set is where the stderr of the executable is written if it succeeds,
+	// instead of os.Stderr
+	Stderr io.Writer `yaml:"-"`
+}
+
+func (c ExecFilter) String() string {
+	return c.Path
+}
+
+// Filter implements kio.Filter
+func (c *ExecFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	stderr := &bytes.Buffer{}
+	cf := &ContainerFilter{
+		Config:      c.Config,
+		GlobalScope: c.GlobalScope,
+		args:        []string{c.Path},
+		Stderr:      stderr,
+	}
+	output, err := cf.Filter(nodes)
+	c.Results = cf.Results
+	if err != nil {
+		return nil, errors.Errorf("%s: %v\n%s", c.Path, err, stderr.String())
+	}
+	// the executable succeeded, so its stderr is only informational
+	out := c.Stderr
+	if out == nil {
+		out = os.Stderr
+	}
+	if _, err := out.Write(stderr.Bytes()); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return output, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// writeExecutable writes a shell script to dir and returns its path.
+func writeExecutable(t *testing.T, dir, script string) string {
+	p := filepath.Join(dir, "fn.sh")
+	if !assert.NoError(t, ioutil.WriteFile(p, []byte("#!/bin/sh\n"+script), 0700)) {
+		t.FailNow()
+	}
+	return p
+}
+
+func TestExecFilter_Filter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-exec")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := yaml.Parse(`apiVersion: example.com/v1
+kind: Example
+metadata:
+  name: foo
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	input, err := (&kio.ByteReader{Reader: bytes.NewBufferString(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo
+`)}).Read()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the executable reads the ResourceList from stdin and writes it to stdout
+	f := &ExecFilter{
+		Path:   writeExecutable(t, dir, `sed "s/Deployment/StatefulSet/g"`+"\n"),
+		Config: cfg,
+	}
+	output, err := f.Filter(input)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := &bytes.Buffer{}
+	if !assert.NoError(t, kio.ByteWriter{Writer: b}.Write(output)) {
+		return
+	}
+	assert.Equal(t, `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: deployment-foo
+  annotations:
+    config.kubernetes.io/path: 'statefulset_deployment-foo.yaml'
+`, b.String())
+}
+
+func TestExecFilter_Filter_error(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-exec")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := yaml.Parse(`kind: Example`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	p := writeExecutable(t, dir, `echo "replicas must be positive" >&2
+echo "  in deployment-foo" >&2
+exit 2
+`)
+	_, err = (&ExecFilter{Path: p, Config: cfg}).Filter(nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, p+": exit status 2\n"+
+			"replicas must be positive\n  in deployment-foo\n", err.Error())
+	}
+}
+
+func TestExecFilter_Filter_results(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-exec")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := yaml.Parse(`kind: Example`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	f := &ExecFilter{Path: writeExecutable(t, dir, `cat <<EOF
+apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items: []
+results:
+- severity: warning
+  message: no Resources
+EOF
+`), Config: cfg}
+	_, err = f.Filter(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, kio.Results{{Severity: kio.SeverityWarning, Message: "no Resources"}},
+		f.Results)
+}
@@ -16,6 +16,7 @@ import (
 // Filters are the list of known filters for unmarshalling a filter into a concrete
 // implementation.
 var Filters = map[string]func() kio.Filter{
+	"DedupFilter":   func() kio.Filter { return DedupFilter{} },
 	"FileSetter":    func() kio.Filter { return &FileSetter{} },
 	"FormatFilter":  func() kio.Filter { return &FormatFilter{} },
 	"GrepFilter":    func() kio.Filter { return GrepFilter{} },
@@ -101,20 +102,54 @@ func (f MatchModifyFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 	return input, nil
 }
 
+// MatchFilter filters RNodes matching the pipeline and the label and annotation
+// selectors.
+//
+// Selectors follow the Kubernetes label selector syntax -- a comma separated list
+// of requirements which must all be met, e.g. "app=frontend,tier!=cache".
+// Requirements may be one of:
+// `key`, `!key`, `key=value`, `key==value`, `key!=value`, `key in (a,b)` and
+// `key notin (a,b)`.
+// Resources without the key don't meet `key=value` and `key in (a,b)`, and do
+// meet `key!=value` and `key notin (a,b)`.
 type MatchFilter struct {
 	Kind string `yaml:"kind,omitempty"`
 
+	// Filters is the pipeline which must return a value for the Resources to match.
 	Filters yaml.YFilters `yaml:"pipeline,omitempty"`
+
+	// LabelSelector is the selector matched against the labels.
+	// Matches all Resources if empty.
+	LabelSelector string `yaml:"labelSelector,omitempty"`
+
+	// AnnotationSelector is the selector matched against the annotations.
+	// Matches all Resources if empty.
+	AnnotationSelector string `yaml:"annotationSelector,omitempty"`
+
+	// InvertMatch if set will only pass through the Resources which don't match.
+	InvertMatch bool `yaml:"invertMatch,omitempty"`
 }
 
 var _ kio.Filter = &MatchFilter{}
 
 func (f MatchFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	// parse the selectors 1 time
+	labels, err := parseSelector(f.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := parseSelector(f.AnnotationSelector)
+	if err != nil {
+		return nil, err
+	}
+
 	var output []*yaml.RNode
 	for i := range input {
-		if v, err := input[i].Pipe(f.Filters.Filters()...); err != nil {
+		found, err := f.matches(input[i], labels, annotations)
+		if err != nil {
 			return nil, err
-		} else if v == nil {
+		}
+		if found == f.InvertMatch {
 			continue
 		}
 		output = append(output, input[i])
@@ -122,6 +157,22 @@ func (f MatchFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 	return output, nil
 }
 
+// matches returns true if the Resource matches the pipeline and the selectors.
+func (f MatchFilter) matches(node *yaml.RNode, labels, annotations selector) (bool, error) {
+	if v, err := node.Pipe(f.Filters.Filters()...); err != nil || v == nil {
+		return false, err
+	}
+	if len(labels) == 0 && len(annotations) == 0 {
+		return true, nil
+	}
+	// Resources without metadata have no labels or annotations
+	meta, err := node.GetMeta()
+	if err != nil && err != yaml.ErrMissingMetadata {
+		return false, err
+	}
+	return labels.matches(meta.Labels) && annotations.matches(meta.Annotations), nil
+}
+
 type FilenameFmtVerb string
 
 const (
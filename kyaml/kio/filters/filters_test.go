@@ -168,3 +168,128 @@ metadata:
     config.kubernetes.io/path: 'resource.yaml'
 `, out.String())
 }
+
+func TestMatchFilter_Filter(t *testing.T) {
+	in := `kind: Deployment
+metadata:
+  name: frontend
+  labels:
+    app: frontend
+    tier: web
+  annotations:
+    owner: team-a
+---
+kind: Deployment
+metadata:
+  name: cache
+  labels:
+    app: frontend
+    tier: cache
+---
+kind: Deployment
+metadata:
+  name: backend
+  labels:
+    app: backend
+  annotations:
+    owner: team-b
+---
+kind: Service
+metadata:
+  name: unlabeled
+---
+kind: ConfigMap
+`
+	testCases := []struct {
+		name     string
+		filter   MatchFilter
+		expected []string
+	}{
+		{
+			name:     "empty",
+			filter:   MatchFilter{},
+			expected: []string{"frontend", "cache", "backend", "unlabeled", ""},
+		},
+		{
+			name:     "equals",
+			filter:   MatchFilter{LabelSelector: "app=frontend"},
+			expected: []string{"frontend", "cache"},
+		},
+		{
+			name:     "double-equals",
+			filter:   MatchFilter{LabelSelector: "app==frontend"},
+			expected: []string{"frontend", "cache"},
+		},
+		{
+			name:     "not-equals",
+			filter:   MatchFilter{LabelSelector: "app=frontend,tier!=cache"},
+			expected: []string{"frontend"},
+		},
+		{
+			name:     "not-equals-missing",
+			filter:   MatchFilter{LabelSelector: "tier!=cache"},
+			expected: []string{"frontend", "backend", "unlabeled", ""},
+		},
+		{
+			name:     "in",
+			filter:   MatchFilter{LabelSelector: "app in (frontend, backend),tier in (web)"},
+			expected: []string{"frontend"},
+		},
+		{
+			name:     "notin",
+			filter:   MatchFilter{LabelSelector: "tier notin (cache,web)"},
+			expected: []string{"backend", "unlabeled", ""},
+		},
+		{
+			name:     "exists",
+			filter:   MatchFilter{LabelSelector: "tier"},
+			expected: []string{"frontend", "cache"},
+		},
+		{
+			name:     "does-not-exist",
+			filter:   MatchFilter{LabelSelector: "!app"},
+			expected: []string{"unlabeled", ""},
+		},
+		{
+			name:     "annotations",
+			filter:   MatchFilter{LabelSelector: "app", AnnotationSelector: "owner=team-b"},
+			expected: []string{"backend"},
+		},
+		{
+			name:     "invert",
+			filter:   MatchFilter{LabelSelector: "app=frontend", InvertMatch: true},
+			expected: []string{"backend", "unlabeled", ""},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := (&ByteReader{
+				Reader:                bytes.NewBufferString(in),
+				OmitReaderAnnotations: true,
+			}).Read()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			nodes, err = tc.filter.Filter(nodes)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var names []string
+			for i := range nodes {
+				meta, _ := nodes[i].GetMeta()
+				names = append(names, meta.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestMatchFilter_Filter_invalid(t *testing.T) {
+	for _, s := range []string{"app=frontend,=foo", "app foo", "!"} {
+		_, err := MatchFilter{LabelSelector: s}.Filter(nil)
+		if assert.Error(t, err, s) {
+			assert.Contains(t, err.Error(), "invalid selector", s)
+		}
+	}
+}
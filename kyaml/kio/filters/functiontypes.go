@@ -11,6 +11,10 @@ import (
 const (
 	FunctionAnnotationKey    = "config.kubernetes.io/function"
 	oldFunctionAnnotationKey = "config.k8s.io/function"
+
+	// FunctionOrderAnnotationKey is an integer which orders the functions read from
+	// a package.  Functions with lower values run first.  Defaults to 0.
+	FunctionOrderAnnotationKey = "config.kubernetes.io/function-order"
 )
 
 var functionAnnotationKeys = []string{FunctionAnnotationKey, oldFunctionAnnotationKey}
@@ -29,6 +33,9 @@ type FunctionSpec struct {
 	// Starlark is the spec for running a function as a starlark script
 	Starlark StarlarkSpec `json:"starlark,omitempty" yaml:"starlark,omitempty"`
 
+	// Exec is the spec for running a function as a local executable
+	Exec ExecSpec `json:"exec,omitempty" yaml:"exec,omitempty"`
+
 	// Mounts are the storage or directories to mount into the container
 	StorageMounts []StorageMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
 }
@@ -43,6 +50,10 @@ type ContainerSpec struct {
 
 	// Mounts are the storage or directories to mount into the container
 	StorageMounts []StorageMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
+
+	// Env is a list of environment variables to set in the container, either as
+	// NAME=VALUE or as NAME to forward the variable from the parent process
+	Env []string `json:"envs,omitempty" yaml:"envs,omitempty"`
 }
 
 // ContainerNetwork
@@ -57,6 +68,16 @@ type StarlarkSpec struct {
 
 	// Path specifies a path to a starlark script
 	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Program is the source of a starlark script, and may be set instead of Path
+	Program string `json:"program,omitempty" yaml:"program,omitempty"`
+}
+
+// ExecSpec defines how to run a function as a local executable
+type ExecSpec struct {
+	// Path is the path to the executable.  A relative path is relative to the
+	// package directory.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
 }
 
 // StorageMount represents a container's mounted storage option(s)
@@ -72,6 +93,10 @@ type StorageMount struct {
 
 	// The path where the file or directory is mounted in the container.
 	DstPath string `json:"dst,omitempty" yaml:"dst,omitempty"`
+
+	// ReadWriteMode if set mounts the storage read-write.  Mounts are
+	// read-only by default.
+	ReadWriteMode bool `json:"rw,omitempty" yaml:"rw,omitempty"`
 }
 
 // GetFunctionSpec returns the FunctionSpec for a resource.  Returns
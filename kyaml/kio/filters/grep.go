@@ -5,8 +5,10 @@ package filters
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -19,9 +21,17 @@ const (
 	GreaterThan
 	LessThan
 	LessThanEq
+	// Exists matches Resources which have the field, whatever its value
+	Exists
 )
 
 // GrepFilter filters RNodes with a matching field
+//
+// The comparison MatchTypes use Compare, which compares the values of the fields
+// with Value as numbers by default.  The values are parsed as decimal numbers
+// regardless of their yaml type, so that the string "5" and the int 5 are equal.
+// Fields with values which Compare can't parse don't match, while a Value which it
+// can't parse is an error.
 type GrepFilter struct {
 	Path        []string `yaml:"path,omitempty"`
 	Value       string   `yaml:"value,omitempty"`
@@ -39,7 +49,16 @@ func (f GrepFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 	if f.MatchType == Regexp || f.MatchType == 0 {
 		reg, err = regexp.Compile(f.Value)
 		if err != nil {
-			return nil, err
+			return nil, errors.Errorf("invalid regular expression %q: %v", f.Value, err)
+		}
+	}
+	compare := f.Compare
+	if compare == nil {
+		compare = compareNumbers
+	}
+	if f.MatchType&(GreaterThanEq|GreaterThan|LessThan|LessThanEq) != 0 {
+		if _, err := compare(f.Value, f.Value); err != nil {
+			return nil, errors.Errorf("invalid value %q for comparison: %v", f.Value, err)
 		}
 	}
 
@@ -56,8 +75,12 @@ func (f GrepFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 			}
 			continue
 		}
-		found := false
+		found := f.MatchType == Exists
 		err = val.VisitElements(func(elem *yaml.RNode) error {
+			if f.MatchType == Exists {
+				return nil
+			}
+
 			// get the value
 			var str string
 			if f.MatchType == Regexp {
@@ -85,9 +108,10 @@ func (f GrepFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 				return nil
 			}
 
-			comp, err := f.Compare(str, f.Value)
+			comp, err := compare(str, f.Value)
 			if err != nil {
-				return err
+				// the field can't be compared with the value
+				return nil
 			}
 
 			if f.MatchType == GreaterThan && comp > 0 {
@@ -115,3 +139,161 @@ func (f GrepFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 	}
 	return output, nil
 }
+
+// compareNumbers compares a and b as decimal numbers.
+func compareNumbers(a, b string) (int, error) {
+	na, err := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	if err != nil {
+		return 0, errors.Errorf("%s is not a number", a)
+	}
+	nb, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if err != nil {
+		return 0, errors.Errorf("%s is not a number", b)
+	}
+	switch {
+	case na < nb:
+		return -1, nil
+	case na > nb:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// grepOperators are the operators of grep queries by their MatchType.  Operators
+// which are a prefix of another operator come after it.
+var grepOperators = []struct {
+	op        string
+	matchType GrepType
+}{
+	{op: "=~", matchType: Regexp},
+	{op: ">=", matchType: GreaterThanEq},
+	{op: "<=", matchType: LessThanEq},
+	{op: ">", matchType: GreaterThan},
+	{op: "<", matchType: LessThan},
+	{op: "=", matchType: Regexp},
+}
+
+// ParseGrepQuery parses a query of the form `path.to.field<op>value` into a
+// GrepFilter.
+//
+// Fields are separated by '.', and list elements are matched as
+// 'list[field=value]'.  '.' as part of a field or a value is escaped as '\.'.
+// The operator is one of:
+//
+//   - `=~` matches fields with values matching the regular expression, which is
+//     taken as is
+//   - `=` matches fields with values matching the regular expression, in which
+//     '\.' is unescaped
+//   - `>`, `>=`, `<` and `<=` compare the values of the fields with the value
+//   - no operator and value matches Resources which have the field
+func ParseGrepQuery(query string) (GrepFilter, error) {
+	path, op, value := splitGrepQuery(query)
+	if path == "" {
+		return GrepFilter{}, errors.Errorf("missing field path in query %q", query)
+	}
+	f := GrepFilter{MatchType: Exists}
+	if op != "" {
+		for _, o := range grepOperators {
+			if o.op == op {
+				f.MatchType = o.matchType
+			}
+		}
+		f.Value = value
+		if op != "=~" {
+			if _, op, _ := splitGrepQuery(value); op != "" {
+				return GrepFilter{}, errors.Errorf(
+					"ambiguous match -- multiple of '<', '>', '<=', '>=', '=', '=~' in query %q", query)
+			}
+			f.Value = strings.ReplaceAll(value, `\.`, ".")
+		}
+	}
+
+	switch f.MatchType {
+	case Regexp:
+		if _, err := regexp.Compile(f.Value); err != nil {
+			return GrepFilter{}, errors.Errorf(
+				"invalid regular expression %q in query %q: %v", f.Value, query, err)
+		}
+	case GreaterThanEq, GreaterThan, LessThan, LessThanEq:
+		if f.Value == "" {
+			return GrepFilter{}, errors.Errorf("missing value to compare with in query %q", query)
+		}
+	}
+
+	var err error
+	f.Path, err = splitGrepPath(path)
+	return f, err
+}
+
+// splitGrepQuery splits query at the first operator which isn't escaped or part
+// of a list element.  op is empty if query doesn't contain an operator.
+func splitGrepQuery(query string) (path, op, value string) {
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+			continue
+		case '[':
+			depth++
+			continue
+		case ']':
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		for _, o := range grepOperators {
+			if strings.HasPrefix(query[i:], o.op) {
+				return query[:i], o.op, query[i+len(o.op):]
+			}
+		}
+	}
+	return query, "", ""
+}
+
+// splitGrepPath splits path into its fields and list elements.
+func splitGrepPath(path string) ([]string, error) {
+	var parts []string
+	var part strings.Builder
+	depth := 0
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\' && i+1 < len(path) && path[i+1] == '.':
+			part.WriteByte('.')
+			i++
+			continue
+		case c == '.' && depth == 0:
+			parts = append(parts, part.String())
+			part.Reset()
+			continue
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+		part.WriteByte(c)
+	}
+	parts = append(parts, part.String())
+
+	// split the list elements from the list fields
+	var result []string
+	for _, p := range parts {
+		i := strings.Index(p, "[")
+		if i < 0 {
+			result = append(result, p)
+			continue
+		}
+		if strings.Count(p, "[") != 1 || !strings.HasSuffix(p, "]") {
+			return nil, errors.Errorf("unrecognized path element: %s.  "+
+				"Should be of the form 'list[field=value]'", p)
+		}
+		if i > 0 {
+			result = append(result, p[:i])
+		}
+		result = append(result, p[i:])
+	}
+	return result, nil
+}
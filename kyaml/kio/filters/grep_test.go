@@ -155,3 +155,233 @@ func TestGrepFilter_error(t *testing.T) {
 	}
 	assert.Nil(t, v)
 }
+
+func TestParseGrepQuery(t *testing.T) {
+	var tests = []struct {
+		name     string
+		query    string
+		expected GrepFilter
+		err      string
+	}{
+		{
+			name:     "regexp",
+			query:    "metadata.name=foo",
+			expected: GrepFilter{Path: []string{"metadata", "name"}, Value: "foo", MatchType: Regexp},
+		},
+		{
+			name:  "regexp-operator",
+			query: `spec.template.spec.containers[name=app].image=~gcr\.io/.*:v1\.[0-9]+`,
+			expected: GrepFilter{
+				Path:      []string{"spec", "template", "spec", "containers", "[name=app]", "image"},
+				Value:     `gcr\.io/.*:v1\.[0-9]+`,
+				MatchType: Regexp,
+			},
+		},
+		{
+			name:  "escaped-dots",
+			query: `metadata.annotations.a\.b/c=nginx:1\.7\.9`,
+			expected: GrepFilter{
+				Path:      []string{"metadata", "annotations", "a.b/c"},
+				Value:     "nginx:1.7.9",
+				MatchType: Regexp,
+			},
+		},
+		{
+			name:  "list-element-with-dots",
+			query: "spec.containers[name=a.b].image=foo",
+			expected: GrepFilter{
+				Path:      []string{"spec", "containers", "[name=a.b]", "image"},
+				Value:     "foo",
+				MatchType: Regexp,
+			},
+		},
+		{
+			name:     "greater-than",
+			query:    "spec.replicas>5",
+			expected: GrepFilter{Path: []string{"spec", "replicas"}, Value: "5", MatchType: GreaterThan},
+		},
+		{
+			name:     "greater-than-eq",
+			query:    "spec.replicas>=5",
+			expected: GrepFilter{Path: []string{"spec", "replicas"}, Value: "5", MatchType: GreaterThanEq},
+		},
+		{
+			name:     "less-than",
+			query:    "spec.replicas<5",
+			expected: GrepFilter{Path: []string{"spec", "replicas"}, Value: "5", MatchType: LessThan},
+		},
+		{
+			name:     "less-than-eq",
+			query:    "spec.replicas<=5",
+			expected: GrepFilter{Path: []string{"spec", "replicas"}, Value: "5", MatchType: LessThanEq},
+		},
+		{
+			name:     "exists",
+			query:    "metadata.annotations.scaler",
+			expected: GrepFilter{Path: []string{"metadata", "annotations", "scaler"}, MatchType: Exists},
+		},
+		{
+			name:  "invalid-regexp",
+			query: "metadata.name=~foo(",
+			err: `invalid regular expression "foo(" in query "metadata.name=~foo(": ` +
+				"error parsing regexp: missing closing ): `foo(`",
+		},
+		{
+			name:  "ambiguous",
+			query: "metadata.name=foo=bar",
+			err:   "ambiguous match",
+		},
+		{
+			name:  "missing-value",
+			query: "spec.replicas>",
+			err:   `missing value to compare with in query "spec.replicas>"`,
+		},
+		{
+			name:  "missing-path",
+			query: "=foo",
+			err:   `missing field path in query "=foo"`,
+		},
+		{
+			name:  "unrecognized-path-element",
+			query: "spec.containers[a[b=c].image=foo",
+			err:   "unrecognized path element: containers[a[b=c]",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			f, err := ParseGrepQuery(test.query)
+			if test.err != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, test.expected, f)
+		})
+	}
+}
+
+func TestGrepFilter_Filter_operators(t *testing.T) {
+	in := `kind: Deployment
+metadata:
+  name: int
+  annotations:
+    scaler: hpa
+spec:
+  replicas: 5
+  template:
+    spec:
+      containers:
+      - name: app
+        image: gcr.io/project/app:v1.2
+      - name: sidecar
+        image: envoy:v1.2
+---
+kind: Deployment
+metadata:
+  name: string
+spec:
+  replicas: "10"
+  template:
+    spec:
+      containers:
+      - name: app
+        image: docker.io/app:v1.2
+---
+kind: Deployment
+metadata:
+  name: not-a-number
+spec:
+  replicas: ${replicas}
+---
+kind: Deployment
+metadata:
+  name: float
+spec:
+  replicas: 2.5
+`
+	var tests = []struct {
+		name     string
+		query    string
+		invert   bool
+		expected []string
+	}{
+		{name: "greater-than", query: "spec.replicas>5", expected: []string{"string"}},
+		{name: "greater-than-eq", query: "spec.replicas>=5", expected: []string{"int", "string"}},
+		{name: "less-than", query: "spec.replicas<10", expected: []string{"int", "float"}},
+		{name: "less-than-eq", query: "spec.replicas<=10", expected: []string{"int", "string", "float"}},
+		{name: "less-than-float", query: "spec.replicas<2.6", expected: []string{"float"}},
+		{
+			name:     "regexp",
+			query:    `spec.template.spec.containers[name=app].image=~^gcr\.io/`,
+			expected: []string{"int"},
+		},
+		{
+			name:     "regexp-list-element",
+			query:    `spec.template.spec.containers[name=sidecar].image=~v1\.2$`,
+			expected: []string{"int"},
+		},
+		{name: "exists", query: "metadata.annotations.scaler", expected: []string{"int"}},
+		{
+			name:     "not-exists",
+			query:    "metadata.annotations.scaler",
+			invert:   true,
+			expected: []string{"string", "not-a-number", "float"},
+		},
+		{
+			name:     "exists-map",
+			query:    "spec.template",
+			expected: []string{"int", "string"},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			f, err := ParseGrepQuery(test.query)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			f.InvertMatch = test.invert
+			nodes, err := (&kio.ByteReader{Reader: bytes.NewBufferString(in)}).Read()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			nodes, err = f.Filter(nodes)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var names []string
+			for i := range nodes {
+				meta, err := nodes[i].GetMeta()
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				names = append(names, meta.Name)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestGrepFilter_Filter_invalidValue(t *testing.T) {
+	_, err := GrepFilter{Path: []string{"spec", "replicas"}, Value: "five",
+		MatchType: GreaterThan}.Filter([]*yaml.RNode{yaml.MustParse("spec: {replicas: 5}")})
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `invalid value "five" for comparison: five is not a number`, err.Error())
+}
+
+func TestGrepFilter_Filter_invalidRegexp(t *testing.T) {
+	_, err := GrepFilter{Path: []string{"metadata", "name"}, Value: "foo(",
+		MatchType: Regexp}.Filter([]*yaml.RNode{yaml.MustParse("metadata: {name: foo}")})
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), `invalid regular expression "foo(":`)
+}
@@ -16,8 +16,14 @@ import (
 // - Fields with matching keys will be merged recursively
 // - Lists with an associative key (e.g. name) will have their elements merged using the key
 // - List without an associative key will have the dest list replaced by the source list
+// - Maps and associative list elements with `$patch: delete` in the source will be deleted
+// from the destination, and maps and lists with `$patch: replace` will be replaced
 type MergeFilter struct {
 	Reverse bool
+
+	// MergeKeys maps the paths of list fields to the keys their elements are merged
+	// by, for lists whose merge key isn't in the schema.  See merge2.Merger.
+	MergeKeys map[string]string
 }
 
 type mergeKey struct {
@@ -64,7 +70,7 @@ func (c MergeFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 				// first resources, don't merge it
 				merged = resources[i]
 			} else {
-				merged, err = merge2.Merge(patch, merged)
+				merged, err = merge2.Merger{MergeKeys: c.MergeKeys}.Merge(patch, merged)
 				if err != nil {
 					return nil, err
 				}
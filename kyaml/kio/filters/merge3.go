@@ -5,6 +5,8 @@ package filters
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -30,6 +32,14 @@ type Merge3 struct {
 	// This may be necessary if the directory contains multiple copies of
 	// the same resource, or resources patches.
 	MergeOnPath bool
+
+	// KeepDeleted will keep the resources which were deleted in the updated package
+	// in the destination package, instead of deleting them.
+	KeepDeleted bool
+
+	// MergeKeys maps the paths of list fields to the keys their elements are merged
+	// by, for lists whose merge key isn't in the schema.  See merge3.Visitor.
+	MergeKeys map[string]string
 }
 
 func (m Merge3) Merge() error {
@@ -57,14 +67,27 @@ func (m Merge3) Merge() error {
 		SetAnnotations: map[string]string{mergeSourceAnnotation: mergeSourceUpdated},
 	})
 
-	return kio.Pipeline{
-		Inputs:  inputs,
-		Filters: []kio.Filter{m},
-		Outputs: []kio.Writer{dest},
-	}.Execute()
+	var nodes []*yaml.RNode
+	for i := range inputs {
+		n, err := inputs[i].Read()
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n...)
+	}
+	output, err := m.Filter(nodes)
+	if err != nil {
+		return err
+	}
+	// write the destination package even if all of its resources were deleted
+	return dest.Write(output)
 }
 
 // Filter combines Resources with the same GVK + N + NS into tuples, and then merges them
+//
+// Resources deleted in the updated package are deleted from the destination, unless
+// KeepDeleted is set.  Filter fails with a conflict listing the Resources which were
+// deleted in the update but modified locally.
 func (m Merge3) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	// index the nodes by their identity
 	tl := tuples{mergeOnPath: m.MergeOnPath}
@@ -76,6 +99,7 @@ func (m Merge3) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 
 	// iterate over the inputs, merging as needed
 	var output []*yaml.RNode
+	var conflicts []string
 	for i := range tl.list {
 		t := tl.list[i]
 		switch {
@@ -85,15 +109,29 @@ func (m Merge3) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 		case t.original == nil && t.updated != nil && t.dest == nil:
 			// added in the update -- add update
 			output = append(output, t.updated)
-		case t.original != nil && t.updated == nil:
+		case t.original != nil && t.updated == nil && t.dest != nil:
 			// deleted in the update
-		// don't include the resource in the output
+			if m.KeepDeleted {
+				output = append(output, t.dest)
+				continue
+			}
+			modified, err := t.isModified()
+			if err != nil {
+				return nil, err
+			}
+			if modified {
+				conflicts = append(conflicts, t.id())
+			}
+			// don't include the resource in the output
+		case t.original != nil && t.updated == nil:
+			// deleted in the update and locally
+			// don't include the resource in the output
 		case t.original != nil && t.dest == nil:
 			// deleted locally
 			// don't include the resource in the output
 		default:
 			// dest and updated are non-nil -- merge them
-			node, err := t.merge()
+			node, err := t.merge(m.MergeKeys)
 			if err != nil {
 				return nil, err
 			}
@@ -102,6 +140,11 @@ func (m Merge3) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 			}
 		}
 	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("resources deleted in the update were modified locally: %s",
+			strings.Join(conflicts, ", "))
+	}
 	return output, nil
 }
 
@@ -197,7 +240,35 @@ func (t *tuple) add(node *yaml.RNode) error {
 	return nil
 }
 
-// merge performs a 3-way merge on the tuple
-func (t *tuple) merge() (*yaml.RNode, error) {
-	return merge3.Merge(t.dest, t.original, t.updated)
+// id returns the identity of the tuple's Resource.
+func (t *tuple) id() string {
+	return fmt.Sprintf("%s %s %s/%s",
+		t.meta.APIVersion, t.meta.Kind, t.meta.Namespace, t.meta.Name)
+}
+
+// isModified returns true if dest differs from original, ignoring the annotations
+// set when reading them.
+func (t *tuple) isModified() (bool, error) {
+	var values []string
+	for _, node := range []*yaml.RNode{t.original, t.dest} {
+		node = node.Copy()
+		for _, a := range []string{
+			mergeSourceAnnotation, kioutil.IndexAnnotation, kioutil.PathAnnotation} {
+			if err := node.PipeE(yaml.ClearAnnotation(a)); err != nil {
+				return false, err
+			}
+		}
+		s, err := node.String()
+		if err != nil {
+			return false, err
+		}
+		values = append(values, s)
+	}
+	return values[0] != values[1], nil
+}
+
+// merge performs a 3-way merge on the tuple, merging the lists in mergeKeys by
+// their keys
+func (t *tuple) merge(mergeKeys map[string]string) (*yaml.RNode, error) {
+	return merge3.Visitor{MergeKeys: mergeKeys}.Merge(t.dest, t.original, t.updated)
 }
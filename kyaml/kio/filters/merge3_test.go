@@ -129,3 +129,116 @@ func TestMerge3_Merge_fail(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+// TestMerge3_Merge_deleted tests that resources deleted in the update are deleted
+// from the destination, unless they were modified locally
+func TestMerge3_Merge_deleted(t *testing.T) {
+	configMap := func(name, value string) string {
+		return `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ` + name + `
+  namespace: default
+data:
+  value: "` + value + `"
+`
+	}
+	var tests = []struct {
+		name        string
+		keepDeleted bool
+		original    map[string]string
+		updated     map[string]string
+		dest        map[string]string
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			name:     "deleted",
+			original: map[string]string{"a.yaml": configMap("a", "1"), "b.yaml": configMap("b", "1")},
+			updated:  map[string]string{"a.yaml": configMap("a", "1")},
+			dest:     map[string]string{"a.yaml": configMap("a", "1"), "b.yaml": configMap("b", "1")},
+			expected: map[string]string{"a.yaml": configMap("a", "1")},
+		},
+		{
+			name:     "all-deleted",
+			original: map[string]string{"a.yaml": configMap("a", "1")},
+			updated:  map[string]string{},
+			dest:     map[string]string{"a.yaml": configMap("a", "1")},
+			expected: map[string]string{},
+		},
+		{
+			name:        "deleted-and-modified",
+			original:    map[string]string{"a.yaml": configMap("a", "1"), "b.yaml": configMap("b", "1")},
+			updated:     map[string]string{"a.yaml": configMap("a", "1")},
+			dest:        map[string]string{"a.yaml": configMap("a", "1"), "b.yaml": configMap("b", "2")},
+			expected:    map[string]string{"a.yaml": configMap("a", "1"), "b.yaml": configMap("b", "2")},
+			expectedErr: "resources deleted in the update were modified locally: v1 ConfigMap default/b",
+		},
+		{
+			name:        "keep-deleted",
+			keepDeleted: true,
+			original:    map[string]string{"a.yaml": configMap("a", "1"), "b.yaml": configMap("b", "1")},
+			updated:     map[string]string{"a.yaml": configMap("a", "1")},
+			dest:        map[string]string{"a.yaml": configMap("a", "1"), "b.yaml": configMap("b", "2")},
+			expected:    map[string]string{"a.yaml": configMap("a", "1"), "b.yaml": configMap("b", "2")},
+		},
+		{
+			name:     "added-locally",
+			original: map[string]string{"a.yaml": configMap("a", "1")},
+			updated:  map[string]string{},
+			dest:     map[string]string{"a.yaml": configMap("a", "1"), "c.yaml": configMap("c", "1")},
+			expected: map[string]string{"c.yaml": configMap("c", "1")},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kyaml-test")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+			for pkg, files := range map[string]map[string]string{
+				"original": test.original, "updated": test.updated, "dest": test.dest} {
+				if !assert.NoError(t, os.MkdirAll(filepath.Join(dir, pkg), 0700)) {
+					t.FailNow()
+				}
+				for name, content := range files {
+					err := ioutil.WriteFile(filepath.Join(dir, pkg, name), []byte(content), 0600)
+					if !assert.NoError(t, err) {
+						t.FailNow()
+					}
+				}
+			}
+
+			err = filters.Merge3{
+				OriginalPath: filepath.Join(dir, "original"),
+				UpdatedPath:  filepath.Join(dir, "updated"),
+				DestPath:     filepath.Join(dir, "dest"),
+				KeepDeleted:  test.keepDeleted,
+			}.Merge()
+			if test.expectedErr != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Equal(t, test.expectedErr, err.Error())
+			} else if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			actual := map[string]string{}
+			files, err := ioutil.ReadDir(filepath.Join(dir, "dest"))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			for _, f := range files {
+				b, err := ioutil.ReadFile(filepath.Join(dir, "dest", f.Name()))
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				actual[f.Name()] = string(b)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/sets"
+)
+
+// selector is a parsed label selector.  It matches if all of its requirements are met.
+type selector []requirement
+
+// requirement is a single requirement of a selector -- e.g. "app=frontend".
+type requirement struct {
+	key string
+
+	// op is one of "exists", "!", "=", "!=", "in" and "notin"
+	op string
+
+	values sets.String
+}
+
+// setRequirement matches the `key in (a,b)` and `key notin (a,b)` requirements
+var setRequirement = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// parseSelector parses the requirements of a selector.
+func parseSelector(s string) (selector, error) {
+	var sel selector
+	for _, r := range splitRequirements(s) {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		req := requirement{values: sets.String{}}
+		if m := setRequirement.FindStringSubmatch(r); m != nil {
+			req.key, req.op = m[1], m[2]
+			for _, v := range strings.Split(m[3], ",") {
+				req.values.Insert(strings.TrimSpace(v))
+			}
+		} else if strings.HasPrefix(r, "!") {
+			req.key, req.op = strings.TrimSpace(r[1:]), "!"
+		} else if i := strings.Index(r, "!="); i >= 0 {
+			req.key, req.op = r[:i], "!="
+			req.values.Insert(strings.TrimSpace(r[i+2:]))
+		} else if i := strings.Index(r, "="); i >= 0 {
+			value := strings.TrimPrefix(r[i+1:], "=")
+			req.key, req.op = r[:i], "="
+			req.values.Insert(strings.TrimSpace(value))
+		} else {
+			req.key, req.op = r, "exists"
+		}
+
+		req.key = strings.TrimSpace(req.key)
+		if req.key == "" || strings.ContainsAny(req.key, " \t=!(),") {
+			return nil, fmt.Errorf("invalid selector %q: invalid requirement %q", s, r)
+		}
+		sel = append(sel, req)
+	}
+	return sel, nil
+}
+
+// splitRequirements splits a selector on the commas which aren't in a set of values.
+func splitRequirements(s string) []string {
+	var reqs []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				reqs = append(reqs, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(reqs, s[start:])
+}
+
+// matches returns true if the values meet all of the requirements.
+func (s selector) matches(values map[string]string) bool {
+	for _, r := range s {
+		value, found := values[r.key]
+		var met bool
+		switch r.op {
+		case "exists":
+			met = found
+		case "!":
+			met = !found
+		case "=", "in":
+			met = found && r.values.Has(value)
+		case "!=", "notin":
+			met = !found || !r.values.Has(value)
+		}
+		if !met {
+			return false
+		}
+	}
+	return true
+}
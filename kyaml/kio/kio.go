@@ -6,7 +6,13 @@
 package kio
 
 import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -126,3 +132,75 @@ func FilterAll(filter yaml.Filter) Filter {
 		return nodes, nil
 	})
 }
+
+// FilterAllParallel runs the yaml.Filter against all inputs, using workers goroutines.
+// workers defaults to runtime.GOMAXPROCS if it is not positive.
+//
+// The yaml.Filter is run concurrently, and must only read and modify the Resource it
+// is run against -- it must not depend on the other Resources or their order.
+// The inputs are returned in their order.
+//
+// The yaml.Filter is run against all inputs even if it fails for some of them, and the
+// errors are returned together, prefixed by the path and index annotations of their
+// Resource.
+func FilterAllParallel(filter yaml.Filter, workers int) Filter {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+		errs := make([]error, len(nodes))
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					if _, err := filter.Filter(nodes[i]); err != nil {
+						errs[i] = errors.WrapPrefixf(err, resourcePrefix(i, nodes[i]))
+					}
+				}
+			}()
+		}
+		for i := range nodes {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+
+		var filterErrs filterErrors
+		for i := range errs {
+			if errs[i] != nil {
+				filterErrs = append(filterErrs, errs[i])
+			}
+		}
+		if len(filterErrs) > 0 {
+			return nil, filterErrs
+		}
+		return nodes, nil
+	})
+}
+
+// resourcePrefix returns the prefix for the errors of the i'th Resource, from its
+// path and index annotations if it has them.
+func resourcePrefix(i int, node *yaml.RNode) string {
+	path, index, _ := kioutil.GetFileAnnotations(node)
+	if index == "" {
+		index = fmt.Sprintf("%d", i)
+	}
+	if path == "" {
+		return fmt.Sprintf("[%s]", index)
+	}
+	return fmt.Sprintf("%s [%s]", path, index)
+}
+
+// filterErrors contains the errors of each Resource a Filter failed for.
+type filterErrors []error
+
+func (e filterErrors) Error() string {
+	var msgs []string
+	for i := range e {
+		msgs = append(msgs, e[i].Error())
+	}
+	return strings.Join(msgs, "\n")
+}
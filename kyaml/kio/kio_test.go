@@ -4,10 +4,14 @@
 package kio_test
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	. "sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func TestPipe(t *testing.T) {
@@ -26,3 +30,91 @@ func TestPipe(t *testing.T) {
 func TestSlice_Write(t *testing.T) {
 
 }
+
+// parallelInputs returns n Resources with an index annotation
+func parallelInputs(t testing.TB, n int) []*yaml.RNode {
+	var nodes []*yaml.RNode
+	for i := 0; i < n; i++ {
+		node, err := yaml.Parse(fmt.Sprintf(`kind: ConfigMap
+metadata:
+  name: cm-%d
+  annotations:
+    config.kubernetes.io/index: '%d'
+`, i, i))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func TestFilterAllParallel(t *testing.T) {
+	nodes := parallelInputs(t, 100)
+	result, err := FilterAllParallel(yaml.SetLabel("app", "foo"), 4).Filter(nodes)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	if !assert.Len(t, result, 100) {
+		assert.FailNow(t, "wrong number items")
+	}
+	for i := range result {
+		meta, err := result[i].GetMeta()
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, fmt.Sprintf("cm-%d", i), meta.Name)
+		assert.Equal(t, "foo", meta.Labels["app"])
+	}
+}
+
+func TestFilterAllParallel_errors(t *testing.T) {
+	nodes := parallelInputs(t, 10)
+	if !assert.NoError(t, nodes[7].PipeE(
+		yaml.SetAnnotation("config.kubernetes.io/path", "a/b.yaml"))) {
+		assert.FailNow(t, "")
+	}
+	filter := yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
+		meta, err := node.GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		if meta.Name == "cm-2" || meta.Name == "cm-7" {
+			return nil, fmt.Errorf("invalid %s", meta.Name)
+		}
+		return node, nil
+	})
+
+	_, err := FilterAllParallel(filter, 0).Filter(nodes)
+	if assert.Error(t, err) {
+		assert.Equal(t, "[2]: invalid cm-2\na/b.yaml [7]: invalid cm-7", err.Error())
+	}
+}
+
+// BenchmarkFilterAllParallel runs a CPU bound filter against 5000 Resources
+func BenchmarkFilterAllParallel(b *testing.B) {
+	filter := yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
+		s, err := node.String()
+		if err != nil {
+			return nil, err
+		}
+		sum := []byte(s)
+		for i := 0; i < 100; i++ {
+			h := sha256.Sum256(sum)
+			sum = h[:]
+		}
+		return node, nil
+	})
+	nodes := parallelInputs(b, 5000)
+
+	for workers := 1; workers <= runtime.GOMAXPROCS(0); workers *= 2 {
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			f := FilterAllParallel(filter, workers)
+			for i := 0; i < b.N; i++ {
+				if _, err := f.Filter(nodes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package kioutil
 import (
 	"fmt"
 	"path"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -22,6 +23,10 @@ const (
 
 	// PathAnnotation records the path to the file the Resource was read from
 	PathAnnotation AnnotationKey = "config.kubernetes.io/path"
+
+	// FormatAnnotation records the format the Resource was read in if it
+	// wasn't yaml -- e.g. json
+	FormatAnnotation AnnotationKey = "config.kubernetes.io/format"
 )
 
 func GetFileAnnotations(rn *yaml.RNode) (string, string, error) {
@@ -57,6 +62,25 @@ func CreatePathAnnotationValue(dir string, m yaml.ResourceMeta) string {
 	return path.Join(dir, m.Namespace, filename)
 }
 
+// patternField matches the "{.field.path}" references in a path pattern
+var patternField = regexp.MustCompile(`\{\.([^}]*)\}`)
+
+// CreatePathFromPattern creates a path for a Resource from a pattern, replacing each
+// "{.field.path}" reference with the value of the field -- e.g.
+// "{.kind}-{.metadata.name}.yaml".  Missing fields are replaced with "".
+func CreatePathFromPattern(pattern string, rn *yaml.RNode) (string, error) {
+	var err error
+	p := patternField.ReplaceAllStringFunc(pattern, func(ref string) string {
+		field, lookupErr := rn.Pipe(yaml.Lookup(
+			strings.Split(patternField.FindStringSubmatch(ref)[1], ".")...))
+		if lookupErr != nil && err == nil {
+			err = errors.WrapPrefixf(lookupErr, "invalid reference %s", ref)
+		}
+		return yaml.GetValue(field)
+	})
+	return p, err
+}
+
 // DefaultPathAndIndexAnnotation sets a default path or index value on any nodes missing the
 // annotation
 func DefaultPathAndIndexAnnotation(dir string, nodes []*yaml.RNode) error {
@@ -231,3 +255,63 @@ func SortNodes(nodes []*yaml.RNode) error {
 	})
 	return errors.Wrap(err)
 }
+
+// SortNodesByID sorts nodes in place by namespace, kind, name and apiVersion.
+// If namespacesAndCRDsFirst is set, Namespaces and then CustomResourceDefinitions
+// are sorted before the other nodes.
+// Nodes with the PathAnnotation annotation are sorted after the other nodes as
+// by SortNodes, so that they keep their order within their files.
+// Nodes which are otherwise equal keep their order.
+func SortNodesByID(nodes []*yaml.RNode, namespacesAndCRDsFirst bool) error {
+	var unpathed, pathed []*yaml.RNode
+	metas := map[*yaml.RNode]yaml.ResourceMeta{}
+	for i := range nodes {
+		meta, err := nodes[i].GetMeta()
+		if err != nil && err != yaml.ErrMissingMetadata {
+			return errors.Wrap(err)
+		}
+		if meta.Annotations[PathAnnotation] != "" {
+			pathed = append(pathed, nodes[i])
+			continue
+		}
+		metas[nodes[i]] = meta
+		unpathed = append(unpathed, nodes[i])
+	}
+
+	rank := func(meta yaml.ResourceMeta) int {
+		if !namespacesAndCRDsFirst {
+			return 0
+		}
+		switch meta.Kind {
+		case "Namespace":
+			return 0
+		case "CustomResourceDefinition":
+			return 1
+		default:
+			return 2
+		}
+	}
+	// use stable sort to keep ordering of equal elements
+	sort.SliceStable(unpathed, func(i, j int) bool {
+		iMeta, jMeta := metas[unpathed[i]], metas[unpathed[j]]
+		if rank(iMeta) != rank(jMeta) {
+			return rank(iMeta) < rank(jMeta)
+		}
+		if iMeta.Namespace != jMeta.Namespace {
+			return iMeta.Namespace < jMeta.Namespace
+		}
+		if iMeta.Kind != jMeta.Kind {
+			return iMeta.Kind < jMeta.Kind
+		}
+		if iMeta.Name != jMeta.Name {
+			return iMeta.Name < jMeta.Name
+		}
+		return iMeta.APIVersion < jMeta.APIVersion
+	})
+	if err := SortNodes(pathed); err != nil {
+		return err
+	}
+
+	copy(nodes, append(unpathed, pathed...))
+	return nil
+}
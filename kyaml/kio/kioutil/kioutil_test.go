@@ -330,3 +330,164 @@ func TestCreatePathAnnotationValue(t *testing.T) {
 		}
 	}
 }
+
+func TestSortNodesByID(t *testing.T) {
+	input := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+  namespace: foo
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+  namespace: foo
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  namespace: foo
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: foo
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: a
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+  annotations:
+    config.kubernetes.io/path: a.yaml
+    config.kubernetes.io/index: '1'
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  annotations:
+    config.kubernetes.io/path: a.yaml
+    config.kubernetes.io/index: '0'
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: foo
+  annotations:
+    id: first
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: foo
+  annotations:
+    id: second
+`
+	names := func(nodes []*yaml.RNode) []string {
+		var names []string
+		for i := range nodes {
+			meta, err := nodes[i].GetMeta()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			name := meta.Namespace + "/" + meta.Kind + "/" + meta.Name
+			if id := meta.Annotations["id"]; id != "" {
+				name += "/" + id
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+
+	testCases := []struct {
+		name                   string
+		namespacesAndCRDsFirst bool
+		expected               []string
+	}{
+		{
+			name: "by id",
+			expected: []string{
+				"/CustomResourceDefinition/a",
+				"/Namespace/foo",
+				"foo/ConfigMap/a/first",
+				"foo/ConfigMap/a/second",
+				"foo/Deployment/a",
+				"foo/Deployment/b",
+				"foo/Service/a",
+				"/Deployment/a",
+				"/Deployment/c",
+			},
+		},
+		{
+			name:                   "namespaces and crds first",
+			namespacesAndCRDsFirst: true,
+			expected: []string{
+				"/Namespace/foo",
+				"/CustomResourceDefinition/a",
+				"foo/ConfigMap/a/first",
+				"foo/ConfigMap/a/second",
+				"foo/Deployment/a",
+				"foo/Deployment/b",
+				"foo/Service/a",
+				"/Deployment/a",
+				"/Deployment/c",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := (&kio.ByteReader{
+				Reader:                bytes.NewBufferString(input),
+				OmitReaderAnnotations: true,
+			}).Read()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			if !assert.NoError(t, kioutil.SortNodesByID(nodes, tc.namespacesAndCRDsFirst)) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.expected, names(nodes))
+		})
+	}
+}
+
+func TestCreatePathFromPattern(t *testing.T) {
+	rn, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: bar
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	testCases := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: "{.kind}-{.metadata.name}.yaml", expected: "Deployment-foo.yaml"},
+		{pattern: "{.metadata.namespace}/{.metadata.name}.yaml", expected: "bar/foo.yaml"},
+		{pattern: "{.metadata.labels.app}foo.yaml", expected: "foo.yaml"},
+		{pattern: "foo.yaml", expected: "foo.yaml"},
+	}
+	for _, tc := range testCases {
+		p, err := kioutil.CreatePathFromPattern(tc.pattern, rn)
+		if !assert.NoError(t, err, tc.pattern) {
+			t.FailNow()
+		}
+		assert.Equal(t, tc.expected, p, tc.pattern)
+	}
+}
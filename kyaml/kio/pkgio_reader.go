@@ -6,7 +6,9 @@ package kio
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -52,6 +54,21 @@ type LocalPackageReadWriter struct {
 	// Defaults to ["*.yaml", "*.yml"] if empty.  To match all files specify ["*"].
 	MatchFilesGlob []string `yaml:"matchFilesGlob,omitempty"`
 
+	// MatchFilesGlobPath configures Read to match MatchFilesGlob against the path of the
+	// files relative to the package rather than against their name, e.g. "**/*.yaml".
+	MatchFilesGlobPath bool `yaml:"matchFilesGlobPath,omitempty"`
+
+	// ExcludeDirsGlob configures Read to skip the directories, and their contents, whose
+	// path relative to the package matches any of the provided patterns.
+	// "**" matches any number of directories, e.g. "**/testdata/**" or "charts/**".
+	// Symlinked directories are never followed.
+	ExcludeDirsGlob []string `yaml:"excludeDirsGlob,omitempty"`
+
+	// ExcludeFilesGlob configures Read to skip the files whose path relative to the
+	// package matches any of the provided patterns.
+	// "**" matches any number of directories, e.g. "**/*_test.yaml".
+	ExcludeFilesGlob []string `yaml:"excludeFilesGlob,omitempty"`
+
 	// IncludeSubpackages will configure Read to read Resources from subpackages.
 	// Subpackages are identified by presence of PackageFileName.
 	IncludeSubpackages bool `yaml:"includeSubpackages,omitempty"`
@@ -70,6 +87,14 @@ type LocalPackageReadWriter struct {
 	// NoDeleteFiles if set to true, LocalPackageReadWriter won't delete any files
 	NoDeleteFiles bool `yaml:"noDeleteFiles,omitempty"`
 
+	// FilenamePattern if set, will write each Resource without a path to its own file.
+	// See LocalPackageWriter.FilenamePattern.
+	FilenamePattern string `yaml:"filenamePattern,omitempty"`
+
+	// NamespaceDirectories if set with FilenamePattern, will write the files under a
+	// directory per namespace.  See LocalPackageWriter.NamespaceDirectories.
+	NamespaceDirectories bool `yaml:"namespaceDirectories,omitempty"`
+
 	files sets.String
 }
 
@@ -77,6 +102,9 @@ func (r *LocalPackageReadWriter) Read() ([]*yaml.RNode, error) {
 	nodes, err := LocalPackageReader{
 		PackagePath:         r.PackagePath,
 		MatchFilesGlob:      r.MatchFilesGlob,
+		MatchFilesGlobPath:  r.MatchFilesGlobPath,
+		ExcludeDirsGlob:     r.ExcludeDirsGlob,
+		ExcludeFilesGlob:    r.ExcludeFilesGlob,
 		IncludeSubpackages:  r.IncludeSubpackages,
 		ErrorIfNonResources: r.ErrorIfNonResources,
 		SetAnnotations:      r.SetAnnotations,
@@ -107,6 +135,8 @@ func (r *LocalPackageReadWriter) Write(nodes []*yaml.RNode) error {
 		PackagePath:           r.PackagePath,
 		ClearAnnotations:      clear,
 		KeepReaderAnnotations: r.KeepReaderAnnotations,
+		FilenamePattern:       r.FilenamePattern,
+		NamespaceDirectories:  r.NamespaceDirectories,
 	}.Write(nodes)
 	if err != nil {
 		return errors.Wrap(err)
@@ -148,6 +178,21 @@ type LocalPackageReader struct {
 	// Defaults to ["*.yaml", "*.yml"] if empty.  To match all files specify ["*"].
 	MatchFilesGlob []string `yaml:"matchFilesGlob,omitempty"`
 
+	// MatchFilesGlobPath configures Read to match MatchFilesGlob against the path of the
+	// files relative to the package rather than against their name, e.g. "**/*.yaml".
+	MatchFilesGlobPath bool `yaml:"matchFilesGlobPath,omitempty"`
+
+	// ExcludeDirsGlob configures Read to skip the directories, and their contents, whose
+	// path relative to the package matches any of the provided patterns.
+	// "**" matches any number of directories, e.g. "**/testdata/**" or "charts/**".
+	// Symlinked directories are never followed.
+	ExcludeDirsGlob []string `yaml:"excludeDirsGlob,omitempty"`
+
+	// ExcludeFilesGlob configures Read to skip the files whose path relative to the
+	// package matches any of the provided patterns.
+	// "**" matches any number of directories, e.g. "**/*_test.yaml".
+	ExcludeFilesGlob []string `yaml:"excludeFilesGlob,omitempty"`
+
 	// IncludeSubpackages will configure Read to read Resources from subpackages.
 	// Subpackages are identified by presence of PackageFileName.
 	IncludeSubpackages bool `yaml:"includeSubpackages,omitempty"`
@@ -199,23 +244,35 @@ func (r LocalPackageReader) Read() ([]*yaml.RNode, error) {
 			pathRelativeTo = filepath.Dir(r.PackagePath)
 		}
 
+		// get the relative path to file within the package so we can write the files back out
+		// to another location.
+		relPath, err := filepath.Rel(pathRelativeTo, path)
+		if err != nil {
+			return errors.WrapPrefixf(err, pathRelativeTo)
+		}
+
 		// check if we should skip the directory or file
 		if info.IsDir() {
+			if match, err := matchAnyPath(r.ExcludeDirsGlob, relPath); err != nil {
+				return err
+			} else if match {
+				return filepath.SkipDir
+			}
 			return r.shouldSkipDir(path)
 		}
-		if match, err := r.shouldSkipFile(info); err != nil {
+		if info.Mode()&os.ModeSymlink != 0 {
+			// symlinked directories aren't followed
+			if target, err := os.Stat(path); err == nil && target.IsDir() {
+				return nil
+			}
+		}
+		if match, err := r.shouldSkipFile(relPath, info); err != nil {
 			return err
 		} else if !match {
 			// skip this file
 			return nil
 		}
-
-		// get the relative path to file within the package so we can write the files back out
-		// to another location.
-		path, err = filepath.Rel(pathRelativeTo, path)
-		if err != nil {
-			return errors.WrapPrefixf(err, pathRelativeTo)
-		}
+		path = relPath
 
 		r.initReaderAnnotations(path, info)
 		nodes, err := r.readFile(filepath.Join(pathRelativeTo, path), info)
@@ -240,13 +297,23 @@ func (r *LocalPackageReader) readFile(path string, _ os.FileInfo) ([]*yaml.RNode
 		Reader:                f,
 		OmitReaderAnnotations: r.OmitReaderAnnotations,
 		SetAnnotations:        r.SetAnnotations,
+		// json files are formatted as yaml
+		DisableJSON: true,
 	}
 	return rr.Read()
 }
 
-// shouldSkipFile returns true if the file should be skipped
-func (r *LocalPackageReader) shouldSkipFile(info os.FileInfo) (bool, error) {
+// shouldSkipFile returns false if the file at the package relative path should be skipped
+func (r *LocalPackageReader) shouldSkipFile(path string, info os.FileInfo) (bool, error) {
+	// check if the files are excluded
+	if match, err := matchAnyPath(r.ExcludeFilesGlob, path); err != nil || match {
+		return false, err
+	}
+
 	// check if the files are in scope
+	if r.MatchFilesGlobPath {
+		return matchAnyPath(r.MatchFilesGlob, path)
+	}
 	for _, g := range r.MatchFilesGlob {
 		if match, err := filepath.Match(g, info.Name()); err != nil {
 			return false, errors.Wrap(err)
@@ -257,6 +324,44 @@ func (r *LocalPackageReader) shouldSkipFile(info os.FileInfo) (bool, error) {
 	return false, nil
 }
 
+// matchAnyPath returns true if the package relative path matches any of the patterns.
+// Patterns are matched by matchPath.
+func matchAnyPath(patterns []string, p string) (bool, error) {
+	segments := strings.Split(filepath.ToSlash(p), "/")
+	for _, g := range patterns {
+		if match, err := matchPath(strings.Split(g, "/"), segments); err != nil {
+			return false, errors.WrapPrefixf(err, g)
+		} else if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchPath returns true if the path segments match the pattern segments.
+// A "**" pattern segment matches any number of path segments, including none,
+// and the other pattern segments are matched with path.Match.
+func matchPath(pattern, segments []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if match, err := matchPath(pattern[1:], segments[i:]); err != nil || match {
+					return match, err
+				}
+			}
+			return false, nil
+		}
+		if len(segments) == 0 {
+			return false, nil
+		}
+		if match, err := path.Match(pattern[0], segments[0]); err != nil || !match {
+			return false, err
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0, nil
+}
+
 // initReaderAnnotations adds the LocalPackageReader Annotations to r.SetAnnotations
 func (r *LocalPackageReader) initReaderAnnotations(path string, _ os.FileInfo) {
 	if r.SetAnnotations == nil {
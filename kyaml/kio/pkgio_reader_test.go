@@ -12,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	. "sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	// "sigs.k8s.io/kustomize/kyaml/testutil"
 )
 
@@ -338,6 +339,90 @@ metadata:
 	}
 }
 
+func TestLocalPackageReader_Read_excludeGlob(t *testing.T) {
+	s := setupDirectories(t)
+	defer s.clean()
+	s.writeFile(t, filepath.Join("a", "a_test.yaml"), readFileB)
+	s.writeFile(t, filepath.Join("a", "testdata", "b_test.yaml"), readFileB)
+	s.writeFile(t, filepath.Join("a", "c", "c_test.yml"), readFileB)
+	s.writeFile(t, filepath.Join("a", "c", "d_test.json"), []byte(`{"e": "f"}`))
+	s.writeFile(t, filepath.Join("charts", "e", "e_test.yaml"), readFileB)
+	s.writeFile(t, filepath.Join("testdata", "f_test.yaml"), readFileB)
+	s.writeFile(t, "g_test.yaml", readFileB)
+	s.writeFile(t, "h_fixture.yaml", readFileB)
+	err := os.Symlink(filepath.Join(s.root, "charts"), filepath.Join(s.root, "linked.yaml"))
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+
+	testCases := []struct {
+		name     string
+		reader   LocalPackageReader
+		expected []string
+	}{
+		{
+			name: "exclude",
+			reader: LocalPackageReader{
+				ExcludeDirsGlob:  []string{"**/testdata/**", "charts/**"},
+				ExcludeFilesGlob: []string{"*_fixture.yaml"},
+			},
+			expected: []string{"a/a_test.yaml", "a/c/c_test.yml", "g_test.yaml"},
+		},
+		{
+			name: "match path",
+			reader: LocalPackageReader{
+				MatchFilesGlob:     []string{"a/**/*.yaml", "a/**/*.json"},
+				MatchFilesGlobPath: true,
+				ExcludeDirsGlob:    []string{"**/testdata"},
+			},
+			expected: []string{"a/a_test.yaml", "a/c/d_test.json"},
+		},
+		{
+			name:   "no exclude",
+			reader: LocalPackageReader{},
+			expected: []string{
+				"a/a_test.yaml",
+				"a/c/c_test.yml",
+				"a/testdata/b_test.yaml",
+				"charts/e/e_test.yaml",
+				"g_test.yaml",
+				"h_fixture.yaml",
+				"testdata/f_test.yaml",
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			tc.reader.PackagePath = s.root
+			nodes, err := tc.reader.Read()
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			var paths []string
+			for i := range nodes {
+				meta, err := nodes[i].GetMeta()
+				if !assert.NoError(t, err) {
+					assert.FailNow(t, err.Error())
+				}
+				paths = append(paths, filepath.ToSlash(meta.Annotations[kioutil.PathAnnotation]))
+			}
+			assert.Equal(t, tc.expected, paths)
+		})
+	}
+}
+
+func TestLocalPackageReader_Read_badGlob(t *testing.T) {
+	s := setupDirectories(t)
+	defer s.clean()
+	s.writeFile(t, "a_test.yaml", readFileB)
+
+	_, err := LocalPackageReader{PackagePath: s.root, ExcludeFilesGlob: []string{"[a"}}.Read()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "syntax error in pattern")
+	}
+}
+
 func TestLocalPackageReader_Read_skipSubpackage(t *testing.T) {
 	s := setupDirectories(t, filepath.Join("a", "b"), filepath.Join("a", "c"))
 	defer s.clean()
@@ -6,6 +6,7 @@ package kio
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -26,11 +27,28 @@ type LocalPackageWriter struct {
 
 	// ClearAnnotations will clear annotations before writing the resources
 	ClearAnnotations []string `yaml:"clearAnnotations,omitempty"`
+
+	// FilenamePattern if set, will write each Resource without the config.kubernetes.io/path
+	// annotation to its own file, with the path created from the pattern by
+	// kioutil.CreatePathFromPattern -- e.g. "{.kind}-{.metadata.name}.yaml".
+	// It is an error for a Resource to be written to the file of another Resource.
+	FilenamePattern string `yaml:"filenamePattern,omitempty"`
+
+	// NamespaceDirectories if set with FilenamePattern, will write the files created from
+	// the pattern under a directory named after the namespace of the Resource.
+	NamespaceDirectories bool `yaml:"namespaceDirectories,omitempty"`
 }
 
 var _ Writer = LocalPackageWriter{}
 
 func (r LocalPackageWriter) Write(nodes []*yaml.RNode) error {
+	// set the path annotations created from the pattern
+	if r.FilenamePattern != "" {
+		if err := r.setPatternPathAnnotations(nodes); err != nil {
+			return err
+		}
+	}
+
 	// set the path and index annotations if they are missing
 	if err := kioutil.DefaultPathAndIndexAnnotation("", nodes); err != nil {
 		return err
@@ -111,6 +129,52 @@ func (r LocalPackageWriter) Write(nodes []*yaml.RNode) error {
 	return nil
 }
 
+// setPatternPathAnnotations sets the path annotation created from r.FilenamePattern on
+// the nodes missing it, and returns an error if 2 nodes would be written to the same file.
+func (r LocalPackageWriter) setPatternPathAnnotations(nodes []*yaml.RNode) error {
+	// record the Resource already written to each file
+	files := map[string]yaml.ResourceMeta{}
+	for i := range nodes {
+		m, err := nodes[i].GetMeta()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if p, found := m.Annotations[kioutil.PathAnnotation]; found {
+			if _, found := files[p]; !found {
+				files[p] = m
+			}
+		}
+	}
+
+	for i := range nodes {
+		m, err := nodes[i].GetMeta()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if _, found := m.Annotations[kioutil.PathAnnotation]; found {
+			continue
+		}
+
+		p, err := kioutil.CreatePathFromPattern(r.FilenamePattern, nodes[i])
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if r.NamespaceDirectories {
+			p = path.Join(m.Namespace, p)
+		}
+		if existing, found := files[p]; found {
+			return errors.Errorf("%s %s/%s and %s %s/%s are both written to %s",
+				existing.Kind, existing.Namespace, existing.Name, m.Kind, m.Namespace, m.Name, p)
+		}
+		files[p] = m
+
+		if err := nodes[i].PipeE(yaml.SetAnnotation(kioutil.PathAnnotation, p)); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
 func (r LocalPackageWriter) errorIfMissingRequiredAnnotation(nodes []*yaml.RNode) error {
 	for i := range nodes {
 		for _, s := range requiredResourcePackageAnnotations {
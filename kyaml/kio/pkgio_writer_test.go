@@ -268,6 +268,107 @@ metadata:
 	}
 }
 
+// TestLocalPackageWriter_Write_filenamePattern tests:
+// - Resources without a path are written to their own file created from the pattern
+// - Resources with a path are written to their file
+func TestLocalPackageWriter_Write_filenamePattern(t *testing.T) {
+	d, node1, node2, node3 := getWriterInputs(t)
+	defer os.RemoveAll(d)
+
+	node4, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: bar
+`)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	node5, err := yaml.Parse(`apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+`)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+
+	w := LocalPackageWriter{
+		PackagePath:          d,
+		FilenamePattern:      "{.kind}-{.metadata.name}.yaml",
+		NamespaceDirectories: true,
+	}
+	err = w.Write([]*yaml.RNode{node2, node1, node3, node4, node5})
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(d, "a", "b", "a_test.yaml"))
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, `a: b #first
+---
+c: d # second
+`, string(b))
+
+	b, err = ioutil.ReadFile(filepath.Join(d, "bar", "Deployment-foo.yaml"))
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: bar
+`, string(b))
+
+	b, err = ioutil.ReadFile(filepath.Join(d, "Service-foo.yaml"))
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+`, string(b))
+}
+
+// TestLocalPackageWriter_Write_filenamePatternCollision tests:
+// - If 2 Resources are written to the same file created from the pattern, fail
+func TestLocalPackageWriter_Write_filenamePatternCollision(t *testing.T) {
+	d, node1, node2, node3 := getWriterInputs(t)
+	defer os.RemoveAll(d)
+
+	node4, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: bar
+`)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	node5, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: baz
+`)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+
+	w := LocalPackageWriter{PackagePath: d, FilenamePattern: "{.kind}-{.metadata.name}.yaml"}
+	err = w.Write([]*yaml.RNode{node2, node1, node3, node4, node5})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"Deployment bar/foo and Deployment baz/foo are both written to Deployment-foo.yaml")
+	}
+	_, err = os.Stat(filepath.Join(d, "Deployment-foo.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
 func getWriterInputs(t *testing.T) (string, *yaml.RNode, *yaml.RNode, *yaml.RNode) {
 	node1, err := yaml.Parse(`a: b #first
 metadata:
@@ -0,0 +1,82 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Result severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Result is a result reported by a function in the results field of a ResourceList.
+type Result struct {
+	// Severity is the severity of the result.
+	// May be one of SeverityError, SeverityWarning or SeverityInfo.
+	Severity string `yaml:"severity,omitempty"`
+
+	// Message is the message of the result.
+	Message string `yaml:"message,omitempty"`
+
+	// ResourceRef identifies the Resource the result is for, if any.
+	ResourceRef yaml.ResourceIdentifier `yaml:"resourceRef,omitempty"`
+
+	// Field is the path to the field the result is for, if any -- e.g.
+	// metadata.annotations.scaler
+	Field string `yaml:"field,omitempty"`
+}
+
+// Results are the results reported by functions.
+type Results []Result
+
+// HasErrors returns true if any of the results have SeverityError.
+func (r Results) HasErrors() bool {
+	for i := range r {
+		if r[i].Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the results to w as a table.
+func (r Results) Print(w io.Writer) error {
+	if len(r) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tRESOURCE\tFIELD\tMESSAGE")
+	for i := range r {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r[i].Severity, r[i].Resource(),
+			r[i].Field, strings.ReplaceAll(r[i].Message, "\n", " "))
+	}
+	return errors.Wrap(tw.Flush())
+}
+
+// Resource returns the Resource identified by the result as `apiVersion/kind namespace/name`,
+// or "" if the result isn't for a Resource.
+func (r Result) Resource() string {
+	ref := r.ResourceRef
+	if ref == (yaml.ResourceIdentifier{}) {
+		return ""
+	}
+	id := ref.Kind
+	if ref.APIVersion != "" {
+		id = ref.APIVersion + "/" + id
+	}
+	if ref.Namespace != "" {
+		return id + " " + ref.Namespace + "/" + ref.Name
+	}
+	return id + " " + ref.Name
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestByteReadWriter_results(t *testing.T) {
+	in := bytes.NewBufferString(`apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- kind: Deployment
+  metadata:
+    name: foo
+results:
+- severity: warning
+  message: foo has no replicas
+  resourceRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: foo
+  field: spec.replicas
+`)
+	out := &bytes.Buffer{}
+	rw := &ByteReadWriter{Reader: in, Writer: out}
+	nodes, err := rw.Read()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, rw.Results, 1) {
+		return
+	}
+	assert.Equal(t, SeverityWarning, rw.Results[0].Severity)
+	assert.Equal(t, "foo", rw.Results[0].ResourceRef.Name)
+
+	rw.Results = append(rw.Results, Result{
+		Severity: SeverityError,
+		Message:  "foo is invalid",
+	})
+	if !assert.NoError(t, rw.Write(nodes)) {
+		return
+	}
+	assert.Equal(t, `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- kind: Deployment
+  metadata:
+    name: foo
+results:
+- severity: warning
+  message: foo has no replicas
+  resourceRef:
+    name: foo
+    apiVersion: apps/v1
+    kind: Deployment
+  field: spec.replicas
+- severity: error
+  message: foo is invalid
+`, out.String())
+}
+
+func TestByteWriter_results_notResourceList(t *testing.T) {
+	node, err := yaml.Parse(`kind: Deployment
+metadata:
+  name: foo
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	out := &bytes.Buffer{}
+	err = ByteWriter{
+		Writer:  out,
+		Results: Results{{Severity: SeverityError, Message: "foo is invalid"}},
+	}.Write([]*yaml.RNode{node})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `kind: Deployment
+metadata:
+  name: foo
+`, out.String())
+}
+
+func TestResults_HasErrors(t *testing.T) {
+	assert.False(t, Results{}.HasErrors())
+	assert.False(t, Results{{Severity: SeverityWarning}, {Severity: SeverityInfo}}.HasErrors())
+	assert.True(t, Results{{Severity: SeverityWarning}, {Severity: SeverityError}}.HasErrors())
+}
+
+func TestResults_Print(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := Results{
+		{
+			Severity: SeverityError,
+			Message:  "replicas must be\npositive",
+			ResourceRef: yaml.ResourceIdentifier{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Namespace:  "default",
+				Name:       "foo",
+			},
+			Field: "spec.replicas",
+		},
+		{Severity: SeverityInfo, Message: "checked 1 Resource"},
+	}.Print(out)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `SEVERITY  RESOURCE                        FIELD          MESSAGE
+error     apps/v1/Deployment default/foo  spec.replicas  replicas must be positive
+info                                                     checked 1 Resource
+`, out.String())
+
+	out.Reset()
+	assert.NoError(t, Results{}.Print(out))
+	assert.Empty(t, out.String())
+}
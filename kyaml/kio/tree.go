@@ -46,6 +46,36 @@ type TreeWriterField struct {
 	SubName string
 }
 
+// NewTreeWriterField returns a TreeWriterField for the field path, e.g.
+// `spec.replicas` as []string{"spec", "replicas"}.  Fields under list elements,
+// e.g. `spec.containers.[name=.*].image`, are displayed under the list field with a
+// branch for each matching element.
+func NewTreeWriterField(path ...string) TreeWriterField {
+	for i := 1; i < len(path)-1; i++ {
+		if !yaml.IsListIndex(path[i]) {
+			continue
+		}
+		// display the remainder of the path under the list field
+		var subName []string
+		for _, p := range path[i+1:] {
+			if yaml.IsListIndex(p) && len(subName) > 0 {
+				subName[len(subName)-1] += p
+				continue
+			}
+			subName = append(subName, p)
+		}
+		return TreeWriterField{
+			Name:        strings.Join(path[:i], "."),
+			PathMatcher: yaml.PathMatcher{Path: path, StripComments: true},
+			SubName:     strings.Join(subName, "."),
+		}
+	}
+	return TreeWriterField{
+		Name:        strings.Join(path, "."),
+		PathMatcher: yaml.PathMatcher{Path: path, StripComments: true},
+	}
+}
+
 func (p TreeWriter) packageStructure(nodes []*yaml.RNode) error {
 	indexByPackage := p.index(nodes)
 
@@ -370,7 +400,16 @@ func (p TreeWriter) getFields(leaf *yaml.RNode) (treeFields, error) {
 		if err != nil {
 			return nil, err
 		}
-		if seq == nil {
+		// missing and null fields are omitted
+		var values []*yaml.Node
+		if seq != nil {
+			for _, elem := range seq.Content() {
+				if elem.ShortTag() != yaml.NullNodeTag {
+					values = append(values, elem)
+				}
+			}
+		}
+		if len(values) == 0 {
 			continue
 		}
 
@@ -381,7 +420,7 @@ func (p TreeWriter) getFields(leaf *yaml.RNode) (treeFields, error) {
 		// non-nested field -- add directly to the treeFields list
 		if f.SubName == "" {
 			// non-nested field -- only 1 element
-			val, err := yaml.String(seq.Content()[0], yaml.Trim, yaml.Flow)
+			val, err := yaml.String(values[0], yaml.Trim, yaml.Flow)
 			if err != nil {
 				return nil, err
 			}
@@ -394,8 +433,8 @@ func (p TreeWriter) getFields(leaf *yaml.RNode) (treeFields, error) {
 			fieldsByName[f.Name].subFieldByMatch = map[string]treeFields{}
 		}
 		index := fieldsByName[f.Name].subFieldByMatch
-		for j := range seq.Content() {
-			elem := seq.Content()[j]
+		for j := range values {
+			elem := values[j]
 			matches := f.Matches[elem]
 			str, err := yaml.String(elem, yaml.Trim, yaml.Flow)
 			if err != nil {
@@ -577,3 +577,82 @@ metadata:
 	assert.Error(t, err)
 	assert.Equal(t, "owner 'Application myapp-staging/nginx' not found in input, but found as an owner of input objects", err.Error())
 }
+
+func TestNewTreeWriterField(t *testing.T) {
+	assert.Equal(t, TreeWriterField{
+		Name:        "spec.replicas",
+		PathMatcher: yaml.PathMatcher{Path: []string{"spec", "replicas"}, StripComments: true},
+	}, NewTreeWriterField("spec", "replicas"))
+
+	path := []string{"spec", "containers", "[name=app]", "ports", "[name=http]", "containerPort"}
+	assert.Equal(t, TreeWriterField{
+		Name:        "spec.containers",
+		SubName:     "ports[name=http].containerPort",
+		PathMatcher: yaml.PathMatcher{Path: path, StripComments: true},
+	}, NewTreeWriterField(path...))
+}
+
+func TestPrinter_Write_Fields(t *testing.T) {
+	in := `kind: Deployment
+metadata:
+  name: foo
+  annotations:
+    scaler: "3"
+    config.kubernetes.io/path: f1.yaml
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+        resources:
+          limits:
+            cpu: 1
+      - name: sidecar
+        image: envoy:v1
+---
+kind: Deployment
+metadata:
+  name: bar
+  annotations:
+    scaler: null
+    config.kubernetes.io/path: f1.yaml
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image:
+`
+	out := &bytes.Buffer{}
+	err := Pipeline{
+		Inputs: []Reader{&ByteReader{Reader: bytes.NewBufferString(in)}},
+		Outputs: []Writer{TreeWriter{Writer: out, Structure: TreeStructurePackage,
+			Fields: []TreeWriterField{
+				NewTreeWriterField("spec", "replicas"),
+				NewTreeWriterField("metadata", "annotations", "scaler"),
+				NewTreeWriterField("spec", "template", "spec", "containers", "[name=.*]", "image"),
+				NewTreeWriterField("spec", "template", "spec", "containers", "[name=app]",
+					"resources", "limits", "cpu"),
+			}}},
+	}.Execute()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	if !assert.Equal(t, `
+├── [f1.yaml]  Deployment bar
+└── [f1.yaml]  Deployment foo
+    ├── metadata.annotations.scaler: "3"
+    ├── spec.replicas: 3
+    └── spec.template.spec.containers
+        ├── 0
+        │   ├── image: app:v1
+        │   └── resources.limits.cpu: 1
+        └── 1
+            └── image: envoy:v1
+`, out.String()) {
+		t.FailNow()
+	}
+}
@@ -78,7 +78,13 @@ func AddSchemaFromFileUsingField(path, field string) error {
 	if err != nil {
 		return err
 	}
+	return AddSchemaFromRNodeUsingField(y, field)
+}
 
+// AddSchemaFromRNodeUsingField parses the OpenAPI definitions from the specified
+// field of y, e.g. a file which has been read but not written.  If field is the empty
+// string, use the whole document as OpenAPI.
+func AddSchemaFromRNodeUsingField(y *yaml.RNode, field string) error {
 	if field != "" {
 		// get the field containing the openAPI
 		m := y.Field(field)
@@ -89,31 +95,126 @@ func AddSchemaFromFileUsingField(path, field string) error {
 		y = m.Value
 	}
 
-	oAPI, err := y.String()
+	j, err := toJSON(y)
 	if err != nil {
 		return err
 	}
 
-	// convert the yaml openAPI to a JSON string by unmarshalling it to an
-	// interface{} and the marshalling it to a string
-	var o interface{}
-	err = yaml.Unmarshal([]byte(oAPI), &o)
+	// add the json schema to the global schema
+	_, err = AddSchema(j)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddSchemaFromCRD adds the OpenAPI schemas of the versions of the
+// CustomResourceDefinition crd to the global schema, so that the custom Resources
+// may be looked up with SchemaForResourceType -- e.g. to merge their lists using the
+// `x-kubernetes-patch-merge-key` extensions of the schemas.
+//
+// Both the apiextensions.k8s.io/v1 per-version schemas and the v1beta1
+// `spec.validation` schema are supported.
+func AddSchemaFromCRD(crd *yaml.RNode) error {
+	group, err := lookupValue(crd, "spec", "group")
 	if err != nil {
 		return err
 	}
-	j, err := json.Marshal(o)
+	kind, err := lookupValue(crd, "spec", "names", "kind")
 	if err != nil {
 		return err
 	}
+	if kind == "" {
+		return errors.Errorf("missing spec.names.kind in CustomResourceDefinition")
+	}
 
-	// add the json schema to the global schema
-	_, err = AddSchema(j)
+	// the v1beta1 schema applies to the versions without a schema
+	validation, err := crd.Pipe(yaml.Lookup("spec", "validation", "openAPIV3Schema"))
 	if err != nil {
 		return err
 	}
+	schemas := map[string]*yaml.RNode{}
+	var versions []string
+	if version, err := lookupValue(crd, "spec", "version"); err != nil {
+		return err
+	} else if version != "" {
+		versions = append(versions, version)
+		schemas[version] = validation
+	}
+	elements, err := crd.Pipe(yaml.Lookup("spec", "versions"))
+	if err != nil {
+		return err
+	}
+	if elements != nil {
+		list, err := elements.Elements()
+		if err != nil {
+			return err
+		}
+		for i := range list {
+			version, err := lookupValue(list[i], "name")
+			if err != nil {
+				return err
+			}
+			s, err := list[i].Pipe(yaml.Lookup("schema", "openAPIV3Schema"))
+			if err != nil {
+				return err
+			}
+			if s == nil {
+				s = validation
+			}
+			if _, found := schemas[version]; !found {
+				versions = append(versions, version)
+			}
+			schemas[version] = s
+		}
+	}
+
+	definitions := spec.Definitions{}
+	for _, version := range versions {
+		if schemas[version] == nil {
+			continue
+		}
+		j, err := toJSON(schemas[version])
+		if err != nil {
+			return err
+		}
+		var sc spec.Schema
+		if err := sc.UnmarshalJSON(j); err != nil {
+			return errors.Wrap(err)
+		}
+		// index the schema by the GVK of the custom Resources
+		sc.AddExtension(kubernetesGVKExtensionKey, []interface{}{map[string]interface{}{
+			groupKey: group, versionKey: version, kindKey: kind}})
+		definitions[fmt.Sprintf("%s.%s.%s", group, version, kind)] = sc
+	}
+	AddDefinitions(definitions)
 	return nil
 }
 
+// lookupValue returns the value of the scalar field at path in y, or "" if y has no
+// such field.
+func lookupValue(y *yaml.RNode, path ...string) (string, error) {
+	v, err := y.Pipe(yaml.Lookup(path...))
+	if err != nil || v == nil {
+		return "", err
+	}
+	return yaml.GetValue(v), nil
+}
+
+// toJSON converts the yaml y to JSON by unmarshalling it to an interface{} and
+// then marshalling it to JSON.
+func toJSON(y *yaml.RNode) ([]byte, error) {
+	s, err := y.String()
+	if err != nil {
+		return nil, err
+	}
+	var o interface{}
+	if err := yaml.Unmarshal([]byte(s), &o); err != nil {
+		return nil, err
+	}
+	return json.Marshal(o)
+}
+
 // AddSchema parses s, and adds definitions from s to the global schema.
 func AddSchema(s []byte) (*spec.Schema, error) {
 	return parse(s)
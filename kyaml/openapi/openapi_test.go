@@ -237,3 +237,64 @@ kind: Example
 		t.FailNow()
 	}
 }
+
+func TestAddSchemaFromCRD(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	// the v1beta1 schema applies to both versions
+	err := AddSchemaFromCRD(yaml.MustParse(`apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: routers.example.com
+spec:
+  group: example.com
+  names:
+    kind: Router
+  versions:
+  - name: v1beta1
+  - name: v1
+  validation:
+    openAPIV3Schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          properties:
+            routes:
+              type: array
+              x-kubernetes-patch-merge-key: host
+              x-kubernetes-patch-strategy: merge
+              items:
+                type: object
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	for _, version := range []string{"example.com/v1beta1", "example.com/v1"} {
+		s := SchemaForResourceType(yaml.TypeMeta{APIVersion: version, Kind: "Router"})
+		if !assert.NotNil(t, s, version) {
+			t.FailNow()
+		}
+		strategy, key := s.Lookup("spec", "routes").PatchStrategyAndKey()
+		assert.Equal(t, "merge", strategy, version)
+		assert.Equal(t, "host", key, version)
+	}
+	assert.Nil(t, SchemaForResourceType(
+		yaml.TypeMeta{APIVersion: "example.com/v2", Kind: "Router"}))
+}
+
+func TestAddSchemaFromCRD_noKind(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	err := AddSchemaFromCRD(yaml.MustParse(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: routers.example.com
+spec:
+  group: example.com
+`))
+	if assert.Error(t, err) {
+		assert.Equal(t, "missing spec.names.kind in CustomResourceDefinition", err.Error())
+	}
+}
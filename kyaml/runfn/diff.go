@@ -0,0 +1,168 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// packageDiff is a kio.Writer which writes a unified diff of the files of the package
+// which would be changed by writing the Resources, instead of writing them.
+//
+// The Resources are compared with the original Resources written the same way, so
+// that the diff doesn't contain changes which only format the files.
+type packageDiff struct {
+	// original are copies of the Resources read from the package
+	original []*yaml.RNode
+
+	// pkg is the writer which the Resources would be written with
+	pkg *kio.LocalPackageReadWriter
+
+	// out is where the diff is written
+	out io.Writer
+
+	// prefix is prefixed to the paths of the files in the diff
+	prefix string
+
+	// changed is set by Write to the files which would be changed, created or deleted
+	changed []string
+}
+
+// newPackageDiff returns a packageDiff against the Resources read from input, which
+// must be read before they are modified.
+func newPackageDiff(input kio.Reader, pkg *kio.LocalPackageReadWriter, out io.Writer) (
+	*packageDiff, error) {
+	nodes, err := input.Read()
+	if err != nil {
+		return nil, err
+	}
+	d := &packageDiff{pkg: pkg, out: out}
+	for i := range nodes {
+		d.original = append(d.original, nodes[i].Copy())
+	}
+	return d, nil
+}
+
+func (d *packageDiff) Write(nodes []*yaml.RNode) error {
+	// write the original and the modified Resources to temporary packages, and
+	// compare their files
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-diff")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer os.RemoveAll(dir)
+	from, to := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := d.writeTo(from, d.original); err != nil {
+		return err
+	}
+	if err := d.writeTo(to, nodes); err != nil {
+		return err
+	}
+
+	fromFiles, err := packageFiles(from)
+	if err != nil {
+		return err
+	}
+	toFiles, err := packageFiles(to)
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for p := range fromFiles {
+		paths = append(paths, p)
+	}
+	for p := range toFiles {
+		if _, found := fromFiles[p]; !found {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		a, inFrom := fromFiles[p]
+		b, inTo := toFiles[p]
+		if a == b && inFrom && inTo {
+			continue
+		}
+		name := filepath.ToSlash(filepath.Join(d.prefix, p))
+		d.changed = append(d.changed, name)
+
+		// created and deleted files are compared with /dev/null
+		fromFile, toFile := "a/"+name, "b/"+name
+		if !inFrom {
+			fromFile = "/dev/null"
+		}
+		if !inTo {
+			toFile = "/dev/null"
+		}
+		err := difflib.WriteUnifiedDiff(d.out, difflib.UnifiedDiff{
+			A:        splitLines(a),
+			B:        splitLines(b),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// splitLines splits s into lines which end with a newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	// the last line doesn't end with a newline
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// writeTo writes nodes to a package in dir, the same way as d.pkg would write them.
+func (d *packageDiff) writeTo(dir string, nodes []*yaml.RNode) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err)
+	}
+	return (&kio.LocalPackageReadWriter{
+		PackagePath:           dir,
+		KeepReaderAnnotations: d.pkg.KeepReaderAnnotations,
+		SetAnnotations:        d.pkg.SetAnnotations,
+		FilenamePattern:       d.pkg.FilenamePattern,
+		NamespaceDirectories:  d.pkg.NamespaceDirectories,
+	}).Write(nodes)
+}
+
+// packageFiles returns the contents of the files under dir, by their path relative
+// to dir.
+func packageFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[rel] = string(b)
+		return nil
+	})
+	return files, errors.Wrap(err)
+}
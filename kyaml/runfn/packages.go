@@ -0,0 +1,282 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+// KrmfileName is the name of the file which declares a package when running
+// packages with RunFns.Parallel
+const KrmfileName = "Krmfile"
+
+// executePackages runs the functions of each package under r.Path separately,
+// r.Parallel packages at a time.  A package failing doesn't stop the other packages
+// unless r.FailFast is set, and the errors of the failed packages are returned
+// together.
+func (r RunFns) executePackages() error {
+	if r.Input != nil || r.Output != nil {
+		return errors.Errorf("packages may only be run in parallel when writing to the directory")
+	}
+	pkgs, err := findPackages(r.Path)
+	if err != nil {
+		return err
+	}
+
+	// mu guards the outputs of the packages and errs
+	mu := &sync.Mutex{}
+	errs := &packageErrors{total: len(pkgs)}
+	running := make(chan struct{}, r.Parallel)
+	wg := sync.WaitGroup{}
+	for i := range pkgs {
+		running <- struct{}{}
+		mu.Lock()
+		failed := len(errs.errs) > 0
+		mu.Unlock()
+		if failed && r.FailFast {
+			errs.skipped = len(pkgs) - i
+			break
+		}
+
+		wg.Add(1)
+		go func(pkg string) {
+			defer func() {
+				<-running
+				wg.Done()
+			}()
+			if err := r.executePackage(pkg, pkgs, mu); err != nil {
+				mu.Lock()
+				errs.errs = append(errs.errs, packageError{pkg: pkg, err: err})
+				mu.Unlock()
+			}
+		}(pkgs[i])
+	}
+	wg.Wait()
+
+	if len(errs.errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs.errs, func(i, j int) bool { return errs.errs[i].pkg < errs.errs[j].pkg })
+	return errors.Wrap(errs)
+}
+
+// executePackage runs the functions of the package pkg, which is relative to
+// r.Path.  The nested packages in pkgs aren't read.  The outputs are prefixed with
+// pkg, and mu is locked while writing them.
+func (r RunFns) executePackage(pkg string, pkgs []string, mu *sync.Mutex) error {
+	p := r
+	p.Parallel = 0
+	p.Path = filepath.Join(r.Path, pkg)
+	for _, nested := range pkgs {
+		if rel, err := filepath.Rel(pkg, nested); err == nil && rel != "." &&
+			!strings.HasPrefix(rel, "..") {
+			p.excludeDirs = append(p.excludeDirs, escapeGlob(filepath.ToSlash(rel)))
+		}
+	}
+
+	// write the outputs of the package line by line, prefixed with the package
+	var writers []*prefixWriter
+	prefixed := func(w io.Writer, defaultWriter io.Writer) io.Writer {
+		if w == nil {
+			w = defaultWriter
+		}
+		if w == nil {
+			return nil
+		}
+		pw := &prefixWriter{out: w, prefix: pkg + ": ", mu: mu}
+		writers = append(writers, pw)
+		return pw
+	}
+	p.ResultsOutput = prefixed(r.ResultsOutput, nil)
+	p.WarningOutput = prefixed(r.WarningOutput, os.Stderr)
+	p.Stderr = prefixed(r.Stderr, os.Stderr)
+
+	// the diff isn't prefixed so that it may be applied, and is written at once
+	var diff bytes.Buffer
+	if r.Diff {
+		p.DiffOutput = &diff
+		if pkg != "." {
+			p.diffPrefix = pkg
+		}
+	}
+
+	err := p.Execute()
+	for i := range writers {
+		if ferr := writers[i].Flush(); ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	if diff.Len() > 0 {
+		out := r.DiffOutput
+		if out == nil {
+			out = os.Stdout
+		}
+		mu.Lock()
+		_, derr := out.Write(diff.Bytes())
+		mu.Unlock()
+		if derr != nil && err == nil {
+			err = errors.Wrap(derr)
+		}
+	}
+	return err
+}
+
+// findPackages returns the paths relative to root of the packages under root.
+// Packages are the directories containing a Krmfile, or function configs in the
+// directory or in its functions directory.  Hidden directories are skipped.
+func findPackages(root string) ([]string, error) {
+	var pkgs []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if p != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if info.Name() == "functions" {
+			// the functions are scoped to the parent directory
+			return nil
+		}
+		isPkg, err := isPackage(p)
+		if err != nil {
+			return err
+		}
+		if !isPkg {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		pkgs = append(pkgs, rel)
+		return nil
+	})
+	sort.Strings(pkgs)
+	return pkgs, err
+}
+
+// isPackage returns true if dir contains a Krmfile, or function configs in the
+// directory or in its functions directory.
+func isPackage(dir string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(dir, KrmfileName)); err == nil {
+		return true, nil
+	} else if !os.IsNotExist(err) {
+		return false, errors.Wrap(err)
+	}
+	for _, d := range []string{dir, filepath.Join(dir, "functions")} {
+		if _, err := os.Stat(d); os.IsNotExist(err) {
+			continue
+		}
+		// only read the files in the directory rather than its subdirectories
+		nodes, err := kio.LocalPackageReader{
+			PackagePath: d, ExcludeDirsGlob: []string{"*"}}.Read()
+		if err != nil {
+			return false, err
+		}
+		for i := range nodes {
+			if filters.GetFunctionSpec(nodes[i]) != nil {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// escapeGlob escapes the characters of p which are special in glob patterns.
+func escapeGlob(p string) string {
+	var b strings.Builder
+	for _, c := range p {
+		if strings.ContainsRune(`*?[\`, c) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// prefixWriter writes the lines written to it to out prefixed with prefix.  Lines
+// are written whole while holding mu, so that the lines of packages run in parallel
+// aren't interleaved.
+type prefixWriter struct {
+	out    io.Writer
+	prefix string
+	mu     *sync.Mutex
+
+	// buf is the last line, which hasn't been terminated yet
+	buf []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes the last line if it hasn't been terminated.
+func (w *prefixWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.writeLine(append(w.buf, '\n'))
+	w.buf = nil
+	return err
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+	return errors.Wrap(err)
+}
+
+// packageError is the error of a package which failed.
+type packageError struct {
+	pkg string
+	err error
+}
+
+// packageErrors contains the errors of the packages which failed, and is returned
+// by executePackages.
+type packageErrors struct {
+	errs []packageError
+
+	// total is the number of packages
+	total int
+
+	// skipped is the number of packages which weren't run because of FailFast
+	skipped int
+}
+
+func (e *packageErrors) Error() string {
+	msgs := []string{fmt.Sprintf("%d of %d packages failed:", len(e.errs), e.total)}
+	for i := range e.errs {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", e.errs[i].pkg, e.errs[i].err))
+	}
+	if e.skipped > 0 {
+		msgs = append(msgs, fmt.Sprintf("packages skipped after the failure: %d", e.skipped))
+	}
+	return strings.Join(msgs, "\n")
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// execFn is a function config running the exec function fn.sh in its package
+const execFn = `apiVersion: v1
+kind: ValueReplacer
+metadata:
+  name: fn
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: fn.sh
+    config.kubernetes.io/local-config: "true"
+`
+
+// deployment is a Resource which the exec functions modify
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`
+
+// writeFiles writes files to dir, creating their directories.  Files ending in .sh
+// are executable.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0700)) {
+			t.FailNow()
+		}
+		mode := os.FileMode(0600)
+		if strings.HasSuffix(name, ".sh") {
+			mode = 0700
+		}
+		if !assert.NoError(t, ioutil.WriteFile(p, []byte(content), mode)) {
+			t.FailNow()
+		}
+	}
+}
+
+func TestFindPackages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"a/Krmfile":            "",
+		"a/b/fn.yaml":          execFn,
+		"a/b/deployment.yaml":  deployment,
+		"c/functions/fn.yaml":  execFn,
+		"c/deployment.yaml":    deployment,
+		"d/deployment.yaml":    deployment,
+		".hidden/Krmfile":      "",
+		"e/f/functions/x.yaml": deployment,
+	})
+
+	pkgs, err := findPackages(dir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{"a", filepath.Join("a", "b"), "c"}, pkgs)
+}
+
+func TestCmd_Execute_parallel(t *testing.T) {
+	tests := []struct {
+		name     string
+		parallel int
+		failFast bool
+		// expected are the kinds of the deployment of each package
+		expected map[string]string
+		// expectedStderr are the lines which must be written to stderr
+		expectedStderr []string
+		err            string
+	}{
+		{
+			name:     "parallel",
+			parallel: 2,
+			expected: map[string]string{
+				"a": "StatefulSet", "a/b": "DaemonSet", "c": "Deployment", "d": "StatefulSet"},
+			// the stderr of the failed function is in the error instead
+			expectedStderr: []string{"a: running a\n", "a/b: running a/b\n"},
+			err:            "1 of 4 packages failed:\nc: ",
+		},
+		{
+			name:     "fail-fast",
+			parallel: 1,
+			failFast: true,
+			expected: map[string]string{
+				"a": "StatefulSet", "a/b": "DaemonSet", "c": "Deployment", "d": "Deployment"},
+			err: "packages skipped after the failure: 1",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kustomize-kyaml-test")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+			// the nested package a/b isn't modified by the functions of a, and
+			// the function of c fails
+			writeFiles(t, dir, map[string]string{
+				"a/fn.yaml":           execFn,
+				"a/fn.sh":             "#!/bin/sh\necho running a >&2\nsed 's/kind: Deployment/kind: StatefulSet/'\n",
+				"a/deployment.yaml":   deployment,
+				"a/b/fn.yaml":         execFn,
+				"a/b/fn.sh":           "#!/bin/sh\necho running a/b >&2\nsed 's/kind: Deployment/kind: DaemonSet/'\n",
+				"a/b/deployment.yaml": deployment,
+				"c/fn.yaml":           execFn,
+				"c/fn.sh":             "#!/bin/sh\necho running c >&2\nexit 1\n",
+				"c/deployment.yaml":   deployment,
+				"d/Krmfile":           "",
+				"d/functions/fn.yaml": execFn,
+				"d/fn.sh":             "#!/bin/sh\nsed 's/kind: Deployment/kind: StatefulSet/'\n",
+				"d/deployment.yaml":   deployment,
+			})
+
+			var stderr bytes.Buffer
+			err = RunFns{
+				Path:     dir,
+				Parallel: test.parallel,
+				FailFast: test.failFast,
+				Stderr:   &stderr,
+			}.Execute()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.err)
+				assert.Contains(t, err.Error(), "running c\n")
+			}
+			for _, line := range test.expectedStderr {
+				assert.Contains(t, stderr.String(), line)
+			}
+
+			for pkg, kind := range test.expected {
+				b, err := ioutil.ReadFile(filepath.Join(dir, pkg, "deployment.yaml"))
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, string(b), "kind: "+kind+"\n", pkg)
+			}
+		})
+	}
+}
+
+func TestCmd_Execute_parallelDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"a/fn.yaml":         execFn,
+		"a/fn.sh":           "#!/bin/sh\nsed 's/kind: Deployment/kind: StatefulSet/'\n",
+		"a/deployment.yaml": deployment,
+		"b/fn.yaml":         execFn,
+		"b/fn.sh":           "#!/bin/sh\ncat\n",
+		"b/deployment.yaml": deployment,
+	})
+
+	var diff bytes.Buffer
+	err = RunFns{Path: dir, Parallel: 2, Diff: true, DiffOutput: &diff}.Execute()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"1 of 2 packages failed:\na: functions would change 1 files: a/deployment.yaml")
+	}
+	// the paths in the diff are relative to the directory
+	assert.Equal(t, `--- a/a/deployment.yaml
++++ b/a/deployment.yaml
+@@ -1,4 +1,4 @@
+ apiVersion: apps/v1
+-kind: Deployment
++kind: StatefulSet
+ metadata:
+   name: app
+`, diff.String())
+}
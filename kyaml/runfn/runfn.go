@@ -4,12 +4,16 @@
 package runfn
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio"
@@ -22,8 +26,20 @@ import (
 // RunFns runs the set of configuration functions in a local directory against
 // the Resources in that directory
 type RunFns struct {
+	// StorageMounts are mounted into the containers of all functions, replacing the
+	// mounts of the functions with the same destination.  Their bind mount sources
+	// are checked the same as those of the functions.
 	StorageMounts []filters.StorageMount
 
+	// Env are set in the containers of all functions, replacing the variables of the
+	// functions with the same name.  Variables given as NAME are forwarded from the
+	// parent process.
+	Env []string
+
+	// EnableExternalMounts will enable functions to bind mount host paths outside of
+	// the package directory.
+	EnableExternalMounts bool
+
 	// Path is the path to the directory containing functions
 	Path string
 
@@ -38,6 +54,11 @@ type RunFns struct {
 	// If Functions length is > 0, then NoFunctionsFromInput defaults to true
 	Functions []*yaml.RNode
 
+	// FnConfigPath if set is the path to a file containing the functionConfig for the
+	// explicit Functions.  The file is not read as part of the package, and its
+	// functionConfig isn't written to the output.
+	FnConfigPath string
+
 	// GlobalScope if true, functions read from input will be scoped globally rather
 	// than only to Resources under their subdirs.
 	GlobalScope bool
@@ -54,6 +75,37 @@ type RunFns struct {
 	// Output can be set to write the result to Output rather than back to the directory
 	Output io.Writer
 
+	// Diff if set will not write the Resources back to the directory, and instead
+	// writes a unified diff of the files which would be changed, created or deleted
+	// to DiffOutput.  Execute returns an error if any files would be changed.
+	Diff bool
+
+	// DiffOutput is where the diff is written if Diff is set.  Defaults to os.Stdout.
+	DiffOutput io.Writer
+
+	// ResultsOutput if set, is where the results reported by the functions are written
+	// as a table.  Running the functions returns an error if any of the results have
+	// severity error.
+	ResultsOutput io.Writer
+
+	// WarningOutput is where warnings about the functions are written, such as
+	// functions with the same order.  Defaults to os.Stderr.
+	WarningOutput io.Writer
+
+	// Stderr is where the stderr of container and exec functions is written.
+	// Defaults to os.Stderr.
+	Stderr io.Writer
+
+	// Parallel if greater than 0 will run the functions of each package under Path
+	// separately, Parallel packages at a time.  Packages are the directories
+	// containing a Krmfile or function configs.  The outputs of each package are
+	// prefixed with its path.
+	Parallel int
+
+	// FailFast if set with Parallel will not start running any more packages after
+	// a package fails.
+	FailFast bool
+
 	// NoFunctionsFromInput if set to true will not read any functions from the input,
 	// and only use explicit sources
 	NoFunctionsFromInput *bool
@@ -61,13 +113,28 @@ type RunFns struct {
 	// EnableStarlark will enable functions run as starlark scripts
 	EnableStarlark bool
 
+	// StarlarkTimeout if non-zero is the max time each starlark function may run for
+	StarlarkTimeout time.Duration
+
 	// DisableContainers will disable functions run as containers
 	DisableContainers bool
 
+	// EnableExec will enable functions run as executables outside of the package
+	// directory.  Executables in the package directory are always enabled.
+	EnableExec bool
+
 	// functionFilterProvider provides a filter to perform the function.
 	// this is a variable so it can be mocked in tests
 	functionFilterProvider func(
 		filter filters.FunctionSpec, api *yaml.RNode) kio.Filter
+
+	// excludeDirs are the directories of the nested packages, which aren't read when
+	// running a package with Parallel
+	excludeDirs []string
+
+	// diffPrefix is prefixed to the paths of the diff when running a package with
+	// Parallel, so that they are relative to the parent directory
+	diffPrefix string
 }
 
 // Execute runs the command
@@ -78,6 +145,13 @@ func (r RunFns) Execute() error {
 	if err != nil {
 		return errors.Wrap(err)
 	}
+	if r.Parallel > 0 {
+		return r.executePackages()
+	}
+	r.StorageMounts, err = r.containerMounts(r.StorageMounts)
+	if err != nil {
+		return err
+	}
 
 	// default the containerFilterProvider if it hasn't been override.  Split out for testing.
 	(&r).init()
@@ -97,7 +171,8 @@ func (r RunFns) getNodesAndFilters() (
 	// the same one for reading must be used for writing if deleting Resources
 	var outputPkg *kio.LocalPackageReadWriter
 	if r.Path != "" {
-		outputPkg = &kio.LocalPackageReadWriter{PackagePath: r.Path}
+		outputPkg = &kio.LocalPackageReadWriter{
+			PackagePath: r.Path, ExcludeDirsGlob: r.excludeDirs}
 	}
 
 	if r.Input == nil {
@@ -134,7 +209,7 @@ func (r RunFns) getFilters(nodes []*yaml.RNode) ([]kio.Filter, error) {
 	fltrs = append(fltrs, f...)
 
 	// explicit fns specified on the struct
-	f, err = r.getFunctionsFromFunctions()
+	f, err = r.getFunctionsFromFunctions(nodes)
 	if err != nil {
 		return nil, err
 	}
@@ -145,10 +220,24 @@ func (r RunFns) getFilters(nodes []*yaml.RNode) ([]kio.Filter, error) {
 
 // runFunctions runs the fltrs against the input and writes to either r.Output or output
 func (r RunFns) runFunctions(
-	input kio.Reader, output kio.Writer, fltrs []kio.Filter) error {
+	input kio.Reader, output *kio.LocalPackageReadWriter, fltrs []kio.Filter) error {
 	// use the previously read Resources as input
 	var outputs []kio.Writer
-	if r.Output == nil {
+	var diff *packageDiff
+	if r.Diff && r.Output == nil {
+		// write the diff of the package instead of writing back to it
+		out := r.DiffOutput
+		if out == nil {
+			out = os.Stdout
+		}
+		var err error
+		diff, err = newPackageDiff(input, output, out)
+		if err != nil {
+			return err
+		}
+		diff.prefix = r.diffPrefix
+		outputs = append(outputs, diff)
+	} else if r.Output == nil {
 		// write back to the package
 		outputs = append(outputs, output)
 	} else {
@@ -156,7 +245,52 @@ func (r RunFns) runFunctions(
 		// the output is nil (reading from Input)
 		outputs = append(outputs, kio.ByteWriter{Writer: r.Output})
 	}
-	return kio.Pipeline{Inputs: []kio.Reader{input}, Filters: fltrs, Outputs: outputs}.Execute()
+
+	// don't write the outputs if the functions reported errors
+	checkResults := kio.FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+		if getResults(fltrs).HasErrors() {
+			return nil, errResults
+		}
+		return nodes, nil
+	})
+	err := kio.Pipeline{
+		Inputs:  []kio.Reader{input},
+		Filters: append(fltrs, checkResults),
+		Outputs: outputs,
+	}.Execute()
+
+	// print the results even if a function failed
+	results := getResults(fltrs)
+	if r.ResultsOutput != nil {
+		if err := results.Print(r.ResultsOutput); err != nil {
+			return err
+		}
+	}
+	if err == nil && results.HasErrors() {
+		return errors.Wrap(errResults)
+	}
+	if err == nil && diff != nil && len(diff.changed) > 0 {
+		return errors.Errorf("functions would change %d files: %s",
+			len(diff.changed), strings.Join(diff.changed, ", "))
+	}
+	return err
+}
+
+// errResults is returned if the functions report results with severity error
+var errResults = fmt.Errorf("functions reported results with severity %s", kio.SeverityError)
+
+// getResults returns the results reported by the functions run by fltrs.
+func getResults(fltrs []kio.Filter) kio.Results {
+	var results kio.Results
+	for i := range fltrs {
+		switch f := fltrs[i].(type) {
+		case *filters.ContainerFilter:
+			results = append(results, f.Results...)
+		case *filters.ExecFilter:
+			results = append(results, f.Results...)
+		}
+	}
+	return results
 }
 
 // getFunctionsFromInput scans the input for functions and runs them
@@ -174,7 +308,9 @@ func (r RunFns) getFunctionsFromInput(nodes []*yaml.RNode) ([]kio.Filter, error)
 	if err != nil {
 		return nil, err
 	}
-	sortFns(buff)
+	if err := r.sortFns(buff, true); err != nil {
+		return nil, err
+	}
 	return r.getFunctionFilters(false, buff.Nodes...)
 }
 
@@ -193,13 +329,77 @@ func (r RunFns) getFunctionsFromFunctionPaths() ([]kio.Filter, error) {
 			return nil, err
 		}
 	}
+	if err := r.sortFns(buff, false); err != nil {
+		return nil, err
+	}
 	return r.getFunctionFilters(true, buff.Nodes...)
 }
 
 // getFunctionsFromFunctions returns the set of explicitly provided functions as
-// Filters
-func (r RunFns) getFunctionsFromFunctions() ([]kio.Filter, error) {
-	return r.getFunctionFilters(true, r.Functions...)
+// Filters.  nodes are the input Resources, and are checked for functionConfigs
+// of the functions if r.FnConfigPath is set.
+func (r RunFns) getFunctionsFromFunctions(nodes []*yaml.RNode) ([]kio.Filter, error) {
+	if r.FnConfigPath == "" {
+		return r.getFunctionFilters(true, r.Functions...)
+	}
+
+	b, err := ioutil.ReadFile(r.FnConfigPath)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	fnConfig, err := yaml.Parse(string(b))
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "parsing fn-config %s", r.FnConfigPath)
+	}
+
+	var fns []*yaml.RNode
+	for i := range r.Functions {
+		spec := filters.GetFunctionSpec(r.Functions[i])
+		if spec == nil {
+			continue
+		}
+		if err := checkFunctionConfigs(*spec, nodes, r.FnConfigPath); err != nil {
+			return nil, err
+		}
+
+		// use a copy of the fn-config as the functionConfig for the function, with
+		// the function annotation so that it is run
+		fn := fnConfig.Copy()
+		value, err := r.Functions[i].Pipe(
+			yaml.GetAnnotation(filters.FunctionAnnotationKey))
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			err = fn.PipeE(
+				yaml.LookupCreate(yaml.MappingNode, "metadata", "annotations"),
+				yaml.SetField(filters.FunctionAnnotationKey, value.Copy()))
+			if err != nil {
+				return nil, err
+			}
+		}
+		fns = append(fns, fn)
+	}
+	return r.getFunctionFilters(true, fns...)
+}
+
+// checkFunctionConfigs returns an error if nodes contain a functionConfig for the
+// function spec, as it is also given by fnConfigPath.
+func checkFunctionConfigs(spec filters.FunctionSpec, nodes []*yaml.RNode, fnConfigPath string) error {
+	for i := range nodes {
+		s := filters.GetFunctionSpec(nodes[i])
+		if s == nil {
+			continue
+		}
+		if (spec.Container.Image != "" && s.Container.Image == spec.Container.Image) ||
+			(spec.Starlark.Path != "" && s.Starlark.Path == spec.Starlark.Path) ||
+			(spec.Exec.Path != "" && s.Exec.Path == spec.Exec.Path) {
+			return errors.Errorf(
+				"function has a functionConfig in %s and in fn-config %s, only one may be provided",
+				s.Path, fnConfigPath)
+		}
+	}
+	return nil
 }
 
 func (r RunFns) getFunctionFilters(global bool, fns ...*yaml.RNode) (
@@ -215,6 +415,24 @@ func (r RunFns) getFunctionFilters(global bool, fns ...*yaml.RNode) (
 			}
 			spec.Network = r.NetworkName
 		}
+		if spec.Exec.Path != "" {
+			p, err := r.execPath(spec.Exec.Path)
+			if err != nil {
+				return fltrs, err
+			}
+			spec.Exec.Path = p
+		}
+		if spec.Container.Image != "" {
+			mounts, err := r.containerMounts(spec.Container.StorageMounts)
+			if err != nil {
+				return fltrs, err
+			}
+			spec.Container.StorageMounts = mounts
+		}
+		if !global && spec.Starlark.Path != "" && !filepath.IsAbs(spec.Starlark.Path) {
+			// scripts of functions read from the package are relative to the package
+			spec.Starlark.Path = filepath.Join(r.Path, spec.Starlark.Path)
+		}
 		c := r.functionFilterProvider(*spec, api)
 		if c == nil {
 			continue
@@ -223,55 +441,218 @@ func (r RunFns) getFunctionFilters(global bool, fns ...*yaml.RNode) (
 		if global && ok {
 			cf.GlobalScope = true
 		}
+		if ef, ok := c.(*filters.ExecFilter); global && ok {
+			ef.GlobalScope = true
+		}
 		fltrs = append(fltrs, c)
 	}
 	return fltrs, nil
 }
 
-// sortFns sorts functions so that functions with the longest paths come first
-func sortFns(buff *kio.PackageBuffer) {
-	// sort the nodes so that we traverse them depth first
-	// functions deeper in the file system tree should be run first
-	sort.Slice(buff.Nodes, func(i, j int) bool {
-		mi, _ := buff.Nodes[i].GetMeta()
-		pi := mi.Annotations[kioutil.PathAnnotation]
-		if path.Base(path.Dir(pi)) == "functions" {
-			// don't count the functions dir, the functions are scoped 1 level above
-			pi = path.Dir(path.Dir(pi))
-		} else {
-			pi = path.Dir(pi)
-		}
-
-		mj, _ := buff.Nodes[j].GetMeta()
-		pj := mj.Annotations[kioutil.PathAnnotation]
-		if path.Base(path.Dir(pj)) == "functions" {
-			// don't count the functions dir, the functions are scoped 1 level above
-			pj = path.Dir(path.Dir(pj))
-		} else {
-			pj = path.Dir(pj)
-		}
-
-		// i is "less" than j (comes earlier) if its depth is greater -- e.g. run
-		// i before j if it is deeper in the directory structure
-		li := len(strings.Split(pi, "/"))
-		if pi == "." {
-			// local dir should have 0 path elements instead of 1
-			li = 0
-		}
-		lj := len(strings.Split(pj, "/"))
-		if pj == "." {
-			// local dir should have 0 path elements instead of 1
-			lj = 0
-		}
-		if li != lj {
-			// use greater-than because we want to sort with the longest
-			// paths FIRST rather than last
-			return li > lj
-		}
-
-		// sort by path names if depths are equal
-		return pi < pj
+// execPath returns the absolute path to the executable of an exec function.
+// Relative paths are relative to the package directory, and paths outside of the
+// package directory are an error unless r.EnableExec is set.
+func (r RunFns) execPath(p string) (string, error) {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(r.Path, p)
+	}
+	p = filepath.Clean(p)
+	if r.EnableExec || r.inPackage(p) {
+		return p, nil
+	}
+	return "", errors.Errorf(
+		"exec function %s is outside of the package %s, enable it with --enable-exec",
+		p, r.Path)
+}
+
+// containerMounts returns the mounts of a container function with the sources of
+// bind mounts made absolute.  Relative sources are relative to the package directory,
+// and sources outside of the package directory are an error unless
+// r.EnableExternalMounts is set.
+func (r RunFns) containerMounts(mounts []filters.StorageMount) ([]filters.StorageMount, error) {
+	var result []filters.StorageMount
+	for _, m := range mounts {
+		if m.MountType == "bind" {
+			if !filepath.IsAbs(m.Src) {
+				m.Src = filepath.Join(r.Path, m.Src)
+			}
+			m.Src = filepath.Clean(m.Src)
+			if !r.EnableExternalMounts && !r.inPackage(m.Src) {
+				return nil, errors.Errorf(
+					"mount %s is outside of the package %s, enable it with --enable-external-mounts",
+					m.Src, r.Path)
+			}
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// inPackage returns true if p is in the package directory.  Symlinks are resolved
+// so that they can't point outside of the package.
+func (r RunFns) inPackage(p string) bool {
+	pkg := r.Path
+	if s, err := filepath.EvalSymlinks(pkg); err == nil {
+		pkg = s
+	}
+	if s, err := filepath.EvalSymlinks(p); err == nil {
+		p = s
+	}
+	rel, err := filepath.Rel(pkg, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// mergeMounts returns mounts with overrides, replacing the mounts with the same
+// destination.
+func mergeMounts(mounts, overrides []filters.StorageMount) []filters.StorageMount {
+	var result []filters.StorageMount
+	replaced := map[string]bool{}
+	for _, m := range overrides {
+		replaced[m.DstPath] = true
+	}
+	for _, m := range mounts {
+		if !replaced[m.DstPath] {
+			result = append(result, m)
+		}
+	}
+	return append(result, overrides...)
+}
+
+// mergeEnv returns env with overrides, replacing the variables with the same name.
+func mergeEnv(env, overrides []string) []string {
+	var result []string
+	replaced := map[string]bool{}
+	for _, e := range overrides {
+		replaced[strings.SplitN(e, "=", 2)[0]] = true
+	}
+	for _, e := range env {
+		if !replaced[strings.SplitN(e, "=", 2)[0]] {
+			result = append(result, e)
+		}
+	}
+	return append(result, overrides...)
+}
+
+// fnSortKey is the key functions are sorted by
+type fnSortKey struct {
+	// order is the value of the function-order annotation
+	order int
+
+	// ordered is true if the function-order annotation is set
+	ordered bool
+
+	// dir is the directory the function is scoped to, and depth is the number of
+	// elements of dir
+	dir   string
+	depth int
+
+	// path and index are the file and the index in the file the function was read from
+	path  string
+	index int
+}
+
+// getFnSortKey returns the key to sort the function fn by.
+func getFnSortKey(fn *yaml.RNode) (fnSortKey, error) {
+	meta, err := fn.GetMeta()
+	if err != nil {
+		return fnSortKey{}, err
+	}
+	key := fnSortKey{path: meta.Annotations[kioutil.PathAnnotation]}
+	if v, found := meta.Annotations[filters.FunctionOrderAnnotationKey]; found {
+		key.order, err = strconv.Atoi(v)
+		if err != nil {
+			return key, errors.Errorf("function %s %s: %s must be an integer, got %q",
+				meta.Kind, meta.Name, filters.FunctionOrderAnnotationKey, v)
+		}
+		key.ordered = true
+	}
+	if v, found := meta.Annotations[kioutil.IndexAnnotation]; found {
+		key.index, err = strconv.Atoi(v)
+		if err != nil {
+			return key, errors.Wrap(err)
+		}
+	}
+
+	if path.Base(path.Dir(key.path)) == "functions" {
+		// don't count the functions dir, the functions are scoped 1 level above
+		key.dir = path.Dir(path.Dir(key.path))
+	} else {
+		key.dir = path.Dir(key.path)
+	}
+	key.depth = len(strings.Split(key.dir, "/"))
+	if key.dir == "." {
+		// local dir should have 0 path elements instead of 1
+		key.depth = 0
+	}
+	return key, nil
+}
+
+// less returns true if the function with key k should run before the function with key o.
+// If scoped is false, only the function-order annotations are compared.
+func (k fnSortKey) less(o fnSortKey, scoped bool) bool {
+	if k.order != o.order || !scoped {
+		return k.order < o.order
+	}
+	if k.depth != o.depth {
+		// run k before o if it is deeper in the directory structure -- use
+		// greater-than because we want to sort with the longest paths FIRST
+		return k.depth > o.depth
+	}
+	// sort by path names if depths are equal, then by the order in the file
+	if k.dir != o.dir {
+		return k.dir < o.dir
+	}
+	if k.path != o.path {
+		return k.path < o.path
+	}
+	return k.index < o.index
+}
+
+// sortFns sorts functions by their function-order annotation.  If scoped is true,
+// functions with the same order are sorted so that functions with the longest paths
+// come first, and then by the file and the index in the file they were declared at.
+// A warning is written if several functions declare the same order.
+func (r RunFns) sortFns(buff *kio.PackageBuffer, scoped bool) error {
+	keys := map[*yaml.RNode]fnSortKey{}
+	for i := range buff.Nodes {
+		key, err := getFnSortKey(buff.Nodes[i])
+		if err != nil {
+			return err
+		}
+		keys[buff.Nodes[i]] = key
+	}
+	sort.SliceStable(buff.Nodes, func(i, j int) bool {
+		return keys[buff.Nodes[i]].less(keys[buff.Nodes[j]], scoped)
 	})
+
+	// the order of functions declaring the same order is determined by their paths,
+	// which is unlikely to be intended
+	out := r.WarningOutput
+	if out == nil {
+		out = os.Stderr
+	}
+	for i := 1; i < len(buff.Nodes); i++ {
+		prev, key := keys[buff.Nodes[i-1]], keys[buff.Nodes[i]]
+		if !prev.ordered || !key.ordered || prev.order != key.order {
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "warning: functions %s and %s have the same %s %d\n",
+			fnName(buff.Nodes[i-1]), fnName(buff.Nodes[i]),
+			filters.FunctionOrderAnnotationKey, key.order); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// fnName returns a name identifying the function fn in warnings.
+func fnName(fn *yaml.RNode) string {
+	meta, _ := fn.GetMeta()
+	name := meta.Kind + " " + meta.Name
+	if p := meta.Annotations[kioutil.PathAnnotation]; p != "" {
+		name += " (" + p + ")"
+	}
+	return name
 }
 
 // init initializes the RunFns with a containerFilterProvider.
@@ -301,19 +682,33 @@ func (r *RunFns) init() {
 // ffp provides function filters
 func (r *RunFns) ffp(spec filters.FunctionSpec, api *yaml.RNode) kio.Filter {
 	if !r.DisableContainers && spec.Container.Image != "" {
+		// the filters format the functionConfig, which must not change the
+		// function config written back to the package
 		return &filters.ContainerFilter{
 			Image:         spec.Container.Image,
-			Config:        api,
+			Config:        api.Copy(),
 			Network:       spec.Network,
-			StorageMounts: r.StorageMounts,
+			StorageMounts: mergeMounts(spec.Container.StorageMounts, r.StorageMounts),
+			Env:           mergeEnv(spec.Container.Env, r.Env),
 			GlobalScope:   r.GlobalScope,
+			Stderr:        r.Stderr,
+		}
+	}
+	if spec.Exec.Path != "" {
+		return &filters.ExecFilter{
+			Path:        spec.Exec.Path,
+			Config:      api.Copy(),
+			GlobalScope: r.GlobalScope,
+			Stderr:      r.Stderr,
 		}
 	}
-	if r.EnableStarlark && spec.Starlark.Path != "" {
+	if r.EnableStarlark && (spec.Starlark.Path != "" || spec.Starlark.Program != "") {
 		return &starlark.Filter{
 			Name:           spec.Starlark.Name,
 			Path:           spec.Starlark.Path,
+			Program:        spec.Starlark.Program,
 			FunctionConfig: api,
+			Timeout:        r.StarlarkTimeout,
 		}
 	}
 	return nil
@@ -12,6 +12,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
@@ -486,7 +487,28 @@ metadata:
 				},
 			},
 			enableStarlark: true,
-			out:            []string{"name:  path: a/b/c url:  program:"},
+			// the path is relative to the package
+			out: []string{"name:  path: DIR/a/b/c url:  program:"},
+		},
+
+		{name: "starlark-function-program",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        name: inline
+        program: x = 1
+`,
+				},
+			},
+			enableStarlark: true,
+			out:            []string{"name: inline path:  url:  program: x = 1"},
 		},
 
 		{name: "starlark-function-disabled",
@@ -573,7 +595,9 @@ metadata:
 				t.FailNow()
 			}
 			for _, f := range fltrs {
-				results = append(results, strings.TrimSpace(fmt.Sprintf("%v", f)))
+				// replace the package directory so that it can be compared
+				results = append(results,
+					strings.ReplaceAll(strings.TrimSpace(fmt.Sprintf("%v", f)), d, "DIR"))
 			}
 
 			// compare the actual ordering to the expected ordering
@@ -768,3 +792,729 @@ func getFilterProvider(t *testing.T) func(filters.FunctionSpec, *yaml.RNode) kio
 		}
 	}
 }
+
+func TestRunFns_getResults(t *testing.T) {
+	fltrs := []kio.Filter{
+		&filters.ContainerFilter{Results: kio.Results{
+			{Severity: kio.SeverityWarning, Message: "a"},
+		}},
+		filters.Modifier{},
+		&filters.ContainerFilter{},
+		&filters.ContainerFilter{Results: kio.Results{
+			{Severity: kio.SeverityError, Message: "b"},
+		}},
+	}
+	results := getResults(fltrs)
+	assert.Equal(t, kio.Results{
+		{Severity: kio.SeverityWarning, Message: "a"},
+		{Severity: kio.SeverityError, Message: "b"},
+	}, results)
+	assert.True(t, results.HasErrors())
+	assert.False(t, getResults(fltrs[:3]).HasErrors())
+}
+
+func TestCmd_Execute_fnConfigPath(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+
+	// write the functionConfig outside of the package
+	fnConfigDir, err := ioutil.TempDir("", "kustomize-kyaml-fn-config")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(fnConfigDir)
+	fnConfigPath := filepath.Join(fnConfigDir, "fn-config.yaml")
+	if !assert.NoError(t, ioutil.WriteFile(fnConfigPath, []byte(`apiVersion: v1
+kind: ValueReplacer
+stringMatch: Deployment
+replace: StatefulSet
+`), 0600)) {
+		return
+	}
+
+	fn, err := yaml.Parse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      container: {image: 'gcr.io/example.com/image:version'}
+data: {}
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var apis []*yaml.RNode
+	provider := getFilterProvider(t)
+	instance := RunFns{
+		Path:         dir,
+		Functions:    []*yaml.RNode{fn},
+		FnConfigPath: fnConfigPath,
+		functionFilterProvider: func(f filters.FunctionSpec, node *yaml.RNode) kio.Filter {
+			apis = append(apis, node)
+			return provider(f, node)
+		},
+	}
+	if !assert.NoError(t, instance.Execute()) {
+		return
+	}
+
+	// the function is run with the functionConfig from the file
+	if !assert.Len(t, apis, 1) {
+		return
+	}
+	assert.Equal(t, `apiVersion: v1
+kind: ValueReplacer
+stringMatch: Deployment
+replace: StatefulSet
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      container: {image: 'gcr.io/example.com/image:version'}
+`, apis[0].MustString())
+	b, err := ioutil.ReadFile(
+		filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(b), "kind: StatefulSet")
+
+	// the functionConfig isn't written to the package
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		assert.NotContains(t, string(b), "ValueReplacer", path)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestCmd_Execute_fnConfigPathInPackage(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+
+	// write a functionConfig for the same function to the package
+	if !assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "filter.yaml"), []byte(ValueReplacerYAMLData), 0600)) {
+		return
+	}
+	fnConfigPath := filepath.Join(dir, "filter.yaml")
+
+	fn, err := yaml.Parse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      container: {image: 'gcr.io/example.com/image:version'}
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	instance := RunFns{
+		Path:                   dir,
+		Functions:              []*yaml.RNode{fn},
+		FnConfigPath:           fnConfigPath,
+		functionFilterProvider: getFilterProvider(t),
+	}
+	err = instance.Execute()
+	if assert.Error(t, err) {
+		assert.Equal(t, "function has a functionConfig in filter.yaml and in fn-config "+
+			fnConfigPath+", only one may be provided", err.Error())
+	}
+}
+
+func TestCmd_Execute_exec(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+
+	// write an exec function and its config to the package
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "fn.sh"),
+		[]byte("#!/bin/sh\nsed 's/kind: Deployment/kind: StatefulSet/'\n"), 0700)) {
+		return
+	}
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "filter.yaml"), []byte(`apiVersion: v1
+kind: ValueReplacer
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: ./fn.sh
+    config.kubernetes.io/local-config: "true"
+`), 0600)) {
+		return
+	}
+
+	if !assert.NoError(t, RunFns{Path: dir}.Execute()) {
+		return
+	}
+	b, err := ioutil.ReadFile(
+		filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(b), "kind: StatefulSet")
+}
+
+func TestCmd_Execute_execError(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "fn.sh"),
+		[]byte("#!/bin/sh\necho 'invalid replicas' >&2\nexit 1\n"), 0700)) {
+		return
+	}
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "filter.yaml"), []byte(`apiVersion: v1
+kind: ValueReplacer
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: fn.sh
+`), 0600)) {
+		return
+	}
+
+	err := RunFns{Path: dir}.Execute()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "exit status 1\ninvalid replicas\n")
+	}
+	b, err := ioutil.ReadFile(
+		filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(b), "kind: Deployment")
+}
+
+func TestCmd_Execute_starlark(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+
+	// the script path is relative to the package rather than the working directory
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "fn.star"), []byte(`
+def run(items):
+  for item in items:
+    if item["kind"] == "Deployment":
+      set_field(item, "spec.replicas", 3)
+
+run(ctx.resource_list["items"])
+`), 0600)) {
+		return
+	}
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "filter.yaml"), []byte(`apiVersion: v1
+kind: ValueReplacer
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        path: fn.star
+    config.kubernetes.io/local-config: "true"
+`), 0600)) {
+		return
+	}
+
+	if !assert.NoError(t, RunFns{Path: dir, EnableStarlark: true}.Execute()) {
+		return
+	}
+	b, err := ioutil.ReadFile(
+		filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(b), "replicas: 3")
+}
+
+func TestCmd_Execute_starlarkTimeout(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "filter.yaml"), []byte(`apiVersion: v1
+kind: ValueReplacer
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        name: loop
+        program: |
+          def run():
+            for i in range(10000000):
+              pass
+          run()
+`), 0600)) {
+		return
+	}
+
+	err := RunFns{
+		Path:            dir,
+		EnableStarlark:  true,
+		StarlarkTimeout: 10 * time.Millisecond,
+	}.Execute()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"starlark program loop exceeded the max execution time of 10ms")
+	}
+}
+
+func TestRunFns_execPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-exec")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	pkg := filepath.Join(dir, "pkg")
+	if !assert.NoError(t, os.MkdirAll(filepath.Join(pkg, "fn"), 0700)) {
+		return
+	}
+	// a symlink in the package to an executable outside of it
+	if !assert.NoError(t, os.Symlink(filepath.Join(dir, "fn.sh"), filepath.Join(pkg, "link.sh"))) {
+		return
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		enableExec bool
+		expected   string
+		err        string
+	}{
+		{
+			name:     "relative",
+			path:     "./fn/scaler",
+			expected: filepath.Join(pkg, "fn", "scaler"),
+		},
+		{
+			name:     "absolute",
+			path:     filepath.Join(pkg, "fn", "scaler"),
+			expected: filepath.Join(pkg, "fn", "scaler"),
+		},
+		{
+			name: "outside",
+			path: filepath.Join(dir, "fn.sh"),
+			err: fmt.Sprintf("exec function %s is outside of the package %s, "+
+				"enable it with --enable-exec", filepath.Join(dir, "fn.sh"), pkg),
+		},
+		{
+			name: "parent",
+			path: "../fn.sh",
+			err: fmt.Sprintf("exec function %s is outside of the package %s, "+
+				"enable it with --enable-exec", filepath.Join(dir, "fn.sh"), pkg),
+		},
+		{
+			name: "symlink",
+			path: "link.sh",
+			err: fmt.Sprintf("exec function %s is outside of the package %s, "+
+				"enable it with --enable-exec", filepath.Join(pkg, "link.sh"), pkg),
+		},
+		{
+			name:       "enabled",
+			path:       "../fn.sh",
+			enableExec: true,
+			expected:   filepath.Join(dir, "fn.sh"),
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			// the symlink target must exist to be resolved
+			if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "fn.sh"), nil, 0700)) {
+				t.FailNow()
+			}
+			p, err := RunFns{Path: pkg, EnableExec: test.enableExec}.execPath(test.path)
+			if test.err != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, test.err, err.Error())
+				}
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.expected, p)
+			}
+		})
+	}
+}
+
+func TestRunFns_containerMounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-kyaml-mounts")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	pkg := filepath.Join(dir, "pkg")
+
+	tests := []struct {
+		name                 string
+		mount                filters.StorageMount
+		enableExternalMounts bool
+		expected             filters.StorageMount
+		err                  string
+	}{
+		{
+			name:     "relative",
+			mount:    filters.StorageMount{MountType: "bind", Src: "./schemas", DstPath: "/schemas"},
+			expected: filters.StorageMount{MountType: "bind", Src: filepath.Join(pkg, "schemas"), DstPath: "/schemas"},
+		},
+		{
+			name: "read-write",
+			mount: filters.StorageMount{
+				MountType: "bind", Src: "out", DstPath: "/out", ReadWriteMode: true},
+			expected: filters.StorageMount{
+				MountType: "bind", Src: filepath.Join(pkg, "out"), DstPath: "/out", ReadWriteMode: true},
+		},
+		{
+			name:  "absolute outside",
+			mount: filters.StorageMount{MountType: "bind", Src: "/etc", DstPath: "/etc"},
+			err: fmt.Sprintf("mount /etc is outside of the package %s, "+
+				"enable it with --enable-external-mounts", pkg),
+		},
+		{
+			name:  "parent",
+			mount: filters.StorageMount{MountType: "bind", Src: "../schemas", DstPath: "/schemas"},
+			err: fmt.Sprintf("mount %s is outside of the package %s, "+
+				"enable it with --enable-external-mounts", filepath.Join(dir, "schemas"), pkg),
+		},
+		{
+			name:                 "enabled",
+			mount:                filters.StorageMount{MountType: "bind", Src: "/etc", DstPath: "/etc"},
+			enableExternalMounts: true,
+			expected:             filters.StorageMount{MountType: "bind", Src: "/etc", DstPath: "/etc"},
+		},
+		{
+			name:     "volume",
+			mount:    filters.StorageMount{MountType: "volume", Src: "myvol", DstPath: "/local/"},
+			expected: filters.StorageMount{MountType: "volume", Src: "myvol", DstPath: "/local/"},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			r := RunFns{Path: pkg, EnableExternalMounts: test.enableExternalMounts}
+			mounts, err := r.containerMounts([]filters.StorageMount{test.mount})
+			if test.err != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, test.err, err.Error())
+				}
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, []filters.StorageMount{test.expected}, mounts)
+			}
+		})
+	}
+}
+
+func TestRunFns_ffp_container(t *testing.T) {
+	r := &RunFns{
+		StorageMounts: []filters.StorageMount{
+			{MountType: "bind", Src: "/tmp/schemas", DstPath: "/schemas"}},
+		Env: []string{"REGISTRY=example.com"},
+	}
+	spec := filters.FunctionSpec{Container: filters.ContainerSpec{
+		Image: "example.com/fn",
+		StorageMounts: []filters.StorageMount{
+			{MountType: "bind", Src: "/pkg/schemas", DstPath: "/schemas"},
+			{MountType: "bind", Src: "/pkg/data", DstPath: "/data"}},
+		Env: []string{"TOKEN", "REGISTRY=gcr.io"},
+	}}
+	cf, ok := r.ffp(spec, yaml.MustParse(`kind: Example`)).(*filters.ContainerFilter)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	// the commandline mounts and variables replace those of the function
+	assert.Equal(t, []filters.StorageMount{
+		{MountType: "bind", Src: "/pkg/data", DstPath: "/data"},
+		{MountType: "bind", Src: "/tmp/schemas", DstPath: "/schemas"}}, cf.StorageMounts)
+	assert.Equal(t, []string{"TOKEN", "REGISTRY=example.com"}, cf.Env)
+}
+
+func TestCmd_Execute_storageMountOutsidePackage(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+
+	// write a test filter to the directory of configuration
+	if !assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "filter.yaml"), []byte(ValueReplacerYAMLData), 0600)) {
+		return
+	}
+
+	// the commandline mounts are rejected the same as those of the functions
+	err := RunFns{
+		Path:                   dir,
+		StorageMounts:          []filters.StorageMount{{MountType: "bind", Src: "/etc", DstPath: "/etc"}},
+		functionFilterProvider: getFilterProvider(t),
+	}.Execute()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"mount /etc is outside of the package "+dir+", enable it with --enable-external-mounts")
+	}
+	b, err := ioutil.ReadFile(
+		filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(b), "kind: Deployment")
+
+	err = RunFns{
+		Path:                   dir,
+		StorageMounts:          []filters.StorageMount{{MountType: "bind", Src: "/etc", DstPath: "/etc"}},
+		EnableExternalMounts:   true,
+		functionFilterProvider: getFilterProvider(t),
+	}.Execute()
+	assert.NoError(t, err)
+}
+
+func TestCmd_Execute_diff(t *testing.T) {
+	service := yaml.MustParse(`apiVersion: v1
+kind: Service
+metadata:
+  name: new-service
+  annotations:
+    config.kubernetes.io/path: new/service.yaml
+`)
+
+	tests := []struct {
+		name string
+		// filter is run as the function
+		filter kio.FilterFunc
+		// expected are the lines the diff must contain
+		expected []string
+		err      string
+	}{
+		{
+			name: "unchanged",
+			filter: func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+				return nodes, nil
+			},
+		},
+		{
+			name: "changed",
+			filter: func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+				for i := range nodes {
+					if err := nodes[i].PipeE(yaml.SetLabel("app", "kotlin")); err != nil {
+						return nil, err
+					}
+				}
+				return nodes, nil
+			},
+			expected: []string{
+				"--- a/java/java-deployment.resource.yaml\n+++ b/java/java-deployment.resource.yaml\n",
+				"-    app: java\n+    app: kotlin\n",
+			},
+			err: "functions would change 4 files: filter.yaml, java/java-configmap.resource.yaml, " +
+				"java/java-deployment.resource.yaml, java/java-service.resource.yaml",
+		},
+		{
+			name: "created-and-deleted",
+			filter: func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+				var result []*yaml.RNode
+				for i := range nodes {
+					if meta, _ := nodes[i].GetMeta(); meta.Kind != "Deployment" {
+						result = append(result, nodes[i])
+					}
+				}
+				return append(result, service), nil
+			},
+			expected: []string{
+				"--- a/java/java-deployment.resource.yaml\n+++ /dev/null\n",
+				"-kind: Deployment\n",
+				"--- /dev/null\n+++ b/new/service.yaml\n",
+				"+  name: new-service\n",
+			},
+			err: "functions would change 2 files: java/java-deployment.resource.yaml, " +
+				"new/service.yaml",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			dir := setupTest(t)
+			defer os.RemoveAll(dir)
+			if !assert.NoError(t, ioutil.WriteFile(
+				filepath.Join(dir, "filter.yaml"), []byte(ValueReplacerYAMLData), 0600)) {
+				t.FailNow()
+			}
+			before, err := ioutil.ReadFile(filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			diff := &bytes.Buffer{}
+			err = RunFns{
+				Path:       dir,
+				Diff:       true,
+				DiffOutput: diff,
+				functionFilterProvider: func(filters.FunctionSpec, *yaml.RNode) kio.Filter {
+					return test.filter
+				},
+			}.Execute()
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, diff.String())
+			} else if assert.Error(t, err) {
+				assert.Equal(t, test.err, err.Error())
+			}
+			for _, e := range test.expected {
+				assert.Contains(t, diff.String(), e)
+			}
+
+			// the package is not modified
+			after, err := ioutil.ReadFile(filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, string(before), string(after))
+			_, err = os.Stat(filepath.Join(dir, "new"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestCmd_Execute_functionOrder(t *testing.T) {
+	// fn returns a function config running script with the function-order annotation
+	// set to order, if it isn't empty
+	fn := func(name, script, order string) string {
+		s := fmt.Sprintf(`apiVersion: v1
+kind: ValueReplacer
+metadata:
+  name: %s
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: %s
+    config.kubernetes.io/local-config: "true"
+`, name, script)
+		if order != "" {
+			s += fmt.Sprintf("    config.kubernetes.io/function-order: %q\n", order)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name string
+		// files are the function configs written to the package
+		files map[string]string
+		// expected is the kind of the Deployment after running the functions
+		expected string
+		warning  string
+		err      string
+	}{
+		{
+			// the functions are sorted by their paths without an order
+			name: "path",
+			files: map[string]string{
+				"a.yaml": fn("stateful-set", "a.sh", ""),
+				"b.yaml": fn("daemon-set", "b.sh", ""),
+				"c.yaml": fn("replica-set", "c.sh", ""),
+			},
+			expected: "ReplicaSet",
+		},
+		{
+			name: "index",
+			files: map[string]string{
+				"fns.yaml": fn("stateful-set", "a.sh", "") + "---\n" +
+					fn("daemon-set", "b.sh", "") + "---\n" + fn("replica-set", "c.sh", ""),
+			},
+			expected: "ReplicaSet",
+		},
+		{
+			// the declared order wins over the paths
+			name: "order",
+			files: map[string]string{
+				"a.yaml": fn("stateful-set", "a.sh", "3"),
+				"b.yaml": fn("daemon-set", "b.sh", "2"),
+				"c.yaml": fn("replica-set", "c.sh", "1"),
+			},
+			expected: "StatefulSet",
+		},
+		{
+			name: "order-index",
+			files: map[string]string{
+				"fns.yaml": fn("stateful-set", "a.sh", "1") + "---\n" +
+					fn("daemon-set", "b.sh", "2") + "---\n" + fn("replica-set", "c.sh", "0"),
+			},
+			expected: "DaemonSet",
+		},
+		{
+			// functions without an order have order 0
+			name: "negative-order",
+			files: map[string]string{
+				"a.yaml": fn("stateful-set", "a.sh", ""),
+				"b.yaml": fn("daemon-set", "b.sh", ""),
+				"c.yaml": fn("replica-set", "c.sh", "-1"),
+			},
+			expected: "DaemonSet",
+		},
+		{
+			name: "same-order",
+			files: map[string]string{
+				"a.yaml": fn("stateful-set", "a.sh", "1"),
+				"b.yaml": fn("daemon-set", "b.sh", "1"),
+				"c.yaml": fn("replica-set", "c.sh", "0"),
+			},
+			expected: "DaemonSet",
+			warning: "warning: functions ValueReplacer stateful-set (a.yaml) and ValueReplacer " +
+				"daemon-set (b.yaml) have the same config.kubernetes.io/function-order 1\n",
+		},
+		{
+			name: "invalid-order",
+			files: map[string]string{
+				"a.yaml": fn("stateful-set", "a.sh", "first"),
+			},
+			expected: "Deployment",
+			err: "function ValueReplacer stateful-set: config.kubernetes.io/function-order " +
+				`must be an integer, got "first"`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			dir := setupTest(t)
+			defer os.RemoveAll(dir)
+
+			// each function changes the kind set by the previous function, so the
+			// result depends on the order they run in
+			scripts := map[string]string{
+				"a.sh": "s/kind: Deployment/kind: StatefulSet/",
+				"b.sh": "s/kind: StatefulSet/kind: DaemonSet/",
+				"c.sh": "s/kind: DaemonSet/kind: ReplicaSet/",
+			}
+			for name, script := range scripts {
+				if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name),
+					[]byte("#!/bin/sh\nsed '"+script+"'\n"), 0700)) {
+					return
+				}
+			}
+			for name, content := range test.files {
+				if !assert.NoError(t, ioutil.WriteFile(
+					filepath.Join(dir, name), []byte(content), 0600)) {
+					return
+				}
+			}
+
+			var warnings bytes.Buffer
+			err := RunFns{Path: dir, WarningOutput: &warnings}.Execute()
+			if test.err != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, test.err, err.Error())
+				}
+			} else if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.warning, warnings.String())
+
+			b, err := ioutil.ReadFile(
+				filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Contains(t, string(b), "kind: "+test.expected+"\n")
+		})
+	}
+}
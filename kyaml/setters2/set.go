@@ -4,6 +4,7 @@
 package setters2
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/go-openapi/spec"
@@ -252,6 +253,13 @@ func (s SetOpenAPI) Filter(object *yaml.RNode) (*yaml.RNode, error) {
 		}
 	}
 
+	// if the setter has a minimum or maximum, then ensure the set value is within them
+	if t != "array" {
+		if err := validateBounds(oa, s.Name, s.Value); err != nil {
+			return nil, err
+		}
+	}
+
 	v := yaml.NewScalarRNode(s.Value)
 	// values are always represented as strings the OpenAPI
 	// since the are unmarshalled into strings.  Use double quote style to
@@ -310,6 +318,35 @@ func (s SetOpenAPI) Filter(object *yaml.RNode) (*yaml.RNode, error) {
 	return object, nil
 }
 
+// validateBounds returns an error if the setter definition oa has a minimum or
+// maximum which value isn't within.
+func validateBounds(oa *yaml.RNode, name, value string) error {
+	for _, bound := range []string{"minimum", "maximum"} {
+		n := oa.Field(bound)
+		if n == nil {
+			continue
+		}
+		limit, err := strconv.ParseFloat(n.Value.YNode().Value, 64)
+		if err != nil {
+			return errors.Errorf("%s of %s must be a number, got %s",
+				bound, name, n.Value.YNode().Value)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.Errorf("%s must be a number for %s", value, name)
+		}
+		if bound == "minimum" && v < limit {
+			return errors.Errorf("%s is less than the minimum %s for %s",
+				value, n.Value.YNode().Value, name)
+		}
+		if bound == "maximum" && v > limit {
+			return errors.Errorf("%s is greater than the maximum %s for %s",
+				value, n.Value.YNode().Value, name)
+		}
+	}
+	return nil
+}
+
 // SetAll applies the set filter for all yaml nodes and only returns the nodes whose
 // corresponding file has at least one node with input setter
 func SetAll(s *Set) kio.Filter {
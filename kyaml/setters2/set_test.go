@@ -1196,6 +1196,88 @@ openAPI:
  `,
 			err: "hello does not match the possible values for replicas: [foo,baz]",
 		},
+		{
+			name:   "set-replicas-minimum",
+			setter: "replicas",
+			value:  "0",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      minimum: 1
+      maximum: 10
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+ `,
+			err: "0 is less than the minimum 1 for replicas",
+		},
+		{
+			name:   "set-replicas-maximum",
+			setter: "replicas",
+			value:  "11",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      minimum: 1
+      maximum: 10
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+ `,
+			err: "11 is greater than the maximum 10 for replicas",
+		},
+		{
+			name:   "set-replicas-bounds",
+			setter: "replicas",
+			value:  "10",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      minimum: 1
+      maximum: 10
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+ `,
+			expected: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      minimum: 1
+      maximum: 10
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "10"
+ `,
+		},
+		{
+			name:   "set-replicas-not-a-number",
+			setter: "replicas",
+			value:  "three",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      minimum: 1
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+ `,
+			err: "three must be a number for replicas",
+		},
 		{
 			name:   "error",
 			setter: "replicas",
@@ -39,34 +39,79 @@ func (fs *FieldSetter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 
 // Set updates the OpenAPI definitions and resources with the new setter value
 func (fs FieldSetter) Set(openAPIPath, resourcesPath string) (int, error) {
+	setters := FieldSetters{fs}
+	err := setters.Set(openAPIPath, resourcesPath)
+	return setters[0].Count, err
+}
+
+// FieldSetters sets the values of several field setters at once.
+type FieldSetters []FieldSetter
+
+// Set updates the OpenAPI definitions and resources with the new setter values, and
+// sets the Count of each setter to the number of fields it set.  The values are set
+// on the definitions and resources in memory first, so that nothing is written if
+// any of the setters fails -- e.g. because its value isn't one of its enumValues.
+func (fs FieldSetters) Set(openAPIPath, resourcesPath string) error {
 	// Update the OpenAPI definitions
-	soa := setters2.SetOpenAPI{
-		Name:        fs.Name,
-		Value:       fs.Value,
-		ListValues:  fs.ListValues,
-		Description: fs.Description,
-		SetBy:       fs.SetBy,
+	oa, err := yaml.ReadFile(openAPIPath)
+	if err != nil {
+		return err
 	}
-	if err := soa.UpdateFile(openAPIPath); err != nil {
-		return 0, err
+	for i := range fs {
+		soa := setters2.SetOpenAPI{
+			Name:        fs[i].Name,
+			Value:       fs[i].Value,
+			ListValues:  fs[i].ListValues,
+			Description: fs[i].Description,
+			SetBy:       fs[i].SetBy,
+		}
+		if err := oa.PipeE(soa); err != nil {
+			return err
+		}
 	}
 
 	// Load the updated definitions
-	if err := openapi.AddSchemaFromFile(openAPIPath); err != nil {
-		return 0, err
+	if err := openapi.AddSchemaFromRNodeUsingField(
+		oa, openapi.SupplementaryOpenAPIFieldName); err != nil {
+		return err
 	}
 
-	// Update the resources with the new value
-	// Set NoDeleteFiles to true as SetAll will return only the nodes of files which should be updated and
-	// hence, rest of the files should not be deleted
+	// Update the resources with the new values
+	// Set NoDeleteFiles to true as only the nodes of files which should be updated are
+	// written, and hence, rest of the files should not be deleted
 	inout := &kio.LocalPackageReadWriter{PackagePath: resourcesPath, NoDeleteFiles: true}
-	s := &setters2.Set{Name: fs.Name}
-	err := kio.Pipeline{
-		Inputs:  []kio.Reader{inout},
-		Filters: []kio.Filter{setters2.SetAll(s)},
-		Outputs: []kio.Writer{inout},
-	}.Execute()
-	return s.Count, err
+	nodes, err := inout.Read()
+	if err != nil {
+		return err
+	}
+	updated := map[*yaml.RNode]bool{}
+	for i := range fs {
+		s := &setters2.Set{Name: fs[i].Name}
+		// SetAll returns the nodes of the files which have a field with the setter
+		out, err := setters2.SetAll(s).Filter(nodes)
+		if err != nil {
+			return err
+		}
+		for j := range out {
+			updated[out[j]] = true
+		}
+		fs[i].Count = s.Count
+	}
+	var output []*yaml.RNode
+	for i := range nodes {
+		if updated[nodes[i]] {
+			output = append(output, nodes[i])
+		}
+	}
+
+	// Write the definitions and resources once all of the setters have been set
+	if err := yaml.WriteFile(oa, openAPIPath); err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		return nil
+	}
+	return inout.Write(output)
 }
 
 // SetAllSetterDefinitions reads all the Setter Definitions from the OpenAPI
@@ -6,6 +6,7 @@ package settersutil
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -104,3 +105,146 @@ spec:
 		t.FailNow()
 	}
 }
+
+func TestFieldSetters_Set(t *testing.T) {
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.namespace:
+      x-k8s-cli:
+        setter:
+          name: namespace
+          value: "project-namespace"
+    io.k8s.cli.setters.replicas:
+      type: integer
+      maximum: 10
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+    io.k8s.cli.setters.tier:
+      x-k8s-cli:
+        setter:
+          name: tier
+          value: "dev"
+          enumValues:
+            dev: development
+            prod: production
+`
+	resourceFile := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  namespace: project-namespace # {"$ref": "#/definitions/io.k8s.cli.setters.namespace"}
+  labels:
+    tier: development # {"$ref": "#/definitions/io.k8s.cli.setters.tier"}
+spec:
+  replicas: 3 # {"$ref": "#/definitions/io.k8s.cli.setters.replicas"}
+`
+	other := `apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+`
+
+	tests := []struct {
+		name             string
+		setters          FieldSetters
+		expectedCounts   []int
+		expectedResource string
+		expectedErr      string
+	}{
+		{
+			name: "set",
+			setters: FieldSetters{
+				{Name: "namespace", Value: "prod-namespace"},
+				{Name: "replicas", Value: "5"},
+				{Name: "tier", Value: "prod"},
+			},
+			expectedCounts: []int{1, 1, 1},
+			expectedResource: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  namespace: prod-namespace # {"$ref": "#/definitions/io.k8s.cli.setters.namespace"}
+  labels:
+    tier: production # {"$ref": "#/definitions/io.k8s.cli.setters.tier"}
+spec:
+  replicas: 5 # {"$ref": "#/definitions/io.k8s.cli.setters.replicas"}
+`,
+		},
+		{
+			name: "enum-error",
+			setters: FieldSetters{
+				{Name: "namespace", Value: "prod-namespace"},
+				{Name: "tier", Value: "staging"},
+			},
+			expectedErr: "staging does not match the possible values for tier: [dev,prod]",
+		},
+		{
+			name: "maximum-error",
+			setters: FieldSetters{
+				{Name: "namespace", Value: "prod-namespace"},
+				{Name: "replicas", Value: "11"},
+			},
+			expectedErr: "11 is greater than the maximum 10 for replicas",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+			openAPIPath := filepath.Join(dir, "Krmfile")
+			files := map[string]string{
+				openAPIPath:                           openAPIFile,
+				filepath.Join(dir, "deployment.yaml"): resourceFile,
+				filepath.Join(dir, "service.yaml"):    other,
+			}
+			for path, content := range files {
+				if !assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600)) {
+					t.FailNow()
+				}
+			}
+
+			err = test.setters.Set(openAPIPath, dir)
+			if test.expectedErr != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), test.expectedErr)
+
+				// nothing is written if any of the setters fail
+				for path, content := range files {
+					actual, err := ioutil.ReadFile(path)
+					if !assert.NoError(t, err) {
+						t.FailNow()
+					}
+					assert.Equal(t, content, string(actual))
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			var counts []int
+			for i := range test.setters {
+				counts = append(counts, test.setters[i].Count)
+			}
+			assert.Equal(t, test.expectedCounts, counts)
+			actual, err := ioutil.ReadFile(filepath.Join(dir, "deployment.yaml"))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, test.expectedResource, string(actual))
+			actual, err = ioutil.ReadFile(filepath.Join(dir, "service.yaml"))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, other, string(actual))
+		})
+	}
+}
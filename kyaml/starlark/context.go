@@ -36,7 +36,9 @@ func (c *Context) predeclared() (starlark.StringDict, error) {
 	}
 
 	return starlark.StringDict{
-		"ctx": starlarkstruct.FromStringDict(starlarkstruct.Default, dict),
+		"ctx":       starlarkstruct.FromStringDict(starlarkstruct.Default, dict),
+		"get_field": starlark.NewBuiltin("get_field", getField),
+		"set_field": starlark.NewBuiltin("set_field", setField),
 	}, nil
 }
 
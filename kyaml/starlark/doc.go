@@ -21,6 +21,15 @@
 // Changes made by the starlark program to the "functionConfig" will be reflected in the
 // Filter.FunctionConfig value.
 //
+// The builtins get_field(obj, path, default=None) and set_field(obj, path, value) read and set
+// nested fields, where path is either a string of field names separated by "." -- e.g.
+// "spec.replicas" -- or a list of field names and list indexes -- e.g.
+// ["metadata", "annotations", "config.kubernetes.io/path"].  set_field creates the missing
+// dictionaries in path.
+//
+// The program is sandboxed: it has no access to the file system or the network, and may not
+// load other modules.  Filter.Timeout bounds the time the program may run for.
+//
 // The Filter will also format the output so that output has the preferred field ordering
 // rather than an alphabetical field ordering.
 //
@@ -288,3 +288,78 @@ run(ctx.resource_list["items"])
 	//       - name: nginx
 	//         image: nginx:1.7.9 # {"$ref": "#/definitions/io.k8s.cli.substitutions.image-2"}
 }
+
+// ExampleFilter_Filter_oamTrait injects the replicaCount of the ManualScalerTraits of OAM
+// ApplicationConfigurations from their scaler annotation, using the get_field and set_field
+// builtins to read and set the nested fields.
+func ExampleFilter_Filter_oamTrait() {
+	input := bytes.NewBufferString(`
+apiVersion: core.oam.dev/v1alpha2
+kind: ApplicationConfiguration
+metadata:
+  name: example-appconfig
+  annotations:
+    scaler: "3"
+spec:
+  components:
+  - componentName: example-component
+    traits:
+    - trait:
+        apiVersion: core.oam.dev/v1alpha2
+        kind: ManualScalerTrait
+        metadata:
+          name: example-appconfig-trait
+        spec:
+          replicaCount: 1
+`)
+
+	fltr := &starlark.Filter{
+		Name: "oam-trait",
+		Program: `
+def inject(resource):
+  replicas = get_field(resource, "metadata.annotations.scaler")
+  if resource["kind"] != "ApplicationConfiguration" or replicas == None:
+    return
+  for component in get_field(resource, "spec.components", []):
+    for trait in component.get("traits", []):
+      if get_field(trait, "trait.kind") == "ManualScalerTrait":
+        set_field(trait, "trait.spec.replicaCount", int(replicas))
+
+def run(items):
+  for item in items:
+    inject(item)
+
+run(ctx.resource_list["items"])
+`,
+	}
+
+	output := &bytes.Buffer{}
+	err := kio.Pipeline{
+		Inputs:  []kio.Reader{&kio.ByteReader{Reader: input}},
+		Filters: []kio.Filter{fltr},
+		Outputs: []kio.Writer{&kio.ByteWriter{Writer: output}}}.Execute()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(output.String())
+
+	// Output:
+	// apiVersion: core.oam.dev/v1alpha2
+	// kind: ApplicationConfiguration
+	// metadata:
+	//   name: example-appconfig
+	//   annotations:
+	//     scaler: "3"
+	// spec:
+	//   components:
+	//   - componentName: example-component
+	//     traits:
+	//     - trait:
+	//         apiVersion: core.oam.dev/v1alpha2
+	//         kind: ManualScalerTrait
+	//         metadata:
+	//           name: example-appconfig-trait
+	//         spec:
+	//           replicaCount: 3
+}
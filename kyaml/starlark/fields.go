@@ -0,0 +1,167 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package starlark
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// getField is the get_field builtin.
+//
+// get_field(obj, path, default=None) returns the value of the field at path in
+// obj, or default if any of the fields in path are missing.
+func getField(
+	_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (
+	starlark.Value, error) {
+	var obj, path starlark.Value
+	var def starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"obj", &obj, "path", &path, "default?", &def); err != nil {
+		return nil, err
+	}
+	fields, err := fieldPath(b.Name(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := obj
+	for _, field := range fields {
+		var found bool
+		value, found, err = index(value, field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		if !found {
+			return def, nil
+		}
+	}
+	return value, nil
+}
+
+// setField is the set_field builtin.
+//
+// set_field(obj, path, value) sets the field at path in obj to value, creating
+// the missing dictionaries in path.
+func setField(
+	_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (
+	starlark.Value, error) {
+	var obj, path, value starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"obj", &obj, "path", &path, "value", &value); err != nil {
+		return nil, err
+	}
+	fields, err := fieldPath(b.Name(), path)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s: path must not be empty", b.Name())
+	}
+
+	parent := obj
+	for _, field := range fields[:len(fields)-1] {
+		next, found, err := index(parent, field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		if !found {
+			next = starlark.NewDict(1)
+			if err := setIndex(parent, field, next); err != nil {
+				return nil, fmt.Errorf("%s: %v", b.Name(), err)
+			}
+		}
+		parent = next
+	}
+	if err := setIndex(parent, fields[len(fields)-1], value); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+// fieldPath returns the fields of path, which is either a string of field names
+// separated by '.' -- e.g. "spec.replicas" -- or a list of field names and list
+// indexes -- e.g. ["metadata", "annotations", "config.kubernetes.io/path"].
+func fieldPath(fnName string, path starlark.Value) ([]string, error) {
+	if s, ok := starlark.AsString(path); ok {
+		if s == "" {
+			return nil, nil
+		}
+		return strings.Split(s, "."), nil
+	}
+
+	iterable, ok := path.(starlark.Iterable)
+	if !ok {
+		return nil, fmt.Errorf("%s: path must be a string or a list, got %s",
+			fnName, path.Type())
+	}
+	var fields []string
+	it := iterable.Iterate()
+	defer it.Done()
+	var v starlark.Value
+	for it.Next(&v) {
+		switch field := v.(type) {
+		case starlark.String:
+			fields = append(fields, string(field))
+		case starlark.Int:
+			fields = append(fields, field.String())
+		default:
+			return nil, fmt.Errorf("%s: path elements must be strings or ints, got %s",
+				fnName, v.Type())
+		}
+	}
+	return fields, nil
+}
+
+// index returns the value of field in value, and whether it was found.
+// Fields of lists are their indexes.  A None value has no fields.
+func index(value starlark.Value, field string) (starlark.Value, bool, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, false, nil
+	case *starlark.Dict:
+		found, ok, err := v.Get(starlark.String(field))
+		if err != nil || !ok || found == starlark.None {
+			return nil, false, err
+		}
+		return found, true, nil
+	case *starlark.List:
+		i, err := listIndex(v, field)
+		if err != nil {
+			return nil, false, err
+		}
+		return v.Index(i), true, nil
+	default:
+		return nil, false, fmt.Errorf("can't get field %s of %s", field, value.Type())
+	}
+}
+
+// setIndex sets field in value to fieldValue.
+func setIndex(value starlark.Value, field string, fieldValue starlark.Value) error {
+	switch v := value.(type) {
+	case *starlark.Dict:
+		return v.SetKey(starlark.String(field), fieldValue)
+	case *starlark.List:
+		i, err := listIndex(v, field)
+		if err != nil {
+			return err
+		}
+		return v.SetIndex(i, fieldValue)
+	default:
+		return fmt.Errorf("can't set field %s of %s", field, value.Type())
+	}
+}
+
+// listIndex parses field as an index of list.
+func listIndex(list *starlark.List, field string) (int, error) {
+	i, err := strconv.Atoi(field)
+	if err != nil || i < 0 || i >= list.Len() {
+		return 0, fmt.Errorf("index %s out of range for list of length %d",
+			field, list.Len())
+	}
+	return i, nil
+}
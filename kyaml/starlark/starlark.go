@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/qri-io/starlib/util"
 	"go.starlark.net/starlark"
@@ -33,6 +34,10 @@ type Filter struct {
 	// FunctionConfig is the value to be provided for resourceList.functionConfig as specified by
 	// https://github.com/kubernetes-sigs/kustomize/blob/master/cmd/config/docs/api-conventions/functions-spec.md.
 	FunctionConfig *yaml.RNode
+
+	// Timeout if non-zero is the max time the program may run for, after which
+	// the program is cancelled and the Filter returns an error.
+	Timeout time.Duration
 }
 
 func (sf *Filter) String() string {
@@ -92,9 +97,8 @@ func (sf *Filter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 	if err != nil {
 		return nil, errors.Wrap(err)
 	}
-	_, err = starlark.ExecFile(thread, sf.Name, sf.Program, pd)
-	if err != nil {
-		return nil, errors.Wrap(err)
+	if err := sf.exec(thread, pd); err != nil {
+		return nil, err
 	}
 
 	results, err := sf.resourceListToOutput(value, ids)
@@ -107,6 +111,34 @@ func (sf *Filter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 	return filters.FormatFilter{}.Filter(results)
 }
 
+// exec runs the program on thread, cancelling it and returning an error if it
+// runs for longer than sf.Timeout.  The program stops at its next step once it
+// is cancelled, so a builtin which blocks, e.g. fetching a URL, delays it.
+func (sf *Filter) exec(thread *starlark.Thread, predeclared starlark.StringDict) error {
+	if sf.Timeout == 0 {
+		_, err := starlark.ExecFile(thread, sf.Name, sf.Program, predeclared)
+		return errors.Wrap(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := starlark.ExecFile(thread, sf.Name, sf.Program, predeclared)
+		done <- err
+	}()
+	timer := time.NewTimer(sf.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return errors.Wrap(err)
+	case <-timer.C:
+		// wait for the program to stop, its changes to the resources are discarded
+		thread.Cancel("timeout")
+		<-done
+		return errors.Errorf("starlark program %s exceeded the max execution time of %v",
+			sf.Name, sf.Timeout)
+	}
+}
+
 // tuple maps an input resource to the output resource
 type tuple struct {
 	// in is the RNode provided to the starlark program
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-errors/errors"
 	"github.com/stretchr/testify/assert"
@@ -459,6 +460,52 @@ spec:
 kind: Script
 spec:
   value: updated
+`,
+		},
+		{
+			name: "get_field_set_field",
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    config.kubernetes.io/replicas: "3"
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1
+`,
+			script: `
+def run(r):
+  for resource in r:
+    replicas = get_field(resource, ["metadata", "annotations", "config.kubernetes.io/replicas"])
+    set_field(resource, "spec.replicas", int(replicas))
+    image = get_field(resource, "spec.template.spec.containers.0.image")
+    set_field(resource, ["spec", "template", "spec", "containers", 0, "image"], image + "-alpine")
+    set_field(resource, "spec.template.metadata.labels.app", get_field(resource, "metadata.labels.app", "nginx"))
+
+run(ctx.resource_list["items"])
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    config.kubernetes.io/replicas: "3"
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1-alpine
 `,
 		},
 	}
@@ -508,3 +555,77 @@ spec:
 		})
 	}
 }
+
+func TestFilter_Filter_errors(t *testing.T) {
+	var tests = []struct {
+		name     string
+		script   string
+		expected string
+	}{
+		{
+			name:     "load",
+			script:   `load("os.star", "os")`,
+			expected: "load not implemented by this application",
+		},
+		{
+			name:     "get_field_of_string",
+			script:   `get_field(ctx.resource_list, "items.0.kind.foo")`,
+			expected: "get_field: can't get field foo of string",
+		},
+		{
+			name:     "get_field_index",
+			script:   `get_field(ctx.resource_list, "items.1")`,
+			expected: "get_field: index 1 out of range for list of length 1",
+		},
+		{
+			name:     "set_field_path",
+			script:   `set_field(ctx.resource_list, 1, "foo")`,
+			expected: "set_field: path must be a string or a list, got int",
+		},
+		{
+			name:     "set_field_empty_path",
+			script:   `set_field(ctx.resource_list, "", "foo")`,
+			expected: "set_field: path must not be empty",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			input, err := yaml.Parse(`kind: Deployment`)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			f := &Filter{Name: test.name, Program: test.script}
+			_, err = f.Filter([]*yaml.RNode{input})
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.expected)
+			}
+		})
+	}
+}
+
+func TestFilter_Filter_timeout(t *testing.T) {
+	f := &Filter{
+		// the program never finishes unless it is cancelled
+		Name: "loop",
+		Program: `
+def run():
+  for i in range(1000000000):
+    for j in range(1000000000):
+      pass
+
+run()
+`,
+		Timeout: 10 * time.Millisecond,
+	}
+	_, err := f.Filter(nil)
+	if assert.Error(t, err) {
+		assert.Equal(t, "starlark program loop exceeded the max execution time of 10ms",
+			err.Error())
+	}
+
+	// the program finishes before the timeout
+	f = &Filter{Name: "noop", Program: `x = 1`, Timeout: time.Minute}
+	_, err = f.Filter(nil)
+	assert.NoError(t, err)
+}
@@ -17,7 +17,7 @@ var typeToTag = map[string]string{
 	"string":  StringTag,
 	"integer": IntTag,
 	"boolean": BoolTag,
-	"number":  "!!float",
+	"number":  FloatTag,
 }
 
 // FormatNonStringStyle makes sure that values which parse as non-string values in yaml 1.1
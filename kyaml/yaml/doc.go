@@ -32,7 +32,7 @@
 //  [AnnotationSetter{}, AnnotationGetter{}, AnnotationClearer{}]
 //
 // Working with fields by path:
-//  [PathMatcher{}, PathGetter{}]
+//  [PathMatcher{}, PathGetter{}, PathsGetter{}]
 //
 // Working with individual fields on Maps and Objects:
 //  [FieldMatcher{}, FieldSetter{}, FieldGetter{}]
@@ -40,6 +40,9 @@
 // Working with individual elements in Sequences:
 //  [ElementAppender{}, ElementSetter{}, ElementMatcher{}]
 //
+// Expanding anchors, aliases and merge keys:
+//  [MergeKeysResolver{}]
+//
 // Writing Filters
 //
 // Users may implement their own filter functions.  When doing so, can be necessary to work with
@@ -15,6 +15,7 @@ var Filters = map[string]func() Filter{
 	"AnnotationClearer": func() Filter { return &AnnotationClearer{} },
 	"AnnotationGetter":  func() Filter { return &AnnotationGetter{} },
 	"AnnotationSetter":  func() Filter { return &AnnotationSetter{} },
+	"ConditionMatcher":  func() Filter { return &ConditionMatcher{} },
 	"LabelSetter":       func() Filter { return &LabelSetter{} },
 	"ElementAppender":   func() Filter { return &ElementAppender{} },
 	"ElementMatcher":    func() Filter { return &ElementMatcher{} },
@@ -22,8 +23,11 @@ var Filters = map[string]func() Filter{
 	"FilterMatcher":     func() Filter { return &FilterMatcher{} },
 	"FieldMatcher":      func() Filter { return &FieldMatcher{} },
 	"FieldSetter":       func() Filter { return &FieldSetter{} },
+	"MergeKeysResolver": func() Filter { return &MergeKeysResolver{} },
 	"PathGetter":        func() Filter { return &PathGetter{} },
+	"PathClearer":       func() Filter { return &PathClearer{} },
 	"PathMatcher":       func() Filter { return &PathMatcher{} },
+	"PathsGetter":       func() Filter { return &PathsGetter{} },
 	"Parser":            func() Filter { return &Parser{} },
 	"PrefixSetter":      func() Filter { return &PrefixSetter{} },
 	"ValueReplacer":     func() Filter { return &ValueReplacer{} },
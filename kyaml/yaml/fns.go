@@ -6,6 +6,7 @@ package yaml
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
@@ -55,6 +56,11 @@ type ElementSetter struct {
 	// Value is a field value on the elements.  It is used to find matching elements to
 	// update / delete.
 	Value string `yaml:"value,omitempty"`
+
+	// OverrideStyle can be set to use the style and comments of Element when
+	// replacing an existing element.  Otherwise, the style and comments of the
+	// existing element are retained on the new element.
+	OverrideStyle bool `yaml:"overrideStyle,omitempty"`
 }
 
 func (e ElementSetter) Filter(rn *RNode) (*RNode, error) {
@@ -90,9 +96,13 @@ func (e ElementSetter) Filter(rn *RNode) (*RNode, error) {
 			continue
 		}
 		// replace operation -- replace the element in the Content
+		if !e.OverrideStyle {
+			keepFormat(elem, e.Element)
+		}
 		newContent = append(newContent, e.Element)
 	}
 	rn.YNode().Content = newContent
+	fallbackToBlockStyle(rn.YNode(), e.Element)
 
 	// deletion operation -- return nil
 	if IsMissingOrNull(NewRNode(e.Element)) {
@@ -160,6 +170,68 @@ func (c FieldClearer) Filter(rn *RNode) (*RNode, error) {
 	return nil, nil
 }
 
+// ClearFieldWithPrune returns a PathClearer which prunes the parents left
+// empty by clearing the field.
+func ClearFieldWithPrune(path ...string) PathClearer {
+	return PathClearer{Path: path, Prune: true}
+}
+
+// PathClearer removes the field at Path.
+// Returns a RNode with the removed field value, or nil if the field doesn't
+// exist.
+type PathClearer struct {
+	Kind string `yaml:"kind,omitempty"`
+
+	// Path is a slice of parts leading to the field to remove.
+	// See PathGetter for the supported path parts.  The last part must be the
+	// name of a field.
+	Path []string `yaml:"path,omitempty"`
+
+	// Prune will cause MappingNodes which are left empty by removing the
+	// field to be removed as well, walking back up the Path.  MappingNodes
+	// which are list elements are never removed.
+	Prune bool `yaml:"prune,omitempty"`
+}
+
+func (c PathClearer) Filter(rn *RNode) (*RNode, error) {
+	path := cleanPath(c.Path)
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	// lookup each of the parents of the field
+	parents := []*RNode{rn}
+	for i := 0; i < len(path); i++ {
+		path = splitIndexPart(parents[i], path, i)
+		if i == len(path)-1 {
+			break
+		}
+		parent, err := parents[i].Pipe(Lookup(path[i]))
+		if IsMissingOrError(parent, err) {
+			return nil, err
+		}
+		parents = append(parents, parent)
+	}
+
+	removed, err := parents[len(parents)-1].Pipe(Clear(path[len(path)-1]))
+	if removed == nil || err != nil || !c.Prune {
+		return removed, err
+	}
+
+	// remove the parents which are now empty -- parents[i] is the
+	// value of the path[i-1] field in parents[i-1]
+	for i := len(parents) - 1; i > 0; i-- {
+		if IsListIndex(path[i-1]) || !IsEmpty(parents[i]) ||
+			parents[i].YNode().Kind != yaml.MappingNode {
+			break
+		}
+		if _, err := parents[i-1].Pipe(Clear(path[i-1])); err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
+
 func MatchElement(field, value string) ElementMatcher {
 	return ElementMatcher{FieldName: field, FieldValue: value}
 }
@@ -305,6 +377,71 @@ func (f FieldMatcher) Filter(rn *RNode) (*RNode, error) {
 	return nil, nil
 }
 
+// IfFieldEquals returns a ConditionMatcher which matches RNodes with the
+// field set to value.
+func IfFieldEquals(name, value string) ConditionMatcher {
+	return ConditionMatcher{Path: []string{name}, Value: value}
+}
+
+// IfFieldMatches returns a ConditionMatcher which matches RNodes with the
+// field value matching the regular expression.
+func IfFieldMatches(name, regex string) ConditionMatcher {
+	return ConditionMatcher{Path: []string{name}, Regex: regex}
+}
+
+// IfFieldPresent returns a ConditionMatcher which matches RNodes with the
+// field set.
+func IfFieldPresent(name string) ConditionMatcher {
+	return ConditionMatcher{Path: []string{name}}
+}
+
+// ConditionMatcher returns its input if the field at Path matches, and nil
+// otherwise so that the remaining Filters of a Pipe are skipped.
+// e.g. trait.PipeE(IfFieldEquals("kind", "ManualScalerTrait"), Lookup(...), Set(...))
+// only sets the field on ManualScalerTraits.
+//
+// If neither Value nor Regex is set, the field only needs to be present.
+type ConditionMatcher struct {
+	Kind string `yaml:"kind,omitempty"`
+
+	// Path is the path to the field which is checked.
+	// See PathGetter for the supported path parts.
+	Path []string `yaml:"path,omitempty"`
+
+	// Value if set requires the field value to be equal to it.
+	Value string `yaml:"value,omitempty"`
+
+	// Regex if set requires the field value to match the regular expression.
+	Regex string `yaml:"regex,omitempty"`
+}
+
+func (c ConditionMatcher) Filter(rn *RNode) (*RNode, error) {
+	// never match a missing input, such as from a failed Lookup
+	if IsMissingOrNull(rn) {
+		return nil, nil
+	}
+
+	field, err := rn.Pipe(Lookup(c.Path...))
+	if err != nil || IsMissingOrNull(field) {
+		return nil, err
+	}
+	switch {
+	case c.Regex != "":
+		r, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "ConditionMatcher Regex does not compile")
+		}
+		if field.YNode().Kind != yaml.ScalarNode || !r.MatchString(field.YNode().Value) {
+			return nil, nil
+		}
+	case c.Value != "":
+		if field.YNode().Kind != yaml.ScalarNode || field.YNode().Value != c.Value {
+			return nil, nil
+		}
+	}
+	return rn, nil
+}
+
 // Lookup returns a PathGetter to lookup a field by its path.
 func Lookup(path ...string) PathGetter {
 	return PathGetter{Path: path}
@@ -325,6 +462,8 @@ type PathGetter struct {
 	// * FieldMatcher -- e.g. "spec"
 	// * Map Key -- e.g. "app.k8s.io/version"
 	// * List Entry -- e.g. "[name=nginx]" or "[=-jar]"
+	// * List Index -- e.g. "[0]", or "containers[0]" as a shorthand for
+	//   "containers", "[0]" where the map has no "containers[0]" key
 	//
 	// Map Keys and Fields are equivalent.
 	// See FieldMatcher for more on Fields and Map Keys.
@@ -332,9 +471,16 @@ type PathGetter struct {
 	// List Entries are specified as map entry to match [fieldName=fieldValue].
 	// See Elem for more on List Entries.
 	//
+	// List Indexes are 0-based and must be non-negative -- negative indexes
+	// are rejected rather than counted from the end of the list.  An index
+	// which is out of range is an error, unless Create is set and the index
+	// is equal to the length of the list, in which case a new element is
+	// appended.
+	//
 	// Examples:
 	// * spec.template.spec.container with matching name: [name=nginx]
 	// * spec.template.spec.container.argument matching a value: [=-jar]
+	// * spec.template.spec.container at the first position: containers[0]
 	Path []string `yaml:"path,omitempty"`
 
 	// Create will cause missing path parts to be created as they are walked.
@@ -356,13 +502,16 @@ func (l PathGetter) Filter(rn *RNode) (*RNode, error) {
 
 	// iterate over path until encountering an error or missing value
 	l.Path = cleanPath(l.Path)
-	for i := range l.Path {
+	for i := 0; i < len(l.Path); i++ {
+		l.Path = splitIndexPart(match, l.Path, i)
 		var part, nextPart string
 		part = l.Path[i]
 		if len(l.Path) > i+1 {
 			nextPart = l.Path[i+1]
 		}
-		if IsListIndex(part) {
+		if IsIdxNumber(part) {
+			match, err = l.doIndex(match, part, l.getKind(nextPart))
+		} else if IsListIndex(part) {
 			match, err = l.doElem(match, part)
 		} else {
 			fieldPath = append(fieldPath, part)
@@ -406,6 +555,32 @@ func (l PathGetter) doElem(rn *RNode, part string) (*RNode, error) {
 	return rn.Pipe(ElementMatcher{FieldName: name, FieldValue: value, Create: elem})
 }
 
+func (l PathGetter) doIndex(rn *RNode, part string, kind yaml.Kind) (*RNode, error) {
+	if err := ErrorIfInvalid(rn, yaml.SequenceNode); err != nil {
+		return nil, err
+	}
+	idx, err := strconv.Atoi(part[1 : len(part)-1])
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if idx < 0 {
+		return nil, errors.Errorf("negative list index %s is not supported", part)
+	}
+
+	elements := rn.Content()
+	if idx < len(elements) {
+		return NewRNode(elements[idx]), nil
+	}
+	if !IsCreate(l.Create) || idx > len(elements) {
+		return nil, errors.Errorf(
+			"list index %s out of range for %s: list has %d elements",
+			part, strings.Join(rn.FieldPath(), "."), len(elements))
+	}
+
+	// append a new element at the end of the list
+	return rn.Pipe(Append(&yaml.Node{Kind: kind, Style: l.Style}))
+}
+
 func (l PathGetter) doField(
 	rn *RNode, name string, kind yaml.Kind) (*RNode, error) {
 	if !IsCreate(l.Create) {
@@ -429,6 +604,96 @@ func (l PathGetter) getKind(nextPart string) yaml.Kind {
 	return yaml.MappingNode
 }
 
+// LookupAll returns a PathsGetter to lookup all fields matching a path which
+// may contain wildcards.
+func LookupAll(path ...string) PathsGetter {
+	return PathsGetter{Path: path}
+}
+
+// PathsGetter returns all RNodes under Path.
+//
+// PathsGetter supports the same path parts as PathGetter, and additionally
+// supports the wildcard part "*" (or "[*]", or "field[*]" as a shorthand for
+// "field", "[*]") to match every element of a SequenceNode or every field
+// value of a MappingNode.
+//
+// A wildcard applied to a ScalarNode is an error.  A wildcard applied to an
+// empty SequenceNode or MappingNode yields no matches.
+type PathsGetter struct {
+	Kind string `yaml:"kind,omitempty"`
+
+	// Path is a slice of parts leading to the RNodes to lookup.
+	Path []string `yaml:"path,omitempty"`
+}
+
+// Filter returns the matching RNodes wrapped in a SequenceNode, or nil if
+// nothing matches.
+func (l PathsGetter) Filter(rn *RNode) (*RNode, error) {
+	matches, err := l.FilterAll(rn)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	seq := NewRNode(&yaml.Node{Kind: yaml.SequenceNode})
+	for i := range matches {
+		seq.YNode().Content = append(seq.YNode().Content, matches[i].YNode())
+	}
+	return seq, nil
+}
+
+// FilterAll returns the matching RNodes.
+func (l PathsGetter) FilterAll(rn *RNode) ([]*RNode, error) {
+	return l.filterAll(rn, cleanPath(l.Path))
+}
+
+func (l PathsGetter) filterAll(rn *RNode, path []string) ([]*RNode, error) {
+	// lookup everything up to the next wildcard
+	match := rn
+	var err error
+	i := 0
+	for ; i < len(path); i++ {
+		path = splitIndexPart(match, path, i)
+		if IsWildcard(path[i]) {
+			break
+		}
+		match, err = match.Pipe(Lookup(path[i]))
+		if IsMissingOrError(match, err) {
+			return nil, err
+		}
+	}
+	if i == len(path) {
+		return []*RNode{match}, nil
+	}
+
+	// fan out over the wildcard
+	var children []*RNode
+	switch match.YNode().Kind {
+	case yaml.SequenceNode:
+		children, err = match.Elements()
+	case yaml.MappingNode:
+		err = match.VisitFields(func(node *MapNode) error {
+			node.Value.AppendToFieldPath(append(match.FieldPath(), node.Key.YNode().Value)...)
+			children = append(children, node.Value)
+			return nil
+		})
+	default:
+		err = errors.Errorf("wildcard %s cannot be applied to %s %s",
+			path[i], nodeTypeIndex[match.YNode().Kind], strings.Join(match.FieldPath(), "."))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []*RNode{}
+	for _, child := range children {
+		m, err := l.filterAll(child, path[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m...)
+	}
+	return matches, nil
+}
+
 func SetField(name string, value *RNode) FieldSetter {
 	return FieldSetter{Name: name, Value: value}
 }
@@ -452,9 +717,13 @@ type FieldSetter struct {
 
 	StringValue string `yaml:"stringValue,omitempty"`
 
-	// OverrideStyle can be set to override the style of the existing node
-	// when setting it.  Otherwise, if an existing node is found, the style is
-	// retained.
+	// OverrideStyle can be set to use the style and comments of Value when
+	// replacing an existing node.  Otherwise, if an existing node is found, its
+	// style and comments are retained on the new node.
+	//
+	// Note: OverrideStyle used to keep the style of the existing node if Value
+	// had no style.  Value is now used as-is, so an unstyled Value replaces the
+	// style of the existing node.
 	OverrideStyle bool `yaml:"overrideStyle,omitempty"`
 }
 
@@ -467,11 +736,8 @@ func (s FieldSetter) Filter(rn *RNode) (*RNode, error) {
 		if err := ErrorIfInvalid(rn, yaml.ScalarNode); err != nil {
 			return rn, err
 		}
-		// only apply the style if there is not an existing style
-		// or we want to override it
-		if !s.OverrideStyle || s.Value.YNode().Style == 0 {
-			// keep the original style if it exists
-			s.Value.YNode().Style = rn.YNode().Style
+		if !s.OverrideStyle {
+			keepFormat(rn.YNode(), s.Value.YNode())
 		}
 		rn.SetYNode(s.Value.YNode())
 		return rn, nil
@@ -486,12 +752,11 @@ func (s FieldSetter) Filter(rn *RNode) (*RNode, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the Value may not be representable in the flow style of rn
+	fallbackToBlockStyle(rn.YNode(), s.Value.YNode())
 	if field != nil {
-		// only apply the style if there is not an existing style
-		// or we want to override it
-		if !s.OverrideStyle || field.YNode().Style == 0 {
-			// keep the original style if it exists
-			s.Value.YNode().Style = field.YNode().Style
+		if !s.OverrideStyle {
+			keepFormat(field.YNode(), s.Value.YNode())
 		}
 		// need to def ref the Node since field is ephemeral
 		field.SetYNode(s.Value.YNode())
@@ -505,6 +770,68 @@ func (s FieldSetter) Filter(rn *RNode) (*RNode, error) {
 	return s.Value, nil
 }
 
+// keepFormat copies the comments and style of the existing node onto the node
+// replacing it.
+//
+// The style is only copied between nodes of the same Kind, and quoting is not
+// copied onto values tagged as non-strings (e.g. !!int) since quoting them
+// would change their type.  The flow style is not copied onto values which
+// must be written in block style.
+func keepFormat(existing, value *yaml.Node) {
+	if existing == nil || value == nil {
+		return
+	}
+	if value.HeadComment == "" {
+		value.HeadComment = existing.HeadComment
+	}
+	if value.LineComment == "" {
+		value.LineComment = existing.LineComment
+	}
+	if value.FootComment == "" {
+		value.FootComment = existing.FootComment
+	}
+
+	if existing.Kind != value.Kind {
+		return
+	}
+	style := existing.Style
+	if value.Kind == yaml.ScalarNode && value.Tag != "" && value.Tag != StringTag {
+		// quoting a non-string would turn it into a string
+		style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+	}
+	if requiresBlockStyle(value) {
+		style &^= yaml.FlowStyle
+	}
+	value.Style = style
+}
+
+// fallbackToBlockStyle removes the flow style from the parent of value if
+// value must be written in block style.
+// Note: parent is still written in flow style if any of its own parents
+// have the flow style.
+func fallbackToBlockStyle(parent, value *yaml.Node) {
+	if parent.Style&yaml.FlowStyle != 0 && requiresBlockStyle(value) {
+		parent.Style &^= yaml.FlowStyle
+	}
+}
+
+// requiresBlockStyle returns true if n contains a literal or folded scalar,
+// which may only be written in block style.
+func requiresBlockStyle(n *yaml.Node) bool {
+	if n == nil {
+		return false
+	}
+	if n.Kind == yaml.ScalarNode {
+		return n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0
+	}
+	for i := range n.Content {
+		if requiresBlockStyle(n.Content[i]) {
+			return true
+		}
+	}
+	return false
+}
+
 // Tee calls the provided Filters, and returns its argument rather than the result
 // of the filters.
 // May be used to fork sub-filters from a call.
@@ -528,6 +855,137 @@ func (t TeePiper) Filter(rn *RNode) (*RNode, error) {
 	return rn, err
 }
 
+// ResolveMergeKeys returns a filter which expands the aliases and merge keys
+// (<<) of a node into concrete fields.
+func ResolveMergeKeys() MergeKeysResolver {
+	return MergeKeysResolver{}
+}
+
+// MergeKeysResolver replaces aliases with copies of their anchored nodes, and
+// merge keys (<<) with the fields of the merged maps, so that Filters see the
+// merged fields and the output doesn't contain anchors or aliases.
+//
+// Fields are merged as described by https://yaml.org/type/merge.html:
+// the fields of a map take precedence over the merged fields, and when a list
+// of maps is merged, the maps earlier in the list take precedence.
+//
+// Returns an error for aliases which refer to themselves.
+type MergeKeysResolver struct {
+	Kind string `yaml:"kind,omitempty"`
+}
+
+func (m MergeKeysResolver) Filter(rn *RNode) (*RNode, error) {
+	r := &mergeKeysResolver{
+		resolving: map[*yaml.Node]bool{},
+		resolved:  map[*yaml.Node]bool{},
+	}
+	if err := r.resolve(rn.value); err != nil {
+		return nil, err
+	}
+	return rn, nil
+}
+
+// mergeKeysResolver tracks the nodes resolved by a MergeKeysResolver.
+type mergeKeysResolver struct {
+	// resolving contains the nodes which are being resolved, and is used
+	// to detect circular aliases.
+	resolving map[*yaml.Node]bool
+
+	// resolved contains the nodes which have been resolved.
+	resolved map[*yaml.Node]bool
+}
+
+// resolve resolves the aliases and merge keys of n in place.
+func (r *mergeKeysResolver) resolve(n *yaml.Node) error {
+	if n == nil || r.resolved[n] {
+		return nil
+	}
+	if r.resolving[n] {
+		return errors.Errorf("circular alias to anchor &%s", n.Anchor)
+	}
+	r.resolving[n] = true
+	defer delete(r.resolving, n)
+
+	for i := range n.Content {
+		if n.Content[i].Kind != yaml.AliasNode {
+			if err := r.resolve(n.Content[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		// resolve the anchored node before copying it, so that the copy
+		// doesn't contain any aliases
+		anchored := n.Content[i].Alias
+		if err := r.resolve(anchored); err != nil {
+			return err
+		}
+		n.Content[i] = CopyYNode(anchored)
+	}
+	if n.Kind == yaml.MappingNode {
+		if err := mergeFields(n); err != nil {
+			return err
+		}
+	}
+	n.Anchor = ""
+	r.resolved[n] = true
+	return nil
+}
+
+// mergeFields replaces the merge keys of the resolved MappingNode n with the
+// fields of the merged maps which are not already set.
+func mergeFields(n *yaml.Node) error {
+	// fields set on n take precedence over the merged fields
+	set := map[string]bool{}
+	var hasMergeKey bool
+	for i := 0; i < len(n.Content); i = IncrementFieldIndex(i) {
+		if isMergeKey(n.Content[i]) {
+			hasMergeKey = true
+		} else {
+			set[n.Content[i].Value] = true
+		}
+	}
+	if !hasMergeKey {
+		return nil
+	}
+
+	var content []*yaml.Node
+	for i := 0; i < len(n.Content); i = IncrementFieldIndex(i) {
+		key, value := n.Content[i], n.Content[i+1]
+		if !isMergeKey(key) {
+			content = append(content, key, value)
+			continue
+		}
+
+		// the merged fields replace the merge key
+		maps := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			maps = value.Content
+		}
+		for _, m := range maps {
+			if m.Kind != yaml.MappingNode {
+				return errors.Errorf(
+					"merge key %s must be a map or a list of maps, found %s",
+					key.Value, nodeTypeIndex[m.Kind])
+			}
+			for j := 0; j < len(m.Content); j = IncrementFieldIndex(j) {
+				if set[m.Content[j].Value] {
+					continue
+				}
+				set[m.Content[j].Value] = true
+				content = append(content, m.Content[j], m.Content[j+1])
+			}
+		}
+	}
+	n.Content = content
+	return nil
+}
+
+// isMergeKey returns true if the key Node is a merge key (<<).
+func isMergeKey(key *yaml.Node) bool {
+	return key.Kind == yaml.ScalarNode && key.Value == "<<" &&
+		(key.Tag == MergeTag || key.Tag == "") && key.Style == 0
+}
+
 // IsCreate returns true if kind is specified
 func IsCreate(kind yaml.Kind) bool {
 	return kind != 0
@@ -594,6 +1052,41 @@ func IsListIndex(p string) bool {
 	return strings.HasPrefix(p, "[") && strings.HasSuffix(p, "]")
 }
 
+// IsWildcard returns true if p matches all elements of a list or all fields
+// of a map.
+// e.g. *
+// e.g. [*]
+func IsWildcard(p string) bool {
+	return p == "*" || p == "[*]"
+}
+
+// IsIdxNumber returns true if p is a numeric index into a list.
+// e.g. [0]
+// e.g. [-1]
+func IsIdxNumber(p string) bool {
+	return idxNumberRegexp.MatchString(p)
+}
+
+var idxNumberRegexp = regexp.MustCompile(`^\[-?[0-9]+\]$`)
+
+// fieldIdxRegexp matches a field name with a list index or wildcard suffix.
+// e.g. containers[0]
+// e.g. containers[*]
+var fieldIdxRegexp = regexp.MustCompile(`^(.+)(\[(-?[0-9]+|\*)\])$`)
+
+// splitIndexPart splits path[i] of the form "field[N]" or "field[*]" into the
+// separate parts "field" and "[N]" or "[*]", unless rn has a field named path[i].
+// e.g. containers[0] is split, unless it is a map key of rn
+func splitIndexPart(rn *RNode, path []string, i int) []string {
+	m := fieldIdxRegexp.FindStringSubmatch(path[i])
+	if m == nil || IsListIndex(m[1]) || rn.Field(path[i]) != nil {
+		return path
+	}
+	p := append([]string{}, path[:i]...)
+	p = append(p, m[1], m[2])
+	return append(p, path[i+1:]...)
+}
+
 // SplitIndexNameValue splits a lookup part Val index into the field name
 // and field value to match.
 // e.g. splits [name=nginx] into (name, nginx)
@@ -5,6 +5,9 @@ package yaml_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -137,6 +140,129 @@ a:
 r: s
 `
 
+func TestClearFieldWithPrune(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		path     []string
+		removed  string
+		expected string
+	}{
+		{
+			name: "last-annotation",
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  annotations:
+    config.kubernetes.io/local-config: "true"
+data: {}
+`,
+			path:    []string{"metadata", "annotations", "config.kubernetes.io/local-config"},
+			removed: "\"true\"\n",
+			expected: `apiVersion: v1
+kind: ConfigMap
+data: {}
+`,
+		},
+		{
+			name: "not-last-annotation",
+			input: `metadata:
+  name: foo
+  annotations:
+    a: b
+    c: d
+`,
+			path:    []string{"metadata", "annotations", "a"},
+			removed: "b\n",
+			expected: `metadata:
+  name: foo
+  annotations:
+    c: d
+`,
+		},
+		{
+			name: "three-levels",
+			input: `a:
+  b:
+    c:
+      d: e
+  f: g
+`,
+			path:    []string{"a", "b", "c", "d"},
+			removed: "e\n",
+			expected: `a:
+  f: g
+`,
+		},
+		{
+			name: "empty-target",
+			input: `a:
+  b: {}
+  c: {}
+`,
+			path:    []string{"a", "b"},
+			removed: "{}\n",
+			expected: `a:
+  c: {}
+`,
+		},
+		{
+			name: "list-element",
+			input: `a:
+- name: b
+  c:
+    d: e
+`,
+			path:    []string{"a", "[name=b]", "c", "d"},
+			removed: "e\n",
+			expected: `a:
+- name: b
+`,
+		},
+		{
+			name: "missing-field",
+			input: `a:
+  b: {}
+`,
+			path: []string{"a", "b", "c"},
+			expected: `a:
+  b: {}
+`,
+		},
+		{
+			name: "missing-parent",
+			input: `a: {}
+`,
+			path: []string{"a", "b", "c"},
+			expected: `a: {}
+`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			node, err := Parse(test.input)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			removed, err := node.Pipe(ClearFieldWithPrune(test.path...))
+			assert.NoError(t, err)
+			assert.Equal(t, test.removed, assertNoErrorString(t)(removed.String()))
+			assert.Equal(t, test.expected, assertNoErrorString(t)(node.String()))
+		})
+	}
+
+	// without pruning the empty parents are kept
+	node, err := Parse(`a:
+  b:
+    c: d
+`)
+	assert.NoError(t, err)
+	_, err = node.Pipe(PathClearer{Path: []string{"a", "b", "c"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "a:\n  b: {}\n", assertNoErrorString(t)(node.String()))
+}
+
 func TestLookup_Fn_create(t *testing.T) {
 	// primitive
 	node, err := Parse(s)
@@ -345,6 +471,202 @@ j: k
 	assert.Nil(t, rn)
 }
 
+func TestLookup_index(t *testing.T) {
+	s := `a:
+  b:
+  - c: d
+  - c: e
+    f:
+    - g
+    - h
+  l: m
+`
+	node, err := Parse(s)
+	assert.NoError(t, err)
+
+	rn, err := node.Pipe(Lookup("a", "b", "[1]", "c"))
+	assert.NoError(t, err)
+	assert.Equal(t, "e\n", assertNoErrorString(t)(rn.String()))
+
+	// index suffix on the field name
+	rn, err = node.Pipe(Lookup("a", "b[1]", "f[0]"))
+	assert.NoError(t, err)
+	assert.Equal(t, "g\n", assertNoErrorString(t)(rn.String()))
+
+	// out of range
+	rn, err = node.Pipe(Lookup("a", "b", "[2]"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "list index [2] out of range for a.b: list has 2 elements")
+	}
+	assert.Nil(t, rn)
+
+	// negative indexes are not supported
+	rn, err = node.Pipe(Lookup("a", "b", "[-1]"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "negative list index [-1] is not supported")
+	}
+	assert.Nil(t, rn)
+
+	// index into a non-sequence
+	rn, err = node.Pipe(Lookup("a", "l", "[0]"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "wrong Node Kind")
+	}
+	assert.Nil(t, rn)
+
+	assert.Equal(t, s, assertNoErrorString(t)(node.String()))
+}
+
+func TestLookup_indexKey(t *testing.T) {
+	s := `a:
+  b[0]: c
+  d:
+  - e
+`
+	node, err := Parse(s)
+	assert.NoError(t, err)
+
+	// map keys which look like an index suffix are not split
+	rn, err := node.Pipe(Lookup("a", "b[0]"))
+	assert.NoError(t, err)
+	assert.Equal(t, "c\n", assertNoErrorString(t)(rn.String()))
+
+	matches, err := node.PipeAll(LookupAll("a", "b[0]"))
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, "c\n", assertNoErrorString(t)(matches[0].String()))
+	}
+
+	// other fields of the same map are still split
+	rn, err = node.Pipe(Lookup("a", "d[0]"))
+	assert.NoError(t, err)
+	assert.Equal(t, "e\n", assertNoErrorString(t)(rn.String()))
+
+	rn, err = node.Pipe(PathClearer{Path: []string{"a", "b[0]"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "c\n", assertNoErrorString(t)(rn.String()))
+	assert.Equal(t, `a:
+  d:
+  - e
+`, assertNoErrorString(t)(node.String()))
+}
+
+func TestLookupCreate_index(t *testing.T) {
+	node, err := Parse(`spec: {}
+`)
+	assert.NoError(t, err)
+	rn, err := node.Pipe(LookupCreate(yaml.ScalarNode, "spec", "containers", "[0]", "name"))
+	assert.NoError(t, err)
+	assert.NoError(t, rn.PipeE(Set(NewScalarRNode("nginx"))))
+	assert.Equal(t, `spec: {containers: [{name: nginx}]}
+`, assertNoErrorString(t)(node.String()))
+
+	// existing elements are not appended
+	rn, err = node.Pipe(LookupCreate(yaml.ScalarNode, "spec", "containers[0]", "name"))
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx\n", assertNoErrorString(t)(rn.String()))
+
+	// append at the end of the list
+	rn, err = node.Pipe(LookupCreate(yaml.ScalarNode, "spec", "containers", "[1]", "name"))
+	assert.NoError(t, err)
+	assert.NoError(t, rn.PipeE(Set(NewScalarRNode("sidecar"))))
+	assert.Equal(t, `spec: {containers: [{name: nginx}, {name: sidecar}]}
+`, assertNoErrorString(t)(node.String()))
+
+	// cannot create past the end of the list
+	rn, err = node.Pipe(LookupCreate(yaml.ScalarNode, "spec", "containers", "[3]", "name"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "out of range")
+	}
+	assert.Nil(t, rn)
+}
+
+func TestLookupAll(t *testing.T) {
+	s := `spec:
+  components:
+  - name: a
+    traits:
+    - trait:
+        kind: ManualScalerTrait
+    - trait:
+        kind: Other
+  - name: b
+    traits: []
+  - name: c
+    traits:
+    - trait:
+        kind: ManualScalerTrait
+  labels:
+    x: y
+    z: w
+`
+	node, err := Parse(s)
+	assert.NoError(t, err)
+
+	var values []string
+	matches, err := node.PipeAll(LookupAll("spec", "components", "*", "traits", "*", "trait", "kind"))
+	assert.NoError(t, err)
+	for i := range matches {
+		values = append(values, matches[i].YNode().Value)
+	}
+	assert.Equal(t, []string{"ManualScalerTrait", "Other", "ManualScalerTrait"}, values)
+
+	// shorthand wildcards, with filters applied to each match
+	matches, err = node.PipeAll(LookupAll("spec", "components[*]", "traits[*]", "trait"),
+		FieldMatcher{Name: "kind", StringValue: "ManualScalerTrait"})
+	assert.NoError(t, err)
+	assert.Len(t, matches, 2)
+
+	// wildcard over the fields of a map
+	values = nil
+	var paths []string
+	matches, err = node.PipeAll(LookupAll("spec", "labels", "*"))
+	assert.NoError(t, err)
+	for i := range matches {
+		values = append(values, matches[i].YNode().Value)
+		paths = append(paths, strings.Join(matches[i].FieldPath(), "."))
+	}
+	assert.Equal(t, []string{"y", "w"}, values)
+	assert.Equal(t, []string{"spec.labels.x", "spec.labels.z"}, paths)
+
+	// empty sequence
+	matches, err = node.PipeAll(LookupAll("spec", "components", "[1]", "traits", "*"))
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// missing field
+	matches, err = node.PipeAll(LookupAll("spec", "zzz", "*"))
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// wildcard on a scalar
+	matches, err = node.PipeAll(LookupAll("spec", "components", "*", "name", "*"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "wildcard * cannot be applied to ScalarNode")
+	}
+	assert.Nil(t, matches)
+
+	// set a value on each match
+	_, err = node.PipeAll(LookupAll("spec", "components", "*", "name"),
+		Set(NewScalarRNode("changed")))
+	assert.NoError(t, err)
+	values = nil
+	matches, err = node.PipeAll(LookupAll("spec", "components", "*", "name"))
+	assert.NoError(t, err)
+	for i := range matches {
+		values = append(values, matches[i].YNode().Value)
+	}
+	assert.Equal(t, []string{"changed", "changed", "changed"}, values)
+
+	// as a Filter, matches are wrapped in a SequenceNode
+	rn, err := node.Pipe(LookupAll("spec", "components", "*", "name"))
+	assert.NoError(t, err)
+	assert.Equal(t, "- changed\n- changed\n- changed\n", assertNoErrorString(t)(rn.String()))
+	rn, err = node.Pipe(LookupAll("spec", "components", "[1]", "traits", "*"))
+	assert.NoError(t, err)
+	assert.Nil(t, rn)
+}
+
 func TestSetField_Fn(t *testing.T) {
 	// Change field
 	node, err := Parse(`
@@ -437,6 +759,113 @@ foo: baz
 `, assertNoErrorString(t)(node.String()))
 }
 
+func TestSetField_Fn_keepFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		setter   FieldSetter
+		expected string
+	}{
+		{
+			name:     "single-quoted-string",
+			input:    "foo: 'baz'\n",
+			setter:   SetField("foo", NewScalarRNode("bar")),
+			expected: "foo: 'bar'\n",
+		},
+		{
+			name:     "double-quoted-string",
+			input:    "foo: \"baz\"\n",
+			setter:   SetField("foo", NewScalarRNode("bar")),
+			expected: "foo: \"bar\"\n",
+		},
+		{
+			name:     "untagged-int-into-quoted-field",
+			input:    "foo: \"1\"\n",
+			setter:   SetField("foo", NewScalarRNode("2")),
+			expected: "foo: \"2\"\n",
+		},
+		{
+			name:  "int-into-quoted-field",
+			input: "foo: \"1\"\n",
+			setter: SetField("foo", NewRNode(&yaml.Node{
+				Kind: yaml.ScalarNode, Tag: IntTag, Value: "2"})),
+			expected: "foo: 2\n",
+		},
+		{
+			name: "value-comments",
+			input: `# head comment
+foo: baz # line comment
+bar: buz
+`,
+			setter: SetField("foo", NewScalarRNode("bar")),
+			expected: `# head comment
+foo: bar # line comment
+bar: buz
+`,
+		},
+		{
+			name: "key-comments",
+			input: `# key head comment
+foo: # key line comment
+  b: c
+`,
+			setter: SetField("foo", MustParse("d: e")),
+			expected: `# key head comment
+foo: # key line comment
+  d: e
+`,
+		},
+		{
+			name:     "new-comments-win",
+			input:    "foo: baz # line comment\n",
+			setter:   SetField("foo", NewRNode(&yaml.Node{Kind: yaml.ScalarNode, Value: "bar", LineComment: "# new"})),
+			expected: "foo: bar # new\n",
+		},
+		{
+			name:  "override-style",
+			input: "foo: 'baz' # line comment\n",
+			setter: FieldSetter{
+				Name: "foo", Value: NewScalarRNode("bar"), OverrideStyle: true},
+			expected: "foo: bar\n",
+		},
+		// the styles set by OverrideStyle and by default are the same as before
+		// comments were kept
+		{
+			name:  "override-style-with-value-style",
+			input: "foo: 'baz'\n",
+			setter: FieldSetter{Name: "foo", OverrideStyle: true, Value: NewRNode(&yaml.Node{
+				Kind: yaml.ScalarNode, Value: "bar", Style: yaml.DoubleQuotedStyle})},
+			expected: "foo: \"bar\"\n",
+		},
+		{
+			name:  "existing-style-replaces-value-style",
+			input: "foo: baz\n",
+			setter: SetField("foo", NewRNode(&yaml.Node{
+				Kind: yaml.ScalarNode, Value: "bar", Style: yaml.DoubleQuotedStyle})),
+			expected: "foo: bar\n",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			node, err := Parse(test.input)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			_, err = node.Pipe(test.setter)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, assertNoErrorString(t)(node.String()))
+		})
+	}
+
+	// Set on a scalar keeps its format
+	node, err := Parse("foo: 'baz' # line comment\n")
+	assert.NoError(t, err)
+	_, err = node.Pipe(Get("foo"), Set(NewScalarRNode("bar")))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo: 'bar' # line comment\n", assertNoErrorString(t)(node.String()))
+}
+
 func TestErrorIfInvalid(t *testing.T) {
 	err := ErrorIfInvalid(
 		NewRNode(&yaml.Node{Kind: yaml.SequenceNode}), yaml.SequenceNode)
@@ -713,3 +1142,338 @@ func assertNoErrorString(t *testing.T) func(string, error) string {
 		return s
 	}
 }
+
+func TestResolveMergeKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			name: "anchored-base",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - &base
+        name: base
+        image: nginx:1.7.9
+        imagePullPolicy: Always
+      - <<: *base
+        name: sidecar
+      - name: debug
+        <<: *base
+        imagePullPolicy: IfNotPresent
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: base
+        image: nginx:1.7.9
+        imagePullPolicy: Always
+      - image: nginx:1.7.9
+        imagePullPolicy: Always
+        name: sidecar
+      - name: debug
+        image: nginx:1.7.9
+        imagePullPolicy: IfNotPresent
+`,
+		},
+		{
+			name: "merge-list",
+			input: `a: &a
+  x: 1
+  y: 1
+b: &b
+  y: 2
+  z: 2
+c:
+  <<: [*a, *b]
+  z: 3
+`,
+			expected: `a:
+  x: 1
+  y: 1
+b:
+  y: 2
+  z: 2
+c:
+  x: 1
+  y: 1
+  z: 3
+`,
+		},
+		{
+			name: "nested-merge",
+			input: `a: &a
+  x: 1
+b: &b
+  <<: *a
+  y: 2
+c:
+  <<: *b
+  z: 3
+`,
+			expected: `a:
+  x: 1
+b:
+  x: 1
+  y: 2
+c:
+  x: 1
+  y: 2
+  z: 3
+`,
+		},
+		{
+			name: "alias",
+			input: `a: &a [x, y]
+b: *a
+`,
+			expected: `a: [x, y]
+b: [x, y]
+`,
+		},
+		{
+			name: "circular",
+			input: `a: &a
+  b: *a
+`,
+			err: "circular alias to anchor &a",
+		},
+		{
+			name: "merge-scalar",
+			input: `a: &a x
+b:
+  <<: *a
+`,
+			err: "merge key << must be a map or a list of maps, found ScalarNode",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			rn := MustParse(test.input)
+			_, err := rn.Pipe(ResolveMergeKeys())
+			if test.err != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, test.err, err.Error())
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.expected, rn.MustString())
+
+			// resolving again doesn't change anything
+			_, err = rn.Pipe(ResolveMergeKeys())
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, rn.MustString())
+		})
+	}
+}
+
+func TestResolveMergeKeys_copies(t *testing.T) {
+	rn := MustParse(`containers:
+- &base
+  name: base
+  image: nginx:1.7.9
+- <<: *base
+  name: sidecar
+`)
+	_, err := rn.Pipe(ResolveMergeKeys())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the merged fields may be looked up, and modified independently
+	image, err := rn.Pipe(Lookup("containers", "[name=sidecar]", "image"))
+	if !assert.NoError(t, err) || !assert.NotNil(t, image) {
+		return
+	}
+	assert.Equal(t, "nginx:1.7.9", image.YNode().Value)
+	image.YNode().Value = "nginx:1.8"
+	assert.Equal(t, `containers:
+- name: base
+  image: nginx:1.7.9
+- image: nginx:1.8
+  name: sidecar
+`, rn.MustString())
+}
+
+func TestConditionMatcher(t *testing.T) {
+	input := `apiVersion: core.oam.dev/v1alpha2
+kind: ManualScalerTrait
+metadata:
+  name: example-appconfig-trait
+spec:
+  replicaCount: 1
+`
+	tests := []struct {
+		name     string
+		filter   ConditionMatcher
+		expected string
+	}{
+		{
+			name:     "equals",
+			filter:   IfFieldEquals("kind", "ManualScalerTrait"),
+			expected: "replicaCount: 3",
+		},
+		{
+			name:     "not-equals",
+			filter:   IfFieldEquals("kind", "HorizontalPodAutoscalerTrait"),
+			expected: "replicaCount: 1",
+		},
+		{
+			name:     "matches",
+			filter:   IfFieldMatches("apiVersion", `^core\.oam\.dev/`),
+			expected: "replicaCount: 3",
+		},
+		{
+			name:     "not-matches",
+			filter:   IfFieldMatches("apiVersion", `^apps/`),
+			expected: "replicaCount: 1",
+		},
+		{
+			name:     "present",
+			filter:   IfFieldPresent("spec"),
+			expected: "replicaCount: 3",
+		},
+		{
+			name:     "not-present",
+			filter:   IfFieldPresent("status"),
+			expected: "replicaCount: 1",
+		},
+		{
+			name:     "path",
+			filter:   ConditionMatcher{Path: []string{"metadata", "name"}, Value: "example-appconfig-trait"},
+			expected: "replicaCount: 3",
+		},
+		{
+			name:     "non-scalar",
+			filter:   IfFieldEquals("metadata", "example-appconfig-trait"),
+			expected: "replicaCount: 1",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			rn := MustParse(input)
+			err := rn.PipeE(test.filter, Lookup("spec", "replicaCount"), Set(NewScalarRNode("3")))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Contains(t, rn.MustString(), test.expected)
+		})
+	}
+}
+
+func TestConditionMatcher_missing(t *testing.T) {
+	rn := MustParse(`spec:
+  replicaCount: 1
+`)
+	// a failed Lookup before the match doesn't match
+	match, err := rn.Pipe(Lookup("spec", "template"), IfFieldPresent("spec"))
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+
+	match, err = IfFieldPresent("spec").Filter(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+
+	_, err = rn.Pipe(IfFieldMatches("spec", "["))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ConditionMatcher Regex does not compile")
+	}
+}
+
+func TestSetters_keepStyle(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters func(rn *RNode) error
+	}{
+		{
+			name: "flow-map",
+			filters: func(rn *RNode) error {
+				labels := Lookup("metadata", "labels")
+				resources := Lookup("spec", "template", "spec", "containers", "[name=nginx]", "resources")
+				return rn.PipeE(Tee(labels, SetField("tier", NewScalarRNode("frontend"))),
+					Tee(labels, SetField("version", NewScalarRNode("v1"))),
+					Tee(resources, Lookup("requests"), SetField("cpu", NewScalarRNode("200m"))),
+					Tee(resources, Lookup("requests"), SetField("memory", NewScalarRNode("1Gi"))),
+					Tee(resources, LookupCreate(MappingNode, "limits"),
+						SetField("cpu", NewScalarRNode("500m"))))
+			},
+		},
+		{
+			name: "flow-sequence",
+			filters: func(rn *RNode) error {
+				container := Lookup("spec", "template", "spec", "containers", "[name=nginx]")
+				return rn.PipeE(Tee(container, Lookup("args"), Append(NewScalarRNode("--verbose").YNode())),
+					Tee(container, Lookup("ports"), ElementSetter{
+						Key: "name", Value: "http",
+						Element: MustParse("name: http\ncontainerPort: 8080\n").YNode()}),
+					Tee(container, Lookup("ports"), ElementSetter{
+						Key: "name", Value: "https",
+						Element: MustParse("name: https\ncontainerPort: 443\n").YNode()}))
+			},
+		},
+		{
+			name: "mixed",
+			filters: func(rn *RNode) error {
+				containers := Lookup("spec", "template", "spec", "containers")
+				return rn.PipeE(
+					Tee(Lookup("spec", "selector", "matchLabels", "app"), Set(NewScalarRNode("apache"))),
+					Tee(containers, ElementSetter{
+						Key: "name", Value: "nginx",
+						Element: MustParse("name: nginx\nimage: nginx:1.8\n").YNode()}),
+					Tee(containers, Lookup("[name=sidecar]"),
+						SetField("image", NewScalarRNode("sidecar:1.1"))),
+					Tee(containers, Lookup("[name=sidecar]", "env", "[name=MODE]"),
+						SetField("value", NewScalarRNode("release"))))
+			},
+		},
+		{
+			name: "multi-line",
+			filters: func(rn *RNode) error {
+				script := NewScalarRNode("set -e\necho setup\n")
+				script.YNode().Style = LiteralStyle
+				return rn.PipeE(Lookup("data"), SetField("setup.sh", script))
+			},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "style", test.name)
+			input, err := ioutil.ReadFile(filepath.Join(dir, "input.yaml"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			expected, err := ioutil.ReadFile(filepath.Join(dir, "expected.yaml"))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			// the input is unchanged by a round trip
+			rn := MustParse(string(input))
+			assert.Equal(t, string(input), rn.MustString())
+
+			if !assert.NoError(t, test.filters(rn)) {
+				return
+			}
+			assert.Equal(t, string(expected), rn.MustString())
+		})
+	}
+}
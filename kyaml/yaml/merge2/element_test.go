@@ -461,4 +461,122 @@ containers: # {"items":{"$ref": "#/definitions/io.k8s.api.core.v1.Container"},"t
 `,
 		infer: false,
 	},
+
+	//
+	// Test Case
+	//
+	{description: `delete Element -- $patch: delete keeps the order of the other elements`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: bar
+        $patch: delete
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:v1
+      - name: bar
+        image: bar:v1
+      - name: baz
+        image: baz:v1
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:v1
+      - name: baz
+        image: baz:v1
+`,
+	},
+
+	//
+	// Test Case
+	//
+	{description: `delete Element -- $patch: delete missing from dest`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: bar
+        $patch: delete
+      - name: foo
+        image: foo:v2
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:v1
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:v2
+`,
+	},
+
+	//
+	// Test Case
+	//
+	{description: `replace List -- $patch: replace`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: bar
+        image: bar:v1
+      - $patch: replace
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:v1
+        command: ['run.sh']
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: bar
+        image: bar:v1
+`,
+	},
 }
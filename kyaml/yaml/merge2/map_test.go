@@ -181,6 +181,59 @@ spec:
 `,
 		expected: `
 kind: Deployment
+`,
+	},
+
+	{description: `delete Map -- $patch: delete`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  strategy:
+    $patch: delete
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 1
+  strategy:
+    type: Recreate
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 1
+`,
+	},
+
+	{description: `replace Map -- $patch: replace`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  selector:
+    $patch: replace
+    matchLabels:
+      app: bar
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  selector:
+    matchLabels:
+      app: foo
+      tier: web
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  selector:
+    matchLabels:
+      app: bar
 `,
 	},
 }
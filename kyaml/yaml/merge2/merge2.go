@@ -40,7 +40,20 @@ func MergeStrings(srcStr, destStr string, infer bool) (string, error) {
 }
 
 type Merger struct {
-	// for forwards compatibility when new functions are added to the interface
+	// MergeKeys maps the paths of list fields to the keys their elements are merged
+	// by, for lists whose merge key isn't in the schema -- e.g. the lists of custom
+	// resources.  See walk.Walker.MergeKeys.
+	MergeKeys map[string]string
+}
+
+// Merge merges fields from src into dest, merging the elements of the lists in
+// m.MergeKeys by their keys.
+func (m Merger) Merge(src, dest *yaml.RNode) (*yaml.RNode, error) {
+	return walk.Walker{
+		Sources:   []*yaml.RNode{dest, src},
+		Visitor:   m,
+		MergeKeys: m.MergeKeys,
+	}.Walk()
 }
 
 var _ walk.Visitor = Merger{}
@@ -52,6 +65,18 @@ func (m Merger) VisitMap(nodes walk.Sources, s *openapi.ResourceSchema) (*yaml.R
 	if err := m.SetStyle(nodes); err != nil {
 		return nil, err
 	}
+	directive, err := walk.RemovePatchDirective(nodes.Origin())
+	if err != nil {
+		return nil, err
+	}
+	switch directive {
+	case walk.PatchDelete:
+		// delete the value -- a missing value is left missing
+		return walk.ClearNode, nil
+	case walk.PatchReplace:
+		// replace the value rather than merging it
+		return nodes.Origin(), nil
+	}
 	if yaml.IsEmpty(nodes.Dest()) {
 		// Add
 		return nodes.Origin(), nil
@@ -86,6 +111,14 @@ func (m Merger) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind wa
 	if err := m.SetStyle(nodes); err != nil {
 		return nil, err
 	}
+	directive, err := walk.RemovePatchDirective(nodes.Origin())
+	if err != nil {
+		return nil, err
+	}
+	if directive == walk.PatchReplace {
+		// replace the list with the other elements rather than merging them
+		return nodes.Origin(), nil
+	}
 	if kind == walk.NonAssociateList {
 		// Override value
 		if nodes.Origin() != nil {
@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 	. "sigs.k8s.io/kustomize/kyaml/yaml/merge2"
 )
 
@@ -49,3 +51,122 @@ type testCase struct {
 	expected    string
 	infer       bool
 }
+
+const (
+	routesSource = `apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 8080
+  - host: c.example.com
+    port: 80
+`
+	routesDest = `apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 80
+    timeout: 10s
+  - host: b.example.com
+    port: 80
+`
+	// the elements are merged by host rather than replacing the list
+	routesExpected = `apiVersion: example.com/v1
+kind: Router
+metadata:
+  name: router
+spec:
+  routes:
+  - host: a.example.com
+    port: 8080
+    timeout: 10s
+  - host: b.example.com
+    port: 80
+  - host: c.example.com
+    port: 80
+`
+)
+
+func TestMerger_MergeKeys(t *testing.T) {
+	result, err := Merger{MergeKeys: map[string]string{"spec.routes": "host"}}.Merge(
+		yaml.MustParse(routesSource), yaml.MustParse(routesDest))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, routesExpected, result.MustString())
+
+	// without the merge keys the list is replaced
+	result, err = Merge(yaml.MustParse(routesSource), yaml.MustParse(routesDest))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, routesSource, result.MustString())
+}
+
+func TestMerge_crdSchema(t *testing.T) {
+	openapi.ResetOpenAPI()
+	defer openapi.ResetOpenAPI()
+	err := openapi.AddSchemaFromCRD(yaml.MustParse(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: routers.example.com
+spec:
+  group: example.com
+  names:
+    kind: Router
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              routes:
+                type: array
+                x-kubernetes-patch-merge-key: host
+                x-kubernetes-patch-strategy: merge
+                items:
+                  type: object
+                  properties:
+                    host:
+                      type: string
+                    port:
+                      type: integer
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	result, err := Merge(yaml.MustParse(routesSource), yaml.MustParse(routesDest))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, routesExpected, result.MustString())
+}
+
+func TestMerge_unknownPatchDirective(t *testing.T) {
+	_, err := MergeStrings(`
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  strategy:
+    $patch: remove
+`, `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  strategy:
+    type: Recreate
+`, false)
+	if assert.Error(t, err) {
+		assert.Equal(t, `unknown patch directive "remove"`, err.Error())
+	}
+}
@@ -897,4 +897,111 @@ spec:
 `,
 		infer: false,
 	},
+
+	//
+	// Test Case
+	//
+	{description: `Delete an element with $patch: delete in the update`,
+		origin: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:1
+      - name: bar
+        image: bar:1
+      - name: baz
+        image: baz:1
+`,
+		update: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:1
+      - name: bar
+        $patch: delete
+      - name: baz
+        image: baz:1
+`,
+		local: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:1
+      - name: bar
+        image: bar:1
+      - name: baz
+        image: baz:2
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:1
+      - name: baz
+        image: baz:2
+`},
+
+	//
+	// Test Case
+	//
+	{description: `Replace a list with $patch: replace in the update`,
+		origin: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:1
+`,
+		update: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: bar
+        image: bar:1
+      - $patch: replace
+`,
+		local: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:1
+      - name: baz
+        image: baz:1
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: bar
+        image: bar:1
+`},
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 	. "sigs.k8s.io/kustomize/kyaml/yaml/merge3"
 )
 
@@ -49,3 +50,50 @@ type testCase struct {
 	err         error
 	infer       bool
 }
+
+func TestVisitor_MergeKeys(t *testing.T) {
+	original := yaml.MustParse(`apiVersion: example.com/v1
+kind: Router
+spec:
+  routes:
+  - host: a.example.com
+    port: 80
+  - host: b.example.com
+    port: 80
+`)
+	update := yaml.MustParse(`apiVersion: example.com/v1
+kind: Router
+spec:
+  routes:
+  - host: a.example.com
+    port: 8080
+  - host: b.example.com
+    port: 80
+`)
+	dest := yaml.MustParse(`apiVersion: example.com/v1
+kind: Router
+spec:
+  routes:
+  - host: a.example.com
+    port: 80
+  - host: b.example.com
+    port: 80
+    timeout: 10s
+`)
+	result, err := Visitor{MergeKeys: map[string]string{"spec.routes": "host"}}.Merge(
+		dest, original, update)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	// the local timeout is kept rather than replaced by the updated list
+	assert.Equal(t, `apiVersion: example.com/v1
+kind: Router
+spec:
+  routes:
+  - host: a.example.com
+    port: 8080
+  - host: b.example.com
+    port: 80
+    timeout: 10s
+`, result.MustString())
+}
@@ -16,9 +16,35 @@ const (
 	TakeUpdate ConflictStrategy = 1 + iota
 )
 
-type Visitor struct{}
+type Visitor struct {
+	// MergeKeys maps the paths of list fields to the keys their elements are merged
+	// by, for lists whose merge key isn't in the schema -- e.g. the lists of custom
+	// resources.  See walk.Walker.MergeKeys.
+	MergeKeys map[string]string
+}
+
+// Merge performs a 3-way merge of original and update into dest, merging the
+// elements of the lists in m.MergeKeys by their keys.
+func (m Visitor) Merge(dest, original, update *yaml.RNode) (*yaml.RNode, error) {
+	return walk.Walker{
+		Visitor:   m,
+		MergeKeys: m.MergeKeys,
+		Sources:   []*yaml.RNode{dest, original, update}}.Walk()
+}
 
 func (m Visitor) VisitMap(nodes walk.Sources, s *openapi.ResourceSchema) (*yaml.RNode, error) {
+	directive, err := m.removePatchDirectives(nodes)
+	if err != nil {
+		return nil, err
+	}
+	switch directive {
+	case walk.PatchDelete:
+		// explicitly deleted from update
+		return walk.ClearNode, nil
+	case walk.PatchReplace:
+		// replaced in update rather than merged
+		return nodes.Updated(), nil
+	}
 	if yaml.IsNull(nodes.Updated()) || yaml.IsNull(nodes.Dest()) {
 		// explicitly cleared from either dest or update
 		return walk.ClearNode, nil
@@ -105,6 +131,14 @@ func (m Visitor) visitNAList(nodes walk.Sources) (*yaml.RNode, error) {
 }
 
 func (m Visitor) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind walk.ListKind) (*yaml.RNode, error) {
+	directive, err := m.removePatchDirectives(nodes)
+	if err != nil {
+		return nil, err
+	}
+	if directive == walk.PatchReplace {
+		// replaced in update rather than merged
+		return nodes.Updated(), nil
+	}
 	if kind == walk.AssociativeList {
 		return m.visitAList(nodes, s)
 	}
@@ -112,6 +146,22 @@ func (m Visitor) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind w
 	return m.visitNAList(nodes)
 }
 
+// removePatchDirectives removes the strategic merge patch directives from the
+// sources, and returns the directive of the update.
+func (m Visitor) removePatchDirectives(nodes walk.Sources) (string, error) {
+	var directive string
+	for i := range nodes {
+		d, err := walk.RemovePatchDirective(nodes[i])
+		if err != nil {
+			return "", err
+		}
+		if i == walk.UpdatedIndex {
+			directive = d
+		}
+	}
+	return directive, nil
+}
+
 func (m Visitor) getStrValues(nodes walk.Sources) (strValues, error) {
 	var uStr, oStr, dStr string
 	var err error
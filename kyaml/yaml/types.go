@@ -8,8 +8,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"strconv"
 	"strings"
 
+	goerrors "github.com/go-errors/errors"
+	y1_1 "gopkg.in/yaml.v2"
 	"gopkg.in/yaml.v3"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/sets"
@@ -449,6 +452,47 @@ func (rn *RNode) PipeE(functions ...Filter) error {
 	return errors.Wrap(err)
 }
 
+// MultiFilter defines a function which returns multiple RNodes, such as
+// every element of a list matched by a wildcard path.
+type MultiFilter interface {
+	FilterAll(object *RNode) ([]*RNode, error)
+}
+
+// PipeAll invokes the MultiFilter, and then Pipes each of the results through
+// functions.
+//
+// * returns the non-nil results of the functions for each match
+// * if the MultiFilter or any function returns an error, immediately return the error
+// * if the MultiFilter doesn't match anything, return an empty slice
+func (rn *RNode) PipeAll(filter MultiFilter, functions ...Filter) ([]*RNode, error) {
+	// check if rn is nil to make chaining Pipe calls easier
+	if rn == nil {
+		return nil, nil
+	}
+
+	v := rn
+	if rn.value != nil && rn.value.Kind == yaml.DocumentNode {
+		// the first node may be a DocumentNode containing a single MappingNode
+		v = &RNode{value: rn.value.Content[0]}
+	}
+	matches, err := filter.FilterAll(v)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	results := []*RNode{}
+	for i := range matches {
+		r, err := matches[i].Pipe(functions...)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if r != nil {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
 // Document returns the Node RNode for the value.  Does not unwrap the node if it is a
 // DocumentNodes
 func (rn *RNode) Document() *yaml.Node {
@@ -476,17 +520,79 @@ func (rn *RNode) SetYNode(node *yaml.Node) {
 	*rn.value = *node
 }
 
+// Copy returns a deep copy of the RNode.  The copy may be modified without
+// modifying rn.
+func (rn *RNode) Copy() *RNode {
+	if rn == nil {
+		return nil
+	}
+	return &RNode{
+		fieldPath: append([]string(nil), rn.fieldPath...),
+		value:     CopyYNode(rn.value),
+		Match:     append([]string(nil), rn.Match...),
+	}
+}
+
+// CopyYNode returns a deep copy of the yaml.Node, including its comments,
+// styles, tags and anchors.  Aliases to anchors within the node are
+// pointed at the copied anchors.
+func CopyYNode(n *yaml.Node) *yaml.Node {
+	return copyYNode(n, map[*yaml.Node]*yaml.Node{})
+}
+
+// copyYNode copies n, recording each copied node in copies so that aliases
+// may be resolved to the copy of their anchor.
+func copyYNode(n *yaml.Node, copies map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if c, found := copies[n]; found {
+		return c
+	}
+	c := *n
+	copies[n] = &c
+	if n.Content != nil {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i := range n.Content {
+			c.Content[i] = copyYNode(n.Content[i], copies)
+		}
+	}
+	// anchors are defined before their aliases, so the alias will normally
+	// resolve to an already copied node
+	c.Alias = copyYNode(n.Alias, copies)
+	return &c
+}
+
 // AppendToFieldPath appends a field name to the FieldPath.
 func (rn *RNode) AppendToFieldPath(parts ...string) {
 	rn.fieldPath = append(rn.fieldPath, parts...)
 }
 
 // FieldPath returns the field path from the Resource root node, to rn.
-// Does not include list indexes.
+// Does not include list indexes, except for the elements visited by
+// VisitElementsIndexed.
 func (rn *RNode) FieldPath() []string {
 	return rn.fieldPath
 }
 
+// joinFieldPath joins the parts of a FieldPath with ".", appending list indexes
+// to the preceding field -- e.g. spec.components[2].traits
+func joinFieldPath(path []string) string {
+	var b strings.Builder
+	for i := range path {
+		if i > 0 && !IsIdxNumber(path[i]) {
+			b.WriteString(".")
+		}
+		b.WriteString(path[i])
+	}
+	return b.String()
+}
+
+// childFieldPath returns a copy of the FieldPath of rn with part appended.
+func (rn *RNode) childFieldPath(part string) []string {
+	return append(append([]string{}, rn.FieldPath()...), part)
+}
+
 const (
 	Trim = "Trim"
 	Flow = "Flow"
@@ -537,6 +643,98 @@ func (rn *RNode) MustString() string {
 	return s
 }
 
+// GetString returns the value of a ScalarNode.
+// Returns an error if rn is missing, null or not a ScalarNode.
+func (rn *RNode) GetString() (string, error) {
+	if err := rn.errorIfNotScalar("string"); err != nil {
+		return "", err
+	}
+	return rn.YNode().Value, nil
+}
+
+// GetInt64 returns the value of a ScalarNode as an integer.
+// Returns an error if rn is missing, null, not a ScalarNode, tagged as a
+// non-integer type, or if its value doesn't parse as a decimal integer.
+func (rn *RNode) GetInt64() (int64, error) {
+	if err := rn.errorIfNotScalar("integer", IntTag); err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(rn.YNode().Value, 10, 64)
+	if err != nil {
+		return 0, rn.typeError("integer")
+	}
+	return i, nil
+}
+
+// GetFloat64 returns the value of a ScalarNode as a float.
+// Returns an error if rn is missing, null, not a ScalarNode, tagged as a
+// non-numeric type, or if its value doesn't parse as a number.
+func (rn *RNode) GetFloat64() (float64, error) {
+	if err := rn.errorIfNotScalar("float", IntTag, FloatTag); err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(rn.YNode().Value, 64)
+	if err != nil {
+		return 0, rn.typeError("float")
+	}
+	return f, nil
+}
+
+// GetBool returns the value of a ScalarNode as a bool.
+// Both the yaml 1.2 (true / false) and yaml 1.1 (e.g. yes / no, on / off)
+// spellings are accepted, consistent with IsYaml1_1NonString.
+// Returns an error if rn is missing, null, not a ScalarNode, tagged as a
+// non-bool type, or if its value isn't a bool keyword.
+func (rn *RNode) GetBool() (bool, error) {
+	if err := rn.errorIfNotScalar("bool", BoolTag); err != nil {
+		return false, err
+	}
+	// parse the value with a yaml 1.1 parser, which accepts a superset of the
+	// yaml 1.2 bool keywords
+	var b interface{}
+	if err := y1_1.Unmarshal([]byte(rn.YNode().Value), &b); err == nil {
+		if v, ok := b.(bool); ok {
+			return v, nil
+		}
+	}
+	return false, rn.typeError("bool")
+}
+
+// errorIfNotScalar returns an error if rn isn't a ScalarNode, or if rn has
+// a tag other than !!str or one of tags.
+func (rn *RNode) errorIfNotScalar(expected string, tags ...string) error {
+	if IsMissingOrNull(rn) {
+		return rn.fieldError("expected %s, got null", expected)
+	}
+	if rn.YNode().Kind != yaml.ScalarNode {
+		return rn.fieldError("expected %s, got %s", expected, nodeTypeIndex[rn.YNode().Kind])
+	}
+	tag := rn.YNode().Tag
+	if tag == "" || tag == StringTag || len(tags) == 0 {
+		return nil
+	}
+	for i := range tags {
+		if tag == tags[i] {
+			return nil
+		}
+	}
+	return rn.fieldError("expected %s, got %s %q", expected, tag, rn.YNode().Value)
+}
+
+// typeError returns an error for a scalar value which doesn't parse as the
+// expected type.
+func (rn *RNode) typeError(expected string) error {
+	return rn.fieldError("expected %s, got %q", expected, rn.YNode().Value)
+}
+
+// fieldError returns an error prefixed with the FieldPath of rn.
+func (rn *RNode) fieldError(msg string, args ...interface{}) error {
+	if rn == nil || len(rn.FieldPath()) == 0 {
+		return errors.Errorf(msg, args...)
+	}
+	return errors.Wrap(&fieldPathError{path: rn.FieldPath(), err: fmt.Errorf(msg, args...)})
+}
+
 // Content returns Node Content field.
 func (rn *RNode) Content() []*yaml.Node {
 	if rn == nil {
@@ -595,6 +793,8 @@ const (
 	StringTag = "!!str"
 	BoolTag   = "!!bool"
 	IntTag    = "!!int"
+	FloatTag  = "!!float"
+	MergeTag  = "!!merge"
 )
 
 // Elements returns the list of elements in the RNode.
@@ -656,6 +856,89 @@ func (rn *RNode) VisitElements(fn func(node *RNode) error) error {
 	return nil
 }
 
+// VisitElementsIndexed calls fn with the index of each element in a
+// SequenceNode.  The FieldPath of each element is the FieldPath of rn
+// followed by its index -- e.g. [spec components [2]].
+// Errors returned by fn are prefixed with the path of the element.
+// Returns an error for non-SequenceNodes.
+func (rn *RNode) VisitElementsIndexed(fn func(i int, node *RNode) error) error {
+	elements, err := rn.Elements()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	for i := range elements {
+		elements[i].fieldPath = rn.childFieldPath(fmt.Sprintf("[%d]", i))
+		if err := fn(i, elements[i]); err != nil {
+			return wrapVisitError(elements[i].FieldPath(), err)
+		}
+	}
+	return nil
+}
+
+// VisitFieldsWithPath calls fn with the path of each field in the RNode.
+// The path, which is also set as the FieldPath of the field Value, is the
+// FieldPath of rn followed by the field name.  When called on nodes visited
+// by VisitElementsIndexed or VisitFieldsWithPath, the path includes the
+// path of the outer visit.
+// Errors returned by fn are prefixed with the path of the field.
+// Returns an error for non-MappingNodes.
+func (rn *RNode) VisitFieldsWithPath(fn func(path []string, node *MapNode) error) error {
+	fieldNames, err := rn.Fields()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	for _, fieldName := range fieldNames {
+		field := rn.Field(fieldName)
+		field.Value.fieldPath = rn.childFieldPath(fieldName)
+		if err := fn(field.Value.FieldPath(), field); err != nil {
+			return wrapVisitError(field.Value.FieldPath(), err)
+		}
+	}
+	return nil
+}
+
+// fieldPathError is an error for the field at path.
+type fieldPathError struct {
+	path []string
+	err  error
+}
+
+func (e *fieldPathError) Error() string {
+	return fmt.Sprintf("field %s: %v", joinFieldPath(e.path), e.err)
+}
+
+// wrapVisitError prefixes err with path, unless err is already for a field
+// under path -- e.g. it was returned by a nested visit.
+func wrapVisitError(path []string, err error) error {
+	cause := err
+	for {
+		goerr, ok := cause.(*goerrors.Error)
+		if !ok {
+			break
+		}
+		cause = goerr.Err
+	}
+	if fe, ok := cause.(*fieldPathError); ok && hasPathPrefix(fe.path, path) {
+		return errors.Wrap(err)
+	}
+	return errors.Wrap(&fieldPathError{path: path, err: err})
+}
+
+// hasPathPrefix returns true if path starts with prefix.
+func hasPathPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // AssociativeSequenceKeys is a map of paths to sequences that have associative keys.
 // The order sets the precedence of the merge keys -- if multiple keys are present
 // in Resources in a list, then the FIRST key which ALL elements in the list have is used as the
@@ -4,10 +4,12 @@
 package yaml
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 )
 
 // Test that non-UTF8 characters in comments don't cause failures
@@ -123,3 +125,253 @@ hello: world
 		t.FailNow()
 	}
 }
+
+func TestRNode_GetScalars(t *testing.T) {
+	rn := MustParse(`spec:
+  replicas: 3
+  quotedReplicas: "3"
+  invalidReplicas: abc
+  ratio: 0.5
+  enabled: true
+  legacyEnabled: "yes"
+  disabled: Off
+  taggedString: !!str 3
+  taggedBool: !!bool true
+  name: nginx
+  containers: []
+  empty: null
+`)
+	get := func(field string) *RNode {
+		v, err := rn.Pipe(Lookup("spec", field))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return v
+	}
+
+	i, err := get("replicas").GetInt64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), i)
+	i, err = get("quotedReplicas").GetInt64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), i)
+	i, err = get("taggedString").GetInt64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), i)
+	_, err = get("invalidReplicas").GetInt64()
+	assert.EqualError(t, err, `field spec.invalidReplicas: expected integer, got "abc"`)
+	_, err = get("ratio").GetInt64()
+	assert.EqualError(t, err, `field spec.ratio: expected integer, got !!float "0.5"`)
+	_, err = get("taggedBool").GetInt64()
+	assert.EqualError(t, err, `field spec.taggedBool: expected integer, got !!bool "true"`)
+	_, err = get("containers").GetInt64()
+	assert.EqualError(t, err, `field spec.containers: expected integer, got SequenceNode`)
+	_, err = get("empty").GetInt64()
+	assert.EqualError(t, err, `field spec.empty: expected integer, got null`)
+	_, err = get("missing").GetInt64()
+	assert.EqualError(t, err, `expected integer, got null`)
+
+	f, err := get("ratio").GetFloat64()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+	f, err = get("replicas").GetFloat64()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), f)
+	_, err = get("name").GetFloat64()
+	assert.EqualError(t, err, `field spec.name: expected float, got "nginx"`)
+
+	b, err := get("enabled").GetBool()
+	assert.NoError(t, err)
+	assert.True(t, b)
+	b, err = get("legacyEnabled").GetBool()
+	assert.NoError(t, err)
+	assert.True(t, b)
+	b, err = get("disabled").GetBool()
+	assert.NoError(t, err)
+	assert.False(t, b)
+	_, err = get("replicas").GetBool()
+	assert.EqualError(t, err, `field spec.replicas: expected bool, got !!int "3"`)
+	_, err = get("name").GetBool()
+	assert.EqualError(t, err, `field spec.name: expected bool, got "nginx"`)
+
+	s, err := get("name").GetString()
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx", s)
+	s, err = get("replicas").GetString()
+	assert.NoError(t, err)
+	assert.Equal(t, "3", s)
+	_, err = get("containers").GetString()
+	assert.EqualError(t, err, `field spec.containers: expected string, got SequenceNode`)
+}
+
+const copyInput = `# head comment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app # line comment
+  labels: &labels
+    app: nginx
+spec:
+  selector:
+    matchLabels: *labels
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: 'nginx:1.7.9'
+        args: [--port, "8080"]
+`
+
+func TestRNode_Copy(t *testing.T) {
+	rn := MustParse(copyInput)
+	rn.AppendToFieldPath("spec")
+	c := rn.Copy()
+
+	// the copy must be identical to the original
+	assert.Equal(t, rn.MustString(), c.MustString())
+	assert.Equal(t, rn.FieldPath(), c.FieldPath())
+
+	// aliases must refer to the copied anchors
+	labels, err := c.Pipe(Lookup("metadata", "labels"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	matchLabels, err := c.Pipe(Lookup("spec", "selector", "matchLabels"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Same(t, labels.YNode(), matchLabels.YNode().Alias)
+
+	// modifying the copy must not modify the original
+	assert.NoError(t, c.PipeE(SetField("kind", NewScalarRNode("StatefulSet"))))
+	assert.NoError(t, labels.PipeE(SetField("app", NewScalarRNode("apache"))))
+	c.YNode().HeadComment = "# changed"
+	c.AppendToFieldPath("template")
+	assert.Equal(t, copyInput, rn.MustString())
+	assert.Equal(t, []string{"spec"}, rn.FieldPath())
+	assert.Contains(t, c.MustString(), "kind: StatefulSet")
+	assert.Contains(t, c.MustString(), "app: apache")
+
+	assert.Nil(t, (*RNode)(nil).Copy())
+	assert.Nil(t, CopyYNode(nil))
+}
+
+func BenchmarkRNode_Copy(b *testing.B) {
+	rn := MustParse(copyInput)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = rn.Copy()
+	}
+}
+
+func BenchmarkRNode_CopyStringParse(b *testing.B) {
+	rn := MustParse(copyInput)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s, err := rn.String()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Parse(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRNode_VisitElementsIndexed(t *testing.T) {
+	rn := MustParse(`spec:
+  components:
+  - componentName: a
+  - componentName: b
+  - componentName: c
+    traits:
+    - trait: {kind: ManualScalerTrait}
+    - trait: foo
+`)
+	components, err := rn.Pipe(Lookup("spec", "components"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var visited []string
+	err = components.VisitElementsIndexed(func(i int, node *RNode) error {
+		visited = append(visited, fmt.Sprintf("%d %s", i, joinFieldPath(node.FieldPath())))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"0 spec.components[0]",
+		"1 spec.components[1]",
+		"2 spec.components[2]",
+	}, visited)
+
+	// errors from nested visits are prefixed with the full path
+	err = components.VisitElementsIndexed(func(_ int, node *RNode) error {
+		traits, err := node.Pipe(Lookup("traits"))
+		if err != nil || traits == nil {
+			return err
+		}
+		return traits.VisitElementsIndexed(func(_ int, node *RNode) error {
+			return node.VisitFieldsWithPath(func(path []string, node *MapNode) error {
+				if node.Value.YNode().Kind != MappingNode {
+					return errors.Errorf("expected a map")
+				}
+				return nil
+			})
+		})
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "field spec.components[2].traits[1].trait: expected a map", err.Error())
+	}
+
+	// errors are prefixed with the index if there is no FieldPath
+	err = MustParse(`[a, b]`).VisitElementsIndexed(func(i int, node *RNode) error {
+		if i == 1 {
+			return errors.Errorf("bad element")
+		}
+		return nil
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "field [1]: bad element", err.Error())
+	}
+
+	err = MustParse(`a: b`).VisitElementsIndexed(func(int, *RNode) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestRNode_VisitFieldsWithPath(t *testing.T) {
+	rn := MustParse(`metadata:
+  name: foo
+  labels:
+    app: nginx
+`)
+	var visited []string
+	err := rn.VisitFieldsWithPath(func(path []string, node *MapNode) error {
+		if node.Value.YNode().Kind != MappingNode {
+			return nil
+		}
+		return node.Value.VisitFieldsWithPath(func(path []string, node *MapNode) error {
+			visited = append(visited, joinFieldPath(path))
+			assert.Equal(t, path, node.Value.FieldPath())
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"metadata.name", "metadata.labels"}, visited)
+
+	// fields looked up from a visited field include its path
+	err = rn.VisitFieldsWithPath(func(path []string, node *MapNode) error {
+		name, err := node.Value.Pipe(Lookup("name"))
+		if err != nil {
+			return err
+		}
+		_, err = name.GetInt64()
+		return err
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, `field metadata.name: expected integer, got "foo"`, err.Error())
+	}
+
+	err = MustParse(`[a, b]`).VisitFieldsWithPath(func([]string, *MapNode) error { return nil })
+	assert.Error(t, err)
+}
@@ -19,8 +19,8 @@ func (l *Walker) walkAssociativeSequence() (*yaml.RNode, error) {
 		return nil, err
 	}
 
-	var key string
-	if l.Schema != nil {
+	key := l.mergeKey()
+	if key == "" && l.Schema != nil {
 		_, key = l.Schema.PatchStrategyAndKey()
 	}
 	if key == "" { // no key from the schema, try to infer one
@@ -42,9 +42,11 @@ func (l *Walker) walkAssociativeSequence() (*yaml.RNode, error) {
 		val, err := Walker{
 			VisitKeysAsScalars:    l.VisitKeysAsScalars,
 			InferAssociativeLists: l.InferAssociativeLists,
+			MergeKeys:             l.MergeKeys,
 			Visitor:               l,
 			Schema:                s,
 			Sources:               l.elementValue(key, value),
+			Path:                  l.Path,
 		}.Walk()
 		if err != nil {
 			return nil, err
@@ -65,8 +67,10 @@ func (l *Walker) walkAssociativeSequence() (*yaml.RNode, error) {
 			}
 		}
 
-		// this handles empty and non-empty values
-		_, err = dest.Pipe(yaml.ElementSetter{Element: val.YNode(), Key: key, Value: value})
+		// this handles empty and non-empty values -- the walked value already
+		// has the merged comments and style
+		_, err = dest.Pipe(yaml.ElementSetter{
+			Element: val.YNode(), Key: key, Value: value, OverrideStyle: true})
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,67 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package walk
+
+import (
+	"github.com/go-errors/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// PatchDirectiveField is the field containing the strategic merge patch directive of a
+// map, e.g. `$patch: delete`.  The directive of a list is in an element containing only
+// the field, e.g. `- $patch: replace`.
+const PatchDirectiveField = "$patch"
+
+// Strategic merge patch directives
+const (
+	// PatchDelete deletes the map, or the matching element of an associative list.
+	PatchDelete = "delete"
+	// PatchReplace replaces the map or list instead of merging it.
+	PatchReplace = "replace"
+	// PatchMerge merges the map or list, which is the default.
+	PatchMerge = "merge"
+)
+
+// RemovePatchDirective removes the strategic merge patch directive from the map or list
+// node, so that it isn't merged as a field or element, and returns it.  It returns ""
+// if node doesn't have a directive.
+func RemovePatchDirective(node *yaml.RNode) (string, error) {
+	if yaml.IsEmpty(node) {
+		return "", nil
+	}
+
+	var directive string
+	switch node.YNode().Kind {
+	case yaml.MappingNode:
+		field := node.Field(PatchDirectiveField)
+		if field == nil {
+			return "", nil
+		}
+		directive = yaml.GetValue(field.Value)
+		if _, err := node.Pipe(yaml.Clear(PatchDirectiveField)); err != nil {
+			return "", err
+		}
+	case yaml.SequenceNode:
+		// keep the order of the other elements
+		var content []*yaml.Node
+		for _, elem := range node.Content() {
+			if elem.Kind == yaml.MappingNode && len(elem.Content) == 2 &&
+				elem.Content[0].Value == PatchDirectiveField {
+				directive = elem.Content[1].Value
+				continue
+			}
+			content = append(content, elem)
+		}
+		node.YNode().Content = content
+	default:
+		return "", nil
+	}
+
+	switch directive {
+	case "", PatchDelete, PatchReplace, PatchMerge:
+		return directive, nil
+	default:
+		return "", errors.Errorf("unknown patch directive %q", directive)
+	}
+}
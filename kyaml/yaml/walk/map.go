@@ -64,6 +64,7 @@ func (l Walker) walkMap() (*yaml.RNode, error) {
 		val, err := Walker{
 			VisitKeysAsScalars:    l.VisitKeysAsScalars,
 			InferAssociativeLists: l.InferAssociativeLists,
+			MergeKeys:             l.MergeKeys,
 			Visitor:               l,
 			Schema:                s,
 			Sources:               fv,
@@ -73,7 +74,8 @@ func (l Walker) walkMap() (*yaml.RNode, error) {
 		}
 
 		// this handles empty and non-empty values
-		_, err = dest.Pipe(yaml.FieldSetter{Name: key, Value: val})
+		// the walked value already has the merged comments and style
+		_, err = dest.Pipe(yaml.FieldSetter{Name: key, Value: val, OverrideStyle: true})
 		if err != nil {
 			return nil, err
 		}
@@ -36,6 +36,12 @@ type Walker struct {
 	// VisitKeysAsScalars if true will call VisitScalar on map entry keys,
 	// providing nil as the OpenAPI schema.
 	VisitKeysAsScalars bool
+
+	// MergeKeys maps the paths of list fields to the keys their elements are merged
+	// by, e.g. `spec.rules` to `host`.  The paths are the field names joined by `.`,
+	// and don't contain the list elements -- e.g. `spec.rules.http.paths` for the
+	// paths of each rule.  MergeKeys take precedence over the schema.
+	MergeKeys map[string]string
 }
 
 func (l Walker) Kind() yaml.Kind {
@@ -62,7 +68,8 @@ func (l Walker) Walk() (*yaml.RNode, error) {
 		if err := yaml.ErrorIfAnyInvalidAndNonNull(yaml.SequenceNode, l.Sources...); err != nil {
 			return nil, err
 		}
-		if schema.IsAssociative(l.Schema, l.Sources, l.InferAssociativeLists) {
+		if l.mergeKey() != "" ||
+			schema.IsAssociative(l.Schema, l.Sources, l.InferAssociativeLists) {
 			return l.walkAssociativeSequence()
 		}
 		return l.walkNonAssociativeSequence()
@@ -80,6 +87,11 @@ func (l Walker) Walk() (*yaml.RNode, error) {
 	}
 }
 
+// mergeKey returns the key from MergeKeys for the list at Path, or "" if it has none.
+func (l Walker) mergeKey() string {
+	return l.MergeKeys[strings.Join(l.Path, ".")]
+}
+
 func (l Walker) GetSchema() *openapi.ResourceSchema {
 	for i := range l.Sources {
 		r := l.Sources[i]
@@ -4,8 +4,12 @@
 package main_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"sigs.k8s.io/kustomize/api/image"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
 )
 
@@ -429,4 +433,70 @@ spec:
       - image: some.registry.io/my-image:my-fixed-tag
         name: my-image
 `)
-}
\ No newline at end of file
+}
+func TestImageTagTransformerResolveDigest(t *testing.T) {
+	requests := 0
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v2/app/manifests/v2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", "sha256:2222")
+		w.Write([]byte(`{}`))
+	}))
+	defer s.Close()
+	defer func(c *http.Client) { image.DefaultDigestResolver.Client = c }(
+		image.DefaultDigestResolver.Client)
+	image.DefaultDigestResolver.Client = s.Client()
+	host := strings.TrimPrefix(s.URL, "https://")
+
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ImageTagTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ImageTagTransformer
+metadata:
+  name: notImportantHere
+imageTag:
+  name: `+host+`/app
+  newTag: v2
+  resolveDigest: true
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy1
+spec:
+  template:
+    spec:
+      containers:
+      - image: `+host+`/app:v1
+        name: app
+      initContainers:
+      - image: `+host+`/app
+        name: init
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy1
+spec:
+  template:
+    spec:
+      containers:
+      - image: `+host+`/app@sha256:2222
+        name: app
+      initContainers:
+      - image: `+host+`/app@sha256:2222
+        name: init
+`)
+	// the digest is only queried once
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"sort"
 
 	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/yaml"
 )
 
 // Sort the resources using an ordering defined in the Gvk class.
@@ -17,21 +19,37 @@ import (
 // dependencies (like Namespace, StorageClass, etc.)
 // first, and resources with a high number of dependencies
 // (like ValidatingWebhookConfiguration) last.
-type plugin struct{}
+// The kinds of OrderFirst and OrderLast, if any are set,
+// are sorted first and last instead.
+type plugin struct {
+	OrderFirst []string `json:"orderFirst,omitempty" yaml:"orderFirst,omitempty"`
+	OrderLast  []string `json:"orderLast,omitempty" yaml:"orderLast,omitempty"`
+}
 
 //noinspection GoUnusedGlobalVariable
 var KustomizePlugin plugin
 
-// Nothing needed for configuration.
 func (p *plugin) Config(
-	_ *resmap.PluginHelpers, _ []byte) (err error) {
-	return nil
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.OrderFirst = nil
+	p.OrderLast = nil
+	return yaml.Unmarshal(c, p)
 }
 
 func (p *plugin) Transform(m resmap.ResMap) (err error) {
 	resources := make([]*resource.Resource, m.Size())
 	ids := m.AllIds()
-	sort.Sort(resmap.IdSlice(ids))
+	if len(p.OrderFirst) == 0 && len(p.OrderLast) == 0 {
+		sort.Sort(resmap.IdSlice(ids))
+	} else {
+		order := resid.NewKindOrder(p.OrderFirst, p.OrderLast)
+		sort.Slice(ids, func(i, j int) bool {
+			if !ids[i].Gvk.Equals(ids[j].Gvk) {
+				return order.IsLessThan(ids[i].Gvk, ids[j].Gvk)
+			}
+			return ids[i].String() < ids[j].String()
+		})
+	}
 	for i, id := range ids {
 		resources[i], err = m.GetByCurrentId(id)
 		if err != nil {
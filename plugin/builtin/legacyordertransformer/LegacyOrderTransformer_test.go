@@ -112,3 +112,74 @@ metadata:
   name: pomegranate
 `)
 }
+
+func TestLegacyOrderTransformerOrderFirstAndLast(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("LegacyOrderTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: LegacyOrderTransformer
+metadata:
+  name: notImportantHere
+orderFirst:
+- Secret
+- Namespace
+orderLast:
+- Role
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: v1
+kind: Role
+metadata:
+  name: banana
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apricot
+`)
+
+	// the other kinds are sorted by their gvk
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apricot
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: v1
+kind: Role
+metadata:
+  name: banana
+`)
+}
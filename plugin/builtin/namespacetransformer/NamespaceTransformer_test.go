@@ -228,6 +228,86 @@ fieldSpecs:
 `, noChangeExpected, noChangeExpected)
 }
 
+func TestNamespaceTransformerUnsetOnlyAndExclude(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("NamespaceTransformer")
+	defer th.Reset()
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: NamespaceTransformer
+metadata:
+  name: notImportantHere
+  namespace: test
+unsetOnly: true
+exclude:
+- kind: ConfigMap
+  name: excluded
+fieldSpecs:
+- path: metadata/namespace
+  create: true
+- path: subjects
+  kind: ClusterRoleBinding
+  group: rbac.authorization.k8s.io
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unset
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: excluded
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: set
+  namespace: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: crb1
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: default
+- kind: ServiceAccount
+  name: default
+  namespace: other
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unset
+  namespace: test
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: excluded
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: set
+  namespace: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: crb1
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: test
+- kind: ServiceAccount
+  name: default
+  namespace: other
+`)
+}
+
 func TestNamespaceTransformerObjectConflict(t *testing.T) {
 	th := kusttest_test.MakeEnhancedHarness(t).
 		PrepBuiltin("NamespaceTransformer")
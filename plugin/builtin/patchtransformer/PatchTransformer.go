@@ -21,6 +21,7 @@ type plugin struct {
 	Path         string          `json:"path,omitempty" yaml:"path,omitempty"`
 	Patch        string          `json:"patch,omitempty" yaml:"patch,omitempty"`
 	Target       *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+	Options      map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 //noinspection GoUnusedGlobalVariable
@@ -42,6 +43,13 @@ func (p *plugin) Config(
 			"patch and path can't be set at the same time\n%s", string(c))
 		return
 	}
+	for option := range p.Options {
+		if option != types.AllowNoTargetMatch {
+			err = fmt.Errorf(
+				"unknown option %s in\n%s", option, string(c))
+			return
+		}
+	}
 	var in []byte
 	if p.Path != "" {
 		in, err = h.Loader().Load(p.Path)
@@ -95,6 +103,11 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	if err != nil {
 		return err
 	}
+	if len(resources) == 0 && !p.Options[types.AllowNoTargetMatch] {
+		return fmt.Errorf(
+			"no resource matches the target %v of patch %s; "+
+				"set the %s option to allow it", *p.Target, p.patchName(), types.AllowNoTargetMatch)
+	}
 	for _, res := range resources {
 		if p.decodedPatch != nil {
 			rawObj, err := res.MarshalJSON()
@@ -125,6 +138,14 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	return nil
 }
 
+// patchName returns the path of the patch, if it isn't inline.
+func (p *plugin) patchName() string {
+	if p.Path != "" {
+		return p.Path
+	}
+	return "(inline)"
+}
+
 // jsonPatchFromBytes loads a Json 6902 patch from
 // a bytes input
 func jsonPatchFromBytes(
@@ -127,6 +127,46 @@ patch: '[{"op": "add", "path": "/spec/template/spec/dnsPolicy", "value": "Cluste
 	}
 }
 
+func TestPatchTransformerNoTargetMatch(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PatchTransformer")
+	defer th.Reset()
+
+	_, err := th.RunTransformer(`
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+patch: '[{"op": "add", "path": "/spec/template/spec/dnsPolicy", "value": "ClusterFirst"}]'
+target:
+  kind: Deployment
+  labelSelector: missing-label=value
+`, target)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.Contains(err.Error(),
+		"no resource matches the target ~G_~V_Deployment labelSelector=missing-label=value") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	_, err = th.RunTransformer(`
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+patch: '[{"op": "add", "path": "/spec/template/spec/dnsPolicy", "value": "ClusterFirst"}]'
+target:
+  kind: Deployment
+  labelSelector: missing-label=value
+options:
+  allowNoTargetMatch: true
+`, target)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
 func TestPatchTransformerBothEmptyPathAndPatch(t *testing.T) {
 	th := kusttest_test.MakeEnhancedHarness(t).
 		PrepBuiltin("PatchTransformer")
@@ -21,6 +21,12 @@ type plugin struct {
 	Prefix     string            `json:"prefix,omitempty" yaml:"prefix,omitempty"`
 	Suffix     string            `json:"suffix,omitempty" yaml:"suffix,omitempty"`
 	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// Include if not empty selects the only resources which are renamed.
+	Include []resid.Gvk `json:"include,omitempty" yaml:"include,omitempty"`
+
+	// Exclude selects the resources which aren't renamed.
+	Exclude []resid.Gvk `json:"exclude,omitempty" yaml:"exclude,omitempty"`
 }
 
 //noinspection GoUnusedGlobalVariable
@@ -41,6 +47,8 @@ func (p *plugin) Config(
 	p.Prefix = ""
 	p.Suffix = ""
 	p.FieldSpecs = nil
+	p.Include = nil
+	p.Exclude = nil
 	err = yaml.Unmarshal(c, p)
 	if err != nil {
 		return
@@ -60,7 +68,7 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	for _, r := range m.Resources() {
 		if p.shouldSkip(r.OrgId()) {
 			// Don't change the actual definition
-			// of a CRD.
+			// of a CRD, nor the excluded resources.
 			continue
 		}
 		id := r.OrgId()
@@ -110,7 +118,20 @@ func (p *plugin) shouldSkip(
 			return true
 		}
 	}
-	return false
+	for i := range p.Exclude {
+		if id.IsSelected(&p.Exclude[i]) {
+			return true
+		}
+	}
+	if len(p.Include) == 0 {
+		return false
+	}
+	for i := range p.Include {
+		if id.IsSelected(&p.Include[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func (p *plugin) addPrefixSuffix(
@@ -140,3 +140,67 @@ metadata:
   name: cm
 `)
 }
+
+func TestPrefixSuffixTransformerIncludeExclude(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PrefixSuffixTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: PrefixSuffixTransformer
+metadata:
+  name: notImportantHere
+prefix: dev-
+fieldSpecs:
+  - path: metadata/name
+include:
+  - kind: Deployment
+  - kind: Service
+exclude:
+  - kind: Service
+    version: v2
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: v2
+kind: Service
+metadata:
+  name: next
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dev-app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: dev-app
+---
+apiVersion: v2
+kind: Service
+metadata:
+  name: next
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+}
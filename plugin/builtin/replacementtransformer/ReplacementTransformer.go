@@ -0,0 +1,314 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Copy the value of a field of one resource, or a literal
+// value, into fields of the other resources.
+type plugin struct {
+	Replacements []types.Replacement `json:"replacements,omitempty" yaml:"replacements,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Replacements = []types.Replacement{}
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	for _, r := range p.Replacements {
+		if r.Source == nil {
+			return fmt.Errorf("`source` must be specified in one replacement")
+		}
+		if (r.Source.ObjRef == nil) == (r.Source.Value == "") {
+			return fmt.Errorf(
+				"exactly one of objref and value must be specified in one replacement source")
+		}
+		targets := r.AllTargets()
+		if len(targets) == 0 {
+			return fmt.Errorf("`targets` must be specified in one replacement")
+		}
+		for _, t := range targets {
+			if t.ObjRef == nil || len(t.FieldRefs) == 0 {
+				return fmt.Errorf(
+					"objref and fieldrefs must be specified in one replacement target")
+			}
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) (err error) {
+	for _, r := range p.Replacements {
+		var replacement interface{} = r.Source.Value
+		if r.Source.ObjRef != nil {
+			replacement, err = getReplacement(m, r.Source.ObjRef, r.Source.FieldRef)
+			if err != nil {
+				return err
+			}
+		}
+		for _, t := range r.AllTargets() {
+			err = substitute(m, t, replacement)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getReplacement returns the value of the fieldRef of the one
+// resource matching objRef, which defaults to its name.
+func getReplacement(
+	m resmap.ResMap, objRef *types.Target, fieldRef string) (interface{}, error) {
+	resources, err := m.Select(types.Selector{
+		Gvk:       objRef.GVK(),
+		Name:      objRef.Name,
+		Namespace: objRef.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) > 1 {
+		return nil, fmt.Errorf(
+			"found more than one resource matching replacement source %v", *objRef)
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf(
+			"failed to find one resource matching replacement source %v", *objRef)
+	}
+	if fieldRef == "" {
+		fieldRef = "metadata.name"
+	}
+	value, found, err := getField(resources[0].Map(), parseFieldRef(fieldRef), 0)
+	if err != nil {
+		return nil, fmt.Errorf("replacement source %s in %s: %v",
+			fieldRef, resources[0].CurId(), err)
+	}
+	if !found {
+		return nil, fmt.Errorf("replacement source %s not found in %s",
+			fieldRef, resources[0].CurId())
+	}
+	return value, nil
+}
+
+// substitute sets the fieldRefs of the resources matching
+// the target to the replacement.
+func substitute(
+	m resmap.ResMap, target *types.ReplTarget, replacement interface{}) error {
+	resources, err := m.Select(*target.ObjRef)
+	if err != nil {
+		return err
+	}
+	opts := target.Options
+	if opts == nil {
+		opts = &types.ReplOptions{}
+	}
+	for _, r := range resources {
+		for _, fieldRef := range target.FieldRefs {
+			err = setField(r.Map(), parseFieldRef(fieldRef), 0, opts,
+				func(in interface{}) (interface{}, error) {
+					return replace(in, replacement, opts)
+				})
+			if err != nil {
+				return fmt.Errorf("replacement target %s in %s: %v",
+					fieldRef, r.CurId(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// replace returns the replacement for the value in of a target
+// field, or the value with only the part at the Options Index
+// replaced if the Options have a Delimiter.
+func replace(
+	in interface{}, replacement interface{}, opts *types.ReplOptions) (interface{}, error) {
+	if opts.Delimiter == "" {
+		return replacement, nil
+	}
+	value, ok := replacement.(string)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%#v is expected to be a string to replace a delimited part", replacement)
+	}
+	s, ok := in.(string)
+	if !ok && in != nil {
+		return nil, fmt.Errorf("%#v is expected to be a string to split", in)
+	}
+	parts := strings.Split(s, opts.Delimiter)
+	if opts.Index < 0 || opts.Index >= len(parts) {
+		return nil, fmt.Errorf("index %d is out of bounds of %q split by %q",
+			opts.Index, s, opts.Delimiter)
+	}
+	parts[opts.Index] = value
+	return strings.Join(parts, opts.Delimiter), nil
+}
+
+// parseFieldRef splits a fieldRef such as
+// `spec.containers[name=app].image` into its parts, where
+// the elements of a list are selected by `[key=value]` or
+// by their index, e.g. `[0]`.
+func parseFieldRef(fieldRef string) []string {
+	var result []string
+	start := 0
+	inBrackets := false
+	for i, c := range fieldRef {
+		switch {
+		case c == '.' && !inBrackets:
+			if i > start {
+				result = append(result, fieldRef[start:i])
+			}
+			start = i + 1
+		case c == '[' && !inBrackets:
+			if i > start {
+				result = append(result, fieldRef[start:i])
+			}
+			start = i
+			inBrackets = true
+		case c == ']' && inBrackets:
+			result = append(result, fieldRef[start:i+1])
+			start = i + 1
+			inBrackets = false
+		}
+	}
+	if start < len(fieldRef) {
+		result = append(result, fieldRef[start:])
+	}
+	return result
+}
+
+func isElementSelector(part string) bool {
+	return strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]")
+}
+
+// selectElements returns the indexes of the elements of list
+// selected by part, which is either `[key=value]` or an index.
+func selectElements(list []interface{}, part string) ([]int, error) {
+	selector := part[1 : len(part)-1]
+	if i, err := strconv.Atoi(selector); err == nil {
+		if i < 0 || i >= len(list) {
+			return nil, nil
+		}
+		return []int{i}, nil
+	}
+	kv := strings.SplitN(selector, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return nil, fmt.Errorf("%s is expected to be [key=value] or an index", part)
+	}
+	var result []int
+	for i := range list {
+		item, ok := list[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, found := item[kv[0]]; found && fmt.Sprint(v) == kv[1] {
+			result = append(result, i)
+		}
+	}
+	return result, nil
+}
+
+// getField returns the value of the field at path[i:] under node,
+// using the first element selected in lists.
+func getField(
+	node interface{}, path []string, i int) (interface{}, bool, error) {
+	if i == len(path) {
+		return node, true, nil
+	}
+	part := path[i]
+	if isElementSelector(part) {
+		list, ok := node.([]interface{})
+		if !ok {
+			return nil, false, fmt.Errorf(
+				"%s is expected to be a list", strings.Join(path[:i], "."))
+		}
+		indexes, err := selectElements(list, part)
+		if err != nil || len(indexes) == 0 {
+			return nil, false, err
+		}
+		return getField(list[indexes[0]], path, i+1)
+	}
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf(
+			"%s is expected to be a map", strings.Join(path[:i], "."))
+	}
+	v, found := m[part]
+	if !found {
+		return nil, false, nil
+	}
+	return getField(v, path, i+1)
+}
+
+// setField sets the field at path[i:] under node to the value
+// returned by f for its current value, in every element selected
+// in lists.  The missing fields are only created with the Create
+// option, and the resources without them are skipped otherwise.
+func setField(
+	node interface{}, path []string, i int, opts *types.ReplOptions,
+	f func(interface{}) (interface{}, error)) error {
+	part := path[i]
+	last := i == len(path)-1
+	if isElementSelector(part) {
+		list, ok := node.([]interface{})
+		if !ok {
+			return fmt.Errorf(
+				"%s is expected to be a list", strings.Join(path[:i], "."))
+		}
+		indexes, err := selectElements(list, part)
+		if err != nil {
+			return err
+		}
+		for _, j := range indexes {
+			if last {
+				list[j], err = f(list[j])
+			} else {
+				err = setField(list[j], path, i+1, opts, f)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf(
+			"%s is expected to be a map", strings.Join(path[:i], "."))
+	}
+	v, found := m[part]
+	if !found || v == nil {
+		// lists can't be created, since there aren't any elements to select
+		if !opts.Create || (!last && isElementSelector(path[i+1])) {
+			return nil
+		}
+		if !last {
+			v = map[string]interface{}{}
+			m[part] = v
+		}
+	}
+	if last {
+		value, err := f(v)
+		if err != nil {
+			return err
+		}
+		m[part] = value
+		return nil
+	}
+	return setField(v, path, i+1, opts, f)
+}
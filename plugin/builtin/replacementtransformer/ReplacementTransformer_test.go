@@ -0,0 +1,220 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const replacementInput = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  registry: registry.example.com
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: docker.io/app:1.0
+        args:
+        - --config
+        - config:v1
+      - name: sidecar
+        image: sidecar:1.0
+`
+
+func TestReplacementTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ReplacementTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ReplacementTransformer
+metadata:
+  name: notImportantHere
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: config
+    fieldref: data.replicas
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.replicas
+    options:
+      create: true
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.missing
+- source:
+    value: v2
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[1].image
+    - spec.template.spec.containers[name=app].args[1]
+    options:
+      delimiter: ':'
+      index: 1
+- source:
+    objref:
+      kind: ConfigMap
+      name: config
+    fieldref: data.registry
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].image
+    options:
+      delimiter: /
+      index: 0
+`, replacementInput)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+data:
+  registry: registry.example.com
+  replicas: 3
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - args:
+        - --config
+        - config:v2
+        image: registry.example.com/app:1.0
+        name: app
+      - image: sidecar:v2
+        name: sidecar
+`)
+}
+
+func TestReplacementTransformerErrors(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ReplacementTransformer")
+	defer th.Reset()
+
+	for config, expected := range map[string]string{
+		`
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: config
+    fieldref: data.missing
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.replicas
+`: "replacement source data.missing not found in ~G_v1_ConfigMap|~X|config",
+		`
+replacements:
+- source:
+    objref:
+      kind: Secret
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.replicas
+`: "failed to find one resource matching replacement source",
+		`
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: config
+    fieldref: data.replicas
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].image
+    options:
+      delimiter: ':'
+`: "replacement target spec.template.spec.containers[name=app].image in " +
+			"apps_v1_Deployment|~X|app: 3 is expected to be a string",
+		`
+replacements:
+- source:
+    value: v2
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].image
+    options:
+      delimiter: ':'
+      index: 2
+`: `index 2 is out of bounds of "docker.io/app:1.0" split by ":"`,
+		`
+replacements:
+- source:
+    value: v2
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[app].image
+`: "[app] is expected to be [key=value] or an index",
+		`
+replacements:
+- source:
+    value: v2
+`: "`targets` must be specified in one replacement",
+		`
+replacements:
+- source:
+    value: v2
+    objref:
+      kind: ConfigMap
+  targets:
+  - objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.replicas
+`: "exactly one of objref and value must be specified in one replacement source",
+	} {
+		err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: ReplacementTransformer
+metadata:
+  name: notImportantHere
+`+config, replacementInput)
+		if err == nil {
+			t.Fatalf("expected an error for %s", config)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("unexpected error: %q, expected %q", err, expected)
+		}
+	}
+}
@@ -8,6 +8,9 @@
 package main_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 
@@ -86,3 +89,105 @@ spec:
   type: LoadBalancer
 `)
 }
+
+// writeChart writes a local chart named app, and the files
+// of its values, to a new directory.
+func writeChart(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "chartinflator-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"charts/app/Chart.yaml": `
+apiVersion: v1
+name: app
+version: 0.1.0
+`,
+		"charts/app/values.yaml": `
+greeting: hello
+image:
+  repository: app
+  tag: v1
+ports:
+- 80
+`,
+		"charts/app/templates/configmap.yaml": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-app
+data:
+  greeting: {{ .Values.greeting }}
+  image: {{ .Values.image.repository }}:{{ .Values.image.tag }}
+  ports: {{ join "," .Values.ports | quote }}
+  kubeVersion: {{ printf "%s.%s" .Capabilities.KubeVersion.Major .Capabilities.KubeVersion.Minor | quote }}
+  {{- if .Capabilities.APIVersions.Has "monitoring.coreos.com/v1" }}
+  monitoring: "true"
+  {{- end }}
+`,
+		"base.yaml": `
+greeting: hi
+`,
+		"prod.yaml": `
+greeting: hi prod
+image:
+  tag: v2
+ports:
+- 80
+- 443
+`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// The additional values files, then the inline values, are
+// layered over the values file: the maps are deep-merged,
+// and the lists replaced.
+func TestChartInflatorLayeredValues(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepExecPlugin("someteam.example.com", "v1", "ChartInflator")
+	defer th.Reset()
+	dir := writeChart(t)
+	defer os.RemoveAll(dir)
+
+	m := th.LoadAndRunGenerator(`
+apiVersion: someteam.example.com/v1
+kind: ChartInflator
+metadata:
+  name: notImportantHere
+chartName: app
+chartHome: ` + filepath.Join(dir, "charts") + `
+values: ` + filepath.Join(dir, "base.yaml") + `
+additionalValuesFiles:
+- ` + filepath.Join(dir, "prod.yaml") + `
+valuesInline:
+  image:
+    tag: v3
+  ports:
+  - 8080
+apiVersions:
+- monitoring.coreos.com/v1
+kubeVersion: 1.16.0
+`)
+
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  greeting: hi prod
+  image: app:v3
+  kubeVersion: "1.16"
+  monitoring: "true"
+  ports: "8080"
+kind: ConfigMap
+metadata:
+  name: release-name-app
+`)
+}